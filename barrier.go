@@ -0,0 +1,111 @@
+// @Author Clover
+// @Data 2026/10/16 下午3:00:00
+// @Desc 等待已输出的日志写入完成
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// defaultDrainTimeout Config.DrainTimeout 的默认值
+const defaultDrainTimeout = 5 * time.Second
+
+var drainTimeout time.Duration // Drain 的等待时限，由 stateMu 保护
+
+// Barrierer 可以等待已提交日志全部写入完成的输出目标
+// 异步或带缓冲的输出目标（例如 redissink.Sink）应实现该接口
+type Barrierer interface {
+	Barrier(ctx context.Context) error
+}
+
+// pendingWriter 可以报告尚未完成写入数量的输出目标
+type pendingWriter interface {
+	Pending() uint64
+}
+
+// Barrier 阻塞直到调用前输出的所有日志都已交给各输出目标：
+// 异步写入完成、缓冲区已刷新、日志文件已同步到磁盘。
+// ctx 结束时返回描述未完成输出目标的错误
+func Barrier(ctx context.Context) error {
+	stateMu.RLock()
+	writers, lf, path, outs := activeWriters, logfile, logPath, outputs
+	if !fileOutput {
+		lf = nil
+	}
+	stateMu.RUnlock()
+
+	var stragglers []string
+	for _, w := range writers {
+		if err := barrierWriter(ctx, w); err != nil {
+			desc, inner := fmt.Sprintf("%T", w), w
+			if tw, ok := w.(*trackedWriter); ok {
+				desc, inner = tw.name, tw.w
+			}
+			if pw, ok := inner.(pendingWriter); ok {
+				desc += fmt.Sprintf(" (%d pending)", pw.Pending())
+			}
+			stragglers = append(stragglers, fmt.Sprintf("%s: %v", desc, err))
+		}
+	}
+	if err := spills.Barrier(ctx); err != nil {
+		stragglers = append(stragglers, fmt.Sprintf("deadline spills (%d pending): %v", spills.Pending(), err))
+	}
+	if lf != nil {
+		if err := lf.Sync(); err != nil {
+			stragglers = append(stragglers, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	for _, o := range outs {
+		if err := o.file.Sync(); err != nil {
+			stragglers = append(stragglers, fmt.Sprintf("%s: %v", o.config.Path, err))
+		}
+	}
+	if len(stragglers) > 0 {
+		return fmt.Errorf("barrier incomplete: %s", strings.Join(stragglers, "; "))
+	}
+	return nil
+}
+
+// barrierWriter 等待单个输出目标完成写入，未实现 Barrierer 的输出目标视为同步写入
+func barrierWriter(ctx context.Context, w io.Writer) error {
+	if b, ok := w.(Barrierer); ok {
+		return b.Barrier(ctx)
+	}
+	return nil
+}
+
+// Drain 用于优雅退出：阻塞直到已提交给异步输出目标（DiodeMode、redissink.Sink 等）的日志全部写入，
+// 或超过 Config.DrainTimeout；没有异步输出目标时直接返回 nil。未能全部写入时返回描述剩余日志的错误
+func Drain() error {
+	stateMu.RLock()
+	writers, timeout := activeWriters, drainTimeout
+	stateMu.RUnlock()
+	async := spills.Pending() > 0
+	for _, w := range writers {
+		if _, ok := queueDepth(w); ok {
+			async = true
+		}
+	}
+	if !async {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := Barrier(ctx); err != nil {
+		return fmt.Errorf("drain incomplete after %v: %w", timeout, err)
+	}
+	return nil
+}
+
+// Drain 与包级别的 Drain 相同，等待已输出的日志写入异步输出目标；缓冲区中尚未 Flush 的日志不受影响
+func (lb *LogBuffer) Drain() error {
+	return Drain()
+}
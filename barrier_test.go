@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBarrier(t *testing.T) {
+	slow := &slowWriter{delay: 50 * time.Millisecond}
+	InitLogger(Config{
+		LogPath:          filepath.Join(t.TempDir(), "barrier.log"),
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+	}, WithWriteTimeout(5*time.Millisecond), WithWriters(slow))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+
+	// 写入超时后调用方立即返回，Barrier 需要等待后台写入完成
+	Info("first")
+	Info("second")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	err := Barrier(ctx)
+	cancel()
+	if err == nil || !strings.Contains(err.Error(), "pending") {
+		t.Errorf("expected straggler error on context expiry, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Barrier(ctx); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	// 超时前已开始的写入在后台完成，仍在排队的写入已写入备用输出，不再写入输出目标
+	if out := slow.buf.String(); strings.Count(out, "\n") != 1 {
+		t.Errorf("barrier should wait for the in-flight write and skip abandoned ones: %q", out)
+	}
+}
@@ -0,0 +1,190 @@
+// @Author Clover
+// @Data 2026/10/17 上午10:50:00
+// @Desc 将大量相似记录汇总为一条日志
+
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultBatchFirstExamples   = 5
+	defaultBatchSampledExamples = 5
+	defaultBatchMaxValues       = 20
+	batchOtherValue             = "_other" // 超出不同值数量上限的值计入该项
+)
+
+// BatchOption 用于配置 Batch
+type BatchOption func(*batchOptions)
+
+type batchOptions struct {
+	firstExamples   int           // 保留的前几条记录
+	sampledExamples int           // 其余记录中蓄水池抽样保留的条数
+	groupBy         []string      // 统计取值频率的字段
+	maxValues       int           // 每个字段最多统计的不同取值数
+	flushSize       int           // 累计多少条记录后自动输出，0 表示不自动输出
+	flushInterval   time.Duration // 自动输出的间隔，0 表示不定时输出
+}
+
+// WithBatchExamples 设置 examples 中保留的前 first 条记录与从其余记录中随机抽样的 sampled 条记录
+func WithBatchExamples(first, sampled int) BatchOption {
+	return func(o *batchOptions) {
+		o.firstExamples, o.sampledExamples = first, sampled
+	}
+}
+
+// WithBatchGroupBy 统计指定字段的取值频率
+func WithBatchGroupBy(keys ...string) BatchOption {
+	return func(o *batchOptions) {
+		o.groupBy = append(o.groupBy, keys...)
+	}
+}
+
+// WithBatchMaxValues 设置每个字段最多统计的不同取值数，超出的取值计入 _other
+func WithBatchMaxValues(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.maxValues = n
+	}
+}
+
+// WithBatchFlushSize 累计 n 条记录后自动输出
+func WithBatchFlushSize(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.flushSize = n
+	}
+}
+
+// WithBatchFlushInterval 每隔 d 自动输出一次，需要调用 Close 停止
+func WithBatchFlushInterval(d time.Duration) BatchOption {
+	return func(o *batchOptions) {
+		o.flushInterval = d
+	}
+}
+
+// Batch 汇总大量相似的记录，Flush 时输出一条包含 count、examples 与 group_by 的日志，
+// 无论添加多少条记录，占用的内存都有上限
+type Batch struct {
+	msg   string
+	level zerolog.Level
+	opts  batchOptions
+
+	mu       sync.Mutex
+	count    int
+	first    []map[string]interface{}
+	sampled  []map[string]interface{}
+	rest     int                       // 超出前几条之外的记录数，用于蓄水池抽样
+	groups   map[string]map[string]int // 字段 -> 取值 -> 次数
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewBatch 创建以 level 级别输出消息 msg 的汇总日志
+func NewBatch(msg string, level zerolog.Level, opts ...BatchOption) *Batch {
+	b := &Batch{
+		msg:   msg,
+		level: level,
+		opts: batchOptions{
+			firstExamples:   defaultBatchFirstExamples,
+			sampledExamples: defaultBatchSampledExamples,
+			maxValues:       defaultBatchMaxValues,
+		},
+	}
+	for _, opt := range opts {
+		opt(&b.opts)
+	}
+	b.reset()
+	if b.opts.flushInterval > 0 {
+		b.done = make(chan struct{})
+		go b.run(currentClock().NewTicker(b.opts.flushInterval))
+	}
+	return b
+}
+
+// Add 添加一条记录
+func (b *Batch) Add(fields map[string]interface{}) {
+	b.mu.Lock()
+	b.count++
+	if len(b.first) < b.opts.firstExamples {
+		b.first = append(b.first, fields)
+	} else if b.opts.sampledExamples > 0 {
+		b.rest++
+		if len(b.sampled) < b.opts.sampledExamples {
+			b.sampled = append(b.sampled, fields)
+		} else if j := currentRand().Intn(b.rest); j < b.opts.sampledExamples {
+			b.sampled[j] = fields
+		}
+	}
+	for _, key := range b.opts.groupBy {
+		v, ok := fields[key]
+		if !ok {
+			continue
+		}
+		values := b.groups[key]
+		value := fmt.Sprint(v)
+		if _, seen := values[value]; !seen && len(values) >= b.opts.maxValues {
+			value = batchOtherValue
+		}
+		values[value]++
+	}
+	full := b.opts.flushSize > 0 && b.count >= b.opts.flushSize
+	b.mu.Unlock()
+
+	if full {
+		b.Flush()
+	}
+}
+
+// Flush 输出当前汇总并清空，没有记录时不输出
+func (b *Batch) Flush() {
+	b.mu.Lock()
+	if b.count == 0 {
+		b.mu.Unlock()
+		return
+	}
+	fields := map[string]interface{}{
+		"count":    b.count,
+		"examples": append(b.first, b.sampled...),
+	}
+	if len(b.opts.groupBy) > 0 {
+		fields["group_by"] = b.groups
+	}
+	b.reset()
+	b.mu.Unlock()
+
+	emit(currentLogger().WithLevel(b.level), b.level, nil, b.msg, []map[string]interface{}{fields})
+}
+
+// Close 停止定时输出并输出剩余的汇总
+func (b *Batch) Close() {
+	if b.done != nil {
+		b.stopOnce.Do(func() { close(b.done) })
+	}
+	b.Flush()
+}
+
+// reset 清空汇总状态，调用方需持有 b.mu
+func (b *Batch) reset() {
+	b.count, b.rest = 0, 0
+	b.first, b.sampled = nil, nil
+	b.groups = make(map[string]map[string]int, len(b.opts.groupBy))
+	for _, key := range b.opts.groupBy {
+		b.groups[key] = make(map[string]int)
+	}
+}
+
+func (b *Batch) run(ticker Ticker) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			b.Flush()
+		case <-b.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestBatch(t *testing.T) {
+	buf := captureOutput(t)
+	b := NewBatch("validation failures", zerolog.WarnLevel,
+		WithBatchExamples(2, 3), WithBatchGroupBy("reason"), WithBatchMaxValues(2))
+
+	reasons := []string{"missing email", "bad date", "bad phone", "missing email"}
+	for i := 0; i < 10000; i++ {
+		b.Add(map[string]interface{}{"row": i, "reason": reasons[i%len(reasons)]})
+	}
+	if len(b.first)+len(b.sampled) != 5 || len(b.groups["reason"]) != 3 {
+		t.Fatalf("memory should stay bounded: %d examples, %d values", len(b.first)+len(b.sampled), len(b.groups["reason"]))
+	}
+	b.Flush()
+
+	m := decodeLine(t, buf.Bytes())
+	if m["level"] != "warn" || m["message"] != "validation failures" || m["count"] != float64(10000) {
+		t.Fatalf("unexpected summary: %v", m)
+	}
+	examples := m["examples"].([]interface{})
+	if len(examples) != 5 {
+		t.Fatalf("expected 5 examples, got %d", len(examples))
+	}
+	for i, want := range []float64{0, 1} {
+		if row := examples[i].(map[string]interface{})["row"]; row != want {
+			t.Errorf("example %d should be row %v, got %v", i, want, row)
+		}
+	}
+	for _, e := range examples[2:] {
+		if row := e.(map[string]interface{})["row"].(float64); row < 2 {
+			t.Errorf("sampled examples should come from the remaining rows, got %v", row)
+		}
+	}
+	groups := m["group_by"].(map[string]interface{})["reason"].(map[string]interface{})
+	if groups["missing email"] != float64(5000) || groups["bad date"] != float64(2500) || groups["_other"] != float64(2500) {
+		t.Errorf("unexpected value frequencies: %v", groups)
+	}
+
+	// 已输出后再次 Flush 不输出
+	buf.Reset()
+	b.Flush()
+	if buf.Len() != 0 {
+		t.Errorf("empty batch should not be flushed: %s", buf.String())
+	}
+}
+
+func TestBatchAutoFlush(t *testing.T) {
+	buf := captureOutput(t)
+	b := NewBatch("imported", zerolog.InfoLevel, WithBatchFlushSize(3))
+	for i := 0; i < 7; i++ {
+		b.Add(map[string]interface{}{"row": i})
+	}
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 || lines[0]["count"] != float64(3) || lines[1]["count"] != float64(3) {
+		t.Fatalf("expected two size-triggered flushes: %v", lines)
+	}
+	b.Close()
+	if lines = decodeLines(t, buf); len(lines) != 1 || lines[0]["count"] != float64(1) {
+		t.Fatalf("close should flush the remainder: %v", lines)
+	}
+
+	// 定时输出在后台协程中写入
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	out := &syncBuffer{}
+	baseLogger = zerolog.New(out)
+	timed := NewBatch("timed", zerolog.InfoLevel, WithBatchFlushInterval(time.Minute))
+	defer timed.Close()
+	timed.Add(map[string]interface{}{"row": 1})
+	clock.Advance(time.Minute)
+	waitFor(t, "interval flush", func() bool { return out.String() != "" })
+	if m := decodeLine(t, []byte(out.String())); m["count"] != float64(1) {
+		t.Errorf("unexpected timed flush: %v", m)
+	}
+}
@@ -0,0 +1,136 @@
+// @Author Clover
+// @Data 2026/10/17 下午10:00:00
+// @Desc 按异步输出目标的队列空间分批输出缓冲区中的日志
+
+package logging
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	flushChunkSize       = 256                  // Flush 每批最多输出的条数
+	defaultFlushDeadline = 5 * time.Second      // Flush 等待异步输出目标的默认总时限
+	flushPollInterval    = 5 * time.Millisecond // 等待队列空间时的检查间隔
+)
+
+// Headroomer 可以报告写入队列剩余空间的异步输出目标，例如 redissink.Sink 与 sqlitesink.Sink
+// LogBuffer.Flush 依据剩余空间分批输出，避免队列溢出
+type Headroomer interface {
+	Headroom() int
+}
+
+// SetFlushDeadline 设置 Flush 等待异步输出目标腾出队列空间的总时限，d <= 0 时使用默认的 5s
+func (lb *LogBuffer) SetFlushDeadline(d time.Duration) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.flushDeadline = d
+}
+
+// deadline 返回 Flush 的总时限
+func (lb *LogBuffer) deadline() time.Duration {
+	if lb.flushDeadline > 0 {
+		return lb.flushDeadline
+	}
+	return defaultFlushDeadline
+}
+
+// replay 输出 entries，返回已输出与丢弃的条数，调用方需持有 lb.mu
+func (lb *LogBuffer) replay(entries []LogEntry) (flushed, dropped int) {
+	async := asyncWriters()
+	if len(async) == 0 {
+		for _, entry := range entries {
+			writeEntry(entry)
+		}
+		return len(entries), 0
+	}
+	deadline := now().Add(lb.deadline())
+	for flushed < len(entries) {
+		room := waitHeadroom(async, deadline)
+		if room == 0 {
+			break
+		}
+		n := min(room, flushChunkSize, len(entries)-flushed)
+		if debugBuild {
+			debugTrace("Flushing buffered logs", map[string]interface{}{"chunk": n, "headroom": room, "flushed": flushed, "total": len(entries)})
+		}
+		for _, entry := range entries[flushed : flushed+n] {
+			writeEntry(entry)
+		}
+		flushed += n
+	}
+	dropped = len(entries) - flushed
+	if dropped > 0 {
+		currentLogger().Warn().Int("flushed", flushed).Int("dropped", dropped).
+			Msg("Async outputs did not drain before the flush deadline, dropping buffered logs")
+	}
+	return flushed, dropped
+}
+
+// flushSync 不分批、不等待异步输出目标，直接输出缓冲区中的全部日志，用于进程退出前
+func (lb *LogBuffer) flushSync() {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	for _, entry := range lb.entries {
+		writeEntry(entry)
+	}
+	lb.entries = make([]LogEntry, 0)
+}
+
+// waitHeadroom 等待所有异步输出目标都有剩余空间，返回其中最小的剩余空间，超过 deadline 时返回 0
+func waitHeadroom(writers []Headroomer, deadline time.Time) int {
+	for {
+		room := writers[0].Headroom()
+		for _, w := range writers[1:] {
+			room = min(room, w.Headroom())
+		}
+		if room > 0 {
+			return room
+		}
+		if !now().Before(deadline) {
+			return 0
+		}
+		<-currentClock().After(flushPollInterval)
+	}
+}
+
+// asyncWriters 返回当前输出目标中可以报告队列空间的异步输出目标
+func asyncWriters() []Headroomer {
+	stateMu.RLock()
+	writers := activeWriters
+	stateMu.RUnlock()
+	var async []Headroomer
+	for _, w := range writers {
+		if h, ok := unwrapWriter(w).(Headroomer); ok {
+			async = append(async, h)
+		}
+	}
+	return async
+}
+
+// unwrapWriter 返回被写入状态记录、写入超时与 diode 包装的原始输出目标
+func unwrapWriter(w io.Writer) io.Writer {
+	for {
+		inner, ok := unwrapOnce(w)
+		if !ok {
+			return w
+		}
+		w = inner
+	}
+}
+
+// unwrapOnce 去掉一层包装，w 没有被包装时返回 false
+func unwrapOnce(w io.Writer) (io.Writer, bool) {
+	switch ww := w.(type) {
+	case *trackedWriter:
+		return ww.w, true
+	case *timeoutWriter:
+		return ww.w, true
+	case *diodeWriter:
+		return ww.w, true
+	case *LevelFilter:
+		return ww.w, true
+	}
+	return w, false
+}
@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// queueSink 模拟带有限队列的异步输出目标，队列已满时丢弃日志
+type queueSink struct {
+	queue    chan []byte
+	delay    time.Duration
+	release  chan struct{} // 关闭前后台协程不消费队列
+	received atomic.Int64
+	dropped  atomic.Int64
+	wg       sync.WaitGroup
+}
+
+func newQueueSink(size int, delay time.Duration, release chan struct{}) *queueSink {
+	s := &queueSink{queue: make(chan []byte, size), delay: delay, release: release}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if s.release != nil {
+			<-s.release
+		}
+		for range s.queue {
+			time.Sleep(s.delay)
+			s.received.Add(1)
+		}
+	}()
+	return s
+}
+
+func (s *queueSink) Write(p []byte) (int, error) {
+	select {
+	case s.queue <- append([]byte(nil), p...):
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+func (s *queueSink) Headroom() int { return cap(s.queue) - len(s.queue) }
+
+func (s *queueSink) stop() {
+	close(s.queue)
+	s.wg.Wait()
+}
+
+func initWithSink(t *testing.T, sink *queueSink) {
+	t.Helper()
+	// -tags debug 构建中 Flush 会输出 Trace 日志，只统计 Info 及以上的日志
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	InitLogger(Config{
+		LogPath:    filepath.Join(t.TempDir(), "flush.log"),
+		ProjectKey: "project",
+	}, WithWriters(sink))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+		zerolog.SetGlobalLevel(prevLevel)
+	})
+}
+
+func fillBuffer(n int) *LogBuffer {
+	buf := NewLogBuffer()
+	for i := 0; i < n; i++ {
+		buf.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "startup", Fields: map[string]interface{}{"i": i}})
+	}
+	return buf
+}
+
+func TestFlushWaitsForAsyncHeadroom(t *testing.T) {
+	sink := newQueueSink(64, 20*time.Microsecond, nil)
+	initWithSink(t, sink)
+
+	flushed, dropped := fillBuffer(2000).Flush(zerolog.InfoLevel)
+	sink.stop()
+	if flushed != 2000 || dropped != 0 {
+		t.Fatalf("expected 2000 flushed and 0 dropped, got %d and %d", flushed, dropped)
+	}
+	if sink.dropped.Load() != 0 || sink.received.Load() != 2000 {
+		t.Errorf("async sink should receive every entry without overflowing, received %d dropped %d",
+			sink.received.Load(), sink.dropped.Load())
+	}
+}
+
+func TestFlushDropsBeyondDeadline(t *testing.T) {
+	release := make(chan struct{})
+	sink := newQueueSink(100, 0, release)
+	initWithSink(t, sink)
+
+	buf := fillBuffer(1000)
+	buf.SetFlushDeadline(50 * time.Millisecond)
+	start := time.Now()
+	flushed, dropped := buf.Flush(zerolog.InfoLevel)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flush should give up after the deadline, took %v", elapsed)
+	}
+	if flushed != 100 || dropped != 900 {
+		t.Errorf("expected 100 flushed and 900 dropped, got %d and %d", flushed, dropped)
+	}
+	if len(buf.Peek()) != 0 {
+		t.Errorf("buffer should be empty after flush")
+	}
+	close(release)
+	sink.stop()
+	// 超时后的丢弃警告也写入已满的队列，只有它会被异步输出目标丢弃
+	if sink.received.Load() != 100 || sink.dropped.Load() != 1 {
+		t.Errorf("expected 100 received and only the drop warning dropped, got %d and %d",
+			sink.received.Load(), sink.dropped.Load())
+	}
+}
+
+func TestFatalFlushesBufferSynchronously(t *testing.T) {
+	buf := captureOutput(t)
+	var code int
+	exitFunc = func(c int) { code = c }
+	t.Cleanup(func() { exitFunc = os.Exit })
+
+	prev := Logger
+	Logger = fillBuffer(3)
+	t.Cleanup(func() { Logger = prev })
+	Logger.SetFlushDeadline(time.Millisecond)
+
+	Fatal("boom", 2)
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 4 {
+		t.Fatalf("expected 3 buffered lines and the fatal line, got %d: %v", len(lines), lines)
+	}
+	for _, line := range lines[:3] {
+		if line["message"] != "startup" {
+			t.Errorf("buffered entries should be written before the fatal event: %v", line)
+		}
+	}
+	if lines[3]["level"] != "fatal" || !strings.Contains(lines[3]["message"].(string), "boom") || code != 2 {
+		t.Errorf("unexpected fatal line %v or exit code %d", lines[3], code)
+	}
+}
@@ -0,0 +1,47 @@
+// @Author Clover
+// @Data 2026/10/18 上午2:10:00
+// @Desc 为日志添加二进制中嵌入的 Go 模块信息
+
+package logging
+
+import (
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+)
+
+// buildInfoKey Config.InjectBuildInfo 添加的字段
+const buildInfoKey = "build_info"
+
+// readBuildInfo 读取二进制中嵌入的构建信息，测试中可以替换
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildInfoDict 将 debug.ReadBuildInfo 的结果转换为 build_info 字段：go_version、module_path、
+// module_version 以及 deps（每个依赖的 module@version，被 replace 时附带 => 替换后的 module@version）；
+// 二进制中没有构建信息时返回 nil
+func buildInfoDict() *zerolog.Event {
+	info, ok := readBuildInfo()
+	if !ok {
+		return nil
+	}
+	deps := make([]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps = append(deps, moduleVersion(dep))
+	}
+	return zerolog.Dict().
+		Str("go_version", info.GoVersion).
+		Str("module_path", info.Main.Path).
+		Str("module_version", info.Main.Version).
+		Strs("deps", deps)
+}
+
+func moduleVersion(m *debug.Module) string {
+	s := m.Path + "@" + m.Version
+	if m.Replace != nil {
+		s += " => " + m.Replace.Path
+		if m.Replace.Version != "" {
+			s += "@" + m.Replace.Version
+		}
+	}
+	return s
+}
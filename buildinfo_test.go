@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestInjectBuildInfo(t *testing.T) {
+	prev := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.22.0",
+			Main:      debug.Module{Path: "example.com/app", Version: "v1.2.3"},
+			Deps: []*debug.Module{
+				{Path: "github.com/rs/zerolog", Version: "v1.33.0"},
+				{Path: "example.com/lib", Version: "v0.1.0", Replace: &debug.Module{Path: "../lib"}},
+			},
+		}, true
+	}
+	out := &syncBuffer{}
+	if err := InitLogger(Config{ProjectKey: "project", InjectBuildInfo: true}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; readBuildInfo = prev })
+
+	Info("started")
+	info, _ := decodeLine(t, []byte(out.String()))["build_info"].(map[string]interface{})
+	deps, _ := info["deps"].([]interface{})
+	if info["go_version"] != "go1.22.0" || info["module_path"] != "example.com/app" || info["module_version"] != "v1.2.3" {
+		t.Errorf("unexpected build_info: %v", info)
+	}
+	if len(deps) != 2 || deps[0] != "github.com/rs/zerolog@v1.33.0" || deps[1] != "example.com/lib@v0.1.0 => ../lib" {
+		t.Errorf("unexpected deps: %v", deps)
+	}
+
+	// 同名的用户字段按 ReservedKeyPolicy 重命名，不会输出两个 build_info
+	before := len(out.String())
+	Info("user build info", map[string]interface{}{"build_info": "mine"})
+	m := decodeStrict(t, []byte(strings.TrimSpace(out.String()[before:])))
+	if _, ok := m["build_info"].(map[string]interface{}); !ok || m["field_build_info"] != "mine" {
+		t.Errorf("colliding build_info should be renamed: %v", m)
+	}
+
+	// 没有构建信息时不添加字段
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	out2 := &syncBuffer{}
+	if err := InitLogger(Config{ProjectKey: "project", InjectBuildInfo: true, AllowReinit: true}, WithWriters(out2)); err != nil {
+		t.Fatal(err)
+	}
+	Info("started")
+	if _, ok := decodeLine(t, []byte(out2.String()))["build_info"]; ok {
+		t.Errorf("build_info should be omitted without build information: %s", out2.String())
+	}
+}
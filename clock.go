@@ -0,0 +1,104 @@
+// @Author Clover
+// @Data 2026/10/17 下午5:30:00
+// @Desc 可替换的时钟与随机数来源
+
+package logging
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Clock 时钟，日志文件监控、抑制时段、汇总输出、空闲检测等依赖时间的组件都通过它获取时间与定时器
+// 默认使用真实时间，测试时可通过 Config.Clock 或 SetClockForTesting 替换为假时钟
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker Clock 创建的周期定时器
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Rand 随机数来源，用于汇总输出的示例抽样等，实现需要可以并发调用
+type Rand interface {
+	Intn(n int) int
+}
+
+// realClock 使用真实时间的时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// globalRand 使用 math/rand 的全局随机数来源
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int { return rand.Intn(n) }
+
+type clockHolder struct{ Clock }
+type randHolder struct{ Rand }
+
+var (
+	configClock atomic.Pointer[clockHolder] // Config.Clock
+	testClock   atomic.Pointer[clockHolder] // SetClockForTesting 设置的时钟，优先于 Config.Clock
+	configRand  atomic.Pointer[randHolder]  // Config.Rand
+)
+
+// SetClockForTesting 替换内部使用的时钟，优先于 Config.Clock，传入 nil 时恢复
+// 仅供测试使用，例如配合假时钟验证日志文件清除、抑制时段等逻辑而无需等待真实时间
+func SetClockForTesting(c Clock) {
+	if c == nil {
+		testClock.Store(nil)
+		return
+	}
+	testClock.Store(&clockHolder{c})
+}
+
+// setClock 设置 Config.Clock 与 Config.Rand，为 nil 时使用默认实现
+func setClock(c Clock, r Rand) {
+	if c == nil {
+		configClock.Store(nil)
+	} else {
+		configClock.Store(&clockHolder{c})
+	}
+	if r == nil {
+		configRand.Store(nil)
+	} else {
+		configRand.Store(&randHolder{r})
+	}
+}
+
+// currentClock 返回当前使用的时钟
+func currentClock() Clock {
+	if h := testClock.Load(); h != nil {
+		return h.Clock
+	}
+	if h := configClock.Load(); h != nil {
+		return h.Clock
+	}
+	return realClock{}
+}
+
+// currentRand 返回当前使用的随机数来源
+func currentRand() Rand {
+	if h := configRand.Load(); h != nil {
+		return h.Rand
+	}
+	return globalRand{}
+}
+
+// now 返回当前时间
+func now() time.Time {
+	return currentClock().Now()
+}
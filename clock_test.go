@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 手动推进的时钟，Advance 时触发到期的定时器
+type fakeClock struct {
+	mu      sync.Mutex
+	t       time.Time
+	tickers []*fakeTicker
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeTicker struct {
+	clock   *fakeClock
+	d       time.Duration
+	next    time.Time
+	ch      chan time.Time
+	stopped bool
+}
+
+// useFakeClock 在测试期间使用从 start 开始的假时钟
+func useFakeClock(t *testing.T, start time.Time) *fakeClock {
+	c := &fakeClock{t: start}
+	SetClockForTesting(c)
+	t.Cleanup(func() { SetClockForTesting(nil) })
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.t
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.t.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tk := &fakeTicker{clock: c, d: d, next: c.t.Add(d), ch: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, tk)
+	return tk
+}
+
+func (tk *fakeTicker) C() <-chan time.Time { return tk.ch }
+
+func (tk *fakeTicker) Stop() {
+	tk.clock.mu.Lock()
+	defer tk.clock.mu.Unlock()
+	tk.stopped = true
+}
+
+// Advance 推进时间并触发到期的 After 与 Ticker，与 time.Ticker 相同，来不及接收的 tick 会被丢弃
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.t) {
+			pending = append(pending, w)
+		} else {
+			w.ch <- c.t
+		}
+	}
+	c.waiters = pending
+	for _, tk := range c.tickers {
+		for !tk.stopped && !tk.next.After(c.t) {
+			select {
+			case tk.ch <- c.t:
+			default:
+			}
+			tk.next = tk.next.Add(tk.d)
+		}
+	}
+}
+
+// skip 推进时间但不触发定时器，模拟定时器尚未被调度的情况
+func (c *fakeClock) skip(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// step 推进时间并等待所有 Ticker 的 tick 被接收；连续调用两次可以保证第一次的 tick 已处理完
+func (c *fakeClock) step(t *testing.T, d time.Duration) {
+	t.Helper()
+	c.Advance(d)
+	waitFor(t, "ticks to be received", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, tk := range c.tickers {
+			if !tk.stopped && len(tk.ch) > 0 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// waitForWaiters 等待至少 n 个协程通过 After 等待时钟
+func (c *fakeClock) waitForWaiters(t *testing.T, n int) {
+	t.Helper()
+	waitFor(t, "clock waiters", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.waiters) >= n
+	})
+}
+
+func TestClockSelection(t *testing.T) {
+	if _, ok := currentClock().(realClock); !ok {
+		t.Fatalf("real clock should be the default, got %T", currentClock())
+	}
+	configured := &fakeClock{t: time.Unix(100, 0)}
+	setClock(configured, nil)
+	t.Cleanup(func() { setClock(nil, nil) })
+	if !now().Equal(time.Unix(100, 0)) {
+		t.Errorf("Config.Clock should be used, got %v", now())
+	}
+	fc := useFakeClock(t, time.Unix(200, 0))
+	if currentClock() != Clock(fc) {
+		t.Errorf("SetClockForTesting should take precedence over Config.Clock")
+	}
+	SetClockForTesting(nil)
+	if currentClock() != Clock(configured) {
+		t.Errorf("clearing the test clock should restore Config.Clock")
+	}
+}
@@ -0,0 +1,113 @@
+// Command logreplay
+// @Author Clover
+// @Data 2026/10/17 下午6:50:00
+// @Desc 读取 NDJSON 日志，按级别、时间与正则过滤后重新输出，便于事后排查
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/Clov614/logging"
+	"github.com/Clov614/logging/internal/logfilter"
+)
+
+// 退出码：有匹配的日志为 0，没有匹配为 1，参数或读取错误为 2
+const (
+	exitMatched = 0
+	exitNoMatch = 1
+	exitUsage   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 解析参数并执行回放，返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("logreplay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: logreplay [flags] [file]\n\nreads NDJSON logs from file or stdin and prints matching lines\n\nflags:")
+		fs.PrintDefaults()
+	}
+	level := fs.String("level", "", "minimum level (trace, debug, info, warn, error, fatal, panic)")
+	from := fs.String("from", "", `include entries at or after this time ("2006-01-02 15:04:05" or RFC3339)`)
+	to := fs.String("to", "", "include entries before this time")
+	grep := fs.String("grep", "", "regular expression matched against the message and field values")
+	format := fs.String("format", "json", "output format: json or logfmt")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	var f logfilter.Filter
+	var err error
+	if f.Level, err = logfilter.ParseLevel(*level); err != nil {
+		fmt.Fprintf(stderr, "logreplay: invalid --level %q\n", *level)
+		return exitUsage
+	}
+	if f.From, err = logfilter.ParseTime(*from); err != nil {
+		fmt.Fprintf(stderr, "logreplay: invalid --from: %v\n", err)
+		return exitUsage
+	}
+	if f.To, err = logfilter.ParseTime(*to); err != nil {
+		fmt.Fprintf(stderr, "logreplay: invalid --to: %v\n", err)
+		return exitUsage
+	}
+	if *grep != "" {
+		if f.Grep, err = regexp.Compile(*grep); err != nil {
+			fmt.Fprintf(stderr, "logreplay: invalid --grep: %v\n", err)
+			return exitUsage
+		}
+	}
+	var render func(logging.LogEntry) string
+	switch *format {
+	case "json":
+		render = logfilter.RenderJSON
+	case "logfmt":
+		render = logfilter.RenderLogfmt
+	default:
+		fmt.Fprintf(stderr, "logreplay: unknown --format %q\n", *format)
+		return exitUsage
+	}
+
+	in := stdin
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if name := fs.Arg(0); name != "" && name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "logreplay: %v\n", err)
+			return exitUsage
+		}
+		defer file.Close()
+		in = file
+	}
+
+	entries, err := logging.ReadNDJSON(in)
+	if err != nil {
+		fmt.Fprintf(stderr, "logreplay: %v\n", err)
+		return exitUsage
+	}
+	matched := 0
+	for _, entry := range entries {
+		if logfilter.IsMalformed(entry) {
+			fmt.Fprintf(stderr, "logreplay: skipping malformed line: %v\n", entry.Fields["raw"])
+			continue
+		}
+		if !f.Match(entry) {
+			continue
+		}
+		matched++
+		fmt.Fprintln(stdout, render(entry))
+	}
+	if matched == 0 {
+		return exitNoMatch
+	}
+	return exitMatched
+}
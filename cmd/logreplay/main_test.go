@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `{"level":"debug","time":"2024-07-18 15:00:00","message":"cache warmed"}
+{"level":"info","time":"2024-07-18 15:01:00","message":"request served","path":"/health","status":200}
+not json
+{"level":"error","time":"2024-07-18 15:02:00","message":"request failed","path":"/orders","error":"timeout"}
+{"level":"warn","time":"2024-07-18 15:03:00","message":"slow query","query":"select 1"}
+`
+
+func replay(t *testing.T, stdin string, args ...string) (int, []string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	out := strings.TrimSpace(stdout.String())
+	if out == "" {
+		return code, nil, stderr.String()
+	}
+	return code, strings.Split(out, "\n"), stderr.String()
+}
+
+func TestFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string // 期望输出的消息
+	}{
+		{"all", nil, []string{"cache warmed", "request served", "request failed", "slow query"}},
+		{"level", []string{"--level", "warn"}, []string{"request failed", "slow query"}},
+		{"time range", []string{"--from", "2024-07-18 15:01:00", "--to", "2024-07-18 15:03:00"}, []string{"request served", "request failed"}},
+		{"grep message", []string{"--grep", "^request"}, []string{"request served", "request failed"}},
+		{"grep field", []string{"--grep", "orders|select"}, []string{"request failed", "slow query"}},
+		{"combined", []string{"--level", "info", "--grep", "request"}, []string{"request served", "request failed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, lines, stderr := replay(t, sample, tt.args...)
+			if code != exitMatched {
+				t.Fatalf("exit code %d, stderr %q", code, stderr)
+			}
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d lines %q, want %v", len(lines), lines, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], `"message":"`+want+`"`) {
+					t.Errorf("line %d = %q, want message %q", i, lines[i], want)
+				}
+			}
+			if !strings.Contains(stderr, "not json") {
+				t.Errorf("malformed line should be reported, stderr %q", stderr)
+			}
+		})
+	}
+}
+
+func TestLogfmt(t *testing.T) {
+	code, lines, _ := replay(t, sample, "--format", "logfmt", "--grep", "orders")
+	if code != exitMatched || len(lines) != 1 {
+		t.Fatalf("exit code %d, lines %q", code, lines)
+	}
+	want := `time="2024-07-18 15:02:00" level=error message="request failed" error=timeout path=/orders`
+	if lines[0] != want {
+		t.Errorf("got  %q\nwant %q", lines[0], want)
+	}
+}
+
+func TestNoMatch(t *testing.T) {
+	if code, lines, _ := replay(t, sample, "--level", "fatal"); code != exitNoMatch || lines != nil {
+		t.Errorf("exit code %d, lines %q", code, lines)
+	}
+	if code, _, _ := replay(t, ""); code != exitNoMatch {
+		t.Errorf("empty input should exit %d, got %d", exitNoMatch, code)
+	}
+}
+
+func TestFileArgument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(sample), 0666); err != nil {
+		t.Fatal(err)
+	}
+	code, lines, _ := replay(t, "", "--level", "error", path)
+	if code != exitMatched || len(lines) != 1 {
+		t.Errorf("exit code %d, lines %q", code, lines)
+	}
+	if code, _, _ := replay(t, "", filepath.Join(t.TempDir(), "missing.log")); code != exitUsage {
+		t.Errorf("missing file should exit %d, got %d", exitUsage, code)
+	}
+}
+
+func TestInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"--level", "loud"},
+		{"--from", "yesterday"},
+		{"--grep", "("},
+		{"--format", "xml"},
+	} {
+		if code, _, _ := replay(t, sample, args...); code != exitUsage {
+			t.Errorf("%v: exit code %d, want %d", args, code, exitUsage)
+		}
+	}
+}
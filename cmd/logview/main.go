@@ -0,0 +1,131 @@
+// Command logview
+// @Author Clover
+// @Data 2026/10/18 上午7:30:00
+// @Desc 查看 NDJSON 日志文件，按时间范围、级别与正则过滤，以文本、JSON 或 logfmt 输出
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/Clov614/logging"
+	"github.com/Clov614/logging/internal/logfilter"
+)
+
+// 退出码：有匹配的日志为 0，没有匹配为 1，参数或读取错误为 2
+const (
+	exitMatched = 0
+	exitNoMatch = 1
+	exitUsage   = 2
+)
+
+// now 当前时间，测试中替换
+var now = time.Now
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 解析参数并输出匹配的日志，返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("logview", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: logview [flags]\n\nprints NDJSON log entries within a time range and at or above a level\n\nflags:")
+		fs.PrintDefaults()
+	}
+	file := fs.String("file", "-", `NDJSON log file, "-" reads stdin`)
+	level := fs.String("level", "", "minimum level (trace, debug, info, warn, error, fatal, panic)")
+	since := fs.String("since", "", `include entries at or after this time: a duration before now ("15m") or a time ("2006-01-02 15:04:05" or RFC3339)`)
+	until := fs.String("until", "", "include entries before this time, same forms as --since")
+	grep := fs.String("grep", "", "regular expression matched against the message and field values")
+	format := fs.String("format", "text", "output format: text, json or logfmt")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	var f logfilter.Filter
+	var err error
+	if f.Level, err = logfilter.ParseLevel(*level); err != nil {
+		fmt.Fprintf(stderr, "logview: invalid --level %q\n", *level)
+		return exitUsage
+	}
+	t := now()
+	if f.From, err = parseBound(*since, t); err != nil {
+		fmt.Fprintf(stderr, "logview: invalid --since: %v\n", err)
+		return exitUsage
+	}
+	if f.To, err = parseBound(*until, t); err != nil {
+		fmt.Fprintf(stderr, "logview: invalid --until: %v\n", err)
+		return exitUsage
+	}
+	if *grep != "" {
+		if f.Grep, err = regexp.Compile(*grep); err != nil {
+			fmt.Fprintf(stderr, "logview: invalid --grep: %v\n", err)
+			return exitUsage
+		}
+	}
+	var render func(logging.LogEntry) string
+	switch *format {
+	case "text":
+		render = logfilter.RenderText
+	case "json":
+		render = logfilter.RenderJSON
+	case "logfmt":
+		render = logfilter.RenderLogfmt
+	default:
+		fmt.Fprintf(stderr, "logview: unknown --format %q\n", *format)
+		return exitUsage
+	}
+
+	in := stdin
+	if *file != "-" {
+		fh, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(stderr, "logview: %v\n", err)
+			return exitUsage
+		}
+		defer fh.Close()
+		in = fh
+	}
+	entries, err := logging.ReadNDJSON(in)
+	if err != nil {
+		fmt.Fprintf(stderr, "logview: %v\n", err)
+		return exitUsage
+	}
+	matched := 0
+	for _, entry := range entries {
+		if logfilter.IsMalformed(entry) {
+			fmt.Fprintf(stderr, "logview: skipping malformed line: %v\n", entry.Fields["raw"])
+			continue
+		}
+		if !f.Match(entry) {
+			continue
+		}
+		matched++
+		fmt.Fprintln(stdout, render(entry))
+	}
+	if matched == 0 {
+		return exitNoMatch
+	}
+	return exitMatched
+}
+
+// parseBound 解析时间范围的边界：时长表示 t 之前的时间，否则按日志的时间格式或 RFC3339 解析；空字符串返回零值
+func parseBound(s string, t time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("negative duration %q", s)
+		}
+		return t.Add(-d), nil
+	}
+	return logfilter.ParseTime(s)
+}
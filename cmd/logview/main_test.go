@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `{"level":"debug","time":"2024-07-18 15:00:00","message":"cache warmed"}
+{"level":"info","time":"2024-07-18 15:01:00","message":"request served","path":"/health","status":200}
+not json
+{"level":"error","time":"2024-07-18 15:02:00","message":"request failed","path":"/orders","error":"timeout"}
+{"level":"warn","time":"2024-07-18 15:03:00","message":"slow query","query":"select 1"}
+`
+
+// useNow 将当前时间固定为 t
+func useNow(t *testing.T, at time.Time) {
+	t.Helper()
+	prev := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = prev })
+}
+
+func view(t *testing.T, stdin string, args ...string) (int, []string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	out := strings.TrimSpace(stdout.String())
+	if out == "" {
+		return code, nil, stderr.String()
+	}
+	return code, strings.Split(out, "\n"), stderr.String()
+}
+
+func TestFilters(t *testing.T) {
+	useNow(t, time.Date(2024, 7, 18, 15, 4, 0, 0, time.Local))
+	tests := []struct {
+		name string
+		args []string
+		want []string // 期望输出的消息
+	}{
+		{"all", nil, []string{"cache warmed", "request served", "request failed", "slow query"}},
+		{"level", []string{"--level", "warn"}, []string{"request failed", "slow query"}},
+		{"since duration", []string{"--since", "2m30s"}, []string{"request failed", "slow query"}},
+		{"since and until", []string{"--since", "2024-07-18 15:01:00", "--until", "1m"}, []string{"request served", "request failed"}},
+		{"rfc3339", []string{"--until", time.Date(2024, 7, 18, 15, 1, 0, 0, time.Local).Format(time.RFC3339)}, []string{"cache warmed"}},
+		{"grep", []string{"--grep", "orders|select", "--level", "error"}, []string{"request failed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, lines, stderr := view(t, sample, append(tt.args, "--format", "json")...)
+			if code != exitMatched {
+				t.Fatalf("exit code %d, stderr %q", code, stderr)
+			}
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d lines %q, want %v", len(lines), lines, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], `"message":"`+want+`"`) {
+					t.Errorf("line %d = %q, want message %q", i, lines[i], want)
+				}
+			}
+			if !strings.Contains(stderr, "not json") {
+				t.Errorf("malformed line should be reported, stderr %q", stderr)
+			}
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format, want string
+	}{
+		{"text", `2024-07-18 15:02:00 ERROR request failed error=timeout path=/orders`},
+		{"logfmt", `time="2024-07-18 15:02:00" level=error message="request failed" error=timeout path=/orders`},
+		{"json", `{"error":"timeout","level":"error","message":"request failed","path":"/orders","time":"2024-07-18 15:02:00"}`},
+	}
+	for _, tt := range tests {
+		code, lines, _ := view(t, sample, "--format", tt.format, "--grep", "orders")
+		if code != exitMatched || len(lines) != 1 {
+			t.Fatalf("%s: exit code %d, lines %q", tt.format, code, lines)
+		}
+		if lines[0] != tt.want {
+			t.Errorf("%s: got  %q\nwant %q", tt.format, lines[0], tt.want)
+		}
+	}
+}
+
+func TestFileFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(sample), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if code, lines, _ := view(t, "", "--file", path, "--level", "error"); code != exitMatched || len(lines) != 1 {
+		t.Errorf("exit code %d, lines %q", code, lines)
+	}
+	if code, lines, _ := view(t, sample, "--file", "-", "--level", "error"); code != exitMatched || len(lines) != 1 {
+		t.Errorf("stdin: exit code %d, lines %q", code, lines)
+	}
+	if code, _, _ := view(t, "", "--file", filepath.Join(t.TempDir(), "missing.log")); code != exitUsage {
+		t.Errorf("missing file should exit %d, got %d", exitUsage, code)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	if code, lines, _ := view(t, sample, "--level", "fatal"); code != exitNoMatch || lines != nil {
+		t.Errorf("exit code %d, lines %q", code, lines)
+	}
+	for _, args := range [][]string{
+		{"--level", "loud"},
+		{"--since", "yesterday"},
+		{"--until", "-5m"},
+		{"--grep", "("},
+		{"--format", "xml"},
+		{"app.log"},
+	} {
+		if code, _, _ := view(t, sample, args...); code != exitUsage {
+			t.Errorf("%v: exit code %d, want %d", args, code, exitUsage)
+		}
+	}
+}
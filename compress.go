@@ -0,0 +1,256 @@
+// @Author Clover
+// @Data 2026/10/17 下午8:10:00
+// @Desc 以 zstd 流写入当前日志文件，并透明读取压缩的日志文件
+
+package logging
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	compressedExt                = ".zst"      // 压缩日志文件的扩展名
+	defaultCompressFlushInterval = time.Second // 未配置 CompressFlushInterval 时结束 zstd 帧的间隔
+
+	zstdFrameMagic     = 0xFD2FB528
+	zstdSkippableMagic = 0x184D2A50 // 可跳过帧的魔数，低 4 位可以是任意值
+)
+
+// zstdDecoder 供 DecodeAll 使用的解码器，可以并发调用
+var zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	return dec
+})
+
+// isCompressedPath 判断是否为 zstd 压缩的日志文件
+func isCompressedPath(path string) bool {
+	return strings.HasSuffix(path, compressedExt)
+}
+
+// zstdOutput 将编码器的输出写入日志文件并按压缩后的字节数计算大小，调用方需持有 lf.mu
+type zstdOutput struct {
+	lf *logFile
+}
+
+func (zo zstdOutput) Write(p []byte) (int, error) {
+	n, err := zo.lf.file.Write(p)
+	zo.lf.size += int64(n)
+	return n, err
+}
+
+// enableCompression 以 zstd 流写入日志文件。上次异常退出留下的不完整帧会被截掉，新的帧追加在最后一个完整帧之后
+func (lf *logFile) enableCompression() error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	fi, err := lf.file.Stat()
+	if err != nil {
+		return err
+	}
+	if end := completeFramesEnd(lf.file, 0, fi.Size()); end < fi.Size() {
+		if err := lf.file.Truncate(end); err != nil {
+			return err
+		}
+		lf.size = end
+	}
+	// 单协程编码，保证 Write 返回时压缩数据已经交给文件
+	lf.zw, err = zstd.NewWriter(zstdOutput{lf}, zstd.WithEncoderConcurrency(1))
+	return err
+}
+
+// finishFrame 结束当前的 zstd 帧，使已写入的日志可以被完整读取，调用方需持有 lf.mu
+func (lf *logFile) finishFrame() error {
+	if lf.zw == nil || !lf.dirty {
+		return nil
+	}
+	err := lf.zw.Close()
+	lf.zw.Reset(zstdOutput{lf})
+	lf.dirty = false
+	return err
+}
+
+// resetFrame 丢弃当前未结束的帧，用于文件被截断或替换之后，调用方需持有 lf.mu
+func (lf *logFile) resetFrame() {
+	if lf.zw == nil {
+		return
+	}
+	lf.zw.Reset(zstdOutput{lf})
+	lf.dirty = false
+}
+
+// startFlusher 按 interval 结束 zstd 帧，进程崩溃时最多丢失最近一个间隔内的日志
+func (lf *logFile) startFlusher(interval time.Duration) {
+	if lf.zw == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultCompressFlushInterval
+	}
+	lf.flushTicker = currentClock().NewTicker(interval)
+	lf.flushDone = make(chan struct{})
+	lf.flushStopped = make(chan struct{})
+	go func(ticker <-chan time.Time, done <-chan struct{}) {
+		defer close(lf.flushStopped)
+		for {
+			select {
+			case <-ticker:
+				lf.mu.Lock()
+				err := lf.finishFrame()
+				lf.mu.Unlock()
+				if err != nil {
+					currentLogger().Error().Err(err).Msg("Error flushing compressed log file")
+				}
+			case <-done:
+				return
+			}
+		}
+	}(lf.flushTicker.C(), lf.flushDone)
+}
+
+// stopFlusher 停止定时结束 zstd 帧的协程
+func (lf *logFile) stopFlusher() {
+	if lf.flushTicker == nil {
+		return
+	}
+	lf.flushTicker.Stop()
+	close(lf.flushDone)
+	<-lf.flushStopped
+	lf.flushTicker = nil
+}
+
+// completeFramesEnd 从 start 开始依次解析 zstd 帧头与块头，返回最后一个完整帧的结束位置，
+// 不完整或损坏的帧及其之后的内容被忽略
+func completeFramesEnd(r io.ReaderAt, start, size int64) int64 {
+	end := start
+	for end < size {
+		n, ok := zstdFrameLength(r, end, size)
+		if !ok {
+			break
+		}
+		end += n
+	}
+	return end
+}
+
+// zstdFrameLength 返回从 off 开始的完整帧的长度，帧不完整或格式错误时返回 false
+func zstdFrameLength(r io.ReaderAt, off, size int64) (int64, bool) {
+	read := func(p []byte, at int64) bool {
+		if at+int64(len(p)) > size {
+			return false
+		}
+		_, err := r.ReadAt(p, at)
+		return err == nil
+	}
+	var word [4]byte
+	if !read(word[:], off) {
+		return 0, false
+	}
+	magic := binary.LittleEndian.Uint32(word[:])
+	if magic&0xFFFFFFF0 == zstdSkippableMagic {
+		if !read(word[:], off+4) {
+			return 0, false
+		}
+		n := 8 + int64(binary.LittleEndian.Uint32(word[:]))
+		return n, off+n <= size
+	}
+	if magic != zstdFrameMagic {
+		return 0, false
+	}
+
+	// 帧头：描述符、窗口描述符、字典 ID 与内容大小
+	var desc [1]byte
+	if !read(desc[:], off+4) || desc[0]&0x08 != 0 {
+		return 0, false
+	}
+	d := desc[0]
+	singleSegment := d&0x20 != 0
+	pos := off + 5
+	if !singleSegment {
+		pos++
+	}
+	pos += [4]int64{0, 1, 2, 4}[d&0x03]
+	fcsSize := [4]int64{0, 2, 4, 8}[d>>6]
+	if fcsSize == 0 && singleSegment {
+		fcsSize = 1
+	}
+	pos += fcsSize
+
+	// 块：3 字节块头，最低位标记最后一个块，之后两位为块类型，其余为块大小
+	var header [3]byte
+	for last := false; !last; {
+		if !read(header[:], pos) {
+			return 0, false
+		}
+		v := uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16
+		last = v&1 == 1
+		pos += 3
+		switch (v >> 1) & 3 {
+		case 0, 2: // raw、compressed
+			pos += int64(v >> 3)
+		case 1: // RLE
+			pos++
+		default:
+			return 0, false
+		}
+		if pos > size {
+			return 0, false
+		}
+	}
+	if d&0x04 != 0 { // 内容校验和
+		pos += 4
+	}
+	if pos > size {
+		return 0, false
+	}
+	return pos - off, true
+}
+
+// compressedReader 解压 zstd 日志文件，关闭时同时关闭文件
+type compressedReader struct {
+	io.ReadCloser
+	f *os.File
+}
+
+func (cr compressedReader) Close() error {
+	cr.ReadCloser.Close()
+	return cr.f.Close()
+}
+
+// openLogReader 打开日志文件用于读取，.zst 文件透明解压，末尾不完整的帧（例如写入时进程崩溃）被忽略
+func openLogReader(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil || !isCompressedPath(path) {
+		return f, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	end := completeFramesEnd(f, 0, fi.Size())
+	dec, err := zstd.NewReader(io.NewSectionReader(f, 0, end), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return compressedReader{ReadCloser: dec.IOReadCloser(), f: f}, nil
+}
+
+// decodeFrames 解压 [start, end) 范围内的完整帧
+func decodeFrames(f *os.File, start, end int64) ([]byte, error) {
+	if end <= start {
+		return nil, nil
+	}
+	buf := make([]byte, end-start)
+	if _, err := f.ReadAt(buf, start); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return zstdDecoder().DecodeAll(buf, nil)
+}
@@ -0,0 +1,240 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// entryMessages 返回条目的消息列表
+func entryMessages(entries []LogEntry) []string {
+	msgs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// openCompressed 打开以 zstd 流写入的日志文件
+func openCompressed(t *testing.T, path string) *logFile {
+	t.Helper()
+	lf, err := openLogFile(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lf.enableCompression(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lf.Close() })
+	return lf
+}
+
+func writeLines(t *testing.T, lf *logFile, msgs ...string) {
+	t.Helper()
+	for _, msg := range msgs {
+		if _, err := lf.Write([]byte(`{"level":"info","message":"` + msg + `"}` + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCompressActive(t *testing.T) {
+	dir := t.TempDir()
+	InitLogger(Config{
+		LogPath:          filepath.Join(dir, "app.log"),
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+		CompressActive:   true,
+	})
+	t.Cleanup(Close)
+
+	Info("first")
+	Warn("second")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Barrier(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "app.log.zst")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) < 4 || binary.LittleEndian.Uint32(raw) != zstdFrameMagic {
+		t.Fatalf("log file should be a zstd stream, got %q", raw)
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entryMessages(entries), ","); !strings.HasSuffix(got, "first,second") {
+		t.Errorf("unexpected entries: %s", got)
+	}
+
+	// 大小按压缩后的字节数计算
+	stateMu.RLock()
+	lf := logfile
+	stateMu.RUnlock()
+	lf.mu.Lock()
+	size := lf.size
+	lf.mu.Unlock()
+	if size != int64(len(raw)) {
+		t.Errorf("size accounting should use compressed bytes: %d != %d", size, len(raw))
+	}
+}
+
+func TestCompressedCrashRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	lf := openCompressed(t, path)
+	writeLines(t, lf, "a", "b")
+	if err := lf.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	flushed, _ := os.Stat(path)
+	writeLines(t, lf, strings.Repeat("c", 500))
+	if err := lf.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	full, _ := os.Stat(path)
+	lf.Close()
+
+	// 模拟写入最后一帧时进程崩溃
+	if err := os.Truncate(path, flushed.Size()+(full.Size()-flushed.Size())/2); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entryMessages(entries), ","); got != "a,b" {
+		t.Errorf("entries up to the last complete frame should be readable, got %q", got)
+	}
+
+	// 重新打开时截掉不完整的帧，之后写入的帧可以正常读取
+	lf = openCompressed(t, path)
+	writeLines(t, lf, "d")
+	if err := lf.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entryMessages(entries), ","); got != "a,b,d" {
+		t.Errorf("unexpected entries after recovery: %q", got)
+	}
+}
+
+func TestCompressedClear(t *testing.T) {
+	prev := baseLogger
+	baseLogger = zerolog.New(io.Discard)
+	t.Cleanup(func() { baseLogger = prev })
+
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	lf := openCompressed(t, path)
+	writeLines(t, lf, "old")
+	lf.clear(HeaderReasonSizeClear)
+	writeLines(t, lf, "new")
+	if err := lf.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entryMessages(entries), ","); got != "new" {
+		t.Errorf("cleared file should start a new stream, got %q", got)
+	}
+}
+
+func TestCompressedFlushInterval(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	lf := openCompressed(t, path)
+	lf.startFlusher(time.Second)
+
+	writeLines(t, lf, "buffered")
+	if entries, _ := ReadEntries(path); len(entries) != 0 {
+		t.Errorf("unfinished frame should not be readable yet: %v", entries)
+	}
+	clock.step(t, time.Second)
+	clock.step(t, time.Second)
+	entries, err := ReadEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entryMessages(entries), ","); got != "buffered" {
+		t.Errorf("periodic flush should finish the frame, got %q", got)
+	}
+}
+
+func TestTailCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	lf := openCompressed(t, path)
+	writeLines(t, lf, "existing")
+	lf.Sync()
+
+	ft := NewFileTailer(path)
+	ft.PollInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	entries := ft.Entries(ctx)
+	next := func() string {
+		t.Helper()
+		select {
+		case e := <-entries:
+			return e.Message
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for entry")
+			return ""
+		}
+	}
+	if msg := next(); msg != "existing" {
+		t.Errorf("unexpected first entry %q", msg)
+	}
+	writeLines(t, lf, "live-1", "live-2")
+	lf.Sync()
+	if msg := next(); msg != "live-1" {
+		t.Errorf("unexpected entry %q", msg)
+	}
+	if msg := next(); msg != "live-2" {
+		t.Errorf("unexpected entry %q", msg)
+	}
+}
+
+func TestCompletedFramesEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frames.log.zst")
+	lf := openCompressed(t, path)
+	var ends []int64
+	for _, msg := range []string{"one", "two", "three"} {
+		writeLines(t, lf, msg)
+		lf.Sync()
+		fi, _ := os.Stat(path)
+		ends = append(ends, fi.Size())
+	}
+	raw, _ := os.ReadFile(path)
+	r := bytes.NewReader(raw)
+	if end := completeFramesEnd(r, 0, int64(len(raw))); end != ends[2] {
+		t.Errorf("complete stream: got %d, want %d", end, ends[2])
+	}
+	for cut := ends[0]; cut < ends[1]; cut++ {
+		if end := completeFramesEnd(r, 0, cut); end != ends[0] {
+			t.Fatalf("truncated at %d: got %d, want %d", cut, end, ends[0])
+		}
+	}
+	if end := completeFramesEnd(r, ends[0], int64(len(raw))); end != ends[2] {
+		t.Errorf("scan from frame boundary: got %d, want %d", end, ends[2])
+	}
+	if end := completeFramesEnd(bytes.NewReader([]byte("not zstd")), 0, 8); end != 0 {
+		t.Errorf("garbage should have no complete frames, got %d", end)
+	}
+}
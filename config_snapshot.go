@@ -0,0 +1,115 @@
+// @Author Clover
+// @Data 2026/10/18 上午3:30:00
+// @Desc 实际生效的配置及每个字段的来源
+
+package logging
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/rs/zerolog"
+)
+
+// 配置字段的来源
+const (
+	ConfigSourceDefault = "default" // 未设置，使用默认值
+	ConfigSourceEnv     = "env"     // 由 Config.EnvOverrides 中的环境变量覆盖
+	ConfigSourceCode    = "code"    // 调用 InitLogger 时在 Config 中设置
+	ConfigSourceProfile = "profile" // 未设置，由 Config.Profile 的预设填充
+)
+
+// snapshotVisibleTail 敏感字段保留的末尾字符数
+const snapshotVisibleTail = 4
+
+var (
+	activeEnvFields     map[string]string // 最近一次 InitLogger 中被环境变量覆盖的字段（字段名到环境变量名），由 stateMu 保护
+	activeProfileFields []string          // 最近一次 InitLogger 中由预设填充的字段，由 stateMu 保护
+)
+
+// ConfigSnapshot 返回最近一次 InitLogger 实际生效的配置，用于支持包等场景：
+// 每个字段为 {"value": 值, "source": default/env/profile/code}，来自环境变量的字段附带 "env_var"，来自预设的字段附带 "profile"；
+// 未设置但由日志记录器补全默认值的字段（如 FileFormat）给出补全后的值；
+// 名称看起来敏感的字段（密钥、凭据、DSN 等）只保留最后 4 个字符，其余字符串经过 Masker 处理
+func ConfigSnapshot() map[string]interface{} {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return configSnapshot()
+}
+
+// configSnapshot 调用方需持有 stateMu
+func configSnapshot() map[string]interface{} {
+	cr := configRenderer{secret: maskTail}
+	defaults := resolvedDefaults(activeConfig)
+	v := reflect.ValueOf(activeConfig)
+	t := v.Type()
+	profile := activeConfig.Profile
+	if profile == "" {
+		profile = ProfileDefault
+	}
+	snapshot := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		value, _ := cr.renderField(f.Name, fv)
+		entry := map[string]interface{}{"value": value}
+		switch env, ok := activeEnvFields[f.Name]; {
+		case ok:
+			entry["source"] = ConfigSourceEnv
+			entry["env_var"] = env
+		case slices.Contains(activeProfileFields, f.Name):
+			entry["source"] = ConfigSourceProfile
+			entry["profile"] = profile
+		case fv.IsZero():
+			entry["source"] = ConfigSourceDefault
+			if d, ok := defaults[f.Name]; ok {
+				entry["value"] = d
+			}
+		default:
+			entry["source"] = ConfigSourceCode
+		}
+		snapshot[f.Name] = entry
+	}
+	return snapshot
+}
+
+// resolvedDefaults 返回 InitLogger 为未设置的字段补全的值
+func resolvedDefaults(config Config) map[string]interface{} {
+	d := map[string]interface{}{
+		"FileFormat":            FileFormatJSON,
+		"ReservedKeyPolicy":     string(ReservedKeyRename),
+		"AsyncQueueSize":        defaultAsyncQueueSize,
+		"CompressFlushInterval": defaultCompressFlushInterval.String(),
+		"StatsSampleRate":       defaultStatsSampleRate,
+		"DrainTimeout":          defaultDrainTimeout.String(),
+		"RelayBufferSize":       defaultRelayBufferSize,
+		"Profile":               ProfileDefault,
+		"LogLevel":              zerolog.GlobalLevel().String(),
+	}
+	if config.EnableWindowsEventLog {
+		d["EventSource"] = eventLogSource(config)
+	}
+	if config.DisableMetrics {
+		d["StatsSampleRate"] = 0
+	}
+	if config.MaxTotalLogBytes > 0 {
+		d["MonitorInterval"] = defaultRetentionInterval.String()
+	} else if config.MaxFileAge > 0 {
+		d["MonitorInterval"] = min(defaultMonitorInterval, config.MaxFileAge).String()
+	}
+	return d
+}
+
+// maskTail 只保留字符串的最后 4 个字符，其他类型的值替换为 ***
+func maskTail(fv reflect.Value) interface{} {
+	if fv.Kind() != reflect.String {
+		return maskedConfigValue
+	}
+	s := fv.String()
+	if len(s) <= snapshotVisibleTail {
+		return maskedConfigValue
+	}
+	return maskedConfigValue + s[len(s)-snapshotVisibleTail:]
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestConfigSnapshot(t *testing.T) {
+	prev := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prev) })
+	t.Setenv("APP_NAME", "svc")
+	out := &syncBuffer{}
+	config := Config{
+		ProjectKey:       "project",
+		LogLevel:         "debug",
+		DebugTokenSecret: "abcdefgh",
+		EnvOverrides:     map[string]string{"APP_NAME": "ProjectName"},
+	}
+	if err := InitLogger(config, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+
+	snapshot := ConfigSnapshot()
+	field := func(name string) map[string]interface{} {
+		entry, _ := snapshot[name].(map[string]interface{})
+		return entry
+	}
+	if f := field("ProjectName"); f["value"] != "svc" || f["source"] != ConfigSourceEnv || f["env_var"] != "APP_NAME" {
+		t.Errorf("ProjectName = %v", f)
+	}
+	if f := field("DebugTokenSecret"); f["value"] != "***efgh" || f["source"] != ConfigSourceCode {
+		t.Errorf("secret should keep only its last 4 characters: %v", f)
+	}
+	// 未设置的字段给出日志记录器补全后的值
+	if f := field("FileFormat"); f["value"] != FileFormatJSON || f["source"] != ConfigSourceDefault {
+		t.Errorf("FileFormat = %v", f)
+	}
+
+	logs := out.String()
+	if !strings.Contains(logs, "Effective logging configuration") || strings.Contains(logs, "abcdefgh") {
+		t.Errorf("startup should log the masked snapshot at debug level: %q", logs)
+	}
+	if d := Diagnose(); d.Snapshot["DebugTokenSecret"] == nil {
+		t.Errorf("diagnostics should include the config snapshot")
+	}
+}
@@ -0,0 +1,113 @@
+// @Author Clover
+// @Data 2026/10/16 下午4:50:00
+// @Desc 控制台输出格式
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	consoleLevelLabels map[zerolog.Level]string // 控制台输出使用的级别名称
+	messageTranslator  func(msg string) string  // 控制台输出前的消息翻译
+	consoleFormatters  ConsoleFormatters        // 控制台输出各部分的自定义格式
+)
+
+// ConsoleFormatters 控制台输出各部分的格式化函数，对应 zerolog.ConsoleWriter 的同名字段
+// 为 nil 的字段保持默认格式，FormatLevel 优先于 Config.ConsoleLevelLabels
+type ConsoleFormatters struct {
+	FormatTimestamp     zerolog.Formatter
+	FormatLevel         zerolog.Formatter
+	FormatCaller        zerolog.Formatter
+	FormatMessage       zerolog.Formatter
+	FormatFieldName     zerolog.Formatter
+	FormatFieldValue    zerolog.Formatter
+	FormatErrFieldName  zerolog.Formatter
+	FormatErrFieldValue zerolog.Formatter
+}
+
+// apply 将非 nil 的格式化函数设置到 w
+func (f ConsoleFormatters) apply(w *zerolog.ConsoleWriter) {
+	if f.FormatTimestamp != nil {
+		w.FormatTimestamp = f.FormatTimestamp
+	}
+	if f.FormatLevel != nil {
+		w.FormatLevel = f.FormatLevel
+	}
+	if f.FormatCaller != nil {
+		w.FormatCaller = f.FormatCaller
+	}
+	if f.FormatMessage != nil {
+		w.FormatMessage = f.FormatMessage
+	}
+	if f.FormatFieldName != nil {
+		w.FormatFieldName = f.FormatFieldName
+	}
+	if f.FormatFieldValue != nil {
+		w.FormatFieldValue = f.FormatFieldValue
+	}
+	if f.FormatErrFieldName != nil {
+		w.FormatErrFieldName = f.FormatErrFieldName
+	}
+	if f.FormatErrFieldValue != nil {
+		w.FormatErrFieldValue = f.FormatErrFieldValue
+	}
+}
+
+// newConsoleWriter 创建控制台输出，应用自定义级别名称、消息翻译与格式化函数
+func newConsoleWriter(out io.Writer, noColor bool) zerolog.ConsoleWriter {
+	w := zerolog.ConsoleWriter{Out: out, NoColor: noColor}
+	if len(consoleLevelLabels) > 0 {
+		w.FormatLevel = formatLevelLabel(consoleLevelLabels, noColor)
+	}
+	consoleFormatters.apply(&w)
+	if translate := messageTranslator; translate != nil {
+		w.FormatPrepare = func(evt map[string]interface{}) error {
+			if msg, ok := evt[zerolog.MessageFieldName].(string); ok {
+				evt[zerolog.MessageFieldName] = translate(msg)
+			}
+			return nil
+		}
+	}
+	if consoleMultiline {
+		keys := markedMultilineKeys()
+		w.FieldsExclude = keys
+		w.FormatExtra = formatMultiline(keys, terminalWidth(out))
+	}
+	return w
+}
+
+// formatLevelLabel 使用自定义名称输出级别，未配置的级别保持 zerolog 默认名称
+func formatLevelLabel(labels map[zerolog.Level]string, noColor bool) zerolog.Formatter {
+	return func(i interface{}) string {
+		s, ok := i.(string)
+		if !ok {
+			return "???"
+		}
+		level, err := zerolog.ParseLevel(s)
+		if err != nil {
+			return strings.ToUpper(s)
+		}
+		label, ok := labels[level]
+		if !ok {
+			if label, ok = zerolog.FormattedLevels[level]; !ok {
+				label = strings.ToUpper(s)
+			}
+		}
+		return colorize(label, zerolog.LevelColors[level], noColor)
+	}
+}
+
+// colorize 为控制台输出添加颜色，与 zerolog 默认行为一致，遵循 NO_COLOR 环境变量
+func colorize(s string, color int, noColor bool) string {
+	if noColor || color == 0 || os.Getenv("NO_COLOR") != "" {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, s)
+}
@@ -0,0 +1,147 @@
+// @Author Clover
+// @Data 2026/10/16 下午7:10:00
+// @Desc 控制台多行字段输出
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+const (
+	multilinePrefix     = "  │ " // 多行字段的续行前缀
+	wrapIndent          = "    " // 主日志行折行后的缩进
+	multilineValueShift = "  "   // 多行字段内容相对字段名的缩进
+)
+
+var (
+	consoleMultiline bool // 是否在控制台中将多行字段输出在主日志行下方
+
+	multilineMu   sync.RWMutex
+	multilineKeys = []string{"stack"} // 在控制台中多行输出的字段，按注册顺序输出
+
+	// terminalWidth 返回输出目标的终端宽度，非终端返回 0 表示不折行
+	terminalWidth = func(out io.Writer) int {
+		f, ok := out.(*os.File)
+		if !ok || !term.IsTerminal(int(f.Fd())) {
+			return 0
+		}
+		width, _, err := term.GetSize(int(f.Fd()))
+		if err != nil {
+			return 0
+		}
+		return width
+	}
+)
+
+// MarkMultiline 将字段标记为多行字段，开启 Config.ConsoleMultiline 后，
+// 这些字段会在控制台中缩进输出在主日志行下方，文件中的 JSON 不受影响
+func MarkMultiline(keys ...string) {
+	multilineMu.Lock()
+	defer multilineMu.Unlock()
+	for _, key := range keys {
+		if !containsString(multilineKeys, key) {
+			multilineKeys = append(multilineKeys, key)
+		}
+	}
+}
+
+func markedMultilineKeys() []string {
+	multilineMu.RLock()
+	defer multilineMu.RUnlock()
+	keys := make([]string, len(multilineKeys))
+	copy(keys, multilineKeys)
+	return keys
+}
+
+// formatMultiline 返回 ConsoleWriter.FormatExtra，折行主日志行并输出多行字段
+func formatMultiline(keys []string, width int) func(map[string]interface{}, *bytes.Buffer) error {
+	return func(evt map[string]interface{}, buf *bytes.Buffer) error {
+		if width > 0 {
+			wrapped := wrapLine(buf.String(), width)
+			buf.Reset()
+			buf.WriteString(wrapped)
+		}
+		for _, key := range keys {
+			v, ok := evt[key]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(buf, "\n%s%s:", multilinePrefix, key)
+			for _, line := range strings.Split(multilineValue(v), "\n") {
+				buf.WriteString("\n" + multilinePrefix + multilineValueShift + line)
+			}
+		}
+		return nil
+	}
+}
+
+// multilineValue 将字段值转换为多行文本，非字符串值格式化为缩进的 JSON
+func multilineValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strings.TrimRight(s, "\n")
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// wrapLine 按终端宽度在空格处折行，续行使用缩进，颜色控制字符不计入宽度
+func wrapLine(line string, width int) string {
+	words := strings.Split(line, " ")
+	var sb strings.Builder
+	lineLen := 0
+	for i, word := range words {
+		wordLen := visibleLen(word)
+		if i > 0 {
+			if lineLen+1+wordLen > width {
+				sb.WriteString("\n" + wrapIndent)
+				lineLen = len(wrapIndent)
+			} else {
+				sb.WriteByte(' ')
+				lineLen++
+			}
+		}
+		sb.WriteString(word)
+		lineLen += wordLen
+	}
+	return sb.String()
+}
+
+// visibleLen 返回去除 ANSI 颜色控制字符后的显示宽度（按字符计算）
+func visibleLen(s string) int {
+	n := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			if r == 'm' {
+				inEscape = false
+			}
+		case r == '\x1b':
+			inEscape = true
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden 将输出与 testdata 下的 golden 文件比较，-update 时重新生成
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestConsoleLevelLabels(t *testing.T) {
+	consoleLevelLabels = map[zerolog.Level]string{
+		zerolog.InfoLevel:  "信息",
+		zerolog.WarnLevel:  "警告",
+		zerolog.ErrorLevel: "错误",
+	}
+	translations := map[string]string{"server started": "服务已启动"}
+	messageTranslator = func(msg string) string {
+		if s, ok := translations[msg]; ok {
+			return s
+		}
+		return msg
+	}
+	t.Cleanup(func() {
+		consoleLevelLabels = nil
+		messageTranslator = nil
+	})
+
+	out := &bytes.Buffer{}
+	w := newConsoleWriter(out, true)
+	lines := []string{
+		`{"level":"info","time":"2024-07-18 15:04:05","message":"server started","port":8080}`,
+		`{"level":"warn","time":"2024-07-18 15:04:06","message":"disk almost full"}`,
+		`{"level":"error","time":"2024-07-18 15:04:07","message":"request failed","error":"timeout"}`,
+		`{"level":"debug","time":"2024-07-18 15:04:08","message":"cache miss"}`,
+	}
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertGolden(t, "console_labels.golden", out.Bytes())
+
+	// 文件输出保持英文级别与原始消息
+	path := filepath.Join(t.TempDir(), "labels.log")
+	InitLogger(Config{
+		LogPath:            path,
+		ProjectKey:         "project",
+		EnableFileOutput:   true,
+		ConsoleLevelLabels: consoleLevelLabels,
+		MessageTranslator:  messageTranslator,
+	})
+	t.Cleanup(Close)
+	Info("server started")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := decodeLine(t, content); m["level"] != "info" || m["message"] != "server started" {
+		t.Errorf("file output should keep canonical values: %v", m)
+	}
+}
+
+func TestConsoleMultiline(t *testing.T) {
+	consoleMultiline = true
+	MarkMultiline("sql", "payload")
+	prevWidth := terminalWidth
+	terminalWidth = func(io.Writer) int { return 60 }
+	t.Cleanup(func() {
+		consoleMultiline = false
+		terminalWidth = prevWidth
+		multilineKeys = []string{"stack"}
+	})
+
+	out := &bytes.Buffer{}
+	w := newConsoleWriter(out, true)
+	lines := []string{
+		`{"level":"error","time":"2024-07-18 15:04:05","message":"query failed","user":"u1","sql":"SELECT *\nFROM users\nWHERE id = 1","stack":"goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12"}`,
+		`{"level":"info","time":"2024-07-18 15:04:06","message":"request with a long field list","method":"GET","path":"/api/v1/users","status":200,"latency_ms":12,"payload":{"id":1,"tags":["a","b"]}}`,
+		`{"level":"info","time":"2024-07-18 15:04:07","message":"plain"}`,
+	}
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertGolden(t, "console_multiline.golden", out.Bytes())
+}
+
+func TestConsoleFormatters(t *testing.T) {
+	consoleLevelLabels = map[zerolog.Level]string{zerolog.InfoLevel: "信息"}
+	consoleFormatters = ConsoleFormatters{
+		FormatLevel: func(i interface{}) string {
+			if i == "error" {
+				return "🔥"
+			}
+			return "ℹ️"
+		},
+		FormatMessage:    func(i interface{}) string { return fmt.Sprintf("«%v»", i) },
+		FormatTimestamp:  func(i interface{}) string { return "[" + fmt.Sprint(i) + "]" },
+		FormatFieldValue: func(i interface{}) string { return strings.ToUpper(fmt.Sprint(i)) },
+	}
+	t.Cleanup(func() {
+		consoleLevelLabels = nil
+		consoleFormatters = ConsoleFormatters{}
+	})
+
+	out := &bytes.Buffer{}
+	w := newConsoleWriter(out, true)
+	w.Write([]byte(`{"level":"info","time":"2024-07-18 15:04:05","message":"server started","user":"u1"}` + "\n"))
+	w.Write([]byte(`{"level":"error","time":"2024-07-18 15:04:06","message":"request failed"}` + "\n"))
+	want := "[2024-07-18 15:04:05] ℹ️ «server started» user=U1\n[2024-07-18 15:04:06] 🔥 «request failed»\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\ngot:  %q\nwant: %q", out.String(), want)
+	}
+}
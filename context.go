@@ -0,0 +1,61 @@
+// @Author Clover
+// @Data 2026/10/17 下午2:40:00
+// @Desc 携带 context.Context 的日志函数
+
+package logging
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// InfoCtx 与 Info 相同，额外输出 ctx 中携带的字段（如 ContextWithTraceparent 存入的跟踪信息），
+// ctx 经过 ElevateContext 提升时以提升后的级别作为最低级别；ctx 带有截止时间时，写入各输出目标最多等待到截止时间，未完成的写入转入后台继续进行
+func InfoCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	emitCtx(ctx, zerolog.InfoLevel, nil, msg, fields)
+}
+
+func ErrorCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	emitCtx(ctx, zerolog.ErrorLevel, nil, msg, fields)
+}
+
+func ErrorWithErrCtx(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+	if err == nil && skipNilErrorsEnabled() {
+		return
+	}
+	emitCtx(ctx, zerolog.ErrorLevel, err, msg, fields)
+}
+
+func DebugCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	emitCtx(ctx, zerolog.DebugLevel, nil, msg, fields)
+}
+
+func WarnCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	emitCtx(ctx, zerolog.WarnLevel, nil, msg, fields)
+}
+
+func WarnWithErrCtx(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+	if err == nil && skipNilErrorsEnabled() {
+		return
+	}
+	emitCtx(ctx, zerolog.WarnLevel, err, msg, fields)
+}
+
+// emitCtx 从 contextLogger(ctx) 创建事件并输出，事件被提升级别时同样从该日志记录器重新创建
+func emitCtx(ctx context.Context, level zerolog.Level, err error, msg string, fields []map[string]interface{}) {
+	logger := contextLogger(ctx)
+	emitFrom(logger, withErr(contextEvent(ctx, logger, level), err), level, err, msg, withContextFields(ctx, fields))
+}
+
+// withContextFields 将 ctx 中携带的字段放在最前面，调用方传入的同名字段优先
+func withContextFields(ctx context.Context, fields []map[string]interface{}) []map[string]interface{} {
+	if ctx == nil {
+		return fields
+	}
+	tf := traceFields(ctx)
+	if tf == nil {
+		return fields
+	}
+	return append([]map[string]interface{}{tf}, fields...)
+}
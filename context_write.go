@@ -0,0 +1,172 @@
+// @Author Clover
+// @Data 2026/10/17 下午7:10:00
+// @Desc 按 ctx 截止时间写入阻塞的输出目标
+
+package logging
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// maxSpilledWrites 截止时间到达后仍在后台进行的写入上限，超过后直接丢弃日志
+const maxSpilledWrites = 1024
+
+// ContextWriter 可以在 ctx 结束时放弃写入的输出目标，例如网络输出目标
+// 未实现该接口的输出目标在后台写入，调用方只等待到 ctx 结束
+type ContextWriter interface {
+	WriteContext(ctx context.Context, p []byte) (int, error)
+}
+
+var (
+	spills        spillTracker
+	deadlineSpill atomic.Int64 // 截止时间到达时转为后台写入的次数
+	deadlineDrop  atomic.Int64 // 后台写入过多而丢弃的次数
+)
+
+// DeadlineSpills 返回因 ctx 截止时间到达而转为后台写入的次数
+func DeadlineSpills() int64 {
+	return deadlineSpill.Load()
+}
+
+// DeadlineDrops 返回因后台写入过多而丢弃的次数
+func DeadlineDrops() int64 {
+	return deadlineDrop.Load()
+}
+
+// spillTracker 记录仍在后台进行的写入数量
+type spillTracker struct {
+	mu      sync.Mutex
+	pending int
+	changed chan struct{}
+}
+
+// acquire 登记一次后台写入，已达到上限时返回 false
+func (st *spillTracker) acquire() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.pending >= maxSpilledWrites {
+		return false
+	}
+	st.pending++
+	return true
+}
+
+func (st *spillTracker) release() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.pending--
+	if st.changed != nil {
+		close(st.changed)
+		st.changed = nil
+	}
+}
+
+// Pending 返回仍在后台进行的写入数量
+func (st *spillTracker) Pending() uint64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return uint64(st.pending)
+}
+
+// Barrier 等待后台写入全部完成
+func (st *spillTracker) Barrier(ctx context.Context) error {
+	for {
+		st.mu.Lock()
+		if st.pending == 0 {
+			st.mu.Unlock()
+			return nil
+		}
+		if st.changed == nil {
+			st.changed = make(chan struct{})
+		}
+		ch := st.changed
+		st.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// contextLogger 返回当前日志记录器；ctx 带有截止时间时，各输出目标的写入不会超过该截止时间
+func contextLogger(ctx context.Context) *zerolog.Logger {
+	ensureLogger()
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	l := baseLogger
+	if ctx == nil || len(activeWriters) == 0 {
+		return &l
+	}
+	if _, ok := ctx.Deadline(); ok {
+		l = l.Output(contextMultiWriter{ctx: ctx, writers: activeWriters})
+	}
+	return &l
+}
+
+// contextMultiWriter 依次写入各输出目标，每个输出目标最多等待到 ctx 结束
+type contextMultiWriter struct {
+	ctx     context.Context
+	writers []io.Writer
+}
+
+func (mw contextMultiWriter) Write(p []byte) (int, error) {
+	return mw.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 返回第一个写入错误，某个输出目标出错不影响其他输出目标
+func (mw contextMultiWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var firstErr error
+	for _, w := range mw.writers {
+		if _, err := writeContext(mw.ctx, w, level, p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return len(p), firstErr
+}
+
+// writeContext 写入单个输出目标：实现 ContextWriter 的输出目标自行处理 ctx，
+// 其余输出目标在后台写入，ctx 结束时不再等待，日志留在后台继续写入（可能晚于之后的日志），
+// 后台写入已达到上限时丢弃日志
+func writeContext(ctx context.Context, w io.Writer, level zerolog.Level, p []byte) (int, error) {
+	if tw, ok := w.(*trackedWriter); ok {
+		start := tw.startTimer()
+		n, err := writeContext(ctx, tw.w, level, p)
+		tw.finish(level, start, err)
+		return n, err
+	}
+	if cw, ok := w.(ContextWriter); ok {
+		return cw.WriteContext(ctx, p)
+	}
+	if !spills.acquire() {
+		deadlineDrop.Add(1)
+		return len(p), nil
+	}
+	// zerolog 会复用 p，后台写入需要使用副本
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	done := make(chan writeResult, 1)
+	go func() {
+		defer spills.release()
+		var res writeResult
+		if lw, ok := w.(zerolog.LevelWriter); ok {
+			res.n, res.err = lw.WriteLevel(level, buf)
+		} else {
+			res.n, res.err = w.Write(buf)
+		}
+		done <- res
+	}()
+	select {
+	case res := <-done:
+		return res.n, res.err
+	case <-ctx.Done():
+		deadlineSpill.Add(1)
+		return len(p), nil
+	}
+}
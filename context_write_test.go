@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter 在 release 关闭前阻塞所有写入
+type blockingWriter struct {
+	release chan struct{}
+	buf     syncBuffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.buf.Write(p)
+}
+
+// ctxRecorder 记录 WriteContext 收到的 ctx
+type ctxRecorder struct {
+	mu       sync.Mutex
+	deadline bool
+	buf      syncBuffer
+}
+
+func (w *ctxRecorder) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *ctxRecorder) WriteContext(ctx context.Context, p []byte) (int, error) {
+	w.mu.Lock()
+	_, w.deadline = ctx.Deadline()
+	w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestContextDeadlineWrite(t *testing.T) {
+	blocked := &blockingWriter{release: make(chan struct{})}
+	fast := &syncBuffer{}
+	recorder := &ctxRecorder{}
+	// ctx 结束后之后的输出目标也会转入后台写入，阻塞的输出目标放在最后便于断言
+	InitLogger(Config{ProjectKey: "project"}, WithWriters(fast, recorder, blocked))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+	spilled := DeadlineSpills()
+
+	// 阻塞的输出目标不会让调用方等待超过截止时间
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	InfoCtx(ctx, "bounded")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("InfoCtx should return near the deadline, took %v", elapsed)
+	}
+	if DeadlineSpills() != spilled+1 {
+		t.Errorf("expected one spilled write, got %d", DeadlineSpills()-spilled)
+	}
+	if !strings.Contains(fast.String(), "bounded") {
+		t.Errorf("other sinks should still receive the event: %q", fast.String())
+	}
+	recorder.mu.Lock()
+	deadline := recorder.deadline
+	recorder.mu.Unlock()
+	if !deadline || !strings.Contains(recorder.buf.String(), "bounded") {
+		t.Errorf("ContextWriter should receive the caller's ctx")
+	}
+
+	// 后台写入在输出目标恢复后完成，Barrier 会等待它
+	barrierCtx, cancelBarrier := context.WithTimeout(context.Background(), time.Millisecond)
+	if err := Barrier(barrierCtx); err == nil || !strings.Contains(err.Error(), "deadline spills") {
+		t.Errorf("expected pending spill in barrier error, got %v", err)
+	}
+	cancelBarrier()
+	close(blocked.release)
+	barrierCtx, cancelBarrier = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelBarrier()
+	if err := Barrier(barrierCtx); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	if !strings.Contains(blocked.buf.String(), "bounded") {
+		t.Errorf("spilled event should be written once the sink recovers: %q", blocked.buf.String())
+	}
+
+	// 不带截止时间的 ctx 与普通写入相同
+	InfoCtx(context.Background(), "unbounded")
+	if DeadlineSpills() != spilled+1 || !strings.Contains(blocked.buf.String(), "unbounded") {
+		t.Errorf("context without deadline should write synchronously")
+	}
+}
+
+func TestContextDeadlineDrop(t *testing.T) {
+	for i := 0; i < maxSpilledWrites; i++ {
+		if !spills.acquire() {
+			t.Fatalf("acquire %d failed", i)
+		}
+	}
+	t.Cleanup(func() {
+		for i := 0; i < maxSpilledWrites; i++ {
+			spills.release()
+		}
+	})
+	dropped := DeadlineDrops()
+	var buf syncBuffer
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if n, err := writeContext(ctx, &buf, 0, []byte("x\n")); n != 2 || err != nil {
+		t.Errorf("unexpected result: %d %v", n, err)
+	}
+	if DeadlineDrops() != dropped+1 || buf.String() != "" {
+		t.Errorf("write should be dropped when too many writes are spilled")
+	}
+}
+
+func TestTimeoutWriterContext(t *testing.T) {
+	slow := &slowWriter{delay: 200 * time.Millisecond}
+	tw := newTimeoutWriter(slow, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := tw.WriteContext(ctx, []byte("x\n")); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("WriteContext should return at the ctx deadline, took %v", elapsed)
+	}
+	if err := tw.Barrier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if slow.buf.String() != "x\n" {
+		t.Errorf("write should complete in the background: %q", slow.buf.String())
+	}
+}
@@ -0,0 +1,181 @@
+// @Author Clover
+// @Data 2026/10/17 下午10:50:00
+// @Desc 输出日志记录器的自诊断信息
+
+package logging
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// maskedConfigValue 诊断信息中代替敏感配置值的内容
+const maskedConfigValue = "***"
+
+// secretFieldPattern 名称匹配时视为敏感的配置字段
+var secretFieldPattern = regexp.MustCompile(`(?i)password|secret|token|credential|dsn`)
+
+var activeConfig Config // 最近一次 InitLogger 使用的配置（已应用环境变量覆盖），由 stateMu 保护
+
+// Diagnostics 日志记录器的自诊断信息，可直接序列化为 JSON
+type Diagnostics struct {
+	Time        time.Time              `json:"time"`
+	Initialized bool                   `json:"initialized"`
+	Config      map[string]interface{} `json:"config"`          // 非零值的配置字段，敏感字段与 Masker 匹配的内容已脱敏
+	Snapshot    map[string]interface{} `json:"config_snapshot"` // 与 ConfigSnapshot 相同，包含默认值与每个字段的来源
+	Levels      DiagnosticLevels       `json:"levels"`
+	Sinks       []SinkHealth           `json:"sinks"`
+	Files       []FileDiagnostics      `json:"files"`
+	Counts      DiagnosticCounts       `json:"counts"`
+}
+
+// DiagnosticLevels 实际生效的日志级别
+type DiagnosticLevels struct {
+	Global     string            `json:"global"`     // zerolog 全局级别，SetLogLevel 修改的就是该级别
+	Configured string            `json:"configured"` // Config.LogLevel
+	Outputs    map[string]string `json:"outputs,omitempty"`
+}
+
+// FileDiagnostics 磁盘上实际观察到的日志文件状态
+type FileDiagnostics struct {
+	Path         string `json:"path"`
+	Exists       bool   `json:"exists"`
+	Size         int64  `json:"size"`
+	Mode         string `json:"mode,omitempty"`
+	Error        string `json:"error,omitempty"`
+	MonitorAlive bool   `json:"monitor_alive"` // 大小与使用时间监控协程是否仍在运行
+}
+
+// DiagnosticCounts 已注册的回调、过滤规则与输出目标数量
+type DiagnosticCounts struct {
+	Sinks         int `json:"sinks"`
+	LevelHooks    int `json:"level_hooks"`
+	Maskers       int `json:"maskers"`
+	Suppressions  int `json:"suppressions"`
+	AllowedFields int `json:"allowed_fields"`
+	FieldTypes    int `json:"field_types"`
+}
+
+// Diagnose 收集日志记录器的自诊断信息，以 Info 级别输出到所有输出目标并返回，
+// 用于排查"日志没有输出"之类的问题：写入失败的输出目标会在 Sinks 中标记为不健康并附带最近一次错误
+func Diagnose() Diagnostics {
+	d := collectDiagnostics()
+	currentLogger().Info().Interface("diagnostics", d).Msg("logging diagnostics")
+	return d
+}
+
+func collectDiagnostics() Diagnostics {
+	stateMu.RLock()
+	d := Diagnostics{
+		Time:        now(),
+		Initialized: !startedAt.IsZero(),
+		Config:      renderConfig(activeConfig),
+		Snapshot:    configSnapshot(),
+		Levels: DiagnosticLevels{
+			Global:     zerolog.GlobalLevel().String(),
+			Configured: activeConfig.LogLevel,
+		},
+		Counts: DiagnosticCounts{
+			Sinks:         len(activeWriters),
+			Maskers:       len(options.maskers),
+			AllowedFields: len(allowedFields),
+			FieldTypes:    len(fieldTypes),
+		},
+	}
+	if logfile != nil {
+		d.Files = append(d.Files, observeFile(logPath, logfile))
+	}
+	for _, o := range outputs {
+		if d.Levels.Outputs == nil {
+			d.Levels.Outputs = make(map[string]string)
+		}
+		d.Levels.Outputs[o.config.Path] = o.config.Level.String()
+		if o.aliasOf == "" {
+			d.Files = append(d.Files, observeFile(o.config.Path, o.file))
+		}
+	}
+	stateMu.RUnlock()
+
+	d.Sinks = sinkHealth()
+	levelHooksMu.RLock()
+	for _, hooks := range levelHooks {
+		d.Counts.LevelHooks += len(hooks)
+	}
+	levelHooksMu.RUnlock()
+	d.Counts.Suppressions = int(suppressActive.Load())
+	return d
+}
+
+// observeFile 读取磁盘上的文件信息，而不是日志记录器内部记录的状态
+func observeFile(path string, lf *logFile) FileDiagnostics {
+	fd := FileDiagnostics{Path: path, MonitorAlive: lf.monitorAlive()}
+	fi, err := os.Stat(path)
+	if err != nil {
+		fd.Error = err.Error()
+		return fd
+	}
+	fd.Exists, fd.Size, fd.Mode = true, fi.Size(), fi.Mode().Perm().String()
+	return fd
+}
+
+// renderConfig 将配置转换为字段名到值的映射，省略零值，函数与接口只记录是否设置，
+// 名称看起来敏感的字段替换为 ***，其余字符串经过 Masker 处理，调用方需持有 stateMu
+func renderConfig(config Config) map[string]interface{} {
+	return renderStruct(reflect.ValueOf(config))
+}
+
+func renderStruct(v reflect.Value) map[string]interface{} {
+	return configRenderer{omitZero: true, secret: func(reflect.Value) interface{} { return maskedConfigValue }}.renderStruct(v)
+}
+
+// configRenderer 将配置转换为可以序列化为 JSON 的值，调用方需持有 stateMu
+type configRenderer struct {
+	omitZero bool                            // 是否省略零值字段
+	secret   func(reflect.Value) interface{} // 名称看起来敏感的非零字段的输出内容
+}
+
+func (cr configRenderer) renderStruct(v reflect.Value) map[string]interface{} {
+	m := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if !f.IsExported() || (cr.omitZero && fv.IsZero()) {
+			continue
+		}
+		if val, ok := cr.renderField(f.Name, fv); ok {
+			m[f.Name] = val
+		}
+	}
+	return m
+}
+
+// renderField 转换单个字段，omitZero 时内容全部为零值的结构体返回 false
+func (cr configRenderer) renderField(name string, fv reflect.Value) (interface{}, bool) {
+	switch {
+	case secretFieldPattern.MatchString(name) && !fv.IsZero():
+		return cr.secret(fv), true
+	case fv.Kind() == reflect.Func, fv.Kind() == reflect.Interface:
+		if fv.IsNil() {
+			return nil, true
+		}
+		return "set", true
+	case fv.Type() == durationType:
+		return time.Duration(fv.Int()).String(), true
+	case fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}):
+		sub := cr.renderStruct(fv)
+		return sub, !cr.omitZero || len(sub) > 0
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct:
+		items := make([]interface{}, fv.Len())
+		for i := range items {
+			items[i] = cr.renderStruct(fv.Index(i))
+		}
+		return items, true
+	case fv.Kind() == reflect.String:
+		return maskString(fv.String()), true
+	}
+	return fv.Interface(), true
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDiagnosePinpointsBrokenSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diag.log")
+	good := &syncBuffer{}
+	prevLevel := zerolog.GlobalLevel()
+	InitLogger(Config{
+		LogPath:          path,
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+		MonitorInterval:  time.Hour,
+		LogLevel:         "info",
+	}, WithWriters(good, failingWriter{}))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+		zerolog.SetGlobalLevel(prevLevel)
+	})
+
+	Info("before diagnose")
+	d := Diagnose()
+
+	if !d.Initialized || d.Levels.Configured != "info" || d.Config["LogPath"] != path || d.Config["MonitorInterval"] != "1h0m0s" {
+		t.Errorf("unexpected resolved configuration: %+v %+v", d.Levels, d.Config)
+	}
+	if len(d.Sinks) != 3 || d.Counts.Sinks != 3 {
+		t.Fatalf("expected 3 sinks, got %+v", d.Sinks)
+	}
+	for _, s := range d.Sinks {
+		broken := s.Name == "writer:logging.failingWriter"
+		if s.Healthy == broken {
+			t.Errorf("only the failing writer should be unhealthy: %+v", s)
+		}
+		if broken && (s.LastError != "disk full" || s.Errors == 0 || !s.LastWrite.IsZero()) {
+			t.Errorf("broken sink should report its last error: %+v", s)
+		}
+		if !broken && (s.Errors != 0 || s.LastWrite.IsZero()) {
+			t.Errorf("healthy sink should report its last write: %+v", s)
+		}
+	}
+	if len(d.Files) != 1 || !d.Files[0].Exists || d.Files[0].Size == 0 || d.Files[0].Mode == "" || !d.Files[0].MonitorAlive {
+		t.Errorf("unexpected file diagnostics: %+v", d.Files)
+	}
+
+	// 诊断信息同时输出到仍然可用的输出目标
+	lines := strings.Split(strings.TrimSpace(good.String()), "\n")
+	var event struct {
+		Message     string      `json:"message"`
+		Diagnostics Diagnostics `json:"diagnostics"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &event); err != nil {
+		t.Fatalf("decode diagnostics event: %v", err)
+	}
+	if event.Message != "logging diagnostics" || len(event.Diagnostics.Sinks) != 3 {
+		t.Errorf("unexpected diagnostics event: %+v", event)
+	}
+}
+
+func TestDiagnoseMasksSecrets(t *testing.T) {
+	type sinkConfig struct {
+		Addr     string
+		Password string
+		Timeout  time.Duration
+	}
+	m := renderStruct(reflect.ValueOf(sinkConfig{Addr: "redis:6379", Password: "hunter2", Timeout: time.Second}))
+	if m["Password"] != "***" || m["Addr"] != "redis:6379" || m["Timeout"] != "1s" {
+		t.Errorf("unexpected rendered config: %v", m)
+	}
+}
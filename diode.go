@@ -0,0 +1,92 @@
+// @Author Clover
+// @Data 2026/10/17 下午11:30:00
+// @Desc 使用 zerolog/diode 在后台写入输出目标，日志调用不再等待写入
+
+package logging
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/Clov614/logging/internal/watermark"
+	"github.com/rs/zerolog/diode"
+)
+
+const (
+	defaultAsyncQueueSize = 1000
+	diodePollInterval     = 10 * time.Millisecond // 后台协程检查新日志的间隔，轮询可以避免 Waiter 丢失唤醒
+)
+
+var (
+	diodeMode      bool           // 是否通过 diode 在后台写入，由 stateMu 保护
+	asyncQueueSize int            // 每个 diode 的容量
+	diodes         []*diodeWriter // 当前使用的 diode，Close 时等待写完
+	diodeDropped   atomic.Int64   // diode 已满时被覆盖而丢弃的日志数
+)
+
+// DiodeDropped 返回开启 Config.DiodeMode 后因后台写入跟不上而丢弃的日志数
+func DiodeDropped() int64 {
+	return diodeDropped.Load()
+}
+
+// diodeWriter 将日志放入无锁的 diode 后立即返回，由后台协程写入被包装的输出目标；
+// diode 已满时覆盖最早的日志，不会阻塞调用方
+type diodeWriter struct {
+	d         diode.Writer
+	w         io.Writer
+	mark      watermark.Watermark // 已放入与已处理（写入或丢弃）的日志数
+	processed atomic.Uint64
+}
+
+func newDiodeWriter(w io.Writer, size int) *diodeWriter {
+	dw := &diodeWriter{w: w}
+	// 隐藏被包装输出目标的 Close，关闭 diode 时不关闭调用方传入的输出目标
+	dw.d = diode.NewWriter(diodeSink{dw}, size, diodePollInterval, dw.dropped)
+	return dw
+}
+
+func (dw *diodeWriter) Write(p []byte) (int, error) {
+	dw.mark.Issue()
+	return dw.d.Write(p)
+}
+
+// Barrier 等待调用前放入 diode 的日志全部写入或丢弃，再等待被包装的输出目标
+func (dw *diodeWriter) Barrier(ctx context.Context) error {
+	if err := dw.mark.WaitIssued(ctx); err != nil {
+		return err
+	}
+	return barrierWriter(ctx, dw.w)
+}
+
+// Pending 返回放入 diode 但尚未写入的日志数
+func (dw *diodeWriter) Pending() uint64 {
+	return dw.mark.Pending()
+}
+
+// Close 停止后台协程，返回前写完 diode 中剩余的日志
+func (dw *diodeWriter) Close() error {
+	return dw.d.Close()
+}
+
+func (dw *diodeWriter) dropped(missed int) {
+	diodeDropped.Add(int64(missed))
+	dw.mark.Complete(dw.processed.Add(uint64(missed)))
+}
+
+// diodeSink 后台协程的写入目标
+type diodeSink struct{ dw *diodeWriter }
+
+func (s diodeSink) Write(p []byte) (int, error) {
+	n, err := s.dw.w.Write(p)
+	s.dw.mark.Complete(s.dw.processed.Add(1))
+	return n, err
+}
+
+// closeDiodes 写完并关闭 diode，需在关闭日志文件之前调用
+func closeDiodes(ds []*diodeWriter) {
+	for _, dw := range ds {
+		_ = dw.Close()
+	}
+}
@@ -0,0 +1,173 @@
+package logging
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// contendedWriter 写入时持有锁并等待 delay，模拟多个协程争用同一个较慢的输出目标
+type contendedWriter struct {
+	mu    sync.Mutex
+	delay time.Duration
+	lines int
+}
+
+func (w *contendedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	time.Sleep(w.delay)
+	w.lines++
+	return len(p), nil
+}
+
+func (w *contendedWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lines
+}
+
+func TestDiodeModeDrainsOnClose(t *testing.T) {
+	w := &contendedWriter{delay: 5 * time.Millisecond}
+	InitLogger(Config{
+		LogPath:        filepath.Join(t.TempDir(), "diode.log"),
+		ProjectKey:     "project",
+		DiodeMode:      true,
+		AsyncQueueSize: 128,
+	}, WithWriters(w))
+	t.Cleanup(func() { options = loggerOptions{} })
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		Info("queued")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("log calls should not wait for the slow writer, took %v", elapsed)
+	}
+	if w.count() == 20 {
+		t.Errorf("writes should still be pending in the diode")
+	}
+	Close()
+	if w.count() != 20 {
+		t.Errorf("Close should drain the diode, got %d lines", w.count())
+	}
+}
+
+func TestDiodeModeBarrier(t *testing.T) {
+	w := &contendedWriter{delay: time.Millisecond}
+	InitLogger(Config{
+		LogPath:    filepath.Join(t.TempDir(), "diode.log"),
+		ProjectKey: "project",
+		DiodeMode:  true,
+	}, WithWriters(w))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+
+	for i := 0; i < 10; i++ {
+		Info("queued")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Barrier(ctx); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	if w.count() != 10 {
+		t.Errorf("Barrier should wait for the diode, got %d lines", w.count())
+	}
+	for _, s := range Diagnose().Sinks {
+		if strings.HasPrefix(s.Name, "writer:") && (!s.Async || s.QueueDepth > 1) {
+			t.Errorf("diode sink should report its queue depth: %+v", s)
+		}
+	}
+}
+
+// BenchmarkDiodeLatency 比较 16 个协程同时输出日志时同步写入与 diode 写入的 p99 延迟
+func BenchmarkDiodeLatency(b *testing.B) {
+	for _, mode := range []struct {
+		name  string
+		diode bool
+	}{{"sync", false}, {"diode", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			InitLogger(Config{
+				LogPath:        filepath.Join(b.TempDir(), "bench.log"),
+				ProjectKey:     "project",
+				DiodeMode:      mode.diode,
+				AsyncQueueSize: 1 << 16,
+			}, WithWriters(&contendedWriter{delay: 10 * time.Microsecond}))
+			defer func() {
+				Close()
+				options = loggerOptions{}
+			}()
+
+			const goroutines = 16
+			latencies := make([][]time.Duration, goroutines)
+			var wg sync.WaitGroup
+			b.ResetTimer()
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := g; i < b.N; i += goroutines {
+						start := time.Now()
+						Info("benchmark", map[string]interface{}{"i": i})
+						latencies[g] = append(latencies[g], time.Since(start))
+					}
+				}(g)
+			}
+			wg.Wait()
+			b.StopTimer()
+
+			var all []time.Duration
+			for _, l := range latencies {
+				all = append(all, l...)
+			}
+			sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+			b.ReportMetric(float64(all[len(all)*99/100].Nanoseconds()), "p99-ns")
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	// 同步写入时直接返回
+	if err := Drain(); err != nil {
+		t.Fatalf("drain without async sinks: %v", err)
+	}
+
+	w := &contendedWriter{delay: 2 * time.Millisecond}
+	InitLogger(Config{ProjectKey: "project", DiodeMode: true, AsyncQueueSize: 64, DrainTimeout: 5 * time.Second}, WithWriters(w))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+	for i := 0; i < 50; i++ {
+		Info("queued")
+	}
+	if err := Logger.Drain(); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if w.count() != 50 {
+		t.Errorf("Drain should wait for the queue to empty, got %d lines", w.count())
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	w := &contendedWriter{delay: 20 * time.Millisecond}
+	InitLogger(Config{ProjectKey: "project", DiodeMode: true, AsyncQueueSize: 64, DrainTimeout: 10 * time.Millisecond}, WithWriters(w))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+	for i := 0; i < 20; i++ {
+		Info("queued")
+	}
+	err := Drain()
+	if err == nil || !strings.Contains(err.Error(), "drain incomplete") || !strings.Contains(err.Error(), "pending") {
+		t.Errorf("expected incomplete drain error, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+// @Author Clover
+// @Data 2026/10/17 下午3:30:00
+// @Desc DryRun 模式：完整执行日志处理流程但不写入真实的输出目标
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	dryRunSampleSize  = 5               // 每个输出目标保留的示例条目数
+	dryRunPingTimeout = 5 * time.Second // 检查网络输出目标连通性的超时时间
+)
+
+// Pinger 可以检查连通性的网络输出目标，DryRun 模式下只检查连通性而不发送日志
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Report DryRun 模式下记录的"本应写入哪里"
+type Report struct {
+	Sinks      []SinkReport    `json:"sinks"`
+	Rotations  []RotationEvent `json:"rotations,omitempty"`  // 本应触发的日志文件清除
+	Redactions map[string]int  `json:"redactions,omitempty"` // 被字段白名单丢弃的字段及次数
+}
+
+// SinkReport 单个输出目标的记录
+type SinkReport struct {
+	Name    string     `json:"name"`              // console、file:<path>、output:<path>、eventlog:<source> 或 writer:<type>
+	Count   int        `json:"count"`             // 本应写入的日志条数
+	Bytes   int64      `json:"bytes"`             // 本应写入的 JSON 字节数
+	Samples []LogEntry `json:"samples,omitempty"` // 最先写入的几条日志
+	Error   string     `json:"error,omitempty"`   // 配置错误或连通性检查失败的原因
+}
+
+// RotationEvent 本应触发的日志文件清除
+type RotationEvent struct {
+	Sink   string    `json:"sink"`
+	Reason string    `json:"reason"` // size 或 age
+	Size   int64     `json:"size"`   // 清除前的文件大小
+	Time   time.Time `json:"time"`
+}
+
+// dryRun DryRun 模式下的记录器，未开启时为 nil，由 stateMu 保护
+var dryRun *dryRunRecorder
+
+// DryRunReport 返回 DryRun 模式下记录的结果，Close 之后仍可获取，直到下一次 InitLogger
+// 未开启 Config.DryRun 时返回空的 Report
+func DryRunReport() Report {
+	stateMu.RLock()
+	rec := dryRun
+	stateMu.RUnlock()
+	if rec == nil {
+		return Report{}
+	}
+	return rec.report()
+}
+
+// dryRunRecorder 汇总所有输出目标的记录
+type dryRunRecorder struct {
+	mu         sync.Mutex
+	sinks      []*dryRunSink
+	rotations  []RotationEvent
+	redactions map[string]int
+}
+
+// newDryRunRecorder 按配置为每个输出目标创建记录器，不打开任何文件；
+// 实现了 Pinger 的额外输出目标会检查连通性
+func newDryRunRecorder(config Config, writers []io.Writer) *dryRunRecorder {
+	rec := &dryRunRecorder{redactions: make(map[string]int)}
+	if config.EnableConsoleOutput {
+		rec.addSink("console")
+	}
+	if config.EnableFileOutput {
+		s := rec.addSink("file:" + config.LogPath)
+		s.setRotation(config.LogPath, config.MaxLogSize, config.MaxFileAge)
+	}
+	for _, oc := range config.Outputs {
+		s := rec.addSink("output:" + oc.Path)
+		s.min = oc.Level
+		switch oc.Format {
+		case "", OutputFormatJSON, OutputFormatConsole, OutputFormatLogfmt:
+		default:
+			s.report.Error = fmt.Sprintf("unknown format %q", oc.Format)
+		}
+		if oc.Rotate {
+			s.setRotation(oc.Path, config.MaxLogSize, config.MaxFileAge)
+		}
+	}
+	if config.EnableWindowsEventLog {
+		rec.addSink("eventlog:" + eventLogSource(config))
+	}
+	if config.UnixSocket.SocketPath != "" {
+		s := rec.addSink("unix:" + config.UnixSocket.SocketPath)
+		if err := validUnixSocketConfig(config.UnixSocket); err != nil {
+			s.report.Error = err.Error()
+		}
+	}
+	for _, w := range writers {
+		s := rec.addSink(fmt.Sprintf("writer:%T", w))
+		if p, ok := w.(Pinger); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), dryRunPingTimeout)
+			if err := p.Ping(ctx); err != nil {
+				s.report.Error = fmt.Sprintf("ping failed: %v", err)
+			}
+			cancel()
+		}
+	}
+	return rec
+}
+
+func (rec *dryRunRecorder) addSink(name string) *dryRunSink {
+	s := &dryRunSink{rec: rec, report: SinkReport{Name: name}, min: zerolog.TraceLevel}
+	rec.sinks = append(rec.sinks, s)
+	return s
+}
+
+// writers 返回代替真实输出目标的记录器
+func (rec *dryRunRecorder) writers() []io.Writer {
+	writers := make([]io.Writer, len(rec.sinks))
+	for i, s := range rec.sinks {
+		writers[i] = s
+	}
+	return writers
+}
+
+// recordRedactions 记录被字段白名单丢弃的字段
+func (rec *dryRunRecorder) recordRedactions(keys []string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for _, k := range keys {
+		rec.redactions[k]++
+	}
+}
+
+// report 返回当前记录结果的副本
+func (rec *dryRunRecorder) report() Report {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	r := Report{Sinks: make([]SinkReport, len(rec.sinks))}
+	for i, s := range rec.sinks {
+		r.Sinks[i] = s.report
+		r.Sinks[i].Samples = append([]LogEntry(nil), s.report.Samples...)
+	}
+	r.Rotations = append(r.Rotations, rec.rotations...)
+	if len(rec.redactions) > 0 {
+		r.Redactions = make(map[string]int, len(rec.redactions))
+		for k, v := range rec.redactions {
+			r.Redactions[k] = v
+		}
+	}
+	sort.SliceStable(r.Rotations, func(i, j int) bool { return r.Rotations[i].Time.Before(r.Rotations[j].Time) })
+	return r
+}
+
+// dryRunSink 代替单个输出目标，只记录本应写入的日志，按 maxSize 与 maxAge 模拟日志文件的清除
+type dryRunSink struct {
+	rec    *dryRunRecorder
+	report SinkReport
+	min    zerolog.Level
+
+	maxSize int64
+	maxAge  time.Duration
+	size    int64     // 模拟的文件大小，从现有文件的大小开始
+	start   time.Time // 模拟的文件起始时间
+}
+
+// setRotation 设置模拟清除的条件，只读取现有文件的大小，不会创建或修改文件
+func (s *dryRunSink) setRotation(path string, maxSize int64, maxAge time.Duration) {
+	s.maxSize, s.maxAge, s.start = maxSize, maxAge, now()
+	if fi, err := os.Stat(path); err == nil {
+		s.size = fi.Size()
+	}
+}
+
+func (s *dryRunSink) Write(p []byte) (int, error) {
+	return s.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 记录不低于 min 级别的日志
+func (s *dryRunSink) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level != zerolog.NoLevel && level < s.min {
+		return len(p), nil
+	}
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	s.report.Count++
+	s.report.Bytes += int64(len(p))
+	if len(s.report.Samples) < dryRunSampleSize {
+		if entry, err := parseLogEntry(p); err == nil {
+			s.report.Samples = append(s.report.Samples, entry)
+		}
+	}
+
+	if s.maxSize <= 0 && s.maxAge <= 0 {
+		return len(p), nil
+	}
+	s.size += int64(len(p))
+	t := now()
+	var reason string
+	switch {
+	case s.maxSize > 0 && s.size > s.maxSize:
+		reason = "size"
+	case s.maxAge > 0 && t.Sub(s.start) >= s.maxAge:
+		reason = "age"
+	default:
+		return len(p), nil
+	}
+	s.rec.rotations = append(s.rec.rotations, RotationEvent{Sink: s.report.Name, Reason: reason, Size: s.size, Time: t})
+	s.size, s.start = 0, t
+	return len(p), nil
+}
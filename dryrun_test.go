@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// unreachableSink 连通性检查失败的网络输出目标
+type unreachableSink struct{ bytes.Buffer }
+
+func (*unreachableSink) Ping(context.Context) error { return errors.New("connection refused") }
+
+func TestDryRun(t *testing.T) {
+	prev, prevLevel := baseLogger, zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() {
+		baseLogger = prev
+		zerolog.SetGlobalLevel(prevLevel)
+		setAllowedFields(nil, false)
+	})
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	warnPath := filepath.Join(dir, "warn.log")
+	plain, network := &bytes.Buffer{}, &unreachableSink{}
+	err := InitLogger(Config{
+		LogPath:             logPath,
+		ProjectKey:          "project",
+		ProjectName:         "dry",
+		EnableConsoleOutput: true,
+		EnableFileOutput:    true,
+		MaxLogSize:          150,
+		AllowedFields:       []string{"user"},
+		Outputs:             []OutputConfig{{Path: warnPath, Level: zerolog.WarnLevel, Rotate: true}},
+		DryRun:              true,
+	}, WithWriters(plain, network))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+
+	Info("request done", map[string]interface{}{"user": "u1", "password": "secret"})
+	Warn("slow query", map[string]interface{}{"password": "secret", "token": "t"})
+	Debug("cache miss")
+	Close()
+
+	for _, path := range []string{logPath, warnPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("dry run must not create %s: %v", path, err)
+		}
+	}
+	if plain.Len() != 0 || network.Len() != 0 {
+		t.Errorf("dry run must not write to sinks: %q %q", plain.String(), network.String())
+	}
+
+	report := DryRunReport()
+	want := []struct {
+		name  string
+		count int
+		err   string
+	}{
+		{"console", 3, ""},
+		{"file:" + logPath, 3, ""},
+		{"output:" + warnPath, 1, ""},
+		{"writer:*bytes.Buffer", 3, ""},
+		{"writer:*logging.unreachableSink", 3, "ping failed: connection refused"},
+	}
+	if len(report.Sinks) != len(want) {
+		t.Fatalf("unexpected sinks: %+v", report.Sinks)
+	}
+	for i, w := range want {
+		s := report.Sinks[i]
+		if s.Name != w.name || s.Count != w.count || s.Error != w.err || s.Bytes == 0 {
+			t.Errorf("sink %d = %+v, want %+v", i, s, w)
+		}
+	}
+	if samples := report.Sinks[2].Samples; len(samples) != 1 || samples[0].Message != "slow query" || samples[0].Level != zerolog.WarnLevel {
+		t.Errorf("unexpected samples: %+v", samples)
+	}
+	if report.Redactions["password"] != 2 || report.Redactions["token"] != 1 || len(report.Redactions) != 2 {
+		t.Errorf("unexpected redactions: %v", report.Redactions)
+	}
+	if len(report.Rotations) == 0 {
+		t.Fatal("expected rotations for the small MaxLogSize")
+	}
+	for _, r := range report.Rotations {
+		if (r.Sink != "file:"+logPath && r.Sink != "output:"+warnPath) || r.Reason != "size" || r.Size <= 150 {
+			t.Errorf("unexpected rotation: %+v", r)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// @Author Clover
+// @Data 2026/10/18 上午1:50:00
+// @Desc 每条日志输出时计算的动态字段
+
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// dynamicField 通过 RegisterDynamicField 注册的字段
+type dynamicField struct {
+	key string
+	fn  func() interface{}
+}
+
+var (
+	dynamicMu     sync.Mutex                     // 串行化注册与注销
+	dynamicFields atomic.Pointer[[]dynamicField] // 按注册顺序排列，只整体替换，输出日志时无需加锁
+)
+
+// RegisterDynamicField 注册在每条日志输出时调用的字段函数，返回值作为字段 key 的值，
+// 适用于协程数量、进行中的请求数等持续变化的值；同名字段已注册时替换原来的函数。
+// 函数只在日志级别启用时调用，调用方传入的同名字段优先；函数 panic 时字段值为 "<panic: ...>"，不影响日志输出
+func RegisterDynamicField(key string, fn func() interface{}) {
+	dynamicMu.Lock()
+	defer dynamicMu.Unlock()
+	var next []dynamicField
+	if cur := dynamicFields.Load(); cur != nil {
+		next = make([]dynamicField, 0, len(*cur)+1)
+		for _, f := range *cur {
+			if f.key != key {
+				next = append(next, f)
+			}
+		}
+	}
+	next = append(next, dynamicField{key: key, fn: fn})
+	dynamicFields.Store(&next)
+}
+
+// UnregisterDynamicField 注销动态字段
+func UnregisterDynamicField(key string) {
+	dynamicMu.Lock()
+	defer dynamicMu.Unlock()
+	cur := dynamicFields.Load()
+	if cur == nil {
+		return
+	}
+	next := make([]dynamicField, 0, len(*cur))
+	for _, f := range *cur {
+		if f.key != key {
+			next = append(next, f)
+		}
+	}
+	if len(next) == 0 {
+		dynamicFields.Store(nil)
+		return
+	}
+	dynamicFields.Store(&next)
+}
+
+// withDynamicFields 返回添加了动态字段的新字段集合，fields 中已有的字段不会被覆盖，也不修改 fields；
+// 没有注册动态字段时直接返回 fields
+func withDynamicFields(fields map[string]interface{}) map[string]interface{} {
+	cur := dynamicFields.Load()
+	if cur == nil {
+		return fields
+	}
+	merged := make(map[string]interface{}, len(fields)+len(*cur))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for _, f := range *cur {
+		if _, exists := merged[f.key]; !exists {
+			merged[f.key] = callDynamicField(f.fn)
+		}
+	}
+	return merged
+}
+
+// callDynamicField 调用字段函数，panic 时返回错误标记
+func callDynamicField(fn func() interface{}) (v interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			v = fmt.Sprintf("<panic: %v>", r)
+		}
+	}()
+	return fn()
+}
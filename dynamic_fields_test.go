@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDynamicFields(t *testing.T) {
+	buf := captureOutput(t)
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	t.Cleanup(func() {
+		zerolog.SetGlobalLevel(prevLevel)
+		UnregisterDynamicField("in_flight")
+		UnregisterDynamicField("tenant")
+	})
+	var inFlight, calls atomic.Int64
+	RegisterDynamicField("in_flight", func() interface{} { calls.Add(1); return inFlight.Load() })
+	RegisterDynamicField("tenant", func() interface{} { panic("no tenant") })
+
+	inFlight.Store(3)
+	Info("first")
+	inFlight.Store(5)
+	Info("second", map[string]interface{}{"in_flight": "caller"})
+	Debug("disabled")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 || lines[0]["in_flight"] != float64(3) || lines[1]["in_flight"] != "caller" {
+		t.Errorf("dynamic field should be evaluated per event and yield to caller fields: %v", lines)
+	}
+	if lines[0]["tenant"] != "<panic: no tenant>" {
+		t.Errorf("panicking callback should produce an error marker: %v", lines[0])
+	}
+	if calls.Load() != 1 {
+		t.Errorf("callback should only run for enabled events without the key, ran %d times", calls.Load())
+	}
+
+	UnregisterDynamicField("tenant")
+	buf.Reset()
+	Info("third")
+	if m := decodeLine(t, buf.Bytes()); m["in_flight"] != float64(5) || m["tenant"] != nil {
+		t.Errorf("unregistered field should no longer be added: %v", m)
+	}
+}
+
+func TestDynamicFieldsOnFlush(t *testing.T) {
+	buf := captureOutput(t)
+	t.Cleanup(func() { UnregisterDynamicField("goroutines") })
+	lb := NewLogBuffer()
+	lb.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "plain"})
+	lb.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "own", Fields: map[string]interface{}{"goroutines": "buffered"}})
+	RegisterDynamicField("goroutines", func() interface{} { return 42 })
+	lb.Flush(zerolog.InfoLevel)
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 || lines[0]["goroutines"] != float64(42) || lines[1]["goroutines"] != "buffered" {
+		t.Errorf("dynamic fields should be evaluated at flush time without overriding entry fields: %v", lines)
+	}
+}
+
+func BenchmarkDynamicFields(b *testing.B) {
+	prev := baseLogger
+	baseLogger = zerolog.New(io.Discard)
+	b.Cleanup(func() { baseLogger = prev })
+	for _, bc := range []struct {
+		name string
+		keys []string
+	}{
+		{"none", nil},
+		{"three", []string{"goroutines", "in_flight", "tenant"}},
+	} {
+		for _, k := range bc.keys {
+			RegisterDynamicField(k, func() interface{} { return 1 })
+		}
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Info("request handled", map[string]interface{}{"status": 200})
+			}
+		})
+		for _, k := range bc.keys {
+			UnregisterDynamicField(k)
+		}
+	}
+}
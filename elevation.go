@@ -0,0 +1,134 @@
+// @Author Clover
+// @Data 2026/10/18 上午2:30:00
+// @Desc 为单个请求或匹配字段的日志临时降低最低日志级别
+
+package logging
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DebugTokenHeader 携带调试令牌的请求头，与 Config.DebugTokenSecret 一致时提升该请求的日志级别
+const DebugTokenHeader = "X-Debug-Token"
+
+// ElevationRule 日志匹配 Matcher 时使用 Level 作为最低日志级别，例如只为某个租户输出 Debug 日志
+type ElevationRule struct {
+	Matcher FieldMatcher
+	Level   zerolog.Level
+	TTL     time.Duration // 从 InitLogger 开始的有效期，0 表示一直有效，避免忘记关闭后持续输出大量日志
+}
+
+// elevationRule 生效中的规则
+type elevationRule struct {
+	matcher FieldMatcher
+	level   zerolog.Level
+	expires time.Time // 为零值时一直有效
+}
+
+var (
+	elevationRules   []elevationRule // 由 stateMu 保护
+	elevationActive  atomic.Bool     // 是否存在规则，没有规则时跳过匹配
+	debugTokenSecret string          // Config.DebugTokenSecret，由 stateMu 保护
+)
+
+// setElevationRules 设置规则，调用方需持有 stateMu
+func setElevationRules(rules []ElevationRule) {
+	elevationRules = nil
+	for _, r := range rules {
+		if r.Matcher == nil {
+			continue
+		}
+		er := elevationRule{matcher: r.Matcher, level: r.Level}
+		if r.TTL > 0 {
+			er.expires = now().Add(r.TTL)
+		}
+		elevationRules = append(elevationRules, er)
+	}
+	elevationActive.Store(len(elevationRules) > 0)
+}
+
+type elevationKey struct{}
+
+// ElevateContext 返回提升了日志级别的 ctx：通过 DebugCtx 等携带 ctx 的日志函数输出的日志以 level 作为最低级别，
+// 即使全局级别更高，例如全局为 Info 时只为某个客户的请求输出 Debug 日志
+func ElevateContext(ctx context.Context, level zerolog.Level) context.Context {
+	return context.WithValue(ctx, elevationKey{}, level)
+}
+
+// contextElevation 返回 ctx 中提升后的最低级别
+func contextElevation(ctx context.Context) (zerolog.Level, bool) {
+	if ctx == nil {
+		return zerolog.NoLevel, false
+	}
+	level, ok := ctx.Value(elevationKey{}).(zerolog.Level)
+	return level, ok
+}
+
+// contextEvent 从 logger 创建 level 级别的事件，级别未启用但 ctx 提升了日志级别时仍然创建
+func contextEvent(ctx context.Context, logger *zerolog.Logger, level zerolog.Level) *zerolog.Event {
+	if event := logger.WithLevel(level); event != nil {
+		return event
+	}
+	if min, ok := contextElevation(ctx); ok && level >= min {
+		return elevatedEvent(logger, level)
+	}
+	return nil
+}
+
+// elevateByRules 级别未启用的日志匹配某条规则时创建事件，没有规则时直接返回 nil
+func elevateByRules(level zerolog.Level, err error, msg string, fields []map[string]interface{}) *zerolog.Event {
+	if !elevationActive.Load() {
+		return nil
+	}
+	if !elevationMatches(LogEntry{Level: level, Message: msg, Fields: mergeFields(fields)}) {
+		return nil
+	}
+	return withErr(elevatedEvent(currentLogger(), level), err)
+}
+
+// elevationMatches 判断日志是否匹配某条未过期的规则
+func elevationMatches(entry LogEntry) bool {
+	t := now()
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	for _, r := range elevationRules {
+		if entry.Level >= r.level && (r.expires.IsZero() || t.Before(r.expires)) && r.matcher(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// elevatedEvent 绕过 zerolog 的全局级别创建事件：以 NoLevel 创建后写入 level 字段，输出内容与普通事件相同，
+// 但 zerolog 的 hook 与按级别过滤的输出目标看到的级别为 NoLevel
+func elevatedEvent(logger *zerolog.Logger, level zerolog.Level) *zerolog.Event {
+	event := logger.WithLevel(zerolog.NoLevel)
+	if event == nil {
+		return nil
+	}
+	return event.Str(zerolog.LevelFieldName, zerolog.LevelFieldMarshalFunc(level))
+}
+
+// ElevationMiddleware 请求头 X-Debug-Token 与 Config.DebugTokenSecret 一致时，将请求的 ctx 提升到 Debug 级别，
+// 处理函数中使用 r.Context() 调用 DebugCtx 等函数即可输出该请求的 Debug 日志；未配置 DebugTokenSecret 时不做任何处理
+func ElevationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get(DebugTokenHeader); token != "" && validDebugToken(token) {
+			r = r.WithContext(ElevateContext(r.Context(), zerolog.DebugLevel))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validDebugToken(token string) bool {
+	stateMu.RLock()
+	secret := debugTokenSecret
+	stateMu.RUnlock()
+	return secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
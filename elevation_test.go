@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// useInfoLevel 将全局级别设为 Info，测试结束后恢复
+func useInfoLevel(t *testing.T) {
+	t.Helper()
+	prev := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prev) })
+}
+
+func TestElevateContext(t *testing.T) {
+	buf := captureOutput(t)
+	useInfoLevel(t)
+	ctx := ElevateContext(context.Background(), zerolog.DebugLevel)
+
+	DebugCtx(context.Background(), "hidden")
+	DebugCtx(ctx, "elevated", map[string]interface{}{"user": "u1"})
+	Debug("plain helper")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || lines[0]["message"] != "elevated" || lines[0]["level"] != "debug" || lines[0]["user"] != "u1" {
+		t.Errorf("only the elevated context should emit debug logs: %v", lines)
+	}
+	if zerolog.GlobalLevel() != zerolog.InfoLevel {
+		t.Errorf("global level should stay at info, got %s", zerolog.GlobalLevel())
+	}
+}
+
+func TestElevationRules(t *testing.T) {
+	useInfoLevel(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 2, 0, 0, 0, time.Local))
+	out := &syncBuffer{}
+	config := Config{ElevationRules: []ElevationRule{{Matcher: MatchField("tenant_id", "acme"), Level: zerolog.DebugLevel, TTL: time.Hour}}}
+	if err := InitLogger(config, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; setElevationRules(nil) })
+
+	Debug("acme request", map[string]interface{}{"tenant_id": "acme"})
+	Debug("other request", map[string]interface{}{"tenant_id": "globex"})
+	clock.Advance(time.Hour)
+	Debug("expired", map[string]interface{}{"tenant_id": "acme"})
+
+	lines := decodeLines(t, bytes.NewBufferString(out.String()))
+	if len(lines) != 1 || lines[0]["message"] != "acme request" || lines[0]["level"] != "debug" {
+		t.Errorf("only matching events above the rule level should be elevated until the TTL expires: %v", lines)
+	}
+}
+
+func TestElevationMiddleware(t *testing.T) {
+	buf := captureOutput(t)
+	useInfoLevel(t)
+	stateMu.Lock()
+	debugTokenSecret = "s3cret"
+	stateMu.Unlock()
+	t.Cleanup(func() { debugTokenSecret = "" })
+
+	handler := ElevationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DebugCtx(r.Context(), "handling", map[string]interface{}{"token": r.Header.Get(DebugTokenHeader)})
+	}))
+	for _, token := range []string{"", "wrong", "s3cret"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			r.Header.Set(DebugTokenHeader, token)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || lines[0]["token"] != "s3cret" {
+		t.Errorf("only a request with a valid debug token should be elevated: %v", lines)
+	}
+}
+
+func BenchmarkDebugWithoutElevationRules(b *testing.B) {
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	b.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Debug("disabled", map[string]interface{}{"tenant_id": "acme"})
+	}
+}
@@ -0,0 +1,40 @@
+// @Author Clover
+// @Data 2026/10/16 下午1:20:00
+// @Desc 解析 JSON 日志为 LogEntry
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// parseLogEntry 将一行 zerolog 输出的 JSON 日志解析为 LogEntry
+// level 和 message 分别映射到 Level 与 Message，其余字段保存在 Fields 中
+func parseLogEntry(line []byte) (LogEntry, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(line, &m); err != nil {
+		return LogEntry{}, fmt.Errorf("invalid log line: %w", err)
+	}
+
+	entry := LogEntry{Level: zerolog.NoLevel, Fields: make(map[string]interface{}, len(m))}
+	for k, v := range m {
+		switch k {
+		case zerolog.LevelFieldName:
+			if s, ok := v.(string); ok {
+				if level, err := zerolog.ParseLevel(s); err == nil {
+					entry.Level = level
+				}
+			}
+		case zerolog.MessageFieldName:
+			if s, ok := v.(string); ok {
+				entry.Message = s
+			}
+		default:
+			entry.Fields[k] = v
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,232 @@
+// @Author Clover
+// @Data 2026/10/17 下午9:20:00
+// @Desc 使用环境变量覆盖 Config 字段
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnvOverrides 按 config.EnvOverrides 使用已设置的环境变量覆盖对应字段，返回已覆盖的字段（字段名到环境变量名）与无法应用的覆盖
+// 支持字符串、布尔、整数、浮点数、time.Duration（如 "30s"）与 []string（逗号分隔）类型的字段
+func applyEnvOverrides(config *Config) (map[string]string, []error) {
+	names := make([]string, 0, len(config.EnvOverrides))
+	for env := range config.EnvOverrides {
+		names = append(names, env)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	applied := make(map[string]string)
+	v := reflect.ValueOf(config).Elem()
+	for _, env := range names {
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		field := config.EnvOverrides[env]
+		fv := v.FieldByName(field)
+		if !fv.IsValid() || !fv.CanSet() {
+			errs = append(errs, fmt.Errorf("%s: unknown config field %q", env, field))
+			continue
+		}
+		if err := setFieldFromString(fv, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: config field %s: %w", env, field, err))
+			continue
+		}
+		applied[field] = env
+	}
+	return applied, errs
+}
+
+// setFieldFromString 将字符串解析为字段的类型并赋值
+func setFieldFromString(fv reflect.Value, value string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		s := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			s.Index(i).SetString(item)
+		}
+		fv.Set(s)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// InitLoggerFromEnv 使用的环境变量
+const (
+	EnvLogPath         = "LOG_PATH"             // LogPath，设置时默认开启文件输出
+	EnvLogLevel        = "LOG_LEVEL"            // LogLevel，默认 info
+	EnvProjectName     = "LOG_PROJECT_NAME"     // ProjectName，默认为程序名
+	EnvProjectKey      = "LOG_PROJECT_KEY"      // ProjectKey，默认 project
+	EnvMaxSizeMB       = "LOG_MAX_SIZE_MB"      // MaxLogSize，单位 MiB，默认 100
+	EnvMonitorInterval = "LOG_MONITOR_INTERVAL" // MonitorInterval，例如 "10m"，默认 1 分钟
+	EnvEnableConsole   = "LOG_ENABLE_CONSOLE"   // EnableConsoleOutput，默认 true
+	EnvEnableFile      = "LOG_ENABLE_FILE"      // EnableFileOutput，默认在设置了 LOG_PATH 时为 true
+	EnvFormat          = "LOG_FORMAT"           // FileFormat：json 或 console，默认 json
+	EnvProfile         = "LOG_PROFILE"          // Profile：default 或 minimal，默认 default
+)
+
+// InitLoggerFromEnv 的默认值
+const (
+	defaultEnvMaxSizeMB       = 100
+	defaultEnvMonitorInterval = time.Minute
+)
+
+// InitLoggerFromEnv 完全由环境变量构造配置并初始化日志记录器，不需要在代码中编写 Config，
+// 变量名与默认值见 EnvLogPath 等常量；变量的值无法解析时不初始化并返回所有错误。
+// ConfigSnapshot 中来自环境变量的字段的来源为 env
+func InitLoggerFromEnv(opts ...LoggerOption) error {
+	config, envFields, err := configFromEnv()
+	if err != nil {
+		return err
+	}
+	return InitLogger(config, append(opts, withEnvFields(envFields))...)
+}
+
+// configFromEnv 读取环境变量构造配置，返回已设置的字段（字段名到环境变量名）
+func configFromEnv() (Config, map[string]string, error) {
+	config := Config{
+		ProjectName:         filepath.Base(os.Args[0]),
+		ProjectKey:          defaultProjectKey,
+		LogLevel:            zerolog.InfoLevel.String(),
+		MaxLogSize:          defaultEnvMaxSizeMB << 20,
+		MonitorInterval:     defaultEnvMonitorInterval,
+		EnableConsoleOutput: true,
+		FileFormat:          FileFormatJSON,
+	}
+	fields := make(map[string]string)
+	var errs []error
+	lookup := func(env, field string, set func(string) error) {
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+		if err := set(strings.TrimSpace(value)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			return
+		}
+		fields[field] = env
+	}
+	lookup(EnvLogPath, "LogPath", func(s string) error {
+		config.LogPath = s
+		config.EnableFileOutput = s != ""
+		return nil
+	})
+	lookup(EnvLogLevel, "LogLevel", func(s string) error {
+		if _, err := zerolog.ParseLevel(s); err != nil {
+			return err
+		}
+		config.LogLevel = s
+		return nil
+	})
+	lookup(EnvProjectName, "ProjectName", func(s string) error {
+		config.ProjectName = s
+		return nil
+	})
+	lookup(EnvProjectKey, "ProjectKey", func(s string) error {
+		if s == "" {
+			return errors.New("project key must not be empty")
+		}
+		config.ProjectKey = s
+		return nil
+	})
+	lookup(EnvMaxSizeMB, "MaxLogSize", func(s string) error {
+		mb, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		if mb < 0 || mb > math.MaxInt64>>20 {
+			return fmt.Errorf("size %d MiB out of range", mb)
+		}
+		config.MaxLogSize = mb << 20
+		return nil
+	})
+	lookup(EnvMonitorInterval, "MonitorInterval", func(s string) error {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		config.MonitorInterval = d
+		return nil
+	})
+	lookup(EnvEnableConsole, "EnableConsoleOutput", func(s string) (err error) {
+		config.EnableConsoleOutput, err = strconv.ParseBool(s)
+		return err
+	})
+	lookup(EnvEnableFile, "EnableFileOutput", func(s string) (err error) {
+		config.EnableFileOutput, err = strconv.ParseBool(s)
+		return err
+	})
+	lookup(EnvFormat, "FileFormat", func(s string) error {
+		switch s {
+		case FileFormatJSON, FileFormatConsole:
+			config.FileFormat = s
+			return nil
+		}
+		return fmt.Errorf("unknown log format %q", s)
+	})
+	lookup(EnvProfile, "Profile", func(s string) error {
+		if _, ok := profilePresets[s]; !ok {
+			return fmt.Errorf("unknown profile %q", s)
+		}
+		config.Profile = s
+		return nil
+	})
+	if config.EnableFileOutput && config.LogPath == "" {
+		errs = append(errs, fmt.Errorf("%s: file output requires %s", EnvEnableFile, EnvLogPath))
+	}
+	return config, fields, errors.Join(errs...)
+}
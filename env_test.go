@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	t.Setenv("APP_LOG_PATH", "/var/log/app.log")
+	t.Setenv("APP_CONSOLE", "true")
+	t.Setenv("APP_MAX_SIZE", "1048576")
+	t.Setenv("APP_INTERVAL", "30s")
+	t.Setenv("APP_FIELDS", "user, order ,")
+	t.Setenv("APP_POLICY", "drop")
+	t.Setenv("APP_BAD_BOOL", "maybe")
+
+	config := Config{
+		LogPath:         "./log/app.log",
+		LogLevel:        "info",
+		MonitorInterval: time.Minute,
+		EnvOverrides: map[string]string{
+			"APP_LOG_PATH": "LogPath",
+			"APP_CONSOLE":  "EnableConsoleOutput",
+			"APP_MAX_SIZE": "MaxLogSize",
+			"APP_INTERVAL": "MonitorInterval",
+			"APP_FIELDS":   "AllowedFields",
+			"APP_POLICY":   "ReservedKeyPolicy",
+			"APP_UNSET":    "LogLevel",
+			"APP_BAD_BOOL": "EnableFileOutput",
+			"APP_CONSOLE2": "NoSuchField",
+		},
+	}
+	t.Setenv("APP_CONSOLE2", "1")
+	applied, errs := applyEnvOverrides(&config)
+
+	if config.LogPath != "/var/log/app.log" || !config.EnableConsoleOutput || config.MaxLogSize != 1048576 ||
+		config.MonitorInterval != 30*time.Second || config.ReservedKeyPolicy != ReservedKeyDrop {
+		t.Errorf("overrides not applied: %+v", config)
+	}
+	if !reflect.DeepEqual(config.AllowedFields, []string{"user", "order"}) {
+		t.Errorf("unexpected slice override: %q", config.AllowedFields)
+	}
+	if config.LogLevel != "info" {
+		t.Errorf("unset variables should not override fields, got %q", config.LogLevel)
+	}
+	if applied["MonitorInterval"] != "APP_INTERVAL" || applied["LogLevel"] != "" || applied["EnableFileOutput"] != "" {
+		t.Errorf("only applied overrides should be reported: %v", applied)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "APP_BAD_BOOL") || !strings.Contains(errs[1].Error(), "NoSuchField") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestEnvOverridesInInitLogger(t *testing.T) {
+	t.Setenv("APP_PROJECT", "from-env")
+	t.Setenv("APP_METRICS", "not-a-bool")
+	buf := &syncBuffer{}
+	InitLogger(Config{
+		ProjectKey:   "project",
+		ProjectName:  "from-struct",
+		EnvOverrides: map[string]string{"APP_PROJECT": "ProjectName", "APP_METRICS": "DisableMetrics"},
+	}, WithWriters(buf))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+	Info("hello")
+	out := buf.String()
+	if !strings.Contains(out, `"project":"from-env"`) {
+		t.Errorf("environment should override the struct field: %s", out)
+	}
+	if !strings.Contains(out, "Ignoring environment override") || !strings.Contains(out, "APP_METRICS") {
+		t.Errorf("invalid override should be reported: %s", out)
+	}
+}
+
+// unsetLogEnv 清除 InitLoggerFromEnv 使用的环境变量，测试结束后恢复
+func unsetLogEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{EnvLogPath, EnvLogLevel, EnvProjectName, EnvProjectKey, EnvMaxSizeMB,
+		EnvMonitorInterval, EnvEnableConsole, EnvEnableFile, EnvFormat, EnvProfile} {
+		t.Setenv(env, "")
+		os.Unsetenv(env)
+	}
+}
+
+func TestConfigFromEnvDefaults(t *testing.T) {
+	unsetLogEnv(t)
+	config, fields, err := configFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		ProjectName:         filepath.Base(os.Args[0]),
+		ProjectKey:          defaultProjectKey,
+		LogLevel:            "info",
+		MaxLogSize:          100 << 20,
+		MonitorInterval:     time.Minute,
+		EnableConsoleOutput: true,
+		FileFormat:          FileFormatJSON,
+	}
+	if !reflect.DeepEqual(config, want) || len(fields) != 0 {
+		t.Errorf("unexpected defaults: %+v %v", config, fields)
+	}
+}
+
+func TestInitLoggerFromEnv(t *testing.T) {
+	unsetLogEnv(t)
+	prevLevel := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv(EnvLogPath, path)
+	t.Setenv(EnvLogLevel, "warn")
+	t.Setenv(EnvProjectName, "svc")
+	t.Setenv(EnvProjectKey, "service")
+	t.Setenv(EnvMaxSizeMB, "5")
+	t.Setenv(EnvMonitorInterval, "10m")
+	t.Setenv(EnvEnableConsole, "false")
+	t.Setenv(EnvFormat, "json")
+
+	if err := InitLoggerFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	Info("dropped")
+	Warn("kept")
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"service":"svc"`) || !strings.Contains(string(data), "kept") || strings.Contains(string(data), "dropped") {
+		t.Errorf("unexpected log file: %s", data)
+	}
+	snapshot := ConfigSnapshot()
+	for field, env := range map[string]string{"LogPath": EnvLogPath, "MaxLogSize": EnvMaxSizeMB, "MonitorInterval": EnvMonitorInterval} {
+		entry := snapshot[field].(map[string]interface{})
+		if entry["source"] != ConfigSourceEnv || entry["env_var"] != env {
+			t.Errorf("%s should come from %s: %v", field, env, entry)
+		}
+	}
+	if v := snapshot["MaxLogSize"].(map[string]interface{})["value"]; v != int64(5<<20) {
+		t.Errorf("LOG_MAX_SIZE_MB should be converted to bytes, got %v", v)
+	}
+}
+
+func TestInitLoggerFromEnvInvalid(t *testing.T) {
+	unsetLogEnv(t)
+	t.Setenv(EnvLogLevel, "loud")
+	t.Setenv(EnvMaxSizeMB, "-1")
+	t.Setenv(EnvEnableFile, "true")
+	t.Setenv(EnvProfile, "tiny")
+
+	err := InitLoggerFromEnv()
+	if err == nil {
+		Close()
+		t.Fatal("expected an error")
+	}
+	for _, env := range []string{EnvLogLevel, EnvMaxSizeMB, EnvEnableFile, EnvProfile} {
+		if !strings.Contains(err.Error(), env) {
+			t.Errorf("error should mention %s: %v", env, err)
+		}
+	}
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initialized {
+		t.Errorf("logger should not be initialized with invalid environment")
+	}
+}
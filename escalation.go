@@ -0,0 +1,159 @@
+// @Author Clover
+// @Data 2026/10/18 上午7:10:00
+// @Desc 短时间内重复出现的告警日志升级为错误
+
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// 升级日志的字段
+const (
+	escalationCountKey  = "escalation_count"  // 窗口内匹配的告警数
+	escalationFirstKey  = "escalation_first"  // 窗口内第一条告警的时间
+	escalationLastKey   = "escalation_last"   // 触发升级的告警的时间
+	escalationWindowKey = "escalation_window" // 规则的窗口
+	escalationSampleKey = "escalation_sample" // 窗口内第一条告警的消息与字段
+	escalatedKey        = "escalated"         // 规则生效期间被提升级别的告警
+)
+
+// Escalation 匹配 Match 的 Warn 日志在 Window 内超过 Count 条时，额外输出一条 EscalateTo 级别的汇总日志，
+// 每个窗口最多一条；窗口从第一条匹配的告警开始，到期后由下一条匹配的告警开始新的窗口
+type Escalation struct {
+	Match      FieldMatcher
+	Count      int
+	Window     time.Duration
+	EscalateTo zerolog.Level // 升级后的级别，不高于 Warn 时（包括零值）使用 Error
+	// Promote 是否在升级后将之后匹配的告警本身也提升为 EscalateTo，
+	// 直到一个窗口内的数量不再超过 Count（该窗口结束后恢复为 Warn）
+	Promote bool
+}
+
+// escalationRule 生效中的规则及其窗口状态，每条规则只保存当前窗口的计数与第一条告警，占用的内存固定
+type escalationRule struct {
+	Escalation
+
+	mu        sync.Mutex
+	start     time.Time // 当前窗口的开始时间，零值表示没有窗口
+	count     int
+	sample    map[string]interface{} // 当前窗口第一条告警的消息与字段
+	escalated bool                   // 当前窗口是否已经升级
+	hot       bool                   // 是否提升匹配的告警
+}
+
+var (
+	escalationRules  []*escalationRule // 由 stateMu 保护
+	escalationActive atomic.Bool       // 是否存在规则，没有规则时跳过匹配
+)
+
+// setEscalations 设置规则并清空窗口状态，忽略缺少 Match、Count 或 Window 的规则，调用方需持有 stateMu
+func setEscalations(rules []Escalation) {
+	escalationRules = nil
+	for _, r := range rules {
+		if r.Match == nil || r.Count <= 0 || r.Window <= 0 {
+			continue
+		}
+		if r.EscalateTo <= zerolog.WarnLevel {
+			r.EscalateTo = zerolog.ErrorLevel
+		}
+		escalationRules = append(escalationRules, &escalationRule{Escalation: r})
+	}
+	escalationActive.Store(len(escalationRules) > 0)
+}
+
+// escalationBurst 需要输出的升级日志
+type escalationBurst struct {
+	level  zerolog.Level
+	msg    string
+	fields map[string]interface{}
+}
+
+// escalateRepeated 记录匹配规则的 Warn 日志，规则处于提升状态时返回从 logger（为 nil 时为 currentLogger()）重新创建的事件与提升后的级别；
+// 本条日志使某条规则超过阈值时返回需要在本条日志之后输出的升级日志
+func escalateRepeated(logger *zerolog.Logger, event *zerolog.Event, level zerolog.Level, err error, msg string, fields map[string]interface{}) (*zerolog.Event, zerolog.Level, []escalationBurst) {
+	if level != zerolog.WarnLevel || !escalationActive.Load() {
+		return event, level, nil
+	}
+	stateMu.RLock()
+	rules := escalationRules
+	stateMu.RUnlock()
+
+	entry := LogEntry{Level: level, Message: msg, Fields: fields}
+	if err != nil {
+		entry.Fields = mergeFields([]map[string]interface{}{fields})
+		entry.Fields[zerolog.ErrorFieldName] = err
+	}
+	t := now()
+	promoteTo := level
+	var bursts []escalationBurst
+	for _, r := range rules {
+		if !r.Match(entry) {
+			continue
+		}
+		burst, promote := r.observe(t, entry)
+		if burst != nil {
+			bursts = append(bursts, *burst)
+		}
+		if promote && r.EscalateTo > promoteTo {
+			promoteTo = r.EscalateTo
+		}
+	}
+	if promoteTo != level {
+		if logger == nil {
+			logger = currentLogger()
+		}
+		event.Discard()
+		event = logger.WithLevel(promoteTo).Bool(escalatedKey, true)
+		if err != nil {
+			event = withErr(event, err)
+		}
+	}
+	return event, promoteTo, bursts
+}
+
+// observe 将一条匹配的告警计入当前窗口，返回需要输出的升级日志以及是否提升本条告警
+func (r *escalationRule) observe(t time.Time, entry LogEntry) (*escalationBurst, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.start.IsZero() || !t.Before(r.start.Add(r.Window)) {
+		// 开始新的窗口；上一个窗口没有升级或之后间隔了整个窗口时速率已经回落，不再提升
+		r.hot = r.Promote && r.escalated && t.Before(r.start.Add(2*r.Window))
+		r.start, r.count, r.escalated = t, 0, false
+		r.sample = map[string]interface{}{
+			zerolog.MessageFieldName: entry.Message,
+			"fields":                 mergeFields([]map[string]interface{}{entry.Fields}),
+		}
+	}
+	r.count++
+	if r.count <= r.Count || r.escalated {
+		return nil, r.hot
+	}
+	r.escalated = true
+	r.hot = r.Promote
+	burst := &escalationBurst{
+		level: r.EscalateTo,
+		msg:   fmt.Sprintf("Warning repeated %d times within %s: %s", r.count, r.Window, entry.Message),
+		fields: map[string]interface{}{
+			escalationCountKey:  r.count,
+			escalationFirstKey:  r.start,
+			escalationLastKey:   t,
+			escalationWindowKey: r.Window,
+			escalationSampleKey: r.sample,
+		},
+	}
+	// 触发升级的告警本身保持 Warn，之后的告警才被提升
+	return burst, false
+}
+
+// emitEscalations 输出升级日志
+func emitEscalations(bursts []escalationBurst) {
+	for _, b := range bursts {
+		emit(currentLogger().WithLevel(b.level), b.level, nil, b.msg, []map[string]interface{}{b.fields})
+	}
+}
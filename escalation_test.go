@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// initEscalations 以 out 作为唯一输出初始化日志记录器并设置升级规则
+func initEscalations(t *testing.T, rules ...Escalation) *syncBuffer {
+	t.Helper()
+	out := &syncBuffer{}
+	if err := InitLogger(Config{Escalations: rules}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+		stateMu.Lock()
+		setEscalations(nil)
+		stateMu.Unlock()
+	})
+	return out
+}
+
+// escalationLines 返回 out 中新增的升级日志并清空 out
+func escalationLines(t *testing.T, out *syncBuffer) []map[string]interface{} {
+	t.Helper()
+	var found []map[string]interface{}
+	for _, line := range decodeLines(t, bytes.NewBufferString(out.String())) {
+		if _, ok := line[escalationCountKey]; ok {
+			found = append(found, line)
+		}
+	}
+	out.mu.Lock()
+	out.buf.Reset()
+	out.mu.Unlock()
+	return found
+}
+
+func TestEscalationOncePerWindow(t *testing.T) {
+	useInfoLevel(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 7, 0, 0, 0, time.Local))
+	out := initEscalations(t, Escalation{Match: MatchMessage("disk slow"), Count: 3, Window: time.Minute})
+
+	for i := 0; i < 5; i++ {
+		Warn("disk slow", map[string]interface{}{"disk": "sda", "n": i})
+		Warn("unrelated")
+		Error("disk slow but not a warning")
+		clock.Advance(time.Second)
+	}
+	lines := escalationLines(t, out)
+	if len(lines) != 1 {
+		t.Fatalf("expected one escalation, got %d", len(lines))
+	}
+	e := lines[0]
+	sample := e[escalationSampleKey].(map[string]interface{})
+	if e["level"] != "error" || e[escalationCountKey] != float64(4) || e[escalationWindowKey] != float64(time.Minute) ||
+		sample["message"] != "disk slow" || sample["fields"].(map[string]interface{})["n"] != float64(0) {
+		t.Errorf("unexpected escalation: %v", e)
+	}
+	first, _ := time.Parse(time.RFC3339Nano, e[escalationFirstKey].(string))
+	last, _ := time.Parse(time.RFC3339Nano, e[escalationLastKey].(string))
+	if last.Sub(first) != 3*time.Second {
+		t.Errorf("expected first/last 3s apart, got %v and %v", first, last)
+	}
+
+	// 同一窗口内不再升级
+	for i := 0; i < 20; i++ {
+		Warn("disk slow")
+	}
+	if lines := escalationLines(t, out); len(lines) != 0 {
+		t.Errorf("expected no further escalation in the same window, got %d", len(lines))
+	}
+
+	// 新的窗口再次超过阈值时升级一次
+	clock.Advance(time.Minute)
+	for i := 0; i < 10; i++ {
+		Warn("disk slow")
+	}
+	if lines := escalationLines(t, out); len(lines) != 1 {
+		t.Errorf("expected one escalation in the next window, got %d", len(lines))
+	}
+
+	// 未超过阈值的窗口不升级
+	clock.Advance(time.Minute)
+	for i := 0; i < 3; i++ {
+		Warn("disk slow")
+	}
+	if lines := escalationLines(t, out); len(lines) != 0 {
+		t.Errorf("expected no escalation below the threshold, got %d", len(lines))
+	}
+}
+
+func TestEscalationPromote(t *testing.T) {
+	useInfoLevel(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 7, 0, 0, 0, time.Local))
+	out := initEscalations(t, Escalation{
+		Match: MatchField("component", "db"), Count: 2, Window: time.Minute, EscalateTo: zerolog.FatalLevel, Promote: true,
+	})
+	db := map[string]interface{}{"component": "db"}
+
+	levels := func() []string {
+		var ls []string
+		for _, line := range decodeLines(t, bytes.NewBufferString(out.String())) {
+			if _, ok := line[escalationCountKey]; ok {
+				ls = append(ls, "burst")
+				continue
+			}
+			ls = append(ls, line["level"].(string))
+		}
+		out.mu.Lock()
+		out.buf.Reset()
+		out.mu.Unlock()
+		return ls
+	}
+
+	for i := 0; i < 4; i++ {
+		Warn("query slow", db)
+	}
+	// 触发升级的告警保持 Warn，之后的告警被提升
+	if got := levels(); !reflect.DeepEqual(got, []string{"warn", "warn", "warn", "burst", "fatal"}) {
+		t.Errorf("unexpected levels: %v", got)
+	}
+
+	// 紧接着的窗口仍然提升，直到一个窗口内的数量不再超过阈值
+	clock.Advance(time.Minute)
+	Warn("query slow", db)
+	Warn("query slow", db)
+	if got := levels(); !reflect.DeepEqual(got, []string{"fatal", "fatal"}) {
+		t.Errorf("unexpected levels in the following window: %v", got)
+	}
+	clock.Advance(time.Minute)
+	Warn("query slow", db)
+	if got := levels(); !reflect.DeepEqual(got, []string{"warn"}) {
+		t.Errorf("promotion should stop after a quiet window: %v", got)
+	}
+}
+
+func TestEscalationPromoteKeepsLoggerContext(t *testing.T) {
+	useInfoLevel(t)
+	useFakeClock(t, time.Date(2026, 10, 18, 7, 0, 0, 0, time.Local))
+	out := initEscalations(t, Escalation{
+		Match: MatchField("component", "db"), Count: 1, Window: time.Minute, Promote: true,
+	})
+
+	// 通过 With() 添加上下文字段的子日志记录器，提升后的告警仍然带有这些字段
+	child := currentLogger().With().Str("request_id", "r1").Logger()
+	for i := 0; i < 3; i++ {
+		emitFrom(&child, child.Warn(), zerolog.WarnLevel, nil, "query slow", []map[string]interface{}{{"component": "db"}})
+	}
+	var promoted map[string]interface{}
+	for _, line := range decodeLines(t, bytes.NewBufferString(out.String())) {
+		if line[escalatedKey] == true {
+			promoted = line
+		}
+	}
+	if promoted == nil || promoted["level"] != "error" || promoted["request_id"] != "r1" || promoted["component"] != "db" {
+		t.Errorf("promoted warning should keep the child logger context: %v", promoted)
+	}
+}
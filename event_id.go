@@ -0,0 +1,75 @@
+// @Author Clover
+// @Data 2026/10/16 下午10:40:00
+// @Desc 为每条日志生成唯一的 event_id
+
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// eventIDKey 事件 ID 字段名
+const eventIDKey = "event_id"
+
+// event_id 的生成方式
+const (
+	eventIDOff  int32 = iota
+	eventIDUUID       // crypto/rand 生成的 UUID4
+	eventIDFast       // 单调递增的十六进制序号
+)
+
+var (
+	eventIDMode atomic.Int32  // 当前的 event_id 生成方式
+	eventSeq    atomic.Uint64 // EnableFastEventID 使用的序号
+)
+
+// setEventIDMode 根据配置选择 event_id 的生成方式，EnableFastEventID 优先
+func setEventIDMode(enable, fast bool) {
+	switch {
+	case fast:
+		eventIDMode.Store(eventIDFast)
+	case enable:
+		eventIDMode.Store(eventIDUUID)
+	default:
+		eventIDMode.Store(eventIDOff)
+	}
+}
+
+// eventIDHook 为每条输出的日志添加 event_id 字段
+type eventIDHook struct{}
+
+func (eventIDHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	switch eventIDMode.Load() {
+	case eventIDUUID:
+		e.Str(eventIDKey, newUUID())
+	case eventIDFast:
+		e.Str(eventIDKey, strconv.FormatUint(eventSeq.Add(1), 16))
+	}
+}
+
+// newUUID 使用 crypto/rand 生成 UUID4
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
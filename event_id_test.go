@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestEventID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	prev := baseLogger
+	baseLogger = zerolog.New(buf).Hook(eventIDHook{})
+	t.Cleanup(func() {
+		baseLogger = prev
+		setEventIDMode(false, false)
+	})
+
+	Info("no id")
+	if _, ok := decodeLine(t, buf.Bytes())[eventIDKey]; ok {
+		t.Errorf("event_id should not be added by default")
+	}
+
+	setEventIDMode(true, false)
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		buf.Reset()
+		Info("with id")
+		id, _ := decodeLine(t, buf.Bytes())[eventIDKey].(string)
+		if !uuid.MatchString(id) || seen[id] {
+			t.Fatalf("invalid or duplicate event_id %q", id)
+		}
+		seen[id] = true
+	}
+
+	// 直接使用 baseLogger 的日志同样包含 event_id
+	setEventIDMode(true, true)
+	buf.Reset()
+	currentLogger().Warn().Msg("direct")
+	first, _ := decodeLine(t, buf.Bytes())[eventIDKey].(string)
+	buf.Reset()
+	Error("fast")
+	second, _ := decodeLine(t, buf.Bytes())[eventIDKey].(string)
+	a, errA := strconv.ParseUint(first, 16, 64)
+	b, errB := strconv.ParseUint(second, 16, 64)
+	if errA != nil || errB != nil || b != a+1 {
+		t.Errorf("fast event_id should be increasing hex: %q %q", first, second)
+	}
+}
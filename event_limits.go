@@ -0,0 +1,153 @@
+// @Author Clover
+// @Data 2026/10/18 上午4:10:00
+// @Desc 限制单条日志的字段数与编码后的大小，避免超大的字段拖慢日志调用
+
+package logging
+
+import (
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// 超出限制时添加的字段
+const (
+	fieldsTruncatedKey = "fields_truncated" // 因 MaxFieldsPerEvent 丢弃的字段数
+	eventTooLargeKey   = "event_too_large"  // 字段超过 MaxEventBytes 被整体省略
+	maxEventBytesKey   = "max_event_bytes"  // 生效的 MaxEventBytes
+	fieldsOmittedKey   = "fields_omitted"   // 被省略的字段数
+)
+
+var (
+	maxFieldsPerEvent atomic.Int64 // Config.MaxFieldsPerEvent，0 表示不限制
+	maxEventBytes     atomic.Int64 // Config.MaxEventBytes，0 表示不限制
+)
+
+// limitFields 字段数超过 MaxFieldsPerEvent 时按键名排序保留前面的字段，返回新的字段集合与丢弃的数量，不修改 fields
+func limitFields(fields map[string]interface{}) (map[string]interface{}, int) {
+	max := int(maxFieldsPerEvent.Load())
+	if max <= 0 || len(fields) <= max {
+		return fields, 0
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kept := make(map[string]interface{}, max)
+	for _, k := range keys[:max] {
+		kept[k] = fields[k]
+	}
+	return kept, len(fields) - max
+}
+
+// writeBoundedFields 写入字段与 fields_truncated；消息与字段估算超过 MaxEventBytes 时不写入字段，
+// 改为写入 event_too_large 等说明并截断消息，返回要输出的消息
+func writeBoundedFields(event *zerolog.Event, msg string, fields map[string]interface{}, truncated int) (*zerolog.Event, string) {
+	if limit := int(maxEventBytes.Load()); limit > 0 && !fitsEventBytes(msg, fields, limit) {
+		return event.Bool(eventTooLargeKey, true).
+			Int(maxEventBytesKey, limit).
+			Int(fieldsOmittedKey, len(fields)+truncated), truncateUTF8(msg, limit)
+	}
+	event = writeFields(event, fields)
+	if truncated > 0 {
+		event = event.Int(fieldsTruncatedKey, truncated)
+	}
+	return event, msg
+}
+
+// fitsEventBytes 估算消息与字段编码后的字节数是否不超过 limit，超过时立即停止，
+// 不会为了得到准确大小而完整遍历或编码超大的字段
+func fitsEventBytes(msg string, fields map[string]interface{}, limit int) bool {
+	e := sizeEstimator{remaining: limit}
+	if !e.add(len(msg)) {
+		return false
+	}
+	for k, v := range fields {
+		if !e.add(len(k)+4) || !e.value(reflect.ValueOf(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// sizeEstimator 估算值编码为 JSON 后的字节数，剩余预算用完时返回 false
+type sizeEstimator struct {
+	remaining int
+}
+
+func (e *sizeEstimator) add(n int) bool {
+	e.remaining -= n
+	return e.remaining >= 0
+}
+
+// value 按类型估算，map、切片与结构体逐个元素累加，预算用完时不再继续遍历，
+// 因此深度嵌套或循环引用的值也会在预算耗尽后停止
+func (e *sizeEstimator) value(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Invalid, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return e.add(4)
+	case reflect.String:
+		return e.add(rv.Len() + 2)
+	case reflect.Bool:
+		return e.add(5)
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return e.add(4)
+		}
+		return e.value(rv.Elem())
+	case reflect.Map:
+		if !e.add(2) {
+			return false
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			if !e.add(4) || !e.value(iter.Key()) || !e.value(iter.Value()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return e.add(rv.Len()*4/3 + 4) // []byte 编码为 base64
+		}
+		if !e.add(2) {
+			return false
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if !e.add(1) || !e.value(rv.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if !e.add(2) {
+			return false
+		}
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				if !e.add(len(f.Name)+4) || !e.value(rv.Field(i)) {
+					return false
+				}
+			}
+		}
+		return true
+	default: // 数字
+		return e.add(20)
+	}
+}
+
+// truncateUTF8 将 s 截断到最多 n 字节，不截断多字节字符
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setEventLimits 设置单条日志的限制，测试结束后恢复为不限制
+func setEventLimits(t *testing.T, fields, bytes int) {
+	t.Helper()
+	maxFieldsPerEvent.Store(int64(fields))
+	maxEventBytes.Store(int64(bytes))
+	t.Cleanup(func() {
+		maxFieldsPerEvent.Store(0)
+		maxEventBytes.Store(0)
+	})
+}
+
+// bounded 运行 f 并断言耗时与分配的内存都在上限之内
+func bounded(t *testing.T, maxTime time.Duration, maxAlloc uint64, f func()) {
+	t.Helper()
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	f()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if elapsed > maxTime {
+		t.Errorf("took %v, want under %v", elapsed, maxTime)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > maxAlloc {
+		t.Errorf("allocated %d bytes, want under %d", alloc, maxAlloc)
+	}
+}
+
+func TestMaxFieldsPerEvent(t *testing.T) {
+	buf := captureOutput(t)
+	setEventLimits(t, 3, 0)
+	fields := make(map[string]interface{})
+	for i := 0; i < 50000; i++ {
+		fields[fmt.Sprintf("k%05d", i)] = i
+	}
+
+	bounded(t, time.Second, 16<<20, func() { Info("many keys", fields) })
+
+	m := decodeLine(t, buf.Bytes())
+	if m["k00000"] != float64(0) || m["k00002"] != float64(2) || m["k00003"] != nil {
+		t.Errorf("should keep the first keys in sorted order: %v", m)
+	}
+	if m[fieldsTruncatedKey] != float64(49997) {
+		t.Errorf("fields_truncated = %v", m[fieldsTruncatedKey])
+	}
+	if len(fields) != 50000 {
+		t.Errorf("caller's map should not be modified")
+	}
+}
+
+func TestMaxEventBytesAdversarial(t *testing.T) {
+	deep := map[string]interface{}{"leaf": true}
+	for i := 0; i < 100000; i++ {
+		deep = map[string]interface{}{"n": deep}
+	}
+	wide := make(map[string]interface{}, 50000)
+	for i := 0; i < 50000; i++ {
+		wide[fmt.Sprintf("key-%d", i)] = "value"
+	}
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	cases := map[string]interface{}{
+		"deep nesting": deep,
+		"huge string":  strings.Repeat("x", 64<<20),
+		"many keys":    wide,
+		"huge slice":   make([]int, 1<<20),
+		"cycle":        cyclic,
+	}
+	for name, v := range cases {
+		t.Run(name, func(t *testing.T) {
+			buf := captureOutput(t)
+			setEventLimits(t, 0, 4096)
+
+			// 估算在预算用完后立即停止，不会完整编码超大的值
+			bounded(t, time.Second, 1<<20, func() {
+				Warn("api response", map[string]interface{}{"response": v, "status": 200})
+			})
+
+			m := decodeLine(t, buf.Bytes())
+			if m["message"] != "api response" || m["level"] != "warn" || m[eventTooLargeKey] != true ||
+				m[maxEventBytesKey] != float64(4096) || m[fieldsOmittedKey] != float64(2) {
+				t.Errorf("expected summary event, got %v", m)
+			}
+			if m["response"] != nil || m["status"] != nil {
+				t.Errorf("fields should be omitted: %v", m)
+			}
+		})
+	}
+}
+
+func TestMaxEventBytesSmallEvent(t *testing.T) {
+	buf := captureOutput(t)
+	setEventLimits(t, 0, 4096)
+	Info("small", map[string]interface{}{"user": "u1", "ids": []int{1, 2, 3}})
+	m := decodeLine(t, buf.Bytes())
+	if m["user"] != "u1" || m[eventTooLargeKey] != nil {
+		t.Errorf("events under the cap should be unchanged: %v", m)
+	}
+
+	// 超长的消息截断到上限，不截断多字节字符
+	buf.Reset()
+	Info(strings.Repeat("日志", 3000))
+	m = decodeLine(t, buf.Bytes())
+	if msg := m["message"].(string); len(msg) > 4096 || !strings.HasPrefix(msg, "日志") || m[eventTooLargeKey] != true {
+		t.Errorf("oversized message should be truncated: %d bytes", len(msg))
+	}
+}
@@ -0,0 +1,63 @@
+// @Author Clover
+// @Data 2026/10/17 上午10:10:00
+// @Desc 将日志写入 Windows 事件日志
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/rs/zerolog"
+)
+
+// eventLogID 写入事件日志时使用的事件 ID
+const eventLogID = 1
+
+// eventLogger Windows 事件日志的写入接口，与 eventlog.Log 一致，便于在其他平台上测试
+type eventLogger interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// errEventLogUnsupported 非 Windows 平台开启 EnableWindowsEventLog 时返回
+var errEventLogUnsupported = errors.New("windows event log is only supported on windows")
+
+var eventLog eventLogger // 当前打开的事件日志，未开启时为 nil
+
+// eventLogSource 返回事件日志的事件来源，未设置 EventSource 时使用 ProjectName
+func eventLogSource(config Config) string {
+	if config.EventSource != "" {
+		return config.EventSource
+	}
+	return config.ProjectName
+}
+
+// eventLogWriter 按级别将每行 JSON 日志写入事件日志
+type eventLogWriter struct {
+	el eventLogger
+}
+
+func (w eventLogWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 将 warn 映射为 WARNING，error 及以上映射为 ERROR，其余映射为 INFO
+func (w eventLogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	msg := string(bytes.TrimRight(p, "\n"))
+	var err error
+	switch {
+	case level == zerolog.WarnLevel:
+		err = w.el.Warning(eventLogID, msg)
+	case level >= zerolog.ErrorLevel && level <= zerolog.PanicLevel:
+		err = w.el.Error(eventLogID, msg)
+	default:
+		err = w.el.Info(eventLogID, msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
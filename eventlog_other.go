@@ -0,0 +1,8 @@
+//go:build !windows
+
+package logging
+
+// openEventLog 非 Windows 平台不支持事件日志
+func openEventLog(string) (eventLogger, error) {
+	return nil, errEventLogUnsupported
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+type eventRecord struct {
+	kind string
+	msg  string
+}
+
+type mockEventLog struct {
+	records []eventRecord
+	closed  bool
+}
+
+func (m *mockEventLog) record(kind, msg string) error {
+	m.records = append(m.records, eventRecord{kind, msg})
+	return nil
+}
+
+func (m *mockEventLog) Info(_ uint32, msg string) error    { return m.record("INFO", msg) }
+func (m *mockEventLog) Warning(_ uint32, msg string) error { return m.record("WARNING", msg) }
+func (m *mockEventLog) Error(_ uint32, msg string) error   { return m.record("ERROR", msg) }
+func (m *mockEventLog) Close() error                       { m.closed = true; return nil }
+
+func TestEventLogWriter(t *testing.T) {
+	el := &mockEventLog{}
+	logger := zerolog.New(zerolog.MultiLevelWriter(eventLogWriter{el: el}))
+	logger.Debug().Msg("debug")
+	logger.Info().Str("user", "u1").Msg("info")
+	logger.Warn().Msg("warn")
+	logger.Error().Msg("error")
+	logger.WithLevel(zerolog.FatalLevel).Msg("fatal")
+
+	want := []string{"INFO", "INFO", "WARNING", "ERROR", "ERROR"}
+	if len(el.records) != len(want) {
+		t.Fatalf("unexpected records: %v", el.records)
+	}
+	for i, r := range el.records {
+		if r.kind != want[i] {
+			t.Errorf("record %d: want %s, got %s", i, want[i], r.kind)
+		}
+	}
+	if r := el.records[1]; r.msg != `{"level":"info","user":"u1","message":"info"}` {
+		t.Errorf("event message should be the structured log line: %q", r.msg)
+	}
+}
+
+func TestWindowsEventLogUnsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("event log is supported on windows")
+	}
+	prev := baseLogger
+	t.Cleanup(func() {
+		Close()
+		baseLogger = prev
+	})
+	err := InitLogger(Config{ProjectKey: "project", ProjectName: "app", EnableWindowsEventLog: true})
+	if !errors.Is(err, errEventLogUnsupported) || !strings.Contains(err.Error(), "app") {
+		t.Errorf("expected unsupported error for source app, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package logging
+
+import "golang.org/x/sys/windows/svc/eventlog"
+
+// openEventLog 打开事件来源 source 对应的 Windows 事件日志
+func openEventLog(source string) (eventLogger, error) {
+	return eventlog.Open(source)
+}
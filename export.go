@@ -0,0 +1,385 @@
+// @Author Clover
+// @Data 2026/10/17 下午4:10:00
+// @Desc 将日志文件打包导出，便于用户提交给技术支持
+
+package logging
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// exportManifestName 导出包中清单文件的名称
+const exportManifestName = "manifest.json"
+
+// ExportOptions 导出日志的选项
+type ExportOptions struct {
+	Since     time.Time // 只导出不早于该时间的日志，零值表示不限制
+	Until     time.Time // 只导出早于该时间的日志，零值表示不限制
+	JSONArray bool      // 将所有日志合并为一个 JSON 数组文件 logs.json，而不是逐个导出 NDJSON 文件
+	Version   string    // 写入清单的应用版本
+}
+
+// exportManifest 导出包中的 manifest.json
+type exportManifest struct {
+	Project     string              `json:"project"`
+	Version     string              `json:"version,omitempty"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Since       *time.Time          `json:"since,omitempty"`
+	Until       *time.Time          `json:"until,omitempty"`
+	FirstEntry  *time.Time          `json:"first_entry,omitempty"`
+	LastEntry   *time.Time          `json:"last_entry,omitempty"`
+	Levels      map[string]int      `json:"entries_per_level"`
+	Files       []exportFileSummary `json:"files"`
+	Redaction   exportRedaction     `json:"redaction"`
+}
+
+type exportFileSummary struct {
+	Source    string      `json:"source"`
+	Name      string      `json:"name,omitempty"` // JSONArray 时所有日志写入 logs.json，不单独记录
+	Entries   int         `json:"entries"`
+	Malformed int         `json:"malformed,omitempty"` // 无法解析而被跳过的行数
+	Header    *FileHeader `json:"header,omitempty"`    // 文件首行元数据（Config.FileHeader）
+}
+
+type exportRedaction struct {
+	AllowedFields []string `json:"allowed_fields,omitempty"` // 导出时重新应用的字段白名单，为空表示不限制
+	Redacted      int      `json:"redacted_entries"`         // 导出时丢弃了字段的日志条数
+}
+
+// exportState 导出时使用的配置快照
+type exportState struct {
+	paths   []string
+	allowed map[string]struct{}
+	keep    map[string]struct{} // 不受白名单限制的内置字段
+	prefix  string              // Config.FieldPrefix，白名单使用去掉前缀的字段名
+	project string
+}
+
+// ExportArchive 将当前的日志文件（LogPath 以及 Config.Outputs 中 JSON 格式的文件）打包为 zip 写入 w，
+// 包含 manifest.json（项目、版本、时间范围、各级别的条数与应用的脱敏规则）。
+// 导出时按当前的字段白名单重新脱敏，旧文件中写入时尚未生效的规则同样会被应用
+func ExportArchive(w io.Writer, opts ExportOptions) error {
+	st := snapshotExportState()
+	manifest := exportManifest{
+		Project:     st.project,
+		Version:     opts.Version,
+		GeneratedAt: now(),
+		Levels:      make(map[string]int),
+		Files:       []exportFileSummary{},
+	}
+	if !opts.Since.IsZero() {
+		manifest.Since = &opts.Since
+	}
+	if !opts.Until.IsZero() {
+		manifest.Until = &opts.Until
+	}
+	for k := range st.allowed {
+		manifest.Redaction.AllowedFields = append(manifest.Redaction.AllowedFields, k)
+	}
+	sort.Strings(manifest.Redaction.AllowedFields)
+
+	zw := zip.NewWriter(w)
+	var array io.Writer
+	var arrayEntries int
+	if opts.JSONArray {
+		var err error
+		if array, err = zw.Create("logs.json"); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(array, "["); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]int)
+	for _, path := range st.paths {
+		summary := exportFileSummary{Source: path}
+		var out io.Writer = array
+		if !opts.JSONArray {
+			summary.Name = exportFileName(names, path)
+			var err error
+			if out, err = zw.Create(summary.Name); err != nil {
+				return err
+			}
+		}
+		// 首行元数据记录在清单中，NDJSON 导出时同样保留为文件的第一行；读取时间范围时索引可能跳过首行，因此单独读取
+		header, err := readFileHeader(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("export %s: %w", path, err)
+		}
+		summary.Header = header
+		if header != nil && !opts.JSONArray {
+			if _, err := out.Write(header.encode()); err != nil {
+				return err
+			}
+		}
+		err = st.exportFile(path, opts, &manifest, &summary, func(line []byte) error {
+			if opts.JSONArray {
+				if arrayEntries > 0 {
+					if _, err := io.WriteString(out, ","); err != nil {
+						return err
+					}
+				}
+				arrayEntries++
+				_, err := out.Write(line)
+				return err
+			}
+			if _, err := out.Write(line); err != nil {
+				return err
+			}
+			_, err := io.WriteString(out, "\n")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("export %s: %w", path, err)
+		}
+		manifest.Files = append(manifest.Files, summary)
+	}
+	if opts.JSONArray {
+		if _, err := io.WriteString(array, "]\n"); err != nil {
+			return err
+		}
+	}
+
+	mw, err := zw.Create(exportManifestName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ExportHandler 返回以分块传输下载 ExportArchive 结果的 http.Handler
+// 支持查询参数 since、until（RFC3339）与 format=json（合并为 JSON 数组）
+func ExportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var opts ExportOptions
+		query := r.URL.Query()
+		for _, p := range []struct {
+			name string
+			dst  *time.Time
+		}{{"since", &opts.Since}, {"until", &opts.Until}} {
+			if v := query.Get(p.name); v != "" {
+				t, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("invalid %s: %v", p.name, err), http.StatusBadRequest)
+					return
+				}
+				*p.dst = t
+			}
+		}
+		opts.JSONArray = query.Get("format") == "json"
+
+		filename := fmt.Sprintf("logs-%s.zip", now().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(filename))
+		if r.Method == http.MethodHead {
+			return
+		}
+		// 先发送响应头，之后的内容以分块传输的方式边生成边发送
+		w.WriteHeader(http.StatusOK)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		if err := ExportArchive(w, opts); err != nil {
+			// 响应头已发送，只能记录错误
+			Error("Failed to export logs", map[string]interface{}{"error": err.Error()})
+		}
+	})
+}
+
+// snapshotExportState 读取导出所需的当前配置
+func snapshotExportState() exportState {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	st := exportState{project: projectName, prefix: fieldPrefix}
+	if fileOutput && fileFormat == FileFormatJSON && logPath != "" {
+		st.paths = append(st.paths, logPath)
+	}
+	for _, o := range outputs {
+		if o.config.Format == OutputFormatJSON && o.aliasOf == "" {
+			st.paths = append(st.paths, o.config.Path)
+		}
+	}
+	if allowedFields != nil {
+		st.allowed = make(map[string]struct{}, len(allowedFields))
+		for k := range allowedFields {
+			st.allowed[k] = struct{}{}
+		}
+	}
+	st.keep = make(map[string]struct{})
+	for _, k := range []string{
+		zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.ErrorFieldName,
+		ProjectKey, "error_type", "error_code", "temporary", droppedFieldsKey,
+		eventIDKey, traceIDKey, spanIDKey, traceFlagsKey,
+	} {
+		st.keep[k] = struct{}{}
+	}
+	return st
+}
+
+// exportFileName 返回文件在导出包中的名称，同名文件添加序号，压缩文件导出为解压后的名称
+func exportFileName(names map[string]int, path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), compressedExt)
+	names[base]++
+	if n := names[base]; n > 1 {
+		ext := filepath.Ext(base)
+		base = fmt.Sprintf("%s-%d%s", base[:len(base)-len(ext)], n, ext)
+	}
+	return "logs/" + base
+}
+
+// exportFile 逐行读取日志文件，过滤时间范围并重新脱敏后交给 write
+func (st exportState) exportFile(path string, opts ExportOptions, manifest *exportManifest, summary *exportFileSummary, write func([]byte) error) error {
+	// 存在时间索引时只读取可能包含时间范围内日志的区间
+	f, err := openLogRange(path, opts.Since, opts.Until)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if err := st.exportLine(line, opts, manifest, summary, write); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// exportLine 处理单行日志
+func (st exportState) exportLine(line []byte, opts ExportOptions, manifest *exportManifest, summary *exportFileSummary, write func([]byte) error) error {
+	if isFileHeader(line) {
+		return nil
+	}
+	var evt map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&evt); err != nil {
+		summary.Malformed++
+		return nil
+	}
+
+	ts, hasTime := parseEntryTime(evt[zerolog.TimestampFieldName])
+	if !opts.Since.IsZero() || !opts.Until.IsZero() {
+		if !hasTime || (!opts.Since.IsZero() && ts.Before(opts.Since)) || (!opts.Until.IsZero() && !ts.Before(opts.Until)) {
+			return nil
+		}
+	}
+
+	if st.redact(evt) {
+		manifest.Redaction.Redacted++
+	}
+	out, err := json.Marshal(evt)
+	if err != nil {
+		summary.Malformed++
+		return nil
+	}
+	if err := write(out); err != nil {
+		return err
+	}
+
+	summary.Entries++
+	level, _ := evt[zerolog.LevelFieldName].(string)
+	if level == "" {
+		level = zerolog.NoLevel.String()
+	}
+	manifest.Levels[level]++
+	if hasTime {
+		if manifest.FirstEntry == nil || ts.Before(*manifest.FirstEntry) {
+			t := ts
+			manifest.FirstEntry = &t
+		}
+		if manifest.LastEntry == nil || ts.After(*manifest.LastEntry) {
+			t := ts
+			manifest.LastEntry = &t
+		}
+	}
+	return nil
+}
+
+// redact 按当前的字段白名单丢弃字段，返回是否丢弃了字段
+func (st exportState) redact(evt map[string]interface{}) bool {
+	if st.allowed == nil {
+		return false
+	}
+	var dropped []string
+	for k := range evt {
+		name := k
+		if st.prefix != "" {
+			name = strings.TrimPrefix(k, st.prefix)
+		}
+		if _, ok := st.keep[name]; ok {
+			continue
+		}
+		if _, ok := st.keep[k]; ok {
+			continue
+		}
+		if _, ok := st.allowed[name]; !ok {
+			dropped = append(dropped, k)
+			delete(evt, k)
+		}
+	}
+	if len(dropped) == 0 {
+		return false
+	}
+	if prev, ok := evt[droppedFieldsKey].([]interface{}); ok {
+		for _, v := range prev {
+			if s, ok := v.(string); ok {
+				dropped = append(dropped, s)
+			}
+		}
+	}
+	sort.Strings(dropped)
+	evt[droppedFieldsKey] = dropped
+	return true
+}
+
+// parseEntryTime 解析日志中的时间字段，依次尝试 zerolog.TimeFieldFormat（本地时间）与 RFC3339Nano
+func parseEntryTime(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	if t, err := time.ParseInLocation(zerolog.TimeFieldFormat, s, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// readArchive 返回 zip 中各文件的内容
+func readArchive(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestExportArchive(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := InitLogger(Config{
+		LogPath:          logPath,
+		ProjectKey:       "project",
+		ProjectName:      "shop",
+		EnableFileOutput: true,
+		Outputs: []OutputConfig{
+			{Path: filepath.Join(dir, "warn", "app.log"), Level: zerolog.WarnLevel},
+			{Path: filepath.Join(dir, "app.txt"), Format: OutputFormatLogfmt},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+
+	Info("login", map[string]interface{}{"user": "u1", "password": "secret"})
+	Warn("slow query", map[string]interface{}{"user": "u2"})
+	// 导出时应用写入后才配置的白名单
+	stateMu.Lock()
+	setAllowedFields([]string{"user"}, false)
+	stateMu.Unlock()
+	t.Cleanup(func() { setAllowedFields(nil, false) })
+
+	var buf bytes.Buffer
+	if err := ExportArchive(&buf, ExportOptions{Version: "1.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+	files := readArchive(t, buf.Bytes())
+	if len(files) != 3 || files["logs/app.log"] == "" || files["logs/app-2.log"] == "" {
+		t.Fatalf("unexpected archive contents: %v", files)
+	}
+	if strings.Contains(files["logs/app.log"], "secret") || !strings.Contains(files["logs/app.log"], `"dropped_fields":["password"]`) {
+		t.Errorf("export should re-apply redaction: %s", files["logs/app.log"])
+	}
+
+	var manifest exportManifest
+	if err := json.Unmarshal([]byte(files[exportManifestName]), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Project != "shop" || manifest.Version != "1.2.3" || manifest.FirstEntry == nil {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	if manifest.Levels["info"] != 1 || manifest.Levels["warn"] != 2 || manifest.Redaction.Redacted != 1 {
+		t.Errorf("unexpected counts: %+v", manifest)
+	}
+	if len(manifest.Files) != 2 || manifest.Files[0].Entries != 2 || manifest.Files[1].Entries != 1 {
+		t.Errorf("unexpected file summaries: %+v", manifest.Files)
+	}
+
+	// 时间范围之外的日志不会导出
+	buf.Reset()
+	if err := ExportArchive(&buf, ExportOptions{Since: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	files = readArchive(t, buf.Bytes())
+	if files["logs/app.log"] != "" {
+		t.Errorf("entries outside the time range should be skipped: %q", files["logs/app.log"])
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	if err := InitLogger(Config{LogPath: logPath, ProjectKey: "project", EnableFileOutput: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	Info("first")
+	Info("second")
+
+	srv := httptest.NewServer(ExportHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?format=json&since=2000-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/zip" || len(resp.TransferEncoding) == 0 || resp.TransferEncoding[0] != "chunked" {
+		t.Errorf("expected a chunked zip response: %v %v", resp.Header, resp.TransferEncoding)
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(readArchive(t, body)["logs.json"]), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1]["message"] != "second" {
+		t.Errorf("unexpected exported entries: %v", entries)
+	}
+
+	resp, err = http.Get(srv.URL + "?until=yesterday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid time range should be rejected, got %d", resp.StatusCode)
+	}
+}
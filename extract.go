@@ -0,0 +1,135 @@
+// @Author Clover
+// @Data 2026/10/17 下午11:10:00
+// @Desc 从旧式 printf 风格的日志消息中提取结构化字段
+
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	maxExtractMessageLen    = 1024 // 超过该长度的消息不做提取
+	maxExtractRulesPerEvent = 8    // 每条日志最多尝试的规则数
+)
+
+// ExtractRule 从匹配的日志消息中提取字段，用于迁移 Info(fmt.Sprintf(...)) 这类旧代码
+type ExtractRule struct {
+	Regexp     string          // 匹配日志消息的正则表达式
+	FieldNames []string        // 各捕获组对应的字段名，为空时使用 (?P<name>...) 中的组名
+	Levels     []zerolog.Level // 生效的日志级别，为空时对所有级别生效
+	Normalize  bool            // 是否将消息中被捕获的部分替换为 {字段名}，得到统一的消息模板
+}
+
+// extractRule 编译后的提取规则
+type extractRule struct {
+	re        *regexp.Regexp
+	names     []string
+	levels    map[zerolog.Level]struct{} // 为 nil 时对所有级别生效
+	normalize bool
+}
+
+var extractRules []extractRule // 按配置顺序排列的提取规则，由 stateMu 保护
+
+// compileExtractRules 编译提取规则，无效的规则被跳过并返回对应的错误
+func compileExtractRules(rules []ExtractRule) ([]extractRule, []error) {
+	var compiled []extractRule
+	var errs []error
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Regexp)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("extract rule %d: %w", i, err))
+			continue
+		}
+		names := rule.FieldNames
+		if len(names) == 0 {
+			names = re.SubexpNames()[1:]
+		}
+		if len(names) != re.NumSubexp() {
+			errs = append(errs, fmt.Errorf("extract rule %d: %d field names for %d capture groups", i, len(names), re.NumSubexp()))
+			continue
+		}
+		if missing := indexOf(names, ""); missing >= 0 {
+			errs = append(errs, fmt.Errorf("extract rule %d: capture group %d has no field name", i, missing+1))
+			continue
+		}
+		r := extractRule{re: re, names: names, normalize: rule.Normalize}
+		if len(rule.Levels) > 0 {
+			r.levels = make(map[zerolog.Level]struct{}, len(rule.Levels))
+			for _, l := range rule.Levels {
+				r.levels[l] = struct{}{}
+			}
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, errs
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r extractRule) enabled(level zerolog.Level) bool {
+	if r.levels == nil {
+		return true
+	}
+	_, ok := r.levels[level]
+	return ok
+}
+
+// applyExtractRules 使用第一条匹配的规则提取字段，调用方传入的同名字段优先，不修改 fields，调用方需持有 stateMu
+// 每条日志最多尝试 maxExtractRulesPerEvent 条规则，超过 maxExtractMessageLen 的消息不做提取
+func applyExtractRules(level zerolog.Level, msg string, fields map[string]interface{}) (string, map[string]interface{}) {
+	if len(extractRules) == 0 || len(msg) > maxExtractMessageLen {
+		return msg, fields
+	}
+	tried := 0
+	for _, r := range extractRules {
+		if !r.enabled(level) {
+			continue
+		}
+		if tried == maxExtractRulesPerEvent {
+			break
+		}
+		tried++
+		loc := r.re.FindStringSubmatchIndex(msg)
+		if loc == nil {
+			continue
+		}
+		result := make(map[string]interface{}, len(fields)+len(r.names))
+		for k, v := range fields {
+			result[k] = v
+		}
+		var b strings.Builder
+		last := 0
+		for i, name := range r.names {
+			start, end := loc[2*i+2], loc[2*i+3]
+			if start < 0 { // 可选的捕获组没有参与匹配
+				continue
+			}
+			if _, exists := result[name]; !exists {
+				result[name] = msg[start:end]
+			}
+			if r.normalize && start >= last { // 嵌套的捕获组只替换最外层
+				b.WriteString(msg[last:start])
+				b.WriteString("{" + name + "}")
+				last = end
+			}
+		}
+		if r.normalize {
+			b.WriteString(msg[last:])
+			msg = b.String()
+		}
+		return msg, result
+	}
+	return msg, fields
+}
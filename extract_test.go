@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func useExtractRules(t *testing.T, rules ...ExtractRule) {
+	t.Helper()
+	compiled, errs := compileExtractRules(rules)
+	if len(errs) > 0 {
+		t.Fatalf("compile extract rules: %v", errs)
+	}
+	extractRules = compiled
+	t.Cleanup(func() { extractRules = nil })
+}
+
+func TestExtractPatterns(t *testing.T) {
+	buf := captureOutput(t)
+	useExtractRules(t,
+		ExtractRule{
+			Regexp:     `^user (\S+) failed login from (\S+)$`,
+			FieldNames: []string{"user", "ip"},
+			Normalize:  true,
+		},
+		// 与第一条规则重叠，只有第一条匹配的规则生效
+		ExtractRule{Regexp: `^user (?P<name>\S+)`},
+		ExtractRule{Regexp: `^retry (?P<attempt>\d+)`, Levels: []zerolog.Level{zerolog.WarnLevel}},
+	)
+
+	Info("user alice failed login from 10.0.0.1")
+	Info("user bob logged out")
+	Info("retry 3 scheduled")
+	Warn("retry 4 scheduled")
+	Info("disk usage normal")
+	Info("user carol failed login from 10.0.0.2", map[string]interface{}{"ip": "explicit"})
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 6 {
+		t.Fatalf("expected 6 lines, got %d: %v", len(lines), lines)
+	}
+	first := lines[0]
+	if first["user"] != "alice" || first["ip"] != "10.0.0.1" || first["name"] != nil ||
+		first["message"] != "user {user} failed login from {ip}" {
+		t.Errorf("first matching rule should extract and normalize: %v", first)
+	}
+	if lines[1]["name"] != "bob" || lines[1]["message"] != "user bob logged out" {
+		t.Errorf("overlapping rule should apply when the first does not match: %v", lines[1])
+	}
+	if lines[2]["attempt"] != nil || lines[3]["attempt"] != "4" {
+		t.Errorf("rule should only apply to its levels: %v %v", lines[2], lines[3])
+	}
+	if lines[4]["message"] != "disk usage normal" || len(lines[4]) != 2 {
+		t.Errorf("non-matching message should be untouched: %v", lines[4])
+	}
+	if lines[5]["ip"] != "explicit" || lines[5]["user"] != "carol" {
+		t.Errorf("caller fields should take precedence over extracted ones: %v", lines[5])
+	}
+}
+
+func TestExtractPatternsCost(t *testing.T) {
+	buf := captureOutput(t)
+	rules := make([]ExtractRule, maxExtractRulesPerEvent+1)
+	for i := range rules {
+		rules[i] = ExtractRule{Regexp: `^never$`}
+	}
+	rules[maxExtractRulesPerEvent] = ExtractRule{Regexp: `^job (?P<job>\S+)`}
+	useExtractRules(t, rules...)
+
+	Info("job nightly done")
+	Info("job " + strings.Repeat("x", maxExtractMessageLen))
+	lines := decodeLines(t, buf)
+	if lines[0]["job"] != nil || lines[1]["job"] != nil {
+		t.Errorf("rules beyond the per-event cap and long messages should be skipped: %v", lines)
+	}
+}
+
+func TestCompileExtractRulesErrors(t *testing.T) {
+	compiled, errs := compileExtractRules([]ExtractRule{
+		{Regexp: `(`},
+		{Regexp: `a (\S+) (\S+)`, FieldNames: []string{"one"}},
+		{Regexp: `a (\S+)`},
+		{Regexp: `a (?P<ok>\S+)`},
+	})
+	if len(compiled) != 1 || len(errs) != 3 {
+		t.Errorf("expected 1 valid rule and 3 errors, got %d and %v", len(compiled), errs)
+	}
+}
@@ -0,0 +1,106 @@
+// @Author Clover
+// @Data 2026/10/17 下午7:30:00
+// @Desc 主输出目标写入失败时改写备用输出目标
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultFailoverThreshold = 5                // 连续失败多少次后暂停写入主输出目标
+	defaultFailoverCooldown  = 30 * time.Second // 暂停写入主输出目标的时间
+)
+
+var failoverCount atomic.Int64 // 改写备用输出目标的次数
+
+// FailoverCount 返回启动以来 FailoverWriter 改写备用输出目标的次数
+func FailoverCount() int64 {
+	return failoverCount.Load()
+}
+
+// FailoverWriter 优先写入主输出目标（例如日志文件），失败时将错误输出到 os.Stderr 并改写备用输出目标。
+// 主输出目标连续失败 Threshold 次后熔断，Cooldown 时间内直接写入备用输出目标，之后再尝试一次主输出目标
+type FailoverWriter struct {
+	primary   io.Writer
+	secondary io.Writer
+	Threshold int           // 触发熔断的连续失败次数
+	Cooldown  time.Duration // 熔断持续时间
+
+	errOut    io.Writer // 主输出目标错误的输出位置
+	mu        sync.Mutex
+	failures  int       // 主输出目标连续失败的次数
+	openUntil time.Time // 熔断结束时间
+}
+
+// NewFailoverWriter 创建主备输出目标，例如 NewFailoverWriter(file, os.Stderr)
+func NewFailoverWriter(primary, secondary io.Writer) *FailoverWriter {
+	return &FailoverWriter{
+		primary:   primary,
+		secondary: secondary,
+		Threshold: defaultFailoverThreshold,
+		Cooldown:  defaultFailoverCooldown,
+		errOut:    os.Stderr,
+	}
+}
+
+// Write 写入主输出目标，失败或处于熔断期间时写入备用输出目标
+func (fw *FailoverWriter) Write(p []byte) (int, error) {
+	if fw.usePrimary() {
+		n, err := fw.primary.Write(p)
+		if err == nil {
+			fw.recordSuccess()
+			return n, nil
+		}
+		fw.recordFailure(err)
+	}
+	failoverCount.Add(1)
+	return fw.secondary.Write(p)
+}
+
+// usePrimary 判断当前是否应该尝试主输出目标
+func (fw *FailoverWriter) usePrimary() bool {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.openUntil.IsZero() || !now().Before(fw.openUntil)
+}
+
+func (fw *FailoverWriter) recordSuccess() {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.failures = 0
+	fw.openUntil = time.Time{}
+}
+
+// recordFailure 输出主输出目标的错误，连续失败达到阈值时熔断；熔断结束后的试探写入失败会立即再次熔断
+func (fw *FailoverWriter) recordFailure(err error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.failures++
+	threshold := fw.Threshold
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if fw.failures < threshold {
+		fmt.Fprintf(fw.errOut, "logging: primary writer failed, writing to secondary: %v\n", err)
+		return
+	}
+	fw.openUntil = now().Add(fw.Cooldown)
+	fmt.Fprintf(fw.errOut, "logging: primary writer failed %d times in a row, using secondary for %v: %v\n",
+		fw.failures, fw.Cooldown, err)
+}
+
+// Barrier 等待主备输出目标完成写入
+func (fw *FailoverWriter) Barrier(ctx context.Context) error {
+	if err := barrierWriter(ctx, fw.primary); err != nil {
+		return err
+	}
+	return barrierWriter(ctx, fw.secondary)
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyWriter 在 fail 为 true 时返回错误，模拟磁盘写满
+type flakyWriter struct {
+	fail bool
+	buf  bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("no space left on device")
+	}
+	return w.buf.Write(p)
+}
+
+func TestFailoverWriter(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2024, 7, 18, 15, 0, 0, 0, time.Local))
+	primary := &flakyWriter{}
+	secondary := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	fw := NewFailoverWriter(primary, secondary)
+	fw.Threshold = 2
+	fw.Cooldown = time.Minute
+	fw.errOut = errOut
+	start := FailoverCount()
+
+	fw.Write([]byte("a\n"))
+	if primary.buf.String() != "a\n" || secondary.Len() != 0 {
+		t.Fatalf("healthy primary should receive writes")
+	}
+
+	// 主输出目标失败时改写备用输出目标并输出错误
+	primary.fail = true
+	if n, err := fw.Write([]byte("b\n")); n != 2 || err != nil {
+		t.Fatalf("unexpected result: %d %v", n, err)
+	}
+	if secondary.String() != "b\n" || !strings.Contains(errOut.String(), "no space left on device") {
+		t.Errorf("failed write should go to secondary, secondary %q, stderr %q", secondary.String(), errOut.String())
+	}
+
+	// 连续失败达到阈值后熔断，熔断期间不再尝试主输出目标
+	fw.Write([]byte("c\n"))
+	if !strings.Contains(errOut.String(), "2 times in a row") {
+		t.Errorf("expected circuit open message, got %q", errOut.String())
+	}
+	primary.fail = false
+	fw.Write([]byte("d\n"))
+	if primary.buf.String() != "a\n" || secondary.String() != "b\nc\nd\n" {
+		t.Errorf("primary should be skipped during cooldown: primary %q, secondary %q", primary.buf.String(), secondary.String())
+	}
+
+	// 熔断结束后试探主输出目标，成功后恢复
+	clock.skip(time.Minute)
+	fw.Write([]byte("e\n"))
+	fw.Write([]byte("f\n"))
+	if primary.buf.String() != "a\ne\nf\n" {
+		t.Errorf("primary should recover after cooldown: %q", primary.buf.String())
+	}
+	if got := FailoverCount() - start; got != 3 {
+		t.Errorf("expected 3 failovers, got %d", got)
+	}
+
+	// 试探失败时立即再次熔断
+	primary.fail = true
+	fw.Write([]byte("g\n"))
+	fw.Write([]byte("h\n"))
+	clock.skip(time.Minute)
+	errOut.Reset()
+	fw.Write([]byte("i\n"))
+	if !strings.Contains(errOut.String(), "in a row") {
+		t.Errorf("failed probe should reopen the circuit, got %q", errOut.String())
+	}
+	primary.fail = false
+	fw.Write([]byte("j\n"))
+	if strings.Contains(primary.buf.String(), "j") {
+		t.Errorf("primary should be skipped after a failed probe")
+	}
+}
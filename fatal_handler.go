@@ -0,0 +1,106 @@
+// @Author Clover
+// @Data 2026/10/18 上午5:30:00
+// @Desc Fatal 退出前交给监管方决定立即退出、延迟退出或降级为 Error
+
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// maxFatalDelay 处理函数与 ExitAfter 合计最多推迟退出的时间，避免有问题的处理函数让已出错的进程一直运行
+const maxFatalDelay = 30 * time.Second
+
+// fatalDowngradedKey 被降级的 Fatal 日志重新以 Error 级别输出时添加的字段
+const fatalDowngradedKey = "fatal_downgraded"
+
+type fatalAction int
+
+const (
+	fatalExitNow fatalAction = iota
+	fatalExitAfter
+	fatalDowngrade
+)
+
+// FatalDecision Fatal 处理函数的决定，使用 ExitNow、ExitAfter 或 Downgrade
+type FatalDecision struct {
+	action fatalAction
+	delay  time.Duration
+}
+
+var (
+	// ExitNow 立即退出，与未设置处理函数时相同
+	ExitNow = FatalDecision{action: fatalExitNow}
+	// Downgrade 不退出进程：以 Error 级别重新输出该日志（带 fatal_downgraded=true）后，Fatal 正常返回
+	Downgrade = FatalDecision{action: fatalDowngrade}
+)
+
+// ExitAfter 等待 d 后退出，例如等待正在提交的批次完成；处理函数与等待合计最多 30 秒
+func ExitAfter(d time.Duration) FatalDecision {
+	return FatalDecision{action: fatalExitAfter, delay: d}
+}
+
+var (
+	fatalMu       sync.Mutex
+	fatalHandler  func(entry LogEntry) FatalDecision
+	fatalDelayCap = maxFatalDelay // 测试中可以缩短
+)
+
+// SetFatalHandler 设置 Fatal 退出前调用的处理函数，handler 为 nil 时恢复为直接退出。
+// 处理函数在 Fatal 日志写入各输出目标之后、退出进程之前调用；处理函数 panic 或在 30 秒内没有返回时直接退出
+func SetFatalHandler(handler func(entry LogEntry) FatalDecision) {
+	fatalMu.Lock()
+	defer fatalMu.Unlock()
+	fatalHandler = handler
+}
+
+// fatalExit 按处理函数的决定退出进程，Downgrade 时返回
+func fatalExit(entry LogEntry, exitCode int) {
+	fatalMu.Lock()
+	handler, limit := fatalHandler, fatalDelayCap
+	fatalMu.Unlock()
+	if handler == nil {
+		exit(exitCode)
+		return
+	}
+	awaitWrites()
+
+	start := now()
+	decided := make(chan FatalDecision, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				decided <- ExitNow
+			}
+		}()
+		decided <- handler(entry)
+	}()
+	var decision FatalDecision
+	select {
+	case decision = <-decided:
+	case <-currentClock().After(limit):
+		currentLogger().Error().Dur("limit", limit).Msg("Fatal handler did not return in time, exiting")
+		exit(exitCode)
+		return
+	}
+
+	switch decision.action {
+	case fatalDowngrade:
+		fields := mergeFields([]map[string]interface{}{entry.Fields, {fatalDowngradedKey: true}})
+		emit(currentLogger().Error(), zerolog.ErrorLevel, nil, entry.Message, []map[string]interface{}{fields})
+		return
+	case fatalExitAfter:
+		d := decision.delay
+		if remaining := limit - now().Sub(start); d > remaining {
+			currentLogger().Warn().Dur("delay", d).Dur("limit", limit).Msg("Fatal exit delay exceeds the limit, exiting earlier")
+			d = remaining
+		}
+		if d > 0 {
+			<-currentClock().After(d)
+		}
+	}
+	exit(exitCode)
+}
@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// fatalCaseEnv 子进程中要运行的 Fatal 场景
+const fatalCaseEnv = "LOGGING_FATAL_CASE"
+
+// TestFatalHandlerProcess 在子进程中运行，由 TestFatalHandler 启动
+func TestFatalHandlerProcess(t *testing.T) {
+	name := os.Getenv(fatalCaseEnv)
+	if name == "" {
+		t.Skip("helper process for TestFatalHandler")
+	}
+	baseLogger = zerolog.New(os.Stdout)
+	fatalDelayCap = 300 * time.Millisecond
+	switch name {
+	case "exit-now":
+		SetFatalHandler(func(e LogEntry) FatalDecision {
+			fmt.Printf("handler saw %q batch=%v\n", e.Message, e.Fields["batch"])
+			return ExitNow
+		})
+	case "exit-after":
+		SetFatalHandler(func(LogEntry) FatalDecision {
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				fmt.Println("batch committed")
+			}()
+			return ExitAfter(150 * time.Millisecond)
+		})
+	case "exit-after-capped":
+		SetFatalHandler(func(LogEntry) FatalDecision { return ExitAfter(time.Hour) })
+	case "hung-handler":
+		SetFatalHandler(func(LogEntry) FatalDecision { select {} })
+	case "panicking-handler":
+		SetFatalHandler(func(LogEntry) FatalDecision { panic("bad handler") })
+	case "downgrade":
+		SetFatalHandler(func(LogEntry) FatalDecision { return Downgrade })
+	}
+	Fatal("poisoned", 3, map[string]interface{}{"batch": 42})
+	fmt.Println("continued")
+}
+
+func TestFatalHandler(t *testing.T) {
+	if os.Getenv(fatalCaseEnv) != "" {
+		t.Skip("running as helper process")
+	}
+	cases := []struct {
+		name     string
+		code     int
+		want     []string
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		// 未设置处理函数时与之前的行为相同
+		{name: "none", code: 3, want: []string{`"level":"fatal"`, `"message":"poisoned"`}},
+		{name: "exit-now", code: 3, want: []string{`"message":"poisoned"`, `handler saw "poisoned" batch=42`}},
+		{name: "exit-after", code: 3, want: []string{"batch committed"}, minDelay: 150 * time.Millisecond},
+		// ExitAfter 与处理函数都受 fatalDelayCap 限制
+		{name: "exit-after-capped", code: 3, want: []string{"exceeds the limit"}, minDelay: 300 * time.Millisecond, maxDelay: 5 * time.Second},
+		{name: "hung-handler", code: 3, want: []string{"did not return in time"}, minDelay: 300 * time.Millisecond, maxDelay: 5 * time.Second},
+		{name: "panicking-handler", code: 3},
+		{name: "downgrade", code: 0, want: []string{`"level":"error"`, `"fatal_downgraded":true`, "continued"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestFatalHandlerProcess$", "-test.count=1")
+			cmd.Env = append(os.Environ(), fatalCaseEnv+"="+tc.name)
+			start := time.Now()
+			out, err := cmd.CombinedOutput()
+			elapsed := time.Since(start)
+
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tc.code {
+				t.Errorf("exit code = %d, want %d\n%s", code, tc.code, out)
+			}
+			if !strings.Contains(string(out), `"message":"poisoned"`) {
+				t.Errorf("fatal event should be written before the handler runs:\n%s", out)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(string(out), w) {
+					t.Errorf("output should contain %q:\n%s", w, out)
+				}
+			}
+			if elapsed < tc.minDelay || (tc.maxDelay > 0 && elapsed > tc.maxDelay) {
+				t.Errorf("exited after %v, want between %v and %v", elapsed, tc.minDelay, tc.maxDelay)
+			}
+		})
+	}
+}
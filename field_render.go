@@ -0,0 +1,86 @@
+// @Author Clover
+// @Data 2026/10/16 下午9:10:00
+// @Desc 字段值的输出格式：时长、时间、IP 与 URL
+
+package logging
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// 时长字段的输出单位
+const (
+	DurationUnitMillis  = "ms"     // 毫秒，浮点数
+	DurationUnitSeconds = "s"      // 秒，浮点数
+	DurationUnitString  = "string" // time.Duration.String()，例如 1.5s
+)
+
+var (
+	durationUnit    string // 时长字段的输出单位，为空时输出纳秒整数
+	timeFieldLayout string // 时间字段的输出格式，为空时输出 RFC3339Nano
+)
+
+// validDurationUnit 检查时长单位是否合法
+func validDurationUnit(unit string) bool {
+	switch unit {
+	case "", DurationUnitMillis, DurationUnitSeconds, DurationUnitString:
+		return true
+	}
+	return false
+}
+
+// writeFields 按字段名顺序写入字段
+func writeFields(event *zerolog.Event, fields map[string]interface{}) *zerolog.Event {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		event = writeField(event, k, fields[k])
+	}
+	return event
+}
+
+// writeField 按类型写入单个字段，时长、时间、IP 与 URL 使用统一的格式，其余类型交给 Interface
+func writeField(event *zerolog.Event, key string, v interface{}) *zerolog.Event {
+	switch val := v.(type) {
+	case time.Duration:
+		switch durationUnit {
+		case DurationUnitMillis:
+			return event.Float64(key, float64(val)/float64(time.Millisecond))
+		case DurationUnitSeconds:
+			return event.Float64(key, val.Seconds())
+		case DurationUnitString:
+			return event.Str(key, val.String())
+		}
+		return event.Int64(key, int64(val))
+	case time.Time:
+		if timeFieldLayout != "" {
+			return event.Str(key, val.Format(timeFieldLayout))
+		}
+		return event.Str(key, val.Format(time.RFC3339Nano))
+	case net.IP:
+		return event.Str(key, val.String())
+	case net.IPNet:
+		return event.Str(key, val.String())
+	case *net.IPNet:
+		return event.Str(key, val.String())
+	case url.URL:
+		return event.Str(key, val.String())
+	case *url.URL:
+		return event.Str(key, val.String())
+	case string:
+		if t := interner.Load(); t != nil {
+			if frag := t.fragment(val); frag != nil {
+				return event.RawJSON(key, frag)
+			}
+		}
+	}
+	return event.Interface(key, v)
+}
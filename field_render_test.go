@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldRendering(t *testing.T) {
+	buf := captureOutput(t)
+	t.Cleanup(func() { durationUnit, timeFieldLayout = "", "" })
+
+	_, ipNet, _ := net.ParseCIDR("10.0.0.0/8")
+	u, _ := url.Parse("https://example.com/a?b=1")
+	ts := time.Date(2026, 10, 16, 12, 30, 0, 123456789, time.UTC)
+	fields := map[string]interface{}{
+		"dur":   1500 * time.Millisecond,
+		"at":    ts,
+		"ip":    net.ParseIP("192.168.1.1"),
+		"ip6":   net.ParseIP("::1"),
+		"net":   ipNet,
+		"url":   u,
+		"url_v": *u,
+	}
+
+	cases := []struct {
+		unit, layout string
+		want         string
+	}{
+		{"", "", `{"level":"info","at":"2026-10-16T12:30:00.123456789Z","dur":1500000000,` +
+			`"ip":"192.168.1.1","ip6":"::1","net":"10.0.0.0/8","url":"https://example.com/a?b=1","url_v":"https://example.com/a?b=1","message":"m"}`},
+		{DurationUnitMillis, "2006-01-02 15:04:05", `{"level":"info","at":"2026-10-16 12:30:00","dur":1500,`},
+		{DurationUnitSeconds, time.RFC3339, `{"level":"info","at":"2026-10-16T12:30:00Z","dur":1.5,`},
+		{DurationUnitString, "", `{"level":"info","at":"2026-10-16T12:30:00.123456789Z","dur":"1.5s",`},
+	}
+	for _, c := range cases {
+		durationUnit, timeFieldLayout = c.unit, c.layout
+		buf.Reset()
+		Info("m", fields)
+		if got := strings.TrimSpace(buf.String()); !strings.HasPrefix(got, c.want) {
+			t.Errorf("unit %q layout %q:\n got  %s\n want %s", c.unit, c.layout, got, c.want)
+		}
+	}
+
+	// LogBuffer 直接输出时使用相同的格式
+	durationUnit = DurationUnitMillis
+	buf.Reset()
+	writeEntry(LogEntry{Message: "m", Fields: map[string]interface{}{"dur": 2 * time.Second, "ip": net.IPv4(1, 2, 3, 4)}})
+	if m := decodeLine(t, buf.Bytes()); m["dur"] != float64(2000) || m["ip"] != "1.2.3.4" {
+		t.Errorf("unexpected buffered entry: %v", m)
+	}
+}
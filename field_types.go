@@ -0,0 +1,157 @@
+// @Author Clover
+// @Data 2026/10/17 下午2:00:00
+// @Desc 字段类型转换与浮点数精度控制
+
+package logging
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FieldType 字段的目标类型
+type FieldType string
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeInt    FieldType = "int"
+	FieldTypeFloat  FieldType = "float"
+	FieldTypeBool   FieldType = "bool"
+)
+
+// coerceFailedPrefix 类型转换失败时添加的标记字段前缀
+const coerceFailedPrefix = "coerce_failed_"
+
+var (
+	fieldTypes     map[string]FieldType // 声明了类型的字段
+	floatPrecision int                  // 浮点数字段保留的小数位数，0 表示不限制
+)
+
+// coerceFields 将声明了类型的字段转换为目标类型，并限制浮点数的小数位数，
+// 转换失败时保留原值并添加 coerce_failed_<key>=true；不修改传入的 map
+func coerceFields(fields map[string]interface{}) map[string]interface{} {
+	if len(fields) == 0 || (len(fieldTypes) == 0 && floatPrecision <= 0) {
+		return fields
+	}
+	result := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if t, ok := fieldTypes[k]; ok {
+			coerced, ok := coerceValue(v, t)
+			if ok {
+				v = coerced
+			} else {
+				result[coerceFailedPrefix+k] = true
+			}
+		}
+		result[k] = roundFloat(v)
+	}
+	return result
+}
+
+// coerceValue 将 v 转换为类型 t
+func coerceValue(v interface{}, t FieldType) (interface{}, bool) {
+	switch t {
+	case FieldTypeString:
+		switch val := v.(type) {
+		case string:
+			return val, true
+		case float64:
+			return strconv.FormatFloat(val, 'f', -1, 64), true
+		case float32:
+			return strconv.FormatFloat(float64(val), 'f', -1, 32), true
+		}
+		return fmt.Sprint(v), true
+	case FieldTypeInt:
+		if s, ok := v.(string); ok {
+			i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			return i, err == nil
+		}
+		if f, ok := toFloat(v); ok && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			if i, ok := toInt(v); ok {
+				return i, true
+			}
+			return int64(f), true
+		}
+	case FieldTypeFloat:
+		if s, ok := v.(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			return f, err == nil
+		}
+		if f, ok := toFloat(v); ok {
+			return f, true
+		}
+	case FieldTypeBool:
+		switch val := v.(type) {
+		case bool:
+			return val, true
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(val))
+			return b, err == nil
+		}
+	}
+	return v, false
+}
+
+// toInt 返回整数类型的值
+func toInt(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case int:
+		return int64(val), true
+	case int8:
+		return int64(val), true
+	case int16:
+		return int64(val), true
+	case int32:
+		return int64(val), true
+	case int64:
+		return val, true
+	case uint:
+		return int64(val), true
+	case uint8:
+		return int64(val), true
+	case uint16:
+		return int64(val), true
+	case uint32:
+		return int64(val), true
+	case uint64:
+		return int64(val), true
+	}
+	return 0, false
+}
+
+// toFloat 返回数值类型的值
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	}
+	if i, ok := toInt(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// roundFloat 按 floatPrecision 保留浮点数的小数位数
+func roundFloat(v interface{}) interface{} {
+	if floatPrecision <= 0 {
+		return v
+	}
+	var f float64
+	switch val := v.(type) {
+	case float64:
+		f = val
+	case float32:
+		f = float64(val)
+	default:
+		return v
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return v
+	}
+	scale := math.Pow10(floatPrecision)
+	return math.Round(f*scale) / scale
+}
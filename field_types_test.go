@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestFieldTypes(t *testing.T) {
+	buf := captureOutput(t)
+	fieldTypes = map[string]FieldType{
+		"id":      FieldTypeString,
+		"count":   FieldTypeInt,
+		"ratio":   FieldTypeFloat,
+		"enabled": FieldTypeBool,
+		"port":    FieldTypeInt,
+	}
+	floatPrecision = 2
+	t.Cleanup(func() { fieldTypes, floatPrecision = nil, 0 })
+
+	fields := map[string]interface{}{
+		"id":      12345,
+		"count":   "42",
+		"ratio":   "0.12345",
+		"enabled": "true",
+		"port":    "http",
+		"other":   3.14159,
+	}
+	Info("m", fields)
+	m := decodeLine(t, buf.Bytes())
+	want := map[string]interface{}{
+		"id":                 "12345",
+		"count":              float64(42),
+		"ratio":              0.12,
+		"enabled":            true,
+		"port":               "http",
+		"coerce_failed_port": true,
+		"other":              3.14,
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v (%T), want %v", k, m[k], m[k], v)
+		}
+	}
+	if fields["id"] != 12345 {
+		t.Error("caller's fields must not be modified")
+	}
+
+	// 类型转换在白名单之前执行，LogBuffer 直接输出时同样生效
+	setAllowedFields([]string{"count"}, false)
+	t.Cleanup(func() { setAllowedFields(nil, false) })
+	buf.Reset()
+	writeEntry(LogEntry{Message: "m", Fields: map[string]interface{}{"count": "7", "id": 1}})
+	m = decodeLine(t, buf.Bytes())
+	if m["count"] != float64(7) || m["id"] != nil {
+		t.Errorf("unexpected buffered entry: %v", m)
+	}
+}
+
+func TestCoerceValue(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		typ  FieldType
+		want interface{}
+		ok   bool
+	}{
+		{1.5, FieldTypeString, "1.5", true},
+		{true, FieldTypeString, "true", true},
+		{3.0, FieldTypeInt, int64(3), true},
+		{3.5, FieldTypeInt, 3.5, false},
+		{uint8(9), FieldTypeInt, int64(9), true},
+		{" 12 ", FieldTypeInt, int64(12), true},
+		{int32(2), FieldTypeFloat, float64(2), true},
+		{"abc", FieldTypeFloat, "abc", false},
+		{"0", FieldTypeBool, false, true},
+		{1, FieldTypeBool, 1, false},
+	}
+	for _, c := range cases {
+		got, ok := coerceValue(c.in, c.typ)
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("coerceValue(%v, %s) = %v, %v; want %v, %v", c.in, c.typ, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// @Author Clover
+// @Data 2026/10/16 下午2:00:00
+// @Desc 用户字段处理规则
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	droppedFieldsKey      = "dropped_fields"       // 记录被白名单丢弃的字段名
+	reservedKeyDroppedKey = "reserved_key_dropped" // 记录与保留字段重名而被丢弃的字段名
+	reservedKeyRenamed    = "field_"               // 与保留字段重名的字段重命名时添加的前缀
+)
+
+// ReservedKeyPolicy 用户字段与 time、level、message、ProjectKey 等保留字段重名时的处理方式
+type ReservedKeyPolicy string
+
+const (
+	ReservedKeyRename ReservedKeyPolicy = "rename" // 重命名为 field_<key>（默认）
+	ReservedKeyDrop   ReservedKeyPolicy = "drop"   // 丢弃，并在 reserved_key_dropped 中记录字段名
+	ReservedKeyAllow  ReservedKeyPolicy = "allow"  // 原样输出，JSON 中会出现重复的键
+)
+
+// ErrUnrestrictedDisabled 未开启 Config.AllowUnrestricted 时调用 Unrestricted 返回
+var ErrUnrestrictedDisabled = errors.New("unrestricted logger is disabled, set Config.AllowUnrestricted to enable it")
+
+var (
+	allowedFields     map[string]struct{} // 字段白名单，为 nil 时不限制
+	allowUnrestricted bool                // 是否允许获取不受白名单限制的日志记录器
+	fieldPrefix       string              // 用户字段名的前缀
+	reservedKeyPolicy = ReservedKeyRename // 用户字段与保留字段重名时的处理方式
+
+	// contextDroppedFields SetField 的字段中被白名单丢弃的字段名，由 stateMu 保护；
+	// 不写入 baseLogger 的上下文，而是与每条日志自身丢弃的字段合并后只写入一次 dropped_fields
+	contextDroppedFields []string
+)
+
+func setAllowedFields(keys []string, unrestricted bool) {
+	allowUnrestricted = unrestricted
+	if len(keys) == 0 {
+		allowedFields = nil
+		return
+	}
+	allowedFields = make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		allowedFields[k] = struct{}{}
+	}
+}
+
+// applyFieldRules 对用户传入的字段执行类型转换、白名单、前缀、保留字段、Masker 与重命名等规则，未配置规则时原样返回
+// 类型转换最先执行，之后的规则看到的都是转换后的值；白名单使用原始字段名，重命名最后执行，使用加上前缀后的字段名
+func applyFieldRules(fields map[string]interface{}) map[string]interface{} {
+	return renameFields(maskFields(applyReservedKeyPolicy(prefixFields(filterAllowedFields(coerceFields(fields))))))
+}
+
+// filterAllowedFields 丢弃不在白名单中的字段，并在 dropped_fields 中记录字段名
+func filterAllowedFields(fields map[string]interface{}) map[string]interface{} {
+	if allowedFields == nil || len(fields) == 0 {
+		return fields
+	}
+	result := make(map[string]interface{}, len(fields))
+	var dropped []string
+	for k, v := range fields {
+		if _, ok := allowedFields[k]; ok {
+			result[k] = v
+		} else {
+			dropped = append(dropped, k)
+		}
+	}
+	if len(dropped) > 0 {
+		sort.Strings(dropped)
+		result[droppedFieldsKey] = dropped
+		if dryRun != nil {
+			dryRun.recordRedactions(dropped)
+		}
+	}
+	return result
+}
+
+// recordContextDropped 从 SetField 处理后的字段中取出 dropped_fields 并记录到 contextDroppedFields，调用方需持有 stateMu 的写锁
+func recordContextDropped(fields map[string]interface{}) map[string]interface{} {
+	dropped, ok := fields[droppedFieldsKey].([]string)
+	if !ok {
+		return fields
+	}
+	result := make(map[string]interface{}, len(fields)-1)
+	for k, v := range fields {
+		if k != droppedFieldsKey {
+			result[k] = v
+		}
+	}
+	contextDroppedFields = mergeDropped(contextDroppedFields, dropped)
+	return result
+}
+
+// withContextDropped 将 SetField 丢弃的字段名合并到日志自身的 dropped_fields 中，调用方需持有 stateMu
+func withContextDropped(fields map[string]interface{}) map[string]interface{} {
+	if len(contextDroppedFields) == 0 {
+		return fields
+	}
+	result := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		result[k] = v
+	}
+	own, _ := fields[droppedFieldsKey].([]string)
+	result[droppedFieldsKey] = mergeDropped(contextDroppedFields, own)
+	return result
+}
+
+// mergeDropped 合并两组丢弃的字段名，结果排序且不重复
+func mergeDropped(a, b []string) []string {
+	merged := append(slices.Clone(a), b...)
+	sort.Strings(merged)
+	return slices.Compact(merged)
+}
+
+// prefixFields 为用户字段名添加 Config.FieldPrefix，
+// zerolog 的 level、time、message 以及 dropped_fields 等内部标记字段保持原样
+func prefixFields(fields map[string]interface{}) map[string]interface{} {
+	if fieldPrefix == "" || len(fields) == 0 {
+		return fields
+	}
+	result := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		switch {
+		case k == zerolog.LevelFieldName, k == zerolog.TimestampFieldName, k == zerolog.MessageFieldName,
+			k == droppedFieldsKey, strings.HasPrefix(k, coerceFailedPrefix):
+		default:
+			k = fieldPrefix + k
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// validReservedKeyPolicy 判断是否为支持的保留字段处理方式，空字符串表示默认的 rename
+func validReservedKeyPolicy(p ReservedKeyPolicy) bool {
+	switch p {
+	case "", ReservedKeyRename, ReservedKeyDrop, ReservedKeyAllow:
+		return true
+	}
+	return false
+}
+
+// isReservedKey 判断字段名是否与日志记录器自身输出的字段重名，调用方需持有 stateMu
+func isReservedKey(k string) bool {
+	switch k {
+	case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, ProjectKey:
+		return true
+	case eventIDKey:
+		return eventIDMode.Load() != eventIDOff
+	case hostnameKey, ipAddressKey, buildInfoKey:
+		return slices.Contains(injectedKeys, k)
+	case monoKey:
+		return monoEnabled.Load()
+	}
+	return false
+}
+
+// errorFieldKeys withErr 为附带错误的日志添加的字段
+var errorFieldKeys = []string{zerolog.ErrorFieldName, "error_type", "error_code", "temporary"}
+
+// applyReservedKeyPolicy 按 reservedKeyPolicy 处理与保留字段重名的用户字段，避免输出重复的键
+func applyReservedKeyPolicy(fields map[string]interface{}) map[string]interface{} {
+	return reserveKeys(fields, isReservedKey)
+}
+
+// reserveErrorKeys 日志附带错误时按 reservedKeyPolicy 处理与 withErr 添加的字段重名的用户字段，调用方需持有 stateMu
+func reserveErrorKeys(fields map[string]interface{}, err error) map[string]interface{} {
+	if err == nil {
+		return fields
+	}
+	return reserveKeys(fields, func(k string) bool { return slices.Contains(errorFieldKeys, k) })
+}
+
+// reserveKeys 按 reservedKeyPolicy 处理 reserved 返回 true 的字段，丢弃时与已有的 reserved_key_dropped 合并
+func reserveKeys(fields map[string]interface{}, reserved func(string) bool) map[string]interface{} {
+	if reservedKeyPolicy == ReservedKeyAllow || len(fields) == 0 {
+		return fields
+	}
+	var conflicts []string
+	for k := range fields {
+		if reserved(k) {
+			conflicts = append(conflicts, k)
+		}
+	}
+	if len(conflicts) == 0 {
+		return fields
+	}
+	result := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		result[k] = v
+	}
+	sort.Strings(conflicts)
+	for _, k := range conflicts {
+		if reservedKeyPolicy == ReservedKeyRename {
+			result[reservedKeyRenamed+k] = result[k]
+		}
+		delete(result, k)
+	}
+	if reservedKeyPolicy == ReservedKeyDrop {
+		prev, _ := result[reservedKeyDroppedKey].([]string)
+		result[reservedKeyDroppedKey] = mergeDropped(prev, conflicts)
+	}
+	return result
+}
+
+// Unrestricted 返回不受字段白名单限制的日志记录器，供审计等子系统使用
+// 需要在 Config 中显式开启 AllowUnrestricted，否则返回 ErrUnrestrictedDisabled
+func Unrestricted() (zerolog.Logger, error) {
+	ensureLogger()
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if !allowUnrestricted {
+		return zerolog.Nop(), ErrUnrestrictedDisabled
+	}
+	return baseLogger, nil
+}
+
+// KV 将交替出现的键值对转换为字段集合，例如 KV("user", "u1", "id", 123)
+// 参数个数为奇数或键不是字符串时 panic
+func KV(pairs ...interface{}) map[string]interface{} {
+	if len(pairs)%2 != 0 {
+		panic(fmt.Sprintf("logging.KV: odd number of arguments (%d), missing value for key %v", len(pairs), pairs[len(pairs)-1]))
+	}
+	fields := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			panic(fmt.Sprintf("logging.KV: key at position %d must be a string, got %T (%v)", i, pairs[i], pairs[i]))
+		}
+		fields[key] = pairs[i+1]
+	}
+	return fields
+}
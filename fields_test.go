@@ -0,0 +1,219 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestAllowedFields(t *testing.T) {
+	buf := captureOutput(t)
+	setAllowedFields([]string{"user_id", "action"}, false)
+	t.Cleanup(func() { setAllowedFields(nil, false) })
+
+	Info("login", map[string]interface{}{"user_id": 1, "password": "secret", "email": "a@b.c"})
+	m := decodeLine(t, buf.Bytes())
+	if m["user_id"] != float64(1) || m["password"] != nil || m["email"] != nil {
+		t.Errorf("unexpected fields: %v", m)
+	}
+	if !reflect.DeepEqual(m[droppedFieldsKey], []interface{}{"email", "password"}) {
+		t.Errorf("unexpected dropped fields: %v", m[droppedFieldsKey])
+	}
+
+	// 缓冲区条目同样受白名单限制
+	buf.Reset()
+	lb := NewLogBuffer()
+	lb.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "buffered", Fields: map[string]interface{}{"action": "x", "ssn": "123"}})
+	lb.Flush(zerolog.InfoLevel)
+	m = decodeLine(t, buf.Bytes())
+	if m["action"] != "x" || m["ssn"] != nil {
+		t.Errorf("unexpected buffered fields: %v", m)
+	}
+
+	// SetField 设置的全局字段同样受限制
+	buf.Reset()
+	SetField(map[string]interface{}{"token": "t"})
+	Info("after set field")
+	m = decodeLine(t, buf.Bytes())
+	if m["token"] != nil || !reflect.DeepEqual(m[droppedFieldsKey], []interface{}{"token"}) {
+		t.Errorf("SetField should respect allowlist: %v", m)
+	}
+
+	// 未开启时 Unrestricted 返回错误
+	if _, err := Unrestricted(); !errors.Is(err, ErrUnrestrictedDisabled) {
+		t.Errorf("expected ErrUnrestrictedDisabled, got %v", err)
+	}
+	setAllowedFields([]string{"user_id"}, true)
+	out := &bytes.Buffer{}
+	logger, err := Unrestricted()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger = logger.Output(out)
+	logger.Info().Str("password", "audit").Msg("audit")
+	if m = decodeLine(t, out.Bytes()); m["password"] != "audit" {
+		t.Errorf("unrestricted logger should keep all fields: %v", m)
+	}
+}
+
+func TestFieldPrefix(t *testing.T) {
+	buf := captureOutput(t)
+	fieldPrefix = "app_"
+	setAllowedFields([]string{"user_id", "message"}, false)
+	t.Cleanup(func() {
+		fieldPrefix = ""
+		setAllowedFields(nil, false)
+	})
+
+	SetField(map[string]interface{}{"region": "eu"})
+	ErrorWithErr(errors.New("boom"), "login", map[string]interface{}{"user_id": 1, "message": "m"})
+	m := decodeLine(t, buf.Bytes())
+	if m["app_user_id"] != float64(1) || m["user_id"] != nil {
+		t.Errorf("user fields should be prefixed: %v", m)
+	}
+	if m["error"] != "boom" || m["app_message"] != nil {
+		t.Errorf("reserved keys should not be prefixed: %v", m)
+	}
+	if !reflect.DeepEqual(m[droppedFieldsKey], []interface{}{"region"}) || m["app_region"] != nil {
+		t.Errorf("allowlist should use unprefixed names: %v", m)
+	}
+}
+
+func TestDroppedFieldsWrittenOnce(t *testing.T) {
+	buf := captureOutput(t)
+	setAllowedFields([]string{"user_id"}, false)
+	t.Cleanup(func() { setAllowedFields(nil, false) })
+
+	// SetField 与日志自身的字段都有被丢弃的字段时只输出一个合并后的 dropped_fields
+	SetField(map[string]interface{}{"token": "t", "region": "eu"})
+	Info("login", map[string]interface{}{"user_id": 1, "password": "secret", "token": "again"})
+	m := decodeStrict(t, buf.Bytes())
+	if !reflect.DeepEqual(m[droppedFieldsKey], []interface{}{"password", "region", "token"}) {
+		t.Errorf("dropped fields should be merged: %v", m[droppedFieldsKey])
+	}
+
+	// 日志自身没有丢弃字段时仍然记录 SetField 丢弃的字段
+	buf.Reset()
+	Info("plain", map[string]interface{}{"user_id": 2})
+	if m = decodeStrict(t, buf.Bytes()); !reflect.DeepEqual(m[droppedFieldsKey], []interface{}{"region", "token"}) {
+		t.Errorf("context dropped fields should be kept: %v", m[droppedFieldsKey])
+	}
+}
+
+// decodeStrict 解析单行 JSON 日志，出现重复的键时测试失败
+func decodeStrict(t *testing.T, line []byte) map[string]interface{} {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		t.Fatalf("invalid json line %q: %v", line, err)
+	}
+	m := make(map[string]interface{})
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("invalid json line %q: %v", line, err)
+		}
+		key := tok.(string)
+		if _, ok := m[key]; ok {
+			t.Fatalf("duplicate key %q in %s", key, line)
+		}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("invalid json line %q: %v", line, err)
+		}
+		m[key] = v
+	}
+	return m
+}
+
+func TestReservedKeyPolicy(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() {
+		baseLogger = prev
+		reservedKeyPolicy = ReservedKeyRename
+		setEventIDMode(false, false)
+	})
+	buf := &bytes.Buffer{}
+	baseLogger = zerolog.New(buf).With().Timestamp().Str(ProjectKey, "shop").Logger()
+	setEventIDMode(false, true)
+	conflicting := map[string]interface{}{
+		"time": "t", "level": "l", "message": "m", ProjectKey: "p", eventIDKey: "e", "user": "u1",
+	}
+
+	// 默认重命名，日志函数、SetField 与缓冲区条目均生效
+	Info("renamed", conflicting)
+	m := decodeStrict(t, buf.Bytes())
+	for _, k := range []string{"time", "level", "message", ProjectKey, eventIDKey} {
+		if m[reservedKeyRenamed+k] == nil {
+			t.Errorf("%s should be renamed: %v", k, m)
+		}
+	}
+	if m["level"] != "info" || m["message"] != "renamed" || m[ProjectKey] != "shop" || m["user"] != "u1" {
+		t.Errorf("reserved fields should keep their values: %v", m)
+	}
+
+	buf.Reset()
+	lb := NewLogBuffer()
+	lb.AddEntry(LogEntry{Level: zerolog.WarnLevel, Message: "buffered", Fields: conflicting})
+	lb.Flush(zerolog.InfoLevel)
+	if m = decodeStrict(t, buf.Bytes()); m["field_message"] != "m" {
+		t.Errorf("buffered entries should be renamed: %v", m)
+	}
+
+	// drop 丢弃并记录字段名
+	reservedKeyPolicy = ReservedKeyDrop
+	buf.Reset()
+	Warn("dropped", map[string]interface{}{"level": "l", "time": "t", "user": "u1"})
+	m = decodeStrict(t, buf.Bytes())
+	if !reflect.DeepEqual(m[reservedKeyDroppedKey], []interface{}{"level", "time"}) || m["field_level"] != nil || m["user"] != "u1" {
+		t.Errorf("unexpected dropped fields: %v", m)
+	}
+
+	buf.Reset()
+	SetField(map[string]interface{}{ProjectKey: "other"})
+	Info("after set field")
+	if m = decodeStrict(t, buf.Bytes()); m[ProjectKey] != "shop" {
+		t.Errorf("SetField should not override the project key: %v", m)
+	}
+
+	// allow 保持原有行为
+	reservedKeyPolicy = ReservedKeyAllow
+	buf.Reset()
+	Info("allowed", map[string]interface{}{"message": "m"})
+	if strings.Count(buf.String(), `"message"`) != 2 {
+		t.Errorf("allow should keep the duplicate key: %s", buf.String())
+	}
+}
+
+func TestKV(t *testing.T) {
+	fields := KV("user", "u1", "id", 123)
+	if !reflect.DeepEqual(fields, map[string]interface{}{"user": "u1", "id": 123}) {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if len(KV()) != 0 {
+		t.Errorf("KV() should return an empty map")
+	}
+
+	assertPanics := func(name string, want string, fn func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Errorf("%s: expected panic", name)
+				return
+			}
+			if msg := fmt.Sprint(r); !strings.Contains(msg, want) {
+				t.Errorf("%s: unexpected panic message %q", name, msg)
+			}
+		}()
+		fn()
+	}
+	assertPanics("odd", "odd number of arguments (3)", func() { KV("a", 1, "b") })
+	assertPanics("non-string key", "position 2 must be a string, got int", func() { KV("a", 1, 2, 3) })
+}
@@ -0,0 +1,68 @@
+// @Author Clover
+// @Data 2026/10/16 上午11:35:00
+// @Desc 日志文件起始时间
+
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// logFileStartTime 获取日志文件的起始时间
+// 并非所有文件系统都记录文件创建时间，因此已有内容的文件以第一条日志的时间为准，
+// 空文件视为刚创建，以当前时间为准；开启 Config.FileHeader 时跳过首行元数据
+func logFileStartTime(f *os.File) time.Time {
+	fi, err := f.Stat()
+	if err != nil || fi.Size() == 0 {
+		return now()
+	}
+	rf, err := openLogReader(f.Name())
+	if err != nil {
+		return fi.ModTime()
+	}
+	defer rf.Close()
+
+	scanner := bufio.NewScanner(rf)
+	var created time.Time
+	for scanner.Scan() {
+		// 首行元数据没有时间字段，跳过它读取第一条日志，文件只有首行时以首行的 created_at 为准
+		if h, ok := parseFileHeader(scanner.Bytes()); ok {
+			created = h.CreatedAt
+			continue
+		}
+		if t, ok := parseLineTime(scanner.Bytes()); ok {
+			return t
+		}
+		break
+	}
+	if !created.IsZero() {
+		return created
+	}
+	return fi.ModTime()
+}
+
+// parseLineTime 解析一行日志的时间戳，支持 json 与 console 两种文件格式
+func parseLineTime(line []byte) (time.Time, bool) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(line, &m); err == nil {
+		ts, ok := m[zerolog.TimestampFieldName].(string)
+		if !ok {
+			return time.Time{}, false
+		}
+		t, err := time.ParseInLocation(zerolog.TimeFieldFormat, ts, time.Local)
+		return t, err == nil
+	}
+
+	// console 格式以时间开头
+	layout := zerolog.TimeFieldFormat
+	if len(line) < len(layout) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(layout, string(line[:len(layout)]), time.Local)
+	return t, err == nil
+}
@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxFileAge(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2024, 7, 18, 12, 0, 0, 0, time.Local))
+
+	// 重启前已存在的日志文件，第一条日志的时间为 2 小时前
+	path := filepath.Join(t.TempDir(), "age.log")
+	first := `{"level":"info","time":"2024-07-18 10:00:00","message":"old entry"}` + "\n"
+	if err := os.WriteFile(path, []byte(first), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	InitLogger(Config{
+		LogPath:          path,
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+		MaxFileAge:       3 * time.Hour,
+	})
+	t.Cleanup(Close)
+
+	if want := clock.Now().Add(-2 * time.Hour); !logfile.StartTime().Equal(want) {
+		t.Fatalf("file start time should come from first entry, got %v want %v", logfile.StartTime(), want)
+	}
+
+	clock.Advance(30 * time.Minute)
+	logfile.check()
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "old entry") {
+		t.Fatalf("log file should not be cleared before max age")
+	}
+
+	clock.Advance(31 * time.Minute)
+	logfile.check()
+	if content, _ := os.ReadFile(path); strings.Contains(string(content), "old entry") {
+		t.Fatalf("log file should be cleared after max age")
+	}
+	if !logfile.StartTime().Equal(clock.Now()) {
+		t.Errorf("file age should reset after clearing, got %v want %v", logfile.StartTime(), clock.Now())
+	}
+
+	// 清除后重新计时
+	clock.Advance(2 * time.Hour)
+	Info("new entry")
+	if err := Barrier(context.Background()); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	logfile.check()
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "new entry") {
+		t.Errorf("log file should not be cleared before the reset age elapses")
+	}
+}
+
+func TestMaxFileAgeWithoutMonitorInterval(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2024, 7, 18, 12, 0, 0, 0, time.Local))
+
+	path := filepath.Join(t.TempDir(), "age.log")
+	InitLogger(Config{
+		LogPath:          path,
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+		MaxFileAge:       time.Hour,
+	})
+	t.Cleanup(Close)
+
+	Info("old entry")
+	if err := Barrier(context.Background()); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	if f, _ := ConfigSnapshot()["MonitorInterval"].(map[string]interface{}); f["value"] != defaultMonitorInterval.String() {
+		t.Errorf("snapshot MonitorInterval = %v, want %v", f, defaultMonitorInterval)
+	}
+
+	// 未配置 MonitorInterval 时按默认间隔检查使用时间
+	clock.step(t, time.Hour)
+	clock.step(t, defaultMonitorInterval)
+	waitFor(t, "log file to be cleared by the monitor", func() bool {
+		content, _ := os.ReadFile(path)
+		return !strings.Contains(string(content), "old entry")
+	})
+}
+
+func TestMaxFileAgeWithFileHeader(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2024, 7, 18, 12, 0, 0, 0, time.Local))
+
+	// 重启前由开启 FileHeader 的进程创建的日志文件，首行元数据之后的第一条日志为 2 小时前
+	path := filepath.Join(t.TempDir(), "age.log")
+	h := newFileHeader("svc", "project")
+	h.Reason, h.CreatedAt = HeaderReasonInitial, clock.Now().Add(-2*time.Hour)
+	first := `{"level":"info","time":"2024-07-18 10:00:00","message":"old entry"}` + "\n"
+	if err := os.WriteFile(path, append(h.encode(), first...), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	InitLogger(Config{
+		LogPath:          path,
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+		FileHeader:       true,
+		MaxFileAge:       3 * time.Hour,
+	})
+	t.Cleanup(Close)
+
+	if want := clock.Now().Add(-2 * time.Hour); !logfile.StartTime().Equal(want) {
+		t.Fatalf("file start time should skip the header, got %v want %v", logfile.StartTime(), want)
+	}
+	clock.Advance(61 * time.Minute)
+	logfile.check()
+	if content, _ := os.ReadFile(path); strings.Contains(string(content), "old entry") {
+		t.Fatalf("log file should be cleared after max age across restarts")
+	}
+}
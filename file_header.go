@@ -0,0 +1,178 @@
+// @Author Clover
+// @Data 2026/10/18 上午6:10:00
+// @Desc 新日志文件首行的元数据
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileHeaderKey 首行日志中元数据的字段名，首行的形式为 {"log_header":{...}}
+const fileHeaderKey = "log_header"
+
+// FileHeaderSchemaVersion 当前首行元数据的格式版本
+const FileHeaderSchemaVersion = 1
+
+// 开始新日志文件的原因
+const (
+	HeaderReasonInitial          = "initial"           // 启动时创建
+	HeaderReasonRotation         = "rotation"          // 超过 MaxFileAge 后清除
+	HeaderReasonSizeClear        = "size-clear"        // 超过 MaxLogSize 后清除
+	HeaderReasonExternalRecreate = "external-recreate" // 被外部删除后重新创建（WatchExternalChanges）
+)
+
+// FileHeader 开启 Config.FileHeader 时每个新日志文件的首行
+type FileHeader struct {
+	SchemaVersion int       `json:"schema_version"`
+	Project       string    `json:"project"`
+	ProjectKey    string    `json:"project_key"`
+	ProcessStart  time.Time `json:"process_start"`
+	CreatedAt     time.Time `json:"created_at"`
+	Version       string    `json:"version,omitempty"` // 主模块版本，来自 debug.ReadBuildInfo
+	Commit        string    `json:"commit,omitempty"`  // vcs.revision，来自 debug.ReadBuildInfo
+	Hostname      string    `json:"hostname"`
+	Reason        string    `json:"reason"`
+}
+
+// newFileHeader 返回首行元数据的模板，Reason 与 CreatedAt 在写入时设置
+func newFileHeader(project, projectKey string) *FileHeader {
+	h := &FileHeader{
+		SchemaVersion: FileHeaderSchemaVersion,
+		Project:       project,
+		ProjectKey:    projectKey,
+		ProcessStart:  processStart,
+	}
+	h.Hostname, _ = hostname()
+	if info, ok := readBuildInfo(); ok {
+		h.Version = info.Main.Version
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				h.Commit = s.Value
+			}
+		}
+	}
+	return h
+}
+
+// line 返回以 reason 开始新文件的首行
+func (h *FileHeader) line(reason string) []byte {
+	c := *h
+	c.Reason = reason
+	c.CreatedAt = now()
+	return c.encode()
+}
+
+// encode 返回包含 h 的首行
+func (h *FileHeader) encode() []byte {
+	b, _ := json.Marshal(map[string]*FileHeader{fileHeaderKey: h})
+	return append(b, '\n')
+}
+
+// isFileHeader line 是否为首行元数据
+func isFileHeader(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(line), []byte(`{"`+fileHeaderKey+`":`))
+}
+
+// parseFileHeader 解析首行元数据
+func parseFileHeader(line []byte) (*FileHeader, bool) {
+	if !isFileHeader(line) {
+		return nil, false
+	}
+	var m map[string]*FileHeader
+	if err := json.Unmarshal(bytes.TrimSpace(line), &m); err != nil || m[fileHeaderKey] == nil {
+		return nil, false
+	}
+	return m[fileHeaderKey], true
+}
+
+// writeHeaderFile 在 path 所在目录中写入只包含首行的临时文件，返回临时文件路径
+func writeHeaderFile(path string, line []byte) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".header-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(line)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// installFileHeader path 不存在时以只包含首行的文件创建 path，返回是否创建。
+// 首行先完整写入临时文件，再通过硬链接原子地放到 path，进程崩溃时不会留下首行不完整的日志文件；
+// path 已存在时不修改
+func installFileHeader(path string, line []byte) (bool, error) {
+	tmp, err := writeHeaderFile(path, line)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp)
+	if err := os.Link(tmp, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// replaceWithHeader 以只包含首行的新文件原子地替换日志文件并切换到新文件，调用方需持有 lf.mu
+func (lf *logFile) replaceWithHeader(reason string) error {
+	line := lf.header.line(reason)
+	tmp, err := writeHeaderFile(lf.path, line)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, lf.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	f, err := os.OpenFile(lf.path, os.O_RDWR|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	lf.file.Close()
+	lf.file = f
+	lf.size = int64(len(line))
+	return nil
+}
+
+// ReadEntriesWithHeader 与 ReadEntries 相同，同时返回文件首行的元数据，没有首行元数据时为 nil
+func ReadEntriesWithHeader(path string) (*FileHeader, []LogEntry, error) {
+	header, err := readFileHeader(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := ReadEntries(path)
+	return header, entries, err
+}
+
+// readFileHeader 读取日志文件首行的元数据，没有时返回 nil
+func readFileHeader(path string) (*FileHeader, error) {
+	r, err := openLogReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	h, _ := parseFileHeader(line)
+	return h, nil
+}
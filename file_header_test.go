@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// firstLineHeader 返回日志文件首行的元数据
+func firstLineHeader(t *testing.T, path string) *FileHeader {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	h, ok := parseFileHeader(line)
+	if !ok {
+		t.Fatalf("first line is not a file header: %q", line)
+	}
+	return h
+}
+
+func TestFileHeaderInitial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	config := Config{ProjectName: "svc", LogPath: path, EnableFileOutput: true, FileHeader: true, AllowReinit: true}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	Info("first")
+
+	header, entries, err := ReadEntriesWithHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header == nil || header.Reason != HeaderReasonInitial || header.SchemaVersion != FileHeaderSchemaVersion ||
+		header.Project != "svc" || header.ProjectKey != ProjectKey || header.Hostname == "" ||
+		!header.ProcessStart.Equal(processStart) || header.CreatedAt.IsZero() {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+	// 首行元数据不作为普通日志返回
+	if len(entries) != 1 || entries[0].Message != "first" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+
+	// 追加写入已有的文件时不再写入首行
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	Info("second")
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), fileHeaderKey); n != 1 {
+		t.Errorf("expected a single header, got %d: %s", n, data)
+	}
+}
+
+func TestFileHeaderNewFileReasons(t *testing.T) {
+	captureOutput(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	lf, err := openMainLogFile(path, Config{}, newFileHeader("svc", ProjectKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Close()
+
+	expectReason := func(reason string) {
+		t.Helper()
+		if h := firstLineHeader(t, path); h.Reason != reason {
+			t.Errorf("expected reason %s, got %s", reason, h.Reason)
+		}
+		// 新文件的大小从首行开始计算，之后的写入紧随首行
+		lf.Write([]byte(timedLine(1)))
+		data, _ := os.ReadFile(path)
+		if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || lines[1] != strings.TrimSpace(timedLine(1)) {
+			t.Errorf("unexpected content after %s: %q", reason, data)
+		}
+		if fi, _ := lf.Stat(); fi.Size() != lf.size {
+			t.Errorf("tracked size %d differs from file size %d", lf.size, fi.Size())
+		}
+	}
+	expectReason(HeaderReasonInitial)
+
+	lf.maxSize = 1
+	lf.check()
+	expectReason(HeaderReasonSizeClear)
+
+	lf.maxSize = 0
+	lf.maxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	lf.check()
+	expectReason(HeaderReasonRotation)
+
+	lf.maxAge = 0
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	lf.syncExternal()
+	expectReason(HeaderReasonExternalRecreate)
+
+	if lf.ClearCount() != 2 {
+		t.Errorf("expected 2 clears, got %d", lf.ClearCount())
+	}
+	// 临时文件不会残留在日志目录中
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".app.log.header-*")); len(matches) != 0 {
+		t.Errorf("temporary header files left behind: %v", matches)
+	}
+}
+
+func TestFileHeaderExclusiveCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	config := Config{LogPath: path, EnableFileOutput: true, ExclusiveCreate: true, FileHeader: true}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	Info("first")
+	Close()
+
+	err := InitLogger(config)
+	t.Cleanup(Close)
+	if !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected os.ErrExist, got %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), fileHeaderKey) != 1 || !strings.Contains(string(data), "first") {
+		t.Errorf("existing log file should be left untouched: %q", data)
+	}
+}
+
+func TestFileHeaderExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	config := Config{LogPath: path, EnableFileOutput: true, FileHeader: true, IndexInterval: 64}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	for i := 0; i < 20; i++ {
+		Info("entry")
+	}
+
+	var buf bytes.Buffer
+	// 时间范围经索引定位时同样保留首行
+	if err := ExportArchive(&buf, ExportOptions{Since: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest exportManifest
+	var lines []string
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		switch f.Name {
+		case exportManifestName:
+			json.Unmarshal(data, &manifest)
+		case "logs/app.log":
+			sc := bufio.NewScanner(bytes.NewReader(data))
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+		}
+	}
+	if len(lines) != 21 || !isFileHeader([]byte(lines[0])) {
+		t.Fatalf("exported file should start with the header: %d lines, %q", len(lines), lines)
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0].Header == nil || manifest.Files[0].Header.Reason != HeaderReasonInitial ||
+		manifest.Files[0].Entries != 20 || manifest.Files[0].Malformed != 0 {
+		t.Errorf("unexpected manifest: %+v", manifest.Files)
+	}
+}
@@ -0,0 +1,52 @@
+// @Author Clover
+// @Data 2026/10/18 上午8:50:00
+// @Desc 轮转归档文件名
+
+package logging
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampedFileName 的预定义格式
+const (
+	TimestampDate     = "date"     // 2006-01-02
+	TimestampDateTime = "datetime" // 2006-01-02T15:04:05，format 为空时使用
+	TimestampUnix     = "unix"     // Unix 秒数
+)
+
+// TimestampedFileName 在 basePath 的扩展名之前插入 t 的时间戳，用于生成轮转后的归档文件名，
+// 例如 app.log 与 datetime 得到 app-2024-07-18T15:04:05.log。
+// format 为 date、datetime、unix 或 time.Format 的布局；没有扩展名时追加在末尾，
+// 以点开头的隐藏文件（.app.log）保留开头的点，压缩后缀（app.log.zst、app.log.gz）与扩展名一起保留在时间戳之后；
+// 时间戳中的路径分隔符替换为 -
+func TimestampedFileName(basePath string, t time.Time, format string) string {
+	var stamp string
+	switch format {
+	case TimestampDate:
+		stamp = t.Format("2006-01-02")
+	case "", TimestampDateTime:
+		stamp = t.Format("2006-01-02T15:04:05")
+	case TimestampUnix:
+		stamp = strconv.FormatInt(t.Unix(), 10)
+	default:
+		stamp = t.Format(format)
+	}
+	stamp = strings.NewReplacer("/", "-", string(filepath.Separator), "-").Replace(stamp)
+
+	dir, name := filepath.Split(basePath)
+	hidden := strings.HasPrefix(name, ".")
+	stem := strings.TrimPrefix(name, ".")
+	ext := filepath.Ext(stem)
+	if ext == compressedExt || ext == ".gz" {
+		ext = filepath.Ext(strings.TrimSuffix(stem, ext)) + ext
+	}
+	stem = strings.TrimSuffix(stem, ext)
+	if hidden {
+		stem = "." + stem
+	}
+	return dir + stem + "-" + stamp + ext
+}
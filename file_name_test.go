@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimestampedFileName(t *testing.T) {
+	at := time.Date(2024, 7, 18, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		path, format, want string
+	}{
+		{"app.log", TimestampDateTime, "app-2024-07-18T15:04:05.log"},
+		{"app.log", "", "app-2024-07-18T15:04:05.log"},
+		{"app.log", TimestampDate, "app-2024-07-18.log"},
+		{"app.log", TimestampUnix, "app-1721315045.log"},
+		{"app.log", "20060102-150405", "app-20240718-150405.log"},
+		{"app.log", "2006/01/02", "app-2024-07-18.log"},
+		{"app", TimestampDate, "app-2024-07-18"},
+		{".app.log", TimestampDate, ".app-2024-07-18.log"},
+		{".app", TimestampDate, ".app-2024-07-18"},
+		{"app.log.zst", TimestampDate, "app-2024-07-18.log.zst"},
+		{"app.tar.gz", TimestampDate, "app-2024-07-18.tar.gz"},
+		{"my.app.log", TimestampDate, "my.app-2024-07-18.log"},
+		{filepath.Join("var", "log", "app.log"), TimestampDate, filepath.Join("var", "log", "app-2024-07-18.log")},
+		{filepath.Join("v1.2", "app"), TimestampDate, filepath.Join("v1.2", "app-2024-07-18")},
+	}
+	for _, tt := range tests {
+		if got := TimestampedFileName(tt.path, at, tt.format); got != tt.want {
+			t.Errorf("TimestampedFileName(%q, %q) = %q, want %q", tt.path, tt.format, got, tt.want)
+		}
+	}
+}
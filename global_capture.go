@@ -0,0 +1,57 @@
+// @Author Clover
+// @Data 2026/10/18 上午12:30:00
+// @Desc 将直接使用 zerolog/log 的第三方库日志转发到本包的输出目标
+
+package logging
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// globalViaField 转发自 log.Logger 的日志附带的字段
+const globalViaField = "via"
+
+var (
+	globalCaptured bool           // 是否已替换 log.Logger，由 stateMu 保护
+	prevGlobal     zerolog.Logger // 替换前的 log.Logger，Close 时恢复
+)
+
+// captureGlobalZerolog 将 log.Logger 指向转发适配器，调用方需持有 stateMu
+func captureGlobalZerolog() {
+	if !globalCaptured {
+		prevGlobal = log.Logger
+		globalCaptured = true
+	}
+	log.Logger = zerolog.New(globalForwarder{})
+}
+
+// releaseGlobalZerolog 恢复被替换的 log.Logger，调用方需持有 stateMu
+func releaseGlobalZerolog() {
+	if !globalCaptured {
+		return
+	}
+	log.Logger = prevGlobal
+	prevGlobal = zerolog.Logger{}
+	globalCaptured = false
+}
+
+// globalForwarder 解析 log.Logger 输出的 JSON 日志，经过字段规则、过滤与脱敏后写入本包的输出目标
+type globalForwarder struct{}
+
+func (globalForwarder) Write(p []byte) (int, error) {
+	return globalForwarder{}.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (globalForwarder) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	entry, err := parseLogEntry(p)
+	if err != nil {
+		return 0, err
+	}
+	if level != zerolog.NoLevel {
+		entry.Level = level
+	}
+	fields := []map[string]interface{}{entry.Fields, {globalViaField: "global"}}
+	emit(currentLogger().WithLevel(entry.Level), entry.Level, nil, entry.Message, fields)
+	return len(p), nil
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// useGlobalLogger 将 zerolog 的全局 log.Logger 指向缓冲区，测试结束后恢复
+func useGlobalLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	global := &bytes.Buffer{}
+	prev := log.Logger
+	log.Logger = zerolog.New(global)
+	t.Cleanup(func() { log.Logger = prev })
+	return global
+}
+
+// replaceMasker 将 old 替换为 new
+type replaceMasker struct{ old, new string }
+
+func (m replaceMasker) Mask(s string) string { return strings.ReplaceAll(s, m.old, m.new) }
+
+func TestGlobalZerologIsolation(t *testing.T) {
+	global := useGlobalLogger(t)
+	prev := baseLogger
+	out := &syncBuffer{}
+	if err := InitLogger(Config{ProjectKey: "project", ProjectName: "app"}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; baseLogger = prev })
+
+	Info("ours")
+	log.Info().Msg("theirs")
+
+	if !strings.Contains(out.String(), "ours") || strings.Contains(out.String(), "theirs") {
+		t.Errorf("package output should only contain its own logs: %q", out.String())
+	}
+	if !strings.Contains(global.String(), "theirs") || strings.Contains(global.String(), "ours") {
+		t.Errorf("global logger should be left untouched: %q", global.String())
+	}
+}
+
+func TestCaptureGlobalZerolog(t *testing.T) {
+	global := useGlobalLogger(t)
+	prev := baseLogger
+	out := &syncBuffer{}
+	config := Config{ProjectKey: "project", ProjectName: "app", CaptureGlobalZerolog: true}
+	if err := InitLogger(config, WithWriters(out), WithMasker(replaceMasker{"secret-abc", "[REDACTED]"})); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; baseLogger = prev })
+	SetField(map[string]interface{}{"service": "api"})
+
+	log.Warn().Str("user", "u1").Int("attempt", 3).Msg("login with secret-abc")
+
+	m := decodeLine(t, []byte(out.String()))
+	if m["message"] != "login with [REDACTED]" || m["level"] != "warn" || m["via"] != "global" {
+		t.Errorf("forwarded entry should pass through maskers and be tagged: %v", m)
+	}
+	if m["project"] != "app" || m["service"] != "api" || m["user"] != "u1" || m["attempt"] != float64(3) {
+		t.Errorf("forwarded entry should keep its fields and the package fields: %v", m)
+	}
+	if global.Len() != 0 {
+		t.Errorf("previous global logger should not receive captured logs: %q", global.String())
+	}
+
+	Close()
+	log.Info().Msg("after close")
+	if !strings.Contains(global.String(), "after close") {
+		t.Errorf("Close should restore the previous global logger: %q", global.String())
+	}
+}
@@ -0,0 +1,65 @@
+// @Author Clover
+// @Data 2026/10/17 下午8:40:00
+// @Desc 报告日志记录器状态的健康检查接口
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+var startedAt time.Time // 最近一次 InitLogger 的时间，Close 后清零，由 stateMu 保护
+
+// HealthStatus 健康检查接口返回的日志记录器状态
+type HealthStatus struct {
+	FileOpen         bool    `json:"file_open"`
+	LogPath          string  `json:"log_path"`
+	CurrentSizeBytes int64   `json:"current_size_bytes"`
+	RotationCount    int64   `json:"rotation_count"` // 日志文件因超过大小或使用时间被清除的次数
+	ErrorCount       int64   `json:"error_count"`
+	WarnCount        int64   `json:"warn_count"`
+	UptimeSeconds    float64 `json:"uptime_seconds"` // 距离最近一次 InitLogger 的时间，未初始化时为 0
+}
+
+// Health 返回当前日志记录器的状态，可以并发调用
+func Health() HealthStatus {
+	stateMu.RLock()
+	lf, path, started, fileOn := logfile, logPath, startedAt, fileOutput
+	stateMu.RUnlock()
+
+	status := HealthStatus{
+		LogPath:    path,
+		ErrorCount: ErrorCount(),
+		WarnCount:  WarnCount(),
+	}
+	if lf != nil && fileOn {
+		if fi, err := lf.Stat(); err == nil {
+			status.FileOpen = true
+			status.CurrentSizeBytes = fi.Size()
+		}
+		status.RotationCount = lf.ClearCount()
+	}
+	if !started.IsZero() {
+		status.UptimeSeconds = now().Sub(started).Seconds()
+	}
+	return status
+}
+
+// NewHealthHandler 返回以 JSON 报告 Health 的只读 http.Handler
+func NewHealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Health())
+	})
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	path := filepath.Join(t.TempDir(), "health.log")
+	InitLogger(Config{LogPath: path, ProjectKey: "project", EnableFileOutput: true})
+	t.Cleanup(Close)
+	ResetCounts()
+	t.Cleanup(ResetCounts)
+
+	Warn("disk almost full")
+	Error("request failed")
+	Error("request failed again")
+	clock.skip(90 * time.Second)
+
+	get := func() (*httptest.ResponseRecorder, map[string]interface{}) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		NewHealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid body %q: %v", rec.Body.String(), err)
+		}
+		return rec, body
+	}
+	rec, body := get()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if body["file_open"] != true || body["log_path"] != path {
+		t.Errorf("unexpected file status: %v", body)
+	}
+	if size, _ := body["current_size_bytes"].(float64); size <= 0 {
+		t.Errorf("current_size_bytes should reflect written logs: %v", body)
+	}
+	if body["rotation_count"] != float64(0) || body["error_count"] != float64(2) || body["warn_count"] != float64(1) {
+		t.Errorf("unexpected counters: %v", body)
+	}
+	if body["uptime_seconds"] != float64(90) {
+		t.Errorf("unexpected uptime: %v", body["uptime_seconds"])
+	}
+
+	stateMu.RLock()
+	lf := logfile
+	stateMu.RUnlock()
+	lf.clear(HeaderReasonSizeClear)
+	if _, body = get(); body["rotation_count"] != float64(1) {
+		t.Errorf("rotation should be counted: %v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	NewHealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/health", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("handler should be read-only, got %d", rec.Code)
+	}
+
+	Close()
+	if _, body = get(); body["file_open"] != false || body["uptime_seconds"] != float64(0) {
+		t.Errorf("closed logger should report no open file: %v", body)
+	}
+}
@@ -0,0 +1,63 @@
+// @Author Clover
+// @Data 2026/10/16 上午11:10:00
+// @Desc 指定级别的日志回调
+
+package logging
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	levelHooksMu sync.RWMutex
+	levelHooks   = make(map[zerolog.Level][]func(LogEntry)) // 各级别的回调，按注册顺序执行
+)
+
+// OnError 注册 Error 级别日志的回调
+func OnError(fn func(LogEntry)) {
+	OnLevel(zerolog.ErrorLevel, fn)
+}
+
+// OnWarn 注册 Warn 级别日志的回调
+func OnWarn(fn func(LogEntry)) {
+	OnLevel(zerolog.WarnLevel, fn)
+}
+
+// OnFatal 注册 Fatal 级别日志的回调，回调在进程退出前执行
+func OnFatal(fn func(LogEntry)) {
+	OnLevel(zerolog.FatalLevel, fn)
+}
+
+// OnLevel 注册指定级别日志的回调，同一级别可注册多个回调
+func OnLevel(level zerolog.Level, fn func(LogEntry)) {
+	if fn == nil {
+		return
+	}
+	levelHooksMu.Lock()
+	defer levelHooksMu.Unlock()
+	levelHooks[level] = append(levelHooks[level], fn)
+}
+
+// ResetLevelHooks 清除所有已注册的级别回调
+func ResetLevelHooks() {
+	levelHooksMu.Lock()
+	defer levelHooksMu.Unlock()
+	levelHooks = make(map[zerolog.Level][]func(LogEntry))
+}
+
+func hasLevelHooks(level zerolog.Level) bool {
+	levelHooksMu.RLock()
+	defer levelHooksMu.RUnlock()
+	return len(levelHooks[level]) > 0
+}
+
+func runLevelHooks(entry LogEntry) {
+	levelHooksMu.RLock()
+	hooks := levelHooks[entry.Level]
+	levelHooksMu.RUnlock()
+	for _, fn := range hooks {
+		fn(entry)
+	}
+}
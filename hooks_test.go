@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLevelHooks(t *testing.T) {
+	captureOutput(t)
+	t.Cleanup(ResetLevelHooks)
+
+	var calls []string
+	OnError(func(entry LogEntry) { calls = append(calls, "first:"+entry.Message) })
+	OnError(func(entry LogEntry) { calls = append(calls, "second:"+entry.Message) })
+	var warned LogEntry
+	OnWarn(func(entry LogEntry) { warned = entry })
+
+	Info("ignored")
+	ErrorWithErr(errors.New("boom"), "failed", map[string]interface{}{"id": 1})
+	Warn("careful", map[string]interface{}{"disk": "90%"})
+
+	if len(calls) != 2 || calls[0] != "first:failed" || calls[1] != "second:failed" {
+		t.Errorf("error hooks should run in registration order, got %v", calls)
+	}
+	if warned.Level != zerolog.WarnLevel || warned.Fields["disk"] != "90%" {
+		t.Errorf("unexpected warn entry: %+v", warned)
+	}
+
+	// 缓冲区输出的条目同样触发回调
+	buf := NewLogBuffer()
+	buf.AddEntry(LogEntry{Level: zerolog.ErrorLevel, Message: "buffered"})
+	buf.Flush(zerolog.InfoLevel)
+	if len(calls) != 4 || calls[3] != "second:buffered" {
+		t.Errorf("buffered entries should trigger hooks, got %v", calls)
+	}
+}
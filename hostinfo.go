@@ -0,0 +1,62 @@
+// @Author Clover
+// @Data 2026/10/18 上午5:50:00
+// @Desc 为日志添加主机名与 IP 地址
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// Config.InjectHostname 与 Config.InjectIPAddress 添加的字段
+const (
+	hostnameKey  = "hostname"
+	ipAddressKey = "ip_address"
+	unknownHost  = "unknown" // 无法获取时的字段值
+)
+
+// injectedKeys baseLogger 上下文中由 InjectHostname、InjectBuildInfo 等配置添加的字段名，与 baseLogger 一起重建，由 stateMu 保护；
+// 这些字段名视为保留字段，同名的用户字段按 ReservedKeyPolicy 处理
+var injectedKeys []string
+
+// 获取主机名与网卡地址的函数，测试中可以替换
+var (
+	lookupHostname = os.Hostname
+	interfaceAddrs = net.InterfaceAddrs
+)
+
+// hostname 返回主机名，获取失败时返回 unknown 与错误
+func hostname() (string, error) {
+	name, err := lookupHostname()
+	if err != nil {
+		return unknownHost, fmt.Errorf("resolve hostname: %w", err)
+	}
+	if name == "" {
+		return unknownHost, errors.New("resolve hostname: empty hostname")
+	}
+	return name, nil
+}
+
+// ipAddress 返回第一个非回环的 IPv4 地址，没有时返回 unknown 与错误
+func ipAddress() (string, error) {
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return unknownHost, fmt.Errorf("resolve ip address: %w", err)
+	}
+	for _, addr := range addrs {
+		var ip net.IP
+		switch a := addr.(type) {
+		case *net.IPNet:
+			ip = a.IP
+		case *net.IPAddr:
+			ip = a.IP
+		}
+		if ip4 := ip.To4(); ip4 != nil && !ip4.IsLoopback() {
+			return ip4.String(), nil
+		}
+	}
+	return unknownHost, errors.New("resolve ip address: no non-loopback IPv4 address")
+}
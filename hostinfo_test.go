@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestInjectHostInfo(t *testing.T) {
+	prevHost, prevAddrs := lookupHostname, interfaceAddrs
+	t.Cleanup(func() { lookupHostname, interfaceAddrs = prevHost, prevAddrs })
+	lookupHostname = func() (string, error) { return "web-1", nil }
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+			&net.IPNet{IP: net.ParseIP("10.0.3.7"), Mask: net.CIDRMask(24, 32)},
+		}, nil
+	}
+	out := &syncBuffer{}
+	config := Config{ProjectKey: "project", InjectHostname: true, InjectIPAddress: true}
+	if err := InitLogger(config, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+
+	Info("started")
+	m := decodeLine(t, []byte(out.String()))
+	if m["hostname"] != "web-1" || m["ip_address"] != "10.0.3.7" {
+		t.Errorf("unexpected host fields: %v", m)
+	}
+
+	// 同名的用户字段按 ReservedKeyPolicy 重命名，LogStartup 不再重复添加 hostname
+	before := len(out.String())
+	Info("user fields", map[string]interface{}{"hostname": "mine", "ip_address": "1.2.3.4"})
+	LogStartup(BuildInfo{Version: "1.0.0"}, nil)
+	lines := strings.Split(strings.TrimSpace(out.String()[before:]), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", lines)
+	}
+	m = decodeStrict(t, []byte(lines[0]))
+	if m["hostname"] != "web-1" || m["field_hostname"] != "mine" || m["ip_address"] != "10.0.3.7" || m["field_ip_address"] != "1.2.3.4" {
+		t.Errorf("colliding host fields should be renamed: %v", m)
+	}
+	if m = decodeStrict(t, []byte(lines[1])); m["hostname"] != "web-1" {
+		t.Errorf("startup event should keep the injected hostname: %v", m)
+	}
+
+	// 获取失败时输出警告，字段为 unknown
+	lookupHostname = func() (string, error) { return "", errors.New("no uts namespace") }
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)}}, nil
+	}
+	out2 := &syncBuffer{}
+	config.AllowReinit = true
+	if err := InitLogger(config, WithWriters(out2)); err != nil {
+		t.Fatal(err)
+	}
+	Info("started")
+	lines = strings.Split(strings.TrimSpace(out2.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "no uts namespace") || !strings.Contains(lines[1], "non-loopback") {
+		t.Fatalf("expected two warnings before the entry: %q", lines)
+	}
+	m = decodeLine(t, []byte(lines[2]))
+	if m["hostname"] != "unknown" || m["ip_address"] != "unknown" {
+		t.Errorf("unresolved host fields should be unknown: %v", m)
+	}
+}
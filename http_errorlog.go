@@ -0,0 +1,66 @@
+// @Author Clover
+// @Data 2026/10/16 下午3:40:00
+// @Desc 将 net/http 服务端错误日志转换为结构化日志
+
+package logging
+
+import (
+	stdlog "log"
+	"regexp"
+	"strings"
+)
+
+// httpErrorPattern 描述 net/http 输出的一类错误日志
+type httpErrorPattern struct {
+	errorType string
+	re        *regexp.Regexp // 第一个捕获组（若存在）为客户端地址
+}
+
+var httpErrorPatterns = []httpErrorPattern{
+	{"tls_handshake", regexp.MustCompile(`^http: TLS handshake error from (\S+): `)},
+	{"panic", regexp.MustCompile(`^http: panic serving (\S+): `)},
+	{"accept", regexp.MustCompile(`^http: Accept error: `)},
+	{"superfluous_write_header", regexp.MustCompile(`^http: superfluous response\.WriteHeader call`)},
+	{"hijacked_write_header", regexp.MustCompile(`^http: response\.WriteHeader on hijacked connection`)},
+	{"hijacked_write", regexp.MustCompile(`^http: response\.Write on hijacked connection`)},
+	{"url_semicolon", regexp.MustCompile(`^http: URL query contains semicolon`)},
+	{"http2", regexp.MustCompile(`^http2: `)},
+}
+
+// httpErrorWriter 解析 net/http 写入 ErrorLog 的每一行并输出 Error 日志
+type httpErrorWriter struct{}
+
+func (httpErrorWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	fields := parseHTTPError(msg)
+	// panic 日志附带调用栈，拆分到单独的字段中
+	if idx := strings.IndexByte(msg, '\n'); idx >= 0 {
+		fields["stack"] = msg[idx+1:]
+		msg = msg[:idx]
+	}
+	Error(msg, fields)
+	return len(p), nil
+}
+
+// parseHTTPError 根据 net/http 使用的前缀识别错误类型
+func parseHTTPError(msg string) map[string]interface{} {
+	fields := map[string]interface{}{"http_error_type": "unknown"}
+	for _, pattern := range httpErrorPatterns {
+		match := pattern.re.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		fields["http_error_type"] = pattern.errorType
+		if len(match) > 1 {
+			fields["remote_addr"] = match[1]
+		}
+		break
+	}
+	return fields
+}
+
+// NewHTTPServerErrorLog 返回可用于 http.Server.ErrorLog 的 *log.Logger，
+// 每行错误都以 Error 级别输出，并携带从消息中识别出的 http_error_type 字段
+func NewHTTPServerErrorLog() *stdlog.Logger {
+	return stdlog.New(httpErrorWriter{}, "", 0)
+}
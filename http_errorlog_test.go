@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHTTPServerErrorLog(t *testing.T) {
+	buf := captureOutput(t)
+	errorLog := NewHTTPServerErrorLog()
+
+	tests := []struct {
+		line       string
+		errorType  string
+		remoteAddr string
+	}{
+		{"http: TLS handshake error from 10.0.0.1:52341: EOF", "tls_handshake", "10.0.0.1:52341"},
+		{"http: panic serving 10.0.0.2:4000: boom\ngoroutine 1 [running]:", "panic", "10.0.0.2:4000"},
+		{"http: Accept error: too many open files; retrying in 5ms", "accept", ""},
+		{"http: superfluous response.WriteHeader call from main.handler (main.go:12)", "superfluous_write_header", ""},
+		{"something else", "unknown", ""},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		errorLog.Print(tt.line)
+		m := decodeLine(t, bytes.TrimSpace(buf.Bytes()))
+		if m["level"] != "error" || m["http_error_type"] != tt.errorType {
+			t.Errorf("%q: unexpected fields %v", tt.line, m)
+		}
+		if tt.remoteAddr != "" && m["remote_addr"] != tt.remoteAddr {
+			t.Errorf("%q: unexpected remote_addr %v", tt.line, m["remote_addr"])
+		}
+	}
+
+	buf.Reset()
+	errorLog.Print("http: panic serving 10.0.0.2:4000: boom\ngoroutine 1 [running]:")
+	m := decodeLine(t, bytes.TrimSpace(buf.Bytes()))
+	if m["message"] != "http: panic serving 10.0.0.2:4000: boom" || m["stack"] != "goroutine 1 [running]:" {
+		t.Errorf("panic stack should be split from message: %v", m)
+	}
+}
@@ -0,0 +1,79 @@
+// @Author Clover
+// @Data 2026/10/17 下午5:00:00
+// @Desc 长时间没有日志输出时发出警告
+
+package logging
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// inactivityMessage 长时间没有日志输出时的警告消息
+const inactivityMessage = "logger inactivity detected"
+
+var (
+	lastEventAt atomic.Int64       // 最近一条日志的时间（UnixNano），由 activityHook 更新
+	inactivity  *inactivityMonitor // Config.InactivityWarning 启动的监控，由 stateMu 保护
+)
+
+// activityHook 记录最近一条日志的时间
+type activityHook struct{}
+
+func (activityHook) Run(_ *zerolog.Event, _ zerolog.Level, _ string) {
+	lastEventAt.Store(now().UnixNano())
+}
+
+// inactivityMonitor 在超过 timeout 没有日志输出时输出一条 Warn 日志
+type inactivityMonitor struct {
+	timeout time.Duration
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// startInactivityMonitor 启动监控协程，timeout 不大于 0 时返回 nil
+func startInactivityMonitor(timeout time.Duration) *inactivityMonitor {
+	if timeout <= 0 {
+		return nil
+	}
+	m := &inactivityMonitor{
+		timeout: timeout,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	lastEventAt.Store(now().UnixNano())
+	go m.run()
+	return m
+}
+
+// run 定时器到期时检查最近一条日志的时间，期间有日志输出则按剩余时间重新计时
+func (m *inactivityMonitor) run() {
+	defer close(m.stopped)
+	wait := m.timeout
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-currentClock().After(wait):
+		}
+		idle := now().Sub(time.Unix(0, lastEventAt.Load()))
+		if idle < m.timeout {
+			wait = m.timeout - idle
+			continue
+		}
+		// 警告本身也会更新最近一条日志的时间，持续没有日志时每隔 timeout 警告一次
+		currentLogger().Warn().Dur("idle_for", idle).Msg(inactivityMessage)
+		wait = m.timeout
+	}
+}
+
+// stop 停止监控协程并等待其退出
+func (m *inactivityMonitor) stop() {
+	if m == nil {
+		return
+	}
+	close(m.done)
+	<-m.stopped
+}
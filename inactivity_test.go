@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInactivityWarning(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	out := &syncBuffer{}
+	if err := InitLogger(Config{ProjectKey: "project", InactivityWarning: time.Minute}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	clock.waitForWaiters(t, 1)
+
+	// 期间有日志输出时按剩余时间重新计时，不警告
+	clock.Advance(40 * time.Second)
+	Info("working")
+	clock.Advance(20 * time.Second)
+	clock.waitForWaiters(t, 1)
+	if strings.Contains(out.String(), inactivityMessage) {
+		t.Fatalf("unexpected inactivity warning while active: %s", out.String())
+	}
+
+	clock.Advance(40 * time.Second)
+	waitFor(t, "inactivity warning", func() bool { return strings.Contains(out.String(), inactivityMessage) })
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if m := decodeLine(t, []byte(lines[len(lines)-1])); m["level"] != "warn" || m["idle_for"] != float64(60000) {
+		t.Errorf("unexpected warning: %v", m)
+	}
+
+	// Close 后停止监控
+	clock.waitForWaiters(t, 1)
+	Close()
+	n := strings.Count(out.String(), inactivityMessage)
+	clock.Advance(time.Hour)
+	if strings.Count(out.String(), inactivityMessage) != n {
+		t.Errorf("monitor should stop after Close")
+	}
+}
@@ -0,0 +1,257 @@
+// @Author Clover
+// @Data 2026/10/18 上午10:10:00
+// @Desc 独立配置的日志记录器实例，例如与应用日志分开的审计日志
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// ErrNoInstanceOutput NewInstance 的配置中没有任何输出目标时返回
+	ErrNoInstanceOutput = errors.New("logger instance has no output, enable file or console output or pass WithWriters")
+	// ErrUnsupportedInstanceConfig NewInstance 的配置或选项中设置了实例不支持的字段时返回，错误信息列出这些字段
+	ErrUnsupportedInstanceConfig = errors.New("unsupported logger instance config")
+)
+
+// instanceConfigFields NewInstance 支持的 Config 字段
+var instanceConfigFields = map[string]bool{
+	"LogPath": true, "EnableFileOutput": true, "MaxLogSize": true, "MaxFileAge": true, "MonitorInterval": true,
+	"EnableConsoleOutput": true, "FileFormat": true, "ProjectKey": true, "ProjectName": true, "LogLevel": true,
+	"SkipNilErrors": true,
+}
+
+// unsupportedInstanceConfig 返回 config 与选项中设置了但实例不支持的字段与选项
+func unsupportedInstanceConfig(config Config, o loggerOptions) []string {
+	var names []string
+	v := reflect.ValueOf(config)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if !instanceConfigFields[name] && !v.Field(i).IsZero() {
+			names = append(names, "Config."+name)
+		}
+	}
+	if len(o.maskers) > 0 {
+		names = append(names, "WithMasker")
+	}
+	if len(o.observers) > 0 {
+		names = append(names, "WithWriteObserver")
+	}
+	return names
+}
+
+// Instance 日志记录器实例，拥有自己的日志文件（及其大小与使用时间的监控）与 zerolog.Logger，
+// 同一进程中可以同时存在多个独立配置的实例，例如应用日志与审计日志。
+// 包级的 Info、Error 等函数委托给 Default 返回的默认实例，它使用 InitLogger 的配置
+type Instance struct {
+	mu            sync.RWMutex
+	file          *logFile       // 实例的日志文件，未开启文件输出时为 nil
+	zl            zerolog.Logger // 由 mu 保护
+	writers       []io.Writer    // 实例的输出目标，Fatal 退出前等待写完
+	skipNilErrors bool
+	std           bool // 默认实例：使用 InitLogger 创建的输出目标与字段规则
+}
+
+// std 包级函数使用的默认实例
+var std = &Instance{std: true}
+
+// Default 返回包级函数使用的默认实例，便于将接收 *Instance 的代码用于默认日志记录器
+func Default() *Instance {
+	return std
+}
+
+// NewInstance 按 config 创建独立的日志记录器实例，不影响 InitLogger 创建的默认日志记录器。
+// 支持的配置为 LogPath、EnableFileOutput、MaxLogSize、MaxFileAge、MonitorInterval、EnableConsoleOutput、FileFormat、
+// ProjectKey、ProjectName、LogLevel 与 SkipNilErrors，选项支持 WithWriters 与 WithWriteTimeout；
+// 设置了其余字段（字段白名单、Outputs 等）或 WithMasker 等选项时返回 ErrUnsupportedInstanceConfig，避免以为生效的脱敏等规则被忽略。
+// 实例的级别同样受 zerolog 全局级别（InitLogger 的 LogLevel 与 SetLogLevel）的限制，日志文件的清除记录输出到默认日志记录器
+func NewInstance(config Config, opts ...LoggerOption) (*Instance, error) {
+	var o loggerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if names := unsupportedInstanceConfig(config, o); len(names) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedInstanceConfig, strings.Join(names, ", "))
+	}
+	l := &Instance{skipNilErrors: config.SkipNilErrors}
+	level := zerolog.TraceLevel
+	if config.LogLevel != "" {
+		parsed, err := zerolog.ParseLevel(config.LogLevel)
+		if err != nil {
+			return nil, err
+		}
+		level = parsed
+	}
+
+	var writers []io.Writer
+	if config.EnableConsoleOutput {
+		writers = append(writers, newGuardedConsole(newConsoleWriter(os.Stderr, false), os.Stderr))
+	}
+	if config.EnableFileOutput {
+		f, err := openLogFile(config.LogPath, config.MaxLogSize, config.MaxFileAge)
+		if err != nil {
+			return nil, err
+		}
+		l.file = f
+		var w io.Writer = f
+		if config.FileFormat == FileFormatConsole {
+			w = zerolog.ConsoleWriter{Out: f, NoColor: true, TimeFormat: zerolog.TimeFieldFormat}
+		}
+		writers = append(writers, w)
+	}
+	writers = append(writers, o.writers...)
+	if len(writers) == 0 {
+		return nil, ErrNoInstanceOutput
+	}
+	if o.writeTimeout > 0 {
+		for i, w := range writers {
+			writers[i] = newTimeoutWriter(w, o.writeTimeout)
+		}
+	}
+
+	l.writers = writers
+	key := config.ProjectKey
+	if key == "" {
+		key = defaultProjectKey
+	}
+	l.zl = zerolog.New(zerolog.MultiLevelWriter(writers...)).Level(level).
+		With().Timestamp().Str(key, config.ProjectName).Logger()
+	if l.file != nil {
+		l.file.startMonitor(config.MonitorInterval)
+	}
+	return l, nil
+}
+
+// logger 返回实例当前日志记录器的副本
+func (l *Instance) logger() *zerolog.Logger {
+	if l.std {
+		return currentLogger()
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	zl := l.zl
+	return &zl
+}
+
+// log 默认实例经过完整的处理流程，其他实例只按类型写入字段
+func (l *Instance) log(event *zerolog.Event, level zerolog.Level, err error, msg string, fields []map[string]interface{}) {
+	if l.std {
+		emit(event, level, err, msg, fields)
+		return
+	}
+	if event == nil {
+		return
+	}
+	stateMu.RLock() // 字段的输出格式（DurationUnit 等）由 InitLogger 设置
+	event = writeFields(event, reserveErrorKeys(mergeFields(fields), err))
+	stateMu.RUnlock()
+	event.Msg(msg)
+}
+
+// skipNil 返回是否跳过 err 为 nil 的错误日志
+func (l *Instance) skipNil() bool {
+	if l.std {
+		return skipNilErrorsEnabled()
+	}
+	return l.skipNilErrors
+}
+
+// SetField 为实例之后的日志添加字段
+func (l *Instance) SetField(fields map[string]interface{}) {
+	if l.std {
+		ensureLogger()
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		baseLogger = baseLogger.With().Fields(recordContextDropped(applyFieldRules(fields))).Logger()
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.zl = l.zl.With().Fields(fields).Logger()
+}
+
+// Close 停止日志文件的监控并关闭文件，之后实例的日志被丢弃；默认实例等同于 Close
+func (l *Instance) Close() error {
+	if l.std {
+		Close()
+		return nil
+	}
+	l.mu.Lock()
+	f := l.file
+	l.file, l.writers = nil, nil
+	l.zl = zerolog.Nop()
+	l.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+	return f.Close()
+}
+
+// Fatal 同步输出 Fatal 级别日志，随后以 exitCode 退出进程，与包级的 Fatal 相同地经过 SetFatalHandler 与 exitFunc；
+// 其他实例在退出前等待自身的输出目标写完并同步日志文件
+func (l *Instance) Fatal(msg string, exitCode int, fields ...map[string]interface{}) {
+	if l.std {
+		Logger.flushSync()
+		// zerolog 的 Fatal 事件会以固定的退出码 1 直接退出，这里由 exitFunc 使用调用方指定的退出码
+		emit(currentLogger().WithLevel(zerolog.FatalLevel), zerolog.FatalLevel, nil, msg, fields)
+	} else {
+		l.log(l.logger().WithLevel(zerolog.FatalLevel), zerolog.FatalLevel, nil, msg, fields)
+		l.sync()
+	}
+	fatalExit(LogEntry{Level: zerolog.FatalLevel, Message: msg, Fields: mergeFields(fields)}, exitCode)
+}
+
+// sync 等待实例的输出目标写完已提交的日志并同步日志文件，最多等待 Logger 的 Flush 时限
+func (l *Instance) sync() {
+	l.mu.RLock()
+	writers, f := l.writers, l.file
+	l.mu.RUnlock()
+	ctx, cancel := context.WithTimeout(context.Background(), Logger.deadline())
+	defer cancel()
+	for _, w := range writers {
+		_ = barrierWriter(ctx, w)
+	}
+	if f != nil {
+		_ = f.Sync()
+	}
+}
+
+func (l *Instance) Info(msg string, fields ...map[string]interface{}) {
+	l.log(l.logger().Info(), zerolog.InfoLevel, nil, msg, fields)
+}
+
+func (l *Instance) Error(msg string, fields ...map[string]interface{}) {
+	l.log(l.logger().Error(), zerolog.ErrorLevel, nil, msg, fields)
+}
+
+func (l *Instance) ErrorWithErr(err error, msg string, fields ...map[string]interface{}) {
+	if err == nil && l.skipNil() {
+		return
+	}
+	l.log(withErr(l.logger().Error(), err), zerolog.ErrorLevel, err, msg, fields)
+}
+
+func (l *Instance) Debug(msg string, fields ...map[string]interface{}) {
+	l.log(l.logger().Debug(), zerolog.DebugLevel, nil, msg, fields)
+}
+
+func (l *Instance) Warn(msg string, fields ...map[string]interface{}) {
+	l.log(l.logger().Warn(), zerolog.WarnLevel, nil, msg, fields)
+}
+
+func (l *Instance) WarnWithErr(err error, msg string, fields ...map[string]interface{}) {
+	if err == nil && l.skipNil() {
+		return
+	}
+	l.log(withErr(l.logger().Warn(), err), zerolog.WarnLevel, err, msg, fields)
+}
@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestInstancesAreIndependent(t *testing.T) {
+	useInfoLevel(t)
+	dir := t.TempDir()
+	appOut := &syncBuffer{}
+	if err := InitLogger(Config{ProjectKey: defaultProjectKey, ProjectName: "app", AllowedFields: []string{"user"}}, WithWriters(appOut)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; setAllowedFields(nil, false) })
+
+	auditPath := filepath.Join(dir, "audit.log")
+	audit, err := NewInstance(Config{LogPath: auditPath, EnableFileOutput: true, ProjectName: "audit", LogLevel: "warn", SkipNilErrors: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+	extra := &syncBuffer{}
+	other, err := NewInstance(Config{ProjectKey: "svc", ProjectName: "other"}, WithWriters(extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	audit.SetField(map[string]interface{}{"actor": "admin"})
+	audit.Info("below the audit level")
+	audit.Warn("permission changed", map[string]interface{}{"user": "u1", "role": "owner"})
+	audit.ErrorWithErr(nil, "skipped nil error")
+	other.Info("other instance", map[string]interface{}{"timeout": 2 * time.Second})
+	Info("app event", map[string]interface{}{"user": "u1", "role": "owner"})
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := decodeLines(t, bytes.NewBuffer(data))
+	// 实例不受默认日志记录器的字段白名单影响，也不会写入默认日志记录器的输出
+	if len(lines) != 1 || lines[0]["message"] != "permission changed" || lines[0]["project"] != "audit" ||
+		lines[0]["actor"] != "admin" || lines[0]["role"] != "owner" {
+		t.Errorf("unexpected audit log: %v", lines)
+	}
+	lines = decodeLines(t, bytes.NewBufferString(extra.String()))
+	if len(lines) != 1 || lines[0]["svc"] != "other" || lines[0]["timeout"] != float64(2*time.Second) {
+		t.Errorf("unexpected other log: %v", lines)
+	}
+	for _, line := range decodeLines(t, bytes.NewBufferString(appOut.String())) {
+		if line["message"] == "app event" {
+			if line["project"] != "app" || line["role"] != nil {
+				t.Errorf("default logger should keep its own config: %v", line)
+			}
+		} else if line["project"] != "app" {
+			t.Errorf("instance events should not reach the default logger: %v", line)
+		}
+	}
+}
+
+func TestInstanceFileMonitor(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local))
+	captureOutput(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := NewInstance(Config{LogPath: path, EnableFileOutput: true, MaxLogSize: 100, MonitorInterval: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		l.Info("filling the audit log")
+	}
+	clock.step(t, time.Minute)
+	clock.step(t, time.Minute)
+	if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+		t.Errorf("instance monitor should clear its own file: %v %v", fi, err)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("after close")
+	if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+		t.Errorf("events after Close should be discarded: %v %v", fi, err)
+	}
+}
+
+func TestNewInstanceErrors(t *testing.T) {
+	if _, err := NewInstance(Config{}); !errors.Is(err, ErrNoInstanceOutput) {
+		t.Errorf("expected ErrNoInstanceOutput, got %v", err)
+	}
+	if _, err := NewInstance(Config{EnableConsoleOutput: true, LogLevel: "loud"}); err == nil {
+		t.Errorf("expected an error for an invalid level")
+	}
+}
+
+func TestNewInstanceUnsupportedConfig(t *testing.T) {
+	_, err := NewInstance(Config{EnableConsoleOutput: true, AllowedFields: []string{"user"}, DiodeMode: true},
+		WithMasker(replaceMasker{"secret", "***"}))
+	if !errors.Is(err, ErrUnsupportedInstanceConfig) {
+		t.Fatalf("expected ErrUnsupportedInstanceConfig, got %v", err)
+	}
+	for _, name := range []string{"Config.AllowedFields", "Config.DiodeMode", "WithMasker"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error should name %s: %v", name, err)
+		}
+	}
+}
+
+func TestInstanceFatal(t *testing.T) {
+	var code int
+	exitFunc = func(c int) { code = c }
+	t.Cleanup(func() { exitFunc = os.Exit })
+	out := &syncBuffer{}
+	l, err := NewInstance(Config{}, WithWriters(out), WithWriteTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Fatal("audit store unavailable", 3, map[string]interface{}{"store": "s3"})
+	lines := decodeLines(t, bytes.NewBufferString(out.String()))
+	if code != 3 || len(lines) != 1 || lines[0]["level"] != "fatal" || lines[0]["store"] != "s3" {
+		t.Errorf("unexpected fatal: code %d, %v", code, lines)
+	}
+}
+
+func TestDefaultInstance(t *testing.T) {
+	buf := captureOutput(t)
+	Default().Warn("via default", map[string]interface{}{"k": "v"})
+	Warn("via package")
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 || lines[0]["message"] != "via default" || lines[0]["k"] != "v" || lines[1]["level"] != zerolog.LevelWarnValue {
+		t.Errorf("package functions and the default instance should share one logger: %v", lines)
+	}
+}
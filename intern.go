@@ -0,0 +1,134 @@
+// @Author Clover
+// @Data 2026/10/18 上午9:50:00
+// @Desc 重复出现的短字符串字段值复用编码后的 JSON 片段
+
+package logging
+
+import (
+	"hash/maphash"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	internMaxLen       = 64   // 只驻留不超过该字节数的字符串
+	internShards       = 16   // 分片数，降低并发写入时的锁竞争
+	internShardEntries = 256  // 每个分片最多驻留的值，驻留表总共最多 4096 个值
+	internFilterSlots  = 1024 // 每个分片准入过滤器的槽位数
+	internAdmitCount   = 4    // 值在准入过滤器中连续出现该次数后才驻留
+)
+
+// internTable 分片的驻留表：值到 zerolog 编码后的 JSON 片段，写入时直接复用片段，不再逐条编码与分配；
+// 新值先经过准入过滤器，只有在同一槽位中连续出现多次的值才会驻留，请求 ID 等高基数的值通常在此之前就被其他值挤出。
+// 分片已满时淘汰自上次淘汰以来未被使用的值，请求结束后不再出现的值不会一直占用驻留表
+type internTable struct {
+	seed   maphash.Seed
+	shards [internShards]internShard
+}
+
+type internShard struct {
+	mu      sync.RWMutex
+	entries map[string]*internEntry
+	filter  []internCandidate // 准入过滤器，首次未命中时分配
+}
+
+// internEntry 驻留的值
+type internEntry struct {
+	frag []byte
+	used atomic.Bool // 自上次淘汰以来是否被使用
+}
+
+// internCandidate 准入过滤器的槽位：最近落在该槽位的值的指纹及其连续出现的次数
+type internCandidate struct {
+	fingerprint uint32
+	count       uint8
+}
+
+var interner atomic.Pointer[internTable] // 为 nil 时不驻留
+
+// setInterning 开启时创建新的驻留表，丢弃之前驻留的值
+func setInterning(enabled bool) {
+	if !enabled {
+		interner.Store(nil)
+		return
+	}
+	interner.Store(&internTable{seed: maphash.MakeSeed()})
+}
+
+// fragment 返回 s 编码后的 JSON 片段，与 Event.Interface 的输出相同；s 过长或尚未驻留时返回 nil
+func (t *internTable) fragment(s string) []byte {
+	if len(s) > internMaxLen {
+		return nil
+	}
+	h := maphash.String(t.seed, s)
+	sh := &t.shards[h%internShards]
+	sh.mu.RLock()
+	e := sh.entries[s]
+	sh.mu.RUnlock()
+	if e != nil {
+		if !e.used.Load() { // 避免每次命中都写入共享的缓存行
+			e.used.Store(true)
+		}
+		return e.frag
+	}
+	return sh.admit(s, h)
+}
+
+// admit 记录一次未命中，值在过滤器中出现足够次数后驻留并返回片段
+func (sh *internShard) admit(s string, h uint64) []byte {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if e := sh.entries[s]; e != nil {
+		return e.frag
+	}
+	if sh.filter == nil {
+		sh.filter = make([]internCandidate, internFilterSlots)
+	}
+	// 分片使用哈希的低位，过滤器的槽位与指纹使用其余的位
+	c := &sh.filter[(h>>8)%internFilterSlots]
+	if fp := uint32(h >> 32); c.fingerprint != fp || c.count == 0 {
+		*c = internCandidate{fingerprint: fp, count: 1}
+		return nil
+	}
+	if c.count++; c.count < internAdmitCount {
+		return nil
+	}
+	*c = internCandidate{}
+	if len(sh.entries) >= internShardEntries && !sh.evictUnused() {
+		return nil
+	}
+	frag, err := zerolog.InterfaceMarshalFunc(s)
+	if err != nil {
+		return nil
+	}
+	if sh.entries == nil {
+		sh.entries = make(map[string]*internEntry)
+	}
+	sh.entries[strings.Clone(s)] = &internEntry{frag: frag}
+	return frag
+}
+
+// evictUnused 淘汰自上次淘汰以来未被使用的值并清除其余值的使用标记，返回是否腾出了空间，调用方需持有 sh.mu
+func (sh *internShard) evictUnused() bool {
+	for k, e := range sh.entries {
+		if !e.used.Swap(false) {
+			delete(sh.entries, k)
+		}
+	}
+	return len(sh.entries) < internShardEntries
+}
+
+// size 返回驻留的值的数量
+func (t *internTable) size() int {
+	n := 0
+	for i := range t.shards {
+		sh := &t.shards[i]
+		sh.mu.RLock()
+		n += len(sh.entries)
+		sh.mu.RUnlock()
+	}
+	return n
+}
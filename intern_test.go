@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// useInterning 替换驻留表，测试结束后恢复默认
+func useInterning(t testing.TB, enabled bool) {
+	setInterning(enabled)
+	t.Cleanup(func() { setInterning(true) })
+}
+
+// admitAll 重复查询直到值被驻留
+func admitAll(t *testing.T, table *internTable, values ...string) {
+	t.Helper()
+	for _, v := range values {
+		for i := 0; i < internAdmitCount; i++ {
+			table.fragment(v)
+		}
+		if table.fragment(v) == nil {
+			t.Fatalf("%q should be interned after %d lookups", v, internAdmitCount)
+		}
+	}
+}
+
+func TestInternOutputUnchanged(t *testing.T) {
+	values := []string{"prod", "", "us-east-1", "<a & b>", `quote " and \ backslash`, "tab\tnewline\n", "环境", "\x01 ", strings.Repeat("x", internMaxLen+1)}
+	render := func() string {
+		var buf bytes.Buffer
+		logger := zerolog.New(&buf)
+		event := logger.Info()
+		for i, v := range values {
+			event = writeField(event, fmt.Sprintf("k%d", i), v)
+		}
+		event.Msg("")
+		return buf.String()
+	}
+
+	useInterning(t, false)
+	want := render()
+	setInterning(true)
+	admitAll(t, interner.Load(), values[:len(values)-1]...)
+	if got := render(); got != want {
+		t.Errorf("interned output differs:\n got %s\nwant %s", got, want)
+	}
+	if interner.Load().fragment(values[len(values)-1]) != nil {
+		t.Errorf("values longer than %d bytes should not be interned", internMaxLen)
+	}
+}
+
+func TestInternAdmission(t *testing.T) {
+	useInterning(t, true)
+	table := interner.Load()
+	for i := 1; i < internAdmitCount; i++ {
+		if table.fragment("prod") != nil {
+			t.Fatalf("value should not be interned after %d lookups", i)
+		}
+	}
+	if table.fragment("prod") == nil || table.size() != 1 {
+		t.Fatalf("value should be interned after %d lookups", internAdmitCount)
+	}
+
+	// 高基数的值交替出现，不会进入驻留表
+	for i := 0; i < 100000; i++ {
+		table.fragment(fmt.Sprintf("req-%d", i))
+		table.fragment(fmt.Sprintf("req-%d", i+1))
+		table.fragment("prod")
+	}
+	if n := table.size(); n > 1 {
+		t.Errorf("high-cardinality values should not be admitted, table has %d values", n)
+	}
+}
+
+func TestInternBounded(t *testing.T) {
+	useInterning(t, true)
+	table := interner.Load()
+	hot := []string{"prod", "us-east-1", "billing"}
+	admitAll(t, table, hot...)
+
+	// 每个值连续出现足以驻留的次数（例如同一请求的多条日志），驻留表的大小仍然有上限
+	for i := 0; i < 20000; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		for j := 0; j < internAdmitCount; j++ {
+			table.fragment(id)
+		}
+		for _, v := range hot {
+			table.fragment(v)
+		}
+	}
+	if n := table.size(); n > internShards*internShardEntries {
+		t.Errorf("table grew to %d values", n)
+	}
+	// 一直在使用的值不会被淘汰
+	for _, v := range hot {
+		sh := &table.shards[maphash.String(table.seed, v)%internShards]
+		sh.mu.RLock()
+		_, ok := sh.entries[v]
+		sh.mu.RUnlock()
+		if !ok {
+			t.Errorf("frequently used value %q was evicted", v)
+		}
+	}
+}
+
+func TestDisableInterning(t *testing.T) {
+	t.Cleanup(func() { setInterning(true) })
+	if err := InitLogger(Config{DisableInterning: true}, WithWriters(io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+	if interner.Load() != nil {
+		t.Errorf("interning should be disabled")
+	}
+}
+
+// BenchmarkWriteRepeatedFields 写入重复出现的短字符串字段与唯一的请求 ID
+func BenchmarkWriteRepeatedFields(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("interning=%v", enabled), func(b *testing.B) {
+			useInterning(b, enabled)
+			ids := make([]string, 1<<16)
+			for i := range ids {
+				ids[i] = fmt.Sprintf("%08x-7d3b-4f6e-9a51-0c8e2b7d4a19", i)
+			}
+			logger := zerolog.New(io.Discard)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				writeFields(logger.Info(), map[string]interface{}{
+					"env":        "prod",
+					"region":     "us-east-1",
+					"project":    "billing-service",
+					"component":  "invoice-worker",
+					"request_id": ids[i%len(ids)],
+				}).Msg("request handled")
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package logfilter
+// @Author Clover
+// @Data 2026/10/18 上午7:30:00
+// @Desc 命令行工具共用的 NDJSON 日志过滤与输出格式
+package logfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Clov614/logging"
+	"github.com/rs/zerolog"
+)
+
+// malformedLine ReadNDJSON 为无法解析的行生成的条目的消息
+const malformedLine = "malformed log line"
+
+// Filter 过滤条件，零值表示不限制
+type Filter struct {
+	Level    *zerolog.Level
+	From, To time.Time
+	Grep     *regexp.Regexp
+}
+
+// Match 判断条目是否满足全部过滤条件；设置了级别或时间范围时，没有级别或可解析时间的条目不匹配
+func (f Filter) Match(entry logging.LogEntry) bool {
+	if f.Level != nil && (entry.Level == zerolog.NoLevel || entry.Level < *f.Level) {
+		return false
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		t, err := ParseTime(fmt.Sprint(entry.Fields[zerolog.TimestampFieldName]))
+		if err != nil || t.IsZero() {
+			return false
+		}
+		if !f.From.IsZero() && t.Before(f.From) {
+			return false
+		}
+		if !f.To.IsZero() && !t.Before(f.To) {
+			return false
+		}
+	}
+	if f.Grep != nil {
+		if f.Grep.MatchString(entry.Message) {
+			return true
+		}
+		for _, v := range entry.Fields {
+			if f.Grep.MatchString(fieldString(v)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ParseLevel 解析最低级别，空字符串返回 nil
+func ParseLevel(s string) (*zerolog.Level, error) {
+	if s == "" {
+		return nil, nil
+	}
+	l, err := zerolog.ParseLevel(s)
+	if err != nil || l == zerolog.NoLevel {
+		return nil, fmt.Errorf("invalid level %q", s)
+	}
+	return &l, nil
+}
+
+// IsMalformed 判断是否为 ReadNDJSON 对无法解析的行生成的条目
+func IsMalformed(entry logging.LogEntry) bool {
+	_, raw := entry.Fields["raw"]
+	return raw && len(entry.Fields) == 1 && entry.Message == malformedLine
+}
+
+// ParseTime 依次尝试日志的时间格式（本地时间）与 RFC3339，空字符串返回零值
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(zerolog.TimeFieldFormat, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// sortedKeys 返回除时间外按名称排序的字段名
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != zerolog.TimestampFieldName {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RenderJSON 将条目还原为一行 JSON
+func RenderJSON(entry logging.LogEntry) string {
+	evt := make(map[string]interface{}, len(entry.Fields)+2)
+	for k, v := range entry.Fields {
+		evt[k] = v
+	}
+	if entry.Level != zerolog.NoLevel {
+		evt[zerolog.LevelFieldName] = entry.Level.String()
+	}
+	if entry.Message != "" {
+		evt[zerolog.MessageFieldName] = entry.Message
+	}
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Sprintf(`{"level":"error","message":%q}`, err.Error())
+	}
+	return string(b)
+}
+
+// RenderLogfmt 按时间、级别、消息的顺序输出 key=value，其余字段按名称排序
+func RenderLogfmt(entry logging.LogEntry) string {
+	var parts []string
+	if t, ok := entry.Fields[zerolog.TimestampFieldName]; ok {
+		parts = append(parts, zerolog.TimestampFieldName+"="+logfmtValue(t))
+	}
+	if entry.Level != zerolog.NoLevel {
+		parts = append(parts, zerolog.LevelFieldName+"="+entry.Level.String())
+	}
+	parts = append(parts, zerolog.MessageFieldName+"="+logfmtValue(entry.Message))
+	for _, k := range sortedKeys(entry.Fields) {
+		parts = append(parts, k+"="+logfmtValue(entry.Fields[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// RenderText 输出便于阅读的一行：时间、对齐的大写级别、消息，之后是按名称排序的 key=value
+func RenderText(entry logging.LogEntry) string {
+	var sb strings.Builder
+	if t, ok := entry.Fields[zerolog.TimestampFieldName]; ok {
+		sb.WriteString(fieldString(t))
+		sb.WriteByte(' ')
+	}
+	level := "-"
+	if entry.Level != zerolog.NoLevel {
+		level = strings.ToUpper(entry.Level.String())
+	}
+	fmt.Fprintf(&sb, "%-5s %s", level, entry.Message)
+	for _, k := range sortedKeys(entry.Fields) {
+		sb.WriteString(" " + k + "=" + logfmtValue(entry.Fields[k]))
+	}
+	return sb.String()
+}
+
+// fieldString 字符串原样返回，其他类型格式化为 JSON
+func fieldString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case nil:
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// logfmtValue 格式化字段值，包含空格、引号或等号的值加引号
+func logfmtValue(v interface{}) string {
+	s := fieldString(v)
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+// Package watermark
+// @Author Clover
+// @Data 2026/10/16 下午2:40:00
+// @Desc 顺序写入的序号水位线
+package watermark
+
+import (
+	"context"
+	"sync"
+)
+
+// Watermark 记录已提交与已完成的写入序号，写入需按提交顺序完成
+type Watermark struct {
+	mu        sync.Mutex
+	issued    uint64
+	completed uint64
+	changed   chan struct{}
+}
+
+// Issue 提交一次写入，返回其序号
+func (w *Watermark) Issue() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.issued++
+	return w.issued
+}
+
+// Issued 返回最近一次提交的序号
+func (w *Watermark) Issued() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.issued
+}
+
+// Complete 标记序号 seq 及之前的写入已完成
+func (w *Watermark) Complete(seq uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if seq <= w.completed {
+		return
+	}
+	w.completed = seq
+	if w.changed != nil {
+		close(w.changed)
+		w.changed = nil
+	}
+}
+
+// Pending 返回已提交但尚未完成的写入数量
+func (w *Watermark) Pending() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.issued - w.completed
+}
+
+// Wait 等待序号 target 及之前的写入全部完成，ctx 结束时返回 ctx.Err()
+func (w *Watermark) Wait(ctx context.Context, target uint64) error {
+	for {
+		w.mu.Lock()
+		if w.completed >= target {
+			w.mu.Unlock()
+			return nil
+		}
+		if w.changed == nil {
+			w.changed = make(chan struct{})
+		}
+		ch := w.changed
+		w.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// WaitIssued 等待调用前提交的所有写入完成
+func (w *Watermark) WaitIssued(ctx context.Context) error {
+	return w.Wait(ctx, w.Issued())
+}
@@ -0,0 +1,32 @@
+package watermark
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatermark(t *testing.T) {
+	var w Watermark
+	first, second := w.Issue(), w.Issue()
+	if w.Pending() != 2 {
+		t.Fatalf("expected 2 pending, got %d", w.Pending())
+	}
+
+	go func() {
+		w.Complete(first)
+		w.Complete(second)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.WaitIssued(ctx); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	w.Issue()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := w.WaitIssued(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
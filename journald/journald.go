@@ -0,0 +1,182 @@
+// Package journald
+// @Author Clover
+// @Data 2026/10/17 下午6:40:00
+// @Desc 通过 journald 原生协议写入 systemd 日志
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultSocketPath journald 原生协议的套接字路径
+const DefaultSocketPath = "/run/systemd/journal/socket"
+
+// maxKeyLength journal 字段名的最大长度
+const maxKeyLength = 64
+
+// Config 用于配置 journald 输出
+type Config struct {
+	SocketPath string    // 套接字路径，默认为 DefaultSocketPath
+	Identifier string    // SYSLOG_IDENTIFIER，便于通过 journalctl -t 过滤
+	Fallback   io.Writer // 套接字不存在时改为写入的输出目标，为 nil 时丢弃
+}
+
+// Sink 将每行 JSON 日志写入 journald，实现 io.Writer
+// 日志级别映射为 PRIORITY，message 映射为 MESSAGE，其余字段转换为大写的 journal 字段
+type Sink struct {
+	config      Config
+	conn        *net.UnixConn // 为 nil 时写入 Fallback
+	maxDatagram int           // 超过该大小的日志通过 memfd 发送，0 表示只在数据报过大被拒绝时使用
+}
+
+// New 连接 journald，套接字不存在时（例如非 systemd 主机或非 Linux 平台）静默改为写入 Fallback
+func New(config Config) *Sink {
+	if config.SocketPath == "" {
+		config.SocketPath = DefaultSocketPath
+	}
+	return &Sink{config: config, conn: dial(config.SocketPath)}
+}
+
+// Available 返回是否已连接到 journald
+func (s *Sink) Available() bool {
+	return s.conn != nil
+}
+
+// Write 将一行 JSON 日志编码为 journald 原生协议并发送
+func (s *Sink) Write(p []byte) (int, error) {
+	if s.conn == nil {
+		if s.config.Fallback != nil {
+			return s.config.Fallback.Write(p)
+		}
+		return len(p), nil
+	}
+	payload, err := encode(p, s.config.Identifier)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.send(payload); err != nil {
+		return 0, fmt.Errorf("journald: %w", err)
+	}
+	return len(p), nil
+}
+
+// Close 关闭与 journald 的连接
+func (s *Sink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// encode 将 JSON 日志转换为 journald 原生协议的数据
+func encode(p []byte, identifier string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	var evt map[string]interface{}
+	if err := dec.Decode(&evt); err != nil {
+		return nil, fmt.Errorf("journald: cannot decode event: %w", err)
+	}
+
+	var buf bytes.Buffer
+	level, _ := evt[zerolog.LevelFieldName].(string)
+	appendField(&buf, "PRIORITY", fmt.Sprint(priority(level)))
+	if msg, ok := evt[zerolog.MessageFieldName].(string); ok {
+		appendField(&buf, "MESSAGE", msg)
+	}
+	if identifier != "" {
+		appendField(&buf, "SYSLOG_IDENTIFIER", identifier)
+	}
+
+	keys := make([]string, 0, len(evt))
+	for k := range evt {
+		if k != zerolog.LevelFieldName && k != zerolog.MessageFieldName {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := sanitizeKey(k)
+		if key == "" || key == "PRIORITY" || key == "MESSAGE" || key == "SYSLOG_IDENTIFIER" {
+			continue
+		}
+		appendField(&buf, key, fieldValue(evt[k]))
+	}
+	return buf.Bytes(), nil
+}
+
+// priority 将日志级别映射为 syslog 优先级
+func priority(level string) int {
+	switch level {
+	case zerolog.LevelTraceValue, zerolog.LevelDebugValue:
+		return 7 // debug
+	case zerolog.LevelWarnValue:
+		return 4 // warning
+	case zerolog.LevelErrorValue:
+		return 3 // err
+	case zerolog.LevelFatalValue:
+		return 2 // crit
+	case zerolog.LevelPanicValue:
+		return 0 // emerg
+	}
+	return 6 // info
+}
+
+// sanitizeKey 将字段名转换为合法的 journal 字段名：大写字母、数字与下划线，
+// 不能以下划线（保留给 journald 的受信任字段）或数字开头，最长 64 个字符
+func sanitizeKey(k string) string {
+	b := []byte(strings.ToUpper(k))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	key := strings.TrimLeft(string(b), "_")
+	if key != "" && key[0] >= '0' && key[0] <= '9' {
+		key = "F_" + key
+	}
+	if len(key) > maxKeyLength {
+		key = key[:maxKeyLength]
+	}
+	return key
+}
+
+// fieldValue 字符串原样输出，其他类型输出为 JSON
+func fieldValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// appendField 追加一个字段，包含换行的值使用长度前缀的二进制格式
+func appendField(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
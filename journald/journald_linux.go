@@ -0,0 +1,63 @@
+//go:build linux
+
+package journald
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// dial 连接 journald 的数据报套接字，失败时返回 nil
+func dial(path string) *net.UnixConn {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil
+	}
+	return conn
+}
+
+// send 发送一条日志，数据报过大时改为通过密封的 memfd 传递
+func (s *Sink) send(payload []byte) error {
+	if s.maxDatagram > 0 && len(payload) > s.maxDatagram {
+		return s.sendMemfd(payload)
+	}
+	_, err := s.conn.Write(payload)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return s.sendMemfd(payload)
+	}
+	return err
+}
+
+// sendMemfd 将日志写入 memfd 并密封，再通过 SCM_RIGHTS 将文件描述符发送给 journald
+func (s *Sink) sendMemfd(payload []byte) error {
+	fd, err := unix.MemfdCreate("journald-logging", unix.MFD_ALLOW_SEALING|unix.MFD_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "journald-logging")
+	defer f.Close()
+	if _, err := f.Write(payload); err != nil {
+		return err
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+	rights := unix.UnixRights(int(f.Fd()))
+	raw, err := s.conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	if err := raw.Write(func(fd uintptr) bool {
+		sendErr = unix.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != unix.EAGAIN
+	}); err != nil {
+		return err
+	}
+	return sendErr
+}
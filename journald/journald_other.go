@@ -0,0 +1,18 @@
+//go:build !linux
+
+package journald
+
+import (
+	"errors"
+	"net"
+)
+
+// dial 非 Linux 平台没有 journald
+func dial(string) *net.UnixConn {
+	return nil
+}
+
+// send 非 Linux 平台不会建立连接，不会被调用
+func (s *Sink) send([]byte) error {
+	return errors.New("journald is only available on linux")
+}
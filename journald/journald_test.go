@@ -0,0 +1,179 @@
+//go:build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listen 创建模拟 journald 的数据报套接字
+func listen(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn, path
+}
+
+// parseFields 按 journald 原生协议解析数据
+func parseFields(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(b) > 0 {
+		nl := bytes.IndexByte(b, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated field %q", b)
+		}
+		line := b[:nl]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			b = b[nl+1:]
+			continue
+		}
+		// 二进制格式：KEY\n + 8 字节小端长度 + 值 + \n
+		rest := b[nl+1:]
+		size := int(binary.LittleEndian.Uint64(rest[:8]))
+		if rest[8+size] != '\n' {
+			t.Fatalf("binary field %s not terminated by newline", line)
+		}
+		fields[string(line)] = string(rest[8 : 8+size])
+		b = rest[8+size+1:]
+	}
+	return fields
+}
+
+func TestWireFormat(t *testing.T) {
+	server, path := listen(t)
+	s := New(Config{SocketPath: path, Identifier: "app"})
+	defer s.Close()
+	if !s.Available() {
+		t.Fatal("expected sink to connect to stub socket")
+	}
+
+	line := `{"level":"warn","time":"2024-07-18 15:04:05","message":"disk almost full","request-id":"r1","_hidden":1,"9lives":true,"stack":"line1\nline2","attrs":{"a":1}}` + "\n"
+	if n, err := s.Write([]byte(line)); err != nil || n != len(line) {
+		t.Fatalf("write: %d %v", n, err)
+	}
+	buf := make([]byte, 4096)
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf[:n], []byte("STACK\n")) {
+		t.Errorf("multiline value should use the binary format: %q", buf[:n])
+	}
+	fields := parseFields(t, buf[:n])
+	want := map[string]string{
+		"PRIORITY":          "4",
+		"MESSAGE":           "disk almost full",
+		"SYSLOG_IDENTIFIER": "app",
+		"TIME":              "2024-07-18 15:04:05",
+		"REQUEST_ID":        "r1",
+		"HIDDEN":            "1",
+		"F_9LIVES":          "true",
+		"STACK":             "line1\nline2",
+		"ATTRS":             `{"a":1}`,
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("%s = %q, want %q", k, fields[k], v)
+		}
+	}
+	if _, ok := fields["LEVEL"]; ok {
+		t.Error("level should be sent as PRIORITY only")
+	}
+}
+
+func TestPriority(t *testing.T) {
+	for level, want := range map[string]int{
+		"trace": 7, "debug": 7, "info": 6, "warn": 4, "error": 3, "fatal": 2, "panic": 0, "": 6,
+	} {
+		if got := priority(level); got != want {
+			t.Errorf("priority(%q) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestLargeEntryUsesMemfd(t *testing.T) {
+	server, path := listen(t)
+	s := New(Config{SocketPath: path})
+	defer s.Close()
+	s.maxDatagram = 64
+
+	message := string(bytes.Repeat([]byte("x"), 1024))
+	if _, err := s.Write([]byte(`{"level":"error","message":"` + message + `"}`)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4096)
+	oob := make([]byte, unix.CmsgSpace(4))
+	n, oobn, _, _, err := server.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("memfd datagram should carry no payload, got %d bytes", n)
+	}
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("control messages: %v %v", msgs, err)
+	}
+	fds, err := unix.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("unix rights: %v %v", fds, err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "memfd")
+	defer f.Close()
+
+	seals, err := unix.FcntlInt(f.Fd(), unix.F_GET_SEALS, 0)
+	if err != nil || seals&unix.F_SEAL_WRITE == 0 {
+		t.Errorf("memfd should be sealed, got %#x %v", seals, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, fi.Size())
+	if _, err := f.ReadAt(payload, 0); err != nil {
+		t.Fatal(err)
+	}
+	fields := parseFields(t, payload)
+	if fields["PRIORITY"] != "3" || fields["MESSAGE"] != message {
+		t.Errorf("unexpected memfd payload: %v", fields)
+	}
+}
+
+func TestFallbackWhenSocketMissing(t *testing.T) {
+	var fallback bytes.Buffer
+	s := New(Config{SocketPath: filepath.Join(t.TempDir(), "missing.sock"), Fallback: &fallback})
+	if s.Available() {
+		t.Fatal("sink should not be available without a socket")
+	}
+	line := `{"level":"info","message":"hello"}` + "\n"
+	if _, err := s.Write([]byte(line)); err != nil {
+		t.Fatal(err)
+	}
+	if fallback.String() != line {
+		t.Errorf("fallback got %q", fallback.String())
+	}
+
+	// 未配置 Fallback 时静默丢弃
+	s = New(Config{SocketPath: filepath.Join(t.TempDir(), "missing.sock")})
+	if n, err := s.Write([]byte(line)); err != nil || n != len(line) {
+		t.Errorf("write without fallback: %d %v", n, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,87 @@
+// @Author Clover
+// @Data 2026/10/18 上午2:50:00
+// @Desc 按 JSON 日志中的 level 字段过滤写入任意输出目标的日志
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// LevelFilter 包装任意输出目标，只写入 level 不低于 min 的 JSON 日志行，
+// 与 zerolog.MultiLevelWriter 组合即可将不同级别的日志写入不同的输出目标
+type LevelFilter struct {
+	min zerolog.Level
+	w   io.Writer
+}
+
+// NewLevelFilter 创建按级别过滤的输出目标，无法解析或没有 level 字段的行视为 NoLevel，总是写入
+func NewLevelFilter(minLevel zerolog.Level, w io.Writer) io.Writer {
+	return &LevelFilter{min: minLevel, w: w}
+}
+
+// Write 逐行解析 level 字段，一次写入多行时只将保留的行写入被包装的输出目标
+func (lf *LevelFilter) Write(p []byte) (int, error) {
+	var kept []byte
+	rest := p
+	for len(rest) > 0 {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		rest = rest[len(line):]
+		if lineLevel(line) >= lf.min {
+			kept = append(kept, line...)
+		}
+	}
+	if len(kept) == len(p) {
+		kept = p
+	}
+	if len(kept) > 0 {
+		if _, err := lf.w.Write(kept); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// WriteLevel zerolog 提供级别时直接使用，不再解析
+func (lf *LevelFilter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level == zerolog.NoLevel {
+		return lf.Write(p)
+	}
+	if level < lf.min {
+		return len(p), nil
+	}
+	if _, err := lf.w.Write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Barrier 等待被包装的输出目标完成写入
+func (lf *LevelFilter) Barrier(ctx context.Context) error {
+	return barrierWriter(ctx, lf.w)
+}
+
+// lineLevel 返回一行 JSON 日志的级别
+func lineLevel(line []byte) zerolog.Level {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(line, &m); err != nil {
+		return zerolog.NoLevel
+	}
+	var s string
+	if err := json.Unmarshal(m[zerolog.LevelFieldName], &s); err != nil {
+		return zerolog.NoLevel
+	}
+	level, err := zerolog.ParseLevel(s)
+	if err != nil {
+		return zerolog.NoLevel
+	}
+	return level
+}
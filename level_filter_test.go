@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// recordingWriter 记录每次写入的内容
+type recordingWriter struct {
+	writes []string
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.writes = append(rw.writes, string(p))
+	return len(p), nil
+}
+
+func TestLevelFilter(t *testing.T) {
+	rec := &recordingWriter{}
+	w := NewLevelFilter(zerolog.WarnLevel, rec)
+
+	lines := []string{
+		`{"level":"debug","message":"a"}` + "\n",
+		`{"level":"warn","message":"b"}` + "\n",
+		`{"level":"info","message":"warn in text \"level\":\"error\""}` + "\n",
+		`{"level":"error","message":"c"}` + "\n",
+		`{"message":"no level"}` + "\n",
+		"not json\n",
+	}
+	for _, l := range lines {
+		if n, err := w.Write([]byte(l)); err != nil || n != len(l) {
+			t.Fatalf("Write(%q) = %d, %v", l, n, err)
+		}
+	}
+	want := []string{lines[1], lines[3], lines[4], lines[5]}
+	if strings.Join(rec.writes, "") != strings.Join(want, "") || len(rec.writes) != len(want) {
+		t.Errorf("forwarded %q, want %q", rec.writes, want)
+	}
+
+	// 一次写入多行时只转发保留的行
+	rec.writes = nil
+	if n, err := w.Write([]byte(lines[0] + lines[1] + lines[2])); err != nil || n != len(lines[0]+lines[1]+lines[2]) {
+		t.Fatalf("multi-line Write = %d, %v", n, err)
+	}
+	if len(rec.writes) != 1 || rec.writes[0] != lines[1] {
+		t.Errorf("multi-line write forwarded %q", rec.writes)
+	}
+}
+
+func TestLevelFilterWithZerolog(t *testing.T) {
+	debug, warn := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := zerolog.New(zerolog.MultiLevelWriter(debug, NewLevelFilter(zerolog.WarnLevel, warn)))
+	logger.Info().Msg("info")
+	logger.Error().Msg("error")
+
+	if strings.Count(debug.String(), "\n") != 2 || strings.Contains(warn.String(), "info") || !strings.Contains(warn.String(), "error") {
+		t.Errorf("unexpected routing: all=%q warn=%q", debug.String(), warn.String())
+	}
+}
@@ -0,0 +1,94 @@
+// @Author Clover
+// @Data 2026/10/18 上午6:50:00
+// @Desc 控制台输出与进度条等改写当前行的终端内容协作
+
+package logging
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// clearLineSequence 回到行首并清除整行
+const clearLineSequence = "\r\x1b[2K"
+
+// LineGuard 在每条控制台日志写入前后调用，例如进度条库在写入前清除进度条、写入后重新绘制；
+// 两个方法在控制台锁内调用，不能再调用 LockedConsole
+type LineGuard interface {
+	BeforeWrite()
+	AfterWrite()
+}
+
+var (
+	consoleMu sync.Mutex // 串行化控制台日志的写入与进度条的重绘
+	lineGuard LineGuard  // 由 consoleMu 保护
+
+	// consoleIsTerminal 控制台输出是否为终端，不是终端时不调用 LineGuard
+	consoleIsTerminal = func(out io.Writer) bool {
+		f, ok := out.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd()))
+	}
+)
+
+// SetLineGuard 设置控制台日志写入前后调用的 LineGuard，nil 表示取消；
+// 只影响控制台输出，控制台不是终端（例如重定向到文件）时不会调用
+func SetLineGuard(g LineGuard) {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	lineGuard = g
+}
+
+// LockedConsole 持有控制台锁执行 fn，进度条在 fn 中重绘时不会与控制台日志的写入交错
+func LockedConsole(fn func()) {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	fn()
+}
+
+// ClearLineGuard 内置的 LineGuard：写入日志前以 "\r\x1b[2K" 清除当前行，写入后调用 Repaint 重新绘制
+type ClearLineGuard struct {
+	Out     io.Writer // 写入清除序列的终端，为 nil 时使用 os.Stderr
+	Repaint func()    // 日志写入后重新绘制当前行，为 nil 时不绘制
+}
+
+func (g ClearLineGuard) BeforeWrite() {
+	out := g.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	io.WriteString(out, clearLineSequence)
+}
+
+func (g ClearLineGuard) AfterWrite() {
+	if g.Repaint != nil {
+		g.Repaint()
+	}
+}
+
+// guardedConsole 控制台输出，终端上每次写入持有控制台锁并调用 LineGuard
+type guardedConsole struct {
+	w        io.Writer
+	terminal bool
+}
+
+// newGuardedConsole 包装写入 out 的控制台输出 w
+func newGuardedConsole(w, out io.Writer) io.Writer {
+	return guardedConsole{w: w, terminal: consoleIsTerminal(out)}
+}
+
+func (c guardedConsole) Write(p []byte) (int, error) {
+	if !c.terminal {
+		return c.w.Write(p)
+	}
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	if lineGuard == nil {
+		return c.w.Write(p)
+	}
+	lineGuard.BeforeWrite()
+	defer lineGuard.AfterWrite()
+	return c.w.Write(p)
+}
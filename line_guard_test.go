@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTerminal 将 consoleIsTerminal 固定为 terminal
+func fakeTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+	prev := consoleIsTerminal
+	consoleIsTerminal = func(io.Writer) bool { return terminal }
+	t.Cleanup(func() { consoleIsTerminal = prev })
+}
+
+func TestLineGuard(t *testing.T) {
+	fakeTerminal(t, true)
+	var term bytes.Buffer
+	SetLineGuard(ClearLineGuard{Out: &term, Repaint: func() { term.WriteString("[bar 50%]") }})
+	t.Cleanup(func() { SetLineGuard(nil) })
+
+	w := newGuardedConsole(newConsoleWriter(&term, true), &term)
+	term.WriteString("[bar 40%]")
+	w.Write([]byte(`{"level":"info","message":"step done"}` + "\n"))
+
+	got := term.String()
+	if !strings.HasPrefix(got, "[bar 40%]"+clearLineSequence) || !strings.HasSuffix(got, "step done\n[bar 50%]") {
+		t.Errorf("log line should clear and repaint the bar: %q", got)
+	}
+}
+
+func TestLineGuardSkippedWhenNotTerminal(t *testing.T) {
+	fakeTerminal(t, false)
+	var calls int
+	SetLineGuard(ClearLineGuard{Out: io.Discard, Repaint: func() { calls++ }})
+	t.Cleanup(func() { SetLineGuard(nil) })
+
+	var out bytes.Buffer
+	w := newGuardedConsole(newConsoleWriter(&out, true), &out)
+	w.Write([]byte(`{"level":"info","message":"redirected"}` + "\n"))
+	if calls != 0 || strings.Contains(out.String(), clearLineSequence) {
+		t.Errorf("guard should not run when output is not a terminal: %d %q", calls, out.String())
+	}
+}
+
+// recordingGuard 记录写入前后的调用，写入前后必须成对出现
+type recordingGuard struct {
+	out    *bytes.Buffer
+	active bool
+	t      *testing.T
+}
+
+func (g *recordingGuard) BeforeWrite() {
+	if g.active {
+		g.t.Errorf("BeforeWrite called while another write is in progress")
+	}
+	g.active = true
+	g.out.WriteString("<")
+}
+
+func (g *recordingGuard) AfterWrite() {
+	g.out.WriteString(">")
+	g.active = false
+}
+
+func TestLineGuardSerializesRepaint(t *testing.T) {
+	fakeTerminal(t, true)
+	var term bytes.Buffer
+	SetLineGuard(&recordingGuard{out: &term, t: t})
+	t.Cleanup(func() { SetLineGuard(nil) })
+	w := newGuardedConsole(&term, &term)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				w.Write([]byte("L"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				// 进度条分多次写入重绘的内容，期间不会插入日志
+				LockedConsole(func() {
+					term.WriteString("(")
+					time.Sleep(time.Microsecond)
+					term.WriteString(")")
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := term.String()
+	if strings.Count(got, "<L>") != 160 || strings.Count(got, "()") != 40 || len(got) != 160*3+40*2 {
+		t.Errorf("console writes and repaints interleaved: %q", got)
+	}
+}
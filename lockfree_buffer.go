@@ -0,0 +1,67 @@
+// @Author Clover
+// @Data 2026/10/18 上午7:50:00
+// @Desc 基于 channel 的定长日志缓冲区
+
+package logging
+
+import (
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// LockFreeLogBuffer 基于带缓冲 channel 的日志缓冲区，AddEntry 不使用 LogBuffer 的互斥锁；
+// 容量固定，已满时丢弃新的日志并计数，适用于需要限制缓冲内存的场景。
+// channel 内部同样需要加锁，不保证在竞争下比 LogBuffer 更快（可用 BenchmarkLogBufferAddEntry 在多核机器上比较），LogBuffer 仍是默认的实现
+type LockFreeLogBuffer struct {
+	entries chan LogEntry
+	dropped atomic.Int64
+}
+
+// NewLockFreeLogBuffer 创建最多容纳 capacity 条日志的缓冲区
+func NewLockFreeLogBuffer(capacity int) *LockFreeLogBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LockFreeLogBuffer{entries: make(chan LogEntry, capacity)}
+}
+
+// AddEntry 向缓冲区中添加一个日志条目，缓冲区已满时丢弃该条目并返回 false
+func (lb *LockFreeLogBuffer) AddEntry(entry LogEntry) bool {
+	select {
+	case lb.entries <- entry:
+		return true
+	default:
+		lb.dropped.Add(1)
+		return false
+	}
+}
+
+// Len 返回缓冲区中的日志条数
+func (lb *LockFreeLogBuffer) Len() int {
+	return len(lb.entries)
+}
+
+// Dropped 返回因缓冲区已满而丢弃的条数
+func (lb *LockFreeLogBuffer) Dropped() int64 {
+	return lb.dropped.Load()
+}
+
+// Flush 取出调用时缓冲区中的日志，输出不低于 minLevel 的日志并返回输出的条数；
+// 与 AddEntry 并发调用时，之后添加的日志留到下一次 Flush；多个 Flush 并发调用时每条日志只被其中一个输出
+func (lb *LockFreeLogBuffer) Flush(minLevel zerolog.Level) int {
+	flushed := 0
+	for n := len(lb.entries); n > 0; n-- {
+		// 并发的 Flush 可能已经取走了剩余的日志，不能阻塞等待
+		select {
+		case entry := <-lb.entries:
+			if entry.Level >= minLevel {
+				writeEntry(entry)
+				flushed++
+			}
+		default:
+			return flushed
+		}
+	}
+	return flushed
+}
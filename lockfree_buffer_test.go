@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLockFreeLogBuffer(t *testing.T) {
+	out := captureOutput(t)
+	lb := NewLockFreeLogBuffer(3)
+	lb.AddEntry(LogEntry{Level: zerolog.DebugLevel, Message: "debug"})
+	lb.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "first"})
+	lb.AddEntry(LogEntry{Level: zerolog.ErrorLevel, Message: "second"})
+	if lb.AddEntry(LogEntry{Level: zerolog.ErrorLevel, Message: "overflow"}) || lb.Dropped() != 1 {
+		t.Errorf("full buffer should drop new entries, dropped %d", lb.Dropped())
+	}
+
+	if n := lb.Flush(zerolog.InfoLevel); n != 2 || lb.Len() != 0 {
+		t.Errorf("flushed %d, %d left", n, lb.Len())
+	}
+	s := out.String()
+	if strings.Contains(s, "debug") || strings.Contains(s, "overflow") || strings.Index(s, "first") > strings.Index(s, "second") {
+		t.Errorf("unexpected output: %s", s)
+	}
+}
+
+// TestLockFreeLogBufferConcurrentFlush 并发的 Flush 不会阻塞，每条日志恰好输出一次，可用 -cpu 1,4,8 运行
+func TestLockFreeLogBufferConcurrentFlush(t *testing.T) {
+	prev := baseLogger
+	baseLogger = zerolog.New(io.Discard)
+	t.Cleanup(func() { baseLogger = prev })
+
+	const entries = 2000
+	lb := NewLockFreeLogBuffer(entries)
+	for round := 0; round < 20; round++ {
+		for i := 0; i < entries; i++ {
+			lb.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "buffered"})
+		}
+		var (
+			wg      sync.WaitGroup
+			flushed atomic.Int64
+		)
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				flushed.Add(int64(lb.Flush(zerolog.InfoLevel)))
+			}()
+		}
+		done := make(chan struct{})
+		go func() { wg.Wait(); close(done) }()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("concurrent Flush calls deadlocked")
+		}
+		if flushed.Load() != entries || lb.Len() != 0 {
+			t.Fatalf("round %d: flushed %d of %d entries, %d left", round, flushed.Load(), entries, lb.Len())
+		}
+	}
+}
+
+// BenchmarkLogBufferAddEntry 比较 goroutines 个协程并发调用 AddEntry 时 LogBuffer（互斥锁）与 LockFreeLogBuffer（channel）的吞吐
+func BenchmarkLogBufferAddEntry(b *testing.B) {
+	entry := LogEntry{Level: zerolog.InfoLevel, Message: "buffered", Fields: map[string]interface{}{"k": "v"}}
+	impls := []struct {
+		name string
+		add  func(n int) func(LogEntry)
+	}{
+		{"mutex", func(n int) func(LogEntry) {
+			lb := NewLogBuffer()
+			lb.entries = make([]LogEntry, 0, n) // 与 channel 一样预先分配，只比较同步的开销
+			return lb.AddEntry
+		}},
+		{"chan", func(n int) func(LogEntry) {
+			lb := NewLockFreeLogBuffer(n)
+			return func(e LogEntry) { lb.AddEntry(e) }
+		}},
+	}
+	for _, impl := range impls {
+		for _, goroutines := range []int{1, 4, 8, 32} {
+			b.Run(fmt.Sprintf("%s/goroutines=%d", impl.name, goroutines), func(b *testing.B) {
+				add := impl.add(b.N)
+				var wg sync.WaitGroup
+				b.ReportAllocs()
+				b.ResetTimer()
+				for g := 0; g < goroutines; g++ {
+					wg.Add(1)
+					go func(g int) {
+						defer wg.Done()
+						for i := g; i < b.N; i += goroutines {
+							add(entry)
+						}
+					}(g)
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// @Author Clover
+// @Data 2026/10/18 上午4:50:00
+// @Desc 日志文件的稀疏时间索引，按时间范围读取时只扫描相关的区间
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// indexExt 索引文件的后缀，与日志文件位于同一目录，例如 app.log.idx
+const indexExt = ".idx"
+
+// indexRecordSize 每条索引记录的字节数：大端序的 int64 偏移与 int64 UnixNano 时间
+const indexRecordSize = 16
+
+// indexRecord 日志文件中 offset 处开始的一行日志及其时间
+type indexRecord struct {
+	offset int64
+	time   time.Time
+}
+
+// logIndex 日志文件的稀疏索引：每写入 stride 字节后，为之后的第一行日志追加一条记录
+type logIndex struct {
+	path   string
+	stride int64
+	file   *os.File
+	last   int64 // 最后一条记录的偏移，没有记录时为 -1
+}
+
+// openLogIndex 打开 logPath 的索引，索引不存在或与日志文件不一致时扫描日志文件重新生成
+func openLogIndex(logPath string, stride int64) (*logIndex, error) {
+	ix := &logIndex{path: logPath + indexExt, stride: stride}
+	records, ok := loadIndex(ix.path)
+	if !ok || !consistentIndex(logPath, records) {
+		var err error
+		if records, err = buildIndex(logPath, stride); err != nil {
+			return nil, err
+		}
+		if err := writeIndex(ix.path, records); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(ix.path, os.O_RDWR|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+	ix.file = f
+	ix.last = -1
+	if len(records) > 0 {
+		ix.last = records[len(records)-1].offset
+	}
+	return ix, nil
+}
+
+// observe 记录 offset 处写入的一行日志，距上一条记录不足 stride 字节或没有时间字段时跳过
+func (ix *logIndex) observe(offset int64, line []byte) {
+	if ix.last >= 0 && offset-ix.last < ix.stride {
+		return
+	}
+	t, ok := lineTime(line)
+	if !ok {
+		return
+	}
+	// 写入失败时下一行日志会再次尝试，读取时不完整的记录被忽略
+	if _, err := ix.file.Write(encodeIndexRecord(indexRecord{offset: offset, time: t})); err == nil {
+		ix.last = offset
+	}
+}
+
+// reset 日志文件被清空后清空索引
+func (ix *logIndex) reset() error {
+	ix.last = -1
+	return ix.file.Truncate(0)
+}
+
+// rebuild 日志文件被外部截断或替换后按新的内容重新生成索引
+func (ix *logIndex) rebuild(logPath string) error {
+	records, err := buildIndex(logPath, ix.stride)
+	if err != nil {
+		return err
+	}
+	if err := ix.reset(); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if _, err := ix.file.Write(encodeIndexRecord(r)); err != nil {
+			return err
+		}
+		ix.last = r.offset
+	}
+	return nil
+}
+
+func (ix *logIndex) close() error {
+	return ix.file.Close()
+}
+
+func encodeIndexRecord(r indexRecord) []byte {
+	var b [indexRecordSize]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(r.offset))
+	binary.BigEndian.PutUint64(b[8:], uint64(r.time.UnixNano()))
+	return b[:]
+}
+
+// loadIndex 读取索引文件，忽略末尾不完整的记录；文件不存在、无法读取或偏移不是递增时返回 false
+func loadIndex(path string) ([]indexRecord, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	records := make([]indexRecord, 0, len(data)/indexRecordSize)
+	for len(data) >= indexRecordSize {
+		r := indexRecord{
+			offset: int64(binary.BigEndian.Uint64(data[:8])),
+			time:   time.Unix(0, int64(binary.BigEndian.Uint64(data[8:indexRecordSize]))),
+		}
+		if r.offset < 0 || (len(records) > 0 && r.offset <= records[len(records)-1].offset) {
+			return nil, false
+		}
+		records = append(records, r)
+		data = data[indexRecordSize:]
+	}
+	return records, len(data) == 0
+}
+
+// writeIndex 以 records 替换索引文件
+func writeIndex(path string, records []indexRecord) error {
+	buf := make([]byte, 0, len(records)*indexRecordSize)
+	for _, r := range records {
+		buf = append(buf, encodeIndexRecord(r)...)
+	}
+	return os.WriteFile(path, buf, 0666)
+}
+
+// consistentIndex 检查每条记录是否指向日志文件中一行的开头，且该行的时间与记录一致，
+// 日志文件被截断或替换后（索引超出日志文件）返回 false
+func consistentIndex(logPath string, records []indexRecord) bool {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	for _, r := range records {
+		if r.offset >= fi.Size() || !lineStart(f, r.offset) {
+			return false
+		}
+		line, err := bufio.NewReader(io.NewSectionReader(f, r.offset, fi.Size()-r.offset)).ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return false
+		}
+		if t, ok := lineTime(line); !ok || !t.Equal(r.time) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildIndex 扫描日志文件生成索引记录，日志文件不存在时返回空的索引
+func buildIndex(logPath string, stride int64) ([]indexRecord, error) {
+	f, err := os.Open(logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []indexRecord
+	last := int64(-1)
+	var offset int64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && (last < 0 || offset-last >= stride) {
+			if t, ok := lineTime(line); ok {
+				records = append(records, indexRecord{offset: offset, time: t})
+				last = offset
+			}
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// lineStart offset 是否为一行的开头
+func lineStart(f *os.File, offset int64) bool {
+	if offset == 0 {
+		return true
+	}
+	var b [1]byte
+	_, err := f.ReadAt(b[:], offset-1)
+	return err == nil && b[0] == '\n'
+}
+
+// lineTime 返回一行 JSON 日志的时间字段
+func lineTime(line []byte) (time.Time, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimSpace(line), &fields); err != nil {
+		return time.Time{}, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(fields[zerolog.TimestampFieldName], &v); err != nil {
+		return time.Time{}, false
+	}
+	return parseEntryTime(v)
+}
+
+// indexRange 返回日志文件中可能包含 [from, to) 内日志的区间 [start, end)，假设日志的时间基本单调递增；
+// 没有索引、索引损坏或记录超出日志文件时退回到不可用的部分之前，最差为整个文件
+func indexRange(f *os.File, logPath string, size int64, from, to time.Time) (start, end int64) {
+	records, _ := loadIndex(logPath + indexExt)
+	// 只使用指向当前日志文件中行开头的记录，例如日志文件被截断后超出的记录被忽略
+	valid := records[:0]
+	for _, r := range records {
+		if r.offset >= size || !lineStart(f, r.offset) {
+			break
+		}
+		valid = append(valid, r)
+	}
+	start, end = 0, size
+	if !from.IsZero() {
+		// 第一条时间不早于 from 的记录之前的日志都早于 from，从它的前一条记录开始扫描
+		if i := sort.Search(len(valid), func(i int) bool { return !valid[i].time.Before(from) }); i > 0 {
+			start = valid[i-1].offset
+		}
+	}
+	if !to.IsZero() {
+		// 第一条时间不早于 to 的记录之后的日志都不早于 to
+		if i := sort.Search(len(valid), func(i int) bool { return !valid[i].time.Before(to) }); i < len(valid) {
+			end = valid[i].offset
+		}
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// openLogRange 打开日志文件中可能包含 [from, to) 内日志的部分，存在索引时只读取相关的区间；
+// 压缩的日志文件没有索引，返回整个文件
+func openLogRange(path string, from, to time.Time) (io.ReadCloser, error) {
+	if isCompressedPath(path) || (from.IsZero() && to.IsZero()) {
+		return openLogReader(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	start, end := indexRange(f, path, fi.Size(), from, to)
+	return sectionReadCloser{SectionReader: io.NewSectionReader(f, start, end-start), f: f}, nil
+}
+
+type sectionReadCloser struct {
+	*io.SectionReader
+	f *os.File
+}
+
+func (s sectionReadCloser) Close() error {
+	return s.f.Close()
+}
+
+// ReadEntriesBetween 读取当前日志文件（LogPath）中时间在 [from, to) 内的日志，零值表示不限制；
+// 开启 Config.IndexInterval 时借助 <LogPath>.idx 索引只扫描相关的区间，否则扫描整个文件。
+// 没有时间字段的日志被跳过
+func ReadEntriesBetween(from, to time.Time) ([]LogEntry, error) {
+	stateMu.RLock()
+	path := logPath
+	stateMu.RUnlock()
+	if path == "" {
+		return nil, errors.New("no log file configured")
+	}
+	r, err := openLogRange(path, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var entries []LogEntry
+	err = scanNDJSON(r, func(entry LogEntry) {
+		t, ok := parseEntryTime(entry.Fields[zerolog.TimestampFieldName])
+		if !ok || (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+			return
+		}
+		entries = append(entries, entry)
+	})
+	return entries, err
+}
@@ -0,0 +1,187 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var indexT0 = time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)
+
+// timedLine 返回时间为 t0 之后 i 秒的一行日志
+func timedLine(i int) string {
+	return fmt.Sprintf(`{"level":"info","time":%q,"seq":%d,"message":"entry"}`+"\n", indexT0.Add(time.Duration(i)*time.Second).Format(timeFormat), i)
+}
+
+// writeTimedLog 写入 n 行时间逐秒递增的日志
+func writeTimedLog(t *testing.T, path string, n int) {
+	t.Helper()
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteString(timedLine(i))
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogIndexRecordsEveryStride(t *testing.T) {
+	captureOutput(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	lf, err := openLogFile(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Close()
+	if err := lf.enableIndex(256); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		lf.Write([]byte(timedLine(i)))
+	}
+
+	records, ok := loadIndex(path + indexExt)
+	if !ok || len(records) < 5 || records[0].offset != 0 || !records[0].time.Equal(indexT0) {
+		t.Fatalf("unexpected index: %v %v", ok, records)
+	}
+	for i := 1; i < len(records); i++ {
+		if gap := records[i].offset - records[i-1].offset; gap < 256 || gap > 256+int64(len(timedLine(0))) {
+			t.Errorf("record %d is %d bytes after the previous one", i, gap)
+		}
+	}
+	if !consistentIndex(path, records) {
+		t.Errorf("index written during logging should be consistent")
+	}
+
+	// 清空日志文件时索引随之重新开始
+	lf.clear(HeaderReasonSizeClear)
+	lf.Write([]byte(timedLine(99)))
+	records, _ = loadIndex(path + indexExt)
+	if len(records) != 1 || records[0].offset != 0 || !records[0].time.Equal(indexT0.Add(99*time.Second)) {
+		t.Errorf("index should restart after clear: %v", records)
+	}
+}
+
+func TestReadEntriesBetween(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeTimedLog(t, path, 2000)
+	// 启动时不存在索引，扫描已有的日志文件生成
+	config := Config{LogPath: path, EnableFileOutput: true, IndexInterval: 4096}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+
+	from, to := indexT0.Add(1500*time.Second), indexT0.Add(1510*time.Second)
+	entries, err := ReadEntriesBetween(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 10 || entries[0].Fields["seq"] != float64(1500) || entries[9].Fields["seq"] != float64(1509) {
+		t.Fatalf("unexpected entries: %d %v", len(entries), entries)
+	}
+
+	// 只扫描索引定位的区间，而不是整个文件
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, _ := f.Stat()
+	start, end := indexRange(f, path, fi.Size(), from, to)
+	if start == 0 || end == fi.Size() || end-start > 3*4096 {
+		t.Errorf("range [%d, %d) of %d bytes should be narrowed by the index", start, end, fi.Size())
+	}
+
+	// 开区间的两端
+	if entries, _ := ReadEntriesBetween(time.Time{}, indexT0.Add(3*time.Second)); len(entries) != 3 {
+		t.Errorf("expected 3 entries before t0+3s, got %d", len(entries))
+	}
+	if entries, _ := ReadEntriesBetween(indexT0.Add(1997*time.Second), time.Time{}); len(entries) != 3 {
+		t.Errorf("expected 3 entries from t0+1997s, got %d", len(entries))
+	}
+}
+
+func TestLogIndexRegenerated(t *testing.T) {
+	cases := map[string]func(t *testing.T, path string){
+		"missing": func(t *testing.T, path string) {
+			os.Remove(path + indexExt)
+		},
+		"partial record": func(t *testing.T, path string) {
+			f, _ := os.OpenFile(path+indexExt, os.O_WRONLY|os.O_APPEND, 0)
+			f.Write([]byte{1, 2, 3})
+			f.Close()
+		},
+		"ahead of truncated log": func(t *testing.T, path string) {
+			writeTimedLog(t, path, 100)
+		},
+		"offset inside a line": func(t *testing.T, path string) {
+			records, _ := loadIndex(path + indexExt)
+			records[2].offset++
+			writeIndex(path+indexExt, records)
+		},
+		"time mismatch": func(t *testing.T, path string) {
+			records, _ := loadIndex(path + indexExt)
+			records[1].time = records[1].time.Add(time.Hour)
+			writeIndex(path+indexExt, records)
+		},
+		"garbage": func(t *testing.T, path string) {
+			os.WriteFile(path+indexExt, []byte(strings.Repeat("\xff", 32)), 0644)
+		},
+	}
+	for name, corrupt := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			writeTimedLog(t, path, 400)
+			ix, err := openLogIndex(path, 1024)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ix.close()
+
+			corrupt(t, path)
+			if ix, err = openLogIndex(path, 1024); err != nil {
+				t.Fatal(err)
+			}
+			defer ix.close()
+			records, ok := loadIndex(path + indexExt)
+			want, _ := buildIndex(path, 1024)
+			if !ok || len(records) != len(want) || !consistentIndex(path, records) {
+				t.Errorf("index should be regenerated: got %d records, want %d", len(records), len(want))
+			}
+			if ix.last != want[len(want)-1].offset {
+				t.Errorf("last = %d, want %d", ix.last, want[len(want)-1].offset)
+			}
+		})
+	}
+}
+
+func TestIndexRangeToleratesStaleIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeTimedLog(t, path, 1000)
+	records, _ := buildIndex(path, 1024)
+	writeIndex(path+indexExt, records)
+
+	// 日志文件在运行中被截断，索引中的记录超出日志文件
+	writeTimedLog(t, path, 300)
+	from, to := indexT0.Add(250*time.Second), indexT0.Add(900*time.Second)
+	r, err := openLogRange(path, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var seqs []float64
+	scanNDJSON(r, func(e LogEntry) {
+		if ts, ok := parseEntryTime(e.Fields[zerolog.TimestampFieldName]); ok && !ts.Before(from) && ts.Before(to) {
+			seqs = append(seqs, e.Fields["seq"].(float64))
+		}
+	})
+	if len(seqs) != 50 || seqs[0] != 250 || seqs[49] != 299 {
+		t.Errorf("stale index should not lose entries: %d %v", len(seqs), seqs)
+	}
+}
@@ -0,0 +1,340 @@
+// @Author Clover
+// @Data 2026/10/16 下午5:50:00
+// @Desc 日志文件及其大小、使用时间监控
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// logFile 日志文件，实现 io.Writer，并按照自身的配置独立监控大小与使用时间
+// 每个 logFile 拥有独立的文件句柄、监控协程与清除状态，互不影响
+type logFile struct {
+	path    string
+	maxSize int64         // 最大日志文件大小，0 表示不限制
+	maxAge  time.Duration // 单个日志文件的最长使用时间，0 表示不限制
+
+	mu         sync.Mutex
+	file       *os.File
+	startTime  time.Time     // 当前日志文件的起始时间
+	clearCount int64         // 已清除的次数
+	size       int64         // 内部记录的文件大小，用于发现外部的截断与追加，压缩时为压缩后的字节数
+	zw         *zstd.Encoder // 开启 Config.CompressActive 时的 zstd 编码器
+	dirty      bool          // 当前 zstd 帧是否有尚未结束的数据
+	index      *logIndex     // 开启 Config.IndexInterval 时的时间索引
+	header     *FileHeader   // 开启 Config.FileHeader 时新文件首行元数据的模板
+
+	ticker  Ticker
+	done    chan struct{}
+	stopped chan struct{} // 监控协程退出后关闭
+
+	watchDone    chan struct{}
+	watchStopped chan struct{} // 外部变更监听协程退出后关闭
+
+	flushTicker  Ticker
+	flushDone    chan struct{}
+	flushStopped chan struct{} // 定时结束 zstd 帧的协程退出后关闭
+}
+
+// openLogFile 打开（必要时创建）日志文件
+func openLogFile(path string, maxSize int64, maxAge time.Duration) (*logFile, error) {
+	return openLogFileFlag(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, maxSize, maxAge)
+}
+
+// createLogFile 以 O_EXCL 创建新的日志文件，文件已存在时返回 os.ErrExist
+func createLogFile(path string, maxSize int64, maxAge time.Duration) (*logFile, error) {
+	return openLogFileFlag(path, os.O_RDWR|os.O_CREATE|os.O_EXCL|os.O_APPEND, maxSize, maxAge)
+}
+
+// openMainLogFile 打开 LogPath 的日志文件，header 不为 nil 时新创建的文件以首行元数据开始，
+// 首行与文件一同原子地创建；ExclusiveCreate 时文件已存在返回 os.ErrExist
+func openMainLogFile(path string, config Config, header *FileHeader) (*logFile, error) {
+	if header == nil {
+		if config.ExclusiveCreate {
+			return createLogFile(path, config.MaxLogSize, config.MaxFileAge)
+		}
+		return openLogFile(path, config.MaxLogSize, config.MaxFileAge)
+	}
+	if _, err := validLogPath(path, true); err != nil {
+		return nil, err
+	}
+	created, err := installFileHeader(path, header.line(HeaderReasonInitial))
+	if err != nil {
+		return nil, fmt.Errorf("error creating log file: %w", err)
+	}
+	if config.ExclusiveCreate && !created {
+		return nil, fmt.Errorf("error opening log file: %w", &os.PathError{Op: "open", Path: path, Err: os.ErrExist})
+	}
+	lf, err := openLogFile(path, config.MaxLogSize, config.MaxFileAge)
+	if err != nil {
+		return nil, err
+	}
+	lf.header = header
+	return lf, nil
+}
+
+// validExclusiveCreate 检查 Config.ExclusiveCreate：重复初始化会重新打开并追加写入之前创建的日志文件，
+// 与只创建新文件的 O_EXCL 相矛盾
+func validExclusiveCreate(config Config) error {
+	if config.ExclusiveCreate && config.AllowReinit {
+		return errors.New("ExclusiveCreate cannot be combined with AllowReinit, reinitialization appends to the existing log file")
+	}
+	return nil
+}
+
+func openLogFileFlag(path string, flag int, maxSize int64, maxAge time.Duration) (*logFile, error) {
+	if _, err := validLogPath(path, true); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, flag, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("error opening log file: %w", err)
+	}
+	lf := &logFile{
+		path:      path,
+		maxSize:   maxSize,
+		maxAge:    maxAge,
+		file:      f,
+		startTime: logFileStartTime(f),
+	}
+	if fi, err := f.Stat(); err == nil {
+		lf.size = fi.Size()
+	}
+	return lf, nil
+}
+
+// Write 写入日志文件。每条日志在 lf.mu 内一次写入完整的一行，并发的日志不会交错；
+// 写入不完整（例如磁盘已满）时撤销已写入的部分，之后的日志不会拼接在半行之后
+func (lf *logFile) Write(p []byte) (int, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.file == nil {
+		return 0, os.ErrClosed
+	}
+	if lf.zw != nil {
+		lf.dirty = true
+		return lf.zw.Write(p)
+	}
+	offset := lf.size
+	n, err := lf.file.Write(p)
+	if n > 0 && n < len(p) {
+		n = lf.discardPartial(offset, n)
+	}
+	lf.size += int64(n)
+	if lf.index != nil && err == nil {
+		lf.index.observe(offset, p)
+	}
+	return n, err
+}
+
+// discardPartial 处理从 offset 开始写入了 n 字节的不完整日志，返回留在文件中的字节数：
+// 文件没有被外部追加时截掉这部分，否则（或截断失败时）补一个换行结束这半行，调用方需持有 lf.mu
+func (lf *logFile) discardPartial(offset int64, n int) int {
+	if fi, err := lf.file.Stat(); err == nil && fi.Size() == offset+int64(n) {
+		if lf.file.Truncate(offset) == nil {
+			return 0
+		}
+	}
+	if _, err := lf.file.Write([]byte{'\n'}); err == nil {
+		n++
+	}
+	return n
+}
+
+// enableIndex 为日志文件维护 <path>.idx 时间索引，需在写入日志前调用，不支持压缩的日志文件
+func (lf *logFile) enableIndex(stride int64) error {
+	ix, err := openLogIndex(lf.path, stride)
+	if err != nil {
+		return fmt.Errorf("error opening log index: %w", err)
+	}
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	lf.index = ix
+	return nil
+}
+
+// Sync 将日志文件同步到磁盘，压缩时先结束当前的 zstd 帧
+func (lf *logFile) Sync() error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.file == nil {
+		return nil
+	}
+	if err := lf.finishFrame(); err != nil {
+		return err
+	}
+	return lf.file.Sync()
+}
+
+// Stat 返回日志文件信息
+func (lf *logFile) Stat() (os.FileInfo, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.file == nil {
+		return nil, os.ErrClosed
+	}
+	return lf.file.Stat()
+}
+
+// StartTime 返回当前日志文件的起始时间
+func (lf *logFile) StartTime() time.Time {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.startTime
+}
+
+// ClearCount 返回日志文件已被清除的次数
+func (lf *logFile) ClearCount() int64 {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.clearCount
+}
+
+// monitorAlive 返回监控协程是否仍在运行
+func (lf *logFile) monitorAlive() bool {
+	if lf.stopped == nil {
+		return false
+	}
+	select {
+	case <-lf.stopped:
+		return false
+	default:
+		return true
+	}
+}
+
+// setLimits 修改大小与使用时间限制，需在启动监控前调用
+func (lf *logFile) setLimits(maxSize int64, maxAge time.Duration) {
+	lf.maxSize = maxSize
+	lf.maxAge = maxAge
+}
+
+// defaultMonitorInterval 设置了 MaxFileAge 但未配置 MonitorInterval 时检查使用时间的最长间隔
+const defaultMonitorInterval = time.Minute
+
+// startMonitor 启动监控协程，按 interval 检查大小与使用时间，已经启动时不重复启动；
+// interval 未设置时只有设置了使用时间限制才启动，间隔为 defaultMonitorInterval 与 maxAge 中较小的一个
+func (lf *logFile) startMonitor(interval time.Duration) {
+	if interval <= 0 && lf.maxAge > 0 {
+		interval = min(defaultMonitorInterval, lf.maxAge)
+	}
+	if interval <= 0 || lf.ticker != nil {
+		return
+	}
+	lf.ticker = currentClock().NewTicker(interval)
+	lf.done = make(chan struct{})
+	lf.stopped = make(chan struct{})
+	go lf.monitor(lf.ticker.C(), lf.done)
+}
+
+// monitor 监控日志文件大小和使用时间并在超过限制时清除日志文件
+func (lf *logFile) monitor(ticker <-chan time.Time, done <-chan struct{}) {
+	defer close(lf.stopped)
+	for {
+		select {
+		case <-ticker:
+			lf.check()
+		case <-done:
+			return
+		}
+	}
+}
+
+// check 检查日志文件是否超过大小或使用时间限制
+func (lf *logFile) check() {
+	logger := currentLogger()
+	// Get the current log file size
+	fi, err := lf.Stat()
+	if err != nil {
+		logger.Error().Err(err).Msg("Error getting file info")
+		return
+	}
+	if debugBuild {
+		debugTrace("Checking log file limits", map[string]interface{}{
+			"path": lf.path, "size": fi.Size(), "max_size": lf.maxSize, "age": now().Sub(lf.StartTime()).String(),
+		})
+	}
+
+	if lf.maxSize > 0 && fi.Size() > lf.maxSize {
+		logger.Info().Msg("Log file size exceeds limit. Clearing log file.")
+		lf.clear(HeaderReasonSizeClear)
+		return
+	}
+
+	if lf.maxAge > 0 && now().Sub(lf.StartTime()) >= lf.maxAge {
+		logger.Info().Msg("Log file age exceeds limit. Clearing log file.")
+		lf.clear(HeaderReasonRotation)
+	}
+}
+
+// clear 清空日志文件并重新计时，开启首行元数据时以 reason 开始新的文件
+func (lf *logFile) clear(reason string) {
+	lf.mu.Lock()
+	if lf.file == nil {
+		lf.mu.Unlock()
+		return
+	}
+	// 先结束当前的 zstd 帧，截断后从新的帧开始写入；帧的内容随即被清除，无需关心结束帧的错误
+	_ = lf.finishFrame()
+	var err error
+	if lf.header != nil && lf.zw == nil {
+		// 以只包含首行的新文件替换，读取方不会看到没有首行的空文件
+		err = lf.replaceWithHeader(reason)
+	} else {
+		// 文件以 O_APPEND 打开，截断后的写入从文件开头开始
+		if err = lf.file.Truncate(0); err == nil {
+			lf.size = 0
+		}
+	}
+	if err == nil {
+		lf.startTime = now()
+		lf.clearCount++
+		if lf.index != nil {
+			// 日志文件从头开始写入，索引随之重新开始
+			err = lf.index.reset()
+		}
+	}
+	clears := lf.clearCount
+	lf.mu.Unlock()
+
+	logger := currentLogger()
+	if err != nil {
+		logger.Error().Err(err).Msg("Error truncating log file")
+		return
+	}
+	if debugBuild {
+		debugTrace("Truncated log file", map[string]interface{}{"path": lf.path, "clear_count": clears})
+	}
+	logger.Info().Msg("Log file cleared successfully.")
+}
+
+// Close 停止监控并关闭日志文件
+func (lf *logFile) Close() error {
+	lf.stopWatch()
+	lf.stopFlusher()
+	if lf.ticker != nil {
+		lf.ticker.Stop()
+		close(lf.done)
+		<-lf.stopped
+		lf.ticker = nil
+	}
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.file == nil {
+		return nil
+	}
+	err := errors.Join(lf.finishFrame(), lf.file.Close())
+	if lf.index != nil {
+		err = errors.Join(err, lf.index.close())
+		lf.index = nil
+	}
+	lf.file = nil
+	return err
+}
@@ -0,0 +1,329 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// waitFor 等待条件成立，超时则测试失败
+func waitFor(t *testing.T, desc string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLogFilesRotateIndependently(t *testing.T) {
+	prev := baseLogger
+	baseLogger = zerolog.New(io.Discard)
+	t.Cleanup(func() { baseLogger = prev })
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+
+	dir := t.TempDir()
+	open := func(name string, maxSize int64, interval time.Duration) *logFile {
+		lf, err := openLogFile(filepath.Join(dir, name), maxSize, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lf.startMonitor(interval)
+		t.Cleanup(func() { lf.Close() })
+		return lf
+	}
+	small := open("small.log", 100, time.Second)
+	large := open("large.log", 1000, time.Second)
+	idle := open("idle.log", 50, time.Hour)
+
+	payload := strings.Repeat("x", 199) + "\n"
+	for _, lf := range []*logFile{small, large, idle} {
+		if _, err := lf.Write([]byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 只有超过自身限制的文件被清除
+	clock.step(t, time.Second)
+	clock.step(t, time.Second)
+	if small.ClearCount() != 1 {
+		t.Errorf("small.log should be cleared once, got %d", small.ClearCount())
+	}
+	if large.ClearCount() != 0 {
+		t.Errorf("large.log should not be cleared below its own limit")
+	}
+	if idle.ClearCount() != 0 {
+		t.Errorf("idle.log should not be checked before its own interval")
+	}
+
+	// 关闭一个文件不影响其他文件的监控
+	small.Close()
+	if _, err := large.Write([]byte(strings.Repeat(payload, 5))); err != nil {
+		t.Fatal(err)
+	}
+	clock.step(t, time.Second)
+	clock.step(t, time.Second)
+	if large.ClearCount() != 1 {
+		t.Errorf("large.log should be cleared once, got %d", large.ClearCount())
+	}
+	if fi, err := large.Stat(); err != nil || fi.Size() != 0 {
+		t.Errorf("large.log should be empty after clearing: %v", err)
+	}
+
+	clock.step(t, time.Hour)
+	clock.step(t, time.Hour)
+	if idle.ClearCount() != 1 {
+		t.Errorf("idle.log should be cleared according to its own size limit")
+	}
+}
+
+// syncBuffer 可并发写入的缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogFileExternalChanges(t *testing.T) {
+	out := &syncBuffer{}
+	prev := baseLogger
+	baseLogger = zerolog.New(out)
+	t.Cleanup(func() { baseLogger = prev })
+
+	path := filepath.Join(t.TempDir(), "watched.log")
+	lf, err := openLogFile(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lf.startWatch(20 * time.Millisecond)
+	t.Cleanup(func() { lf.Close() })
+
+	write := func(s string) {
+		t.Helper()
+		if _, err := lf.Write([]byte(s + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("first")
+	write("second")
+
+	// 外部截断：同步内部大小，之后的写入从文件开头开始
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "truncation to be detected", func() bool { return strings.Contains(out.String(), `"change":"truncated"`) })
+	write("after truncate")
+	if content, _ := os.ReadFile(path); string(content) != "after truncate\n" {
+		t.Errorf("unexpected content after truncation: %q", content)
+	}
+
+	// 外部追加
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("external\n")
+	f.Close()
+	waitFor(t, "append to be detected", func() bool { return strings.Contains(out.String(), `"change":"appended"`) })
+
+	// 删除后重新创建文件并继续写入
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "removal to be detected", func() bool { return strings.Contains(out.String(), `"change":"removed"`) })
+	write("after remove")
+	if content, _ := os.ReadFile(path); string(content) != "after remove\n" {
+		t.Errorf("log file should be reopened after removal: %q", content)
+	}
+
+	// 重命名后在原路径重新打开
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "rename to be detected", func() bool {
+		return strings.Contains(out.String(), `"change":"replaced"`) || strings.Count(out.String(), `"change":"removed"`) == 2
+	})
+	write("after rename")
+	if content, _ := os.ReadFile(path); string(content) != "after rename\n" {
+		t.Errorf("log file should be reopened after rename: %q", content)
+	}
+	if content, _ := os.ReadFile(path + ".1"); string(content) != "after remove\n" {
+		t.Errorf("renamed file should keep its content: %q", content)
+	}
+	if !strings.Contains(out.String(), "Log file changed externally") {
+		t.Errorf("expected info events: %s", out.String())
+	}
+}
+
+func TestLogFileExternalChangesPolling(t *testing.T) {
+	out := &syncBuffer{}
+	prev := baseLogger
+	baseLogger = zerolog.New(out)
+	t.Cleanup(func() { baseLogger = prev })
+
+	path := filepath.Join(t.TempDir(), "polled.log")
+	lf, err := openLogFile(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟 fsnotify 不可用的平台，只依靠轮询
+	lf.watchDone = make(chan struct{})
+	lf.watchStopped = make(chan struct{})
+	go lf.watch(nil, 10*time.Millisecond)
+	t.Cleanup(func() { lf.Close() })
+
+	lf.Write([]byte("entry\n"))
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "removal to be detected by polling", func() bool { return strings.Contains(out.String(), `"change":"removed"`) })
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("log file should be recreated: %v", err)
+	}
+}
+
+func TestExclusiveCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	config := Config{LogPath: path, EnableFileOutput: true, ExclusiveCreate: true}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	Info("first")
+	Close()
+
+	// 文件已存在时返回错误而不是终止进程，也不追加写入已有文件
+	err := InitLogger(config)
+	t.Cleanup(Close)
+	if !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected os.ErrExist, got %v", err)
+	}
+	Info("second")
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "first") || strings.Contains(string(data), "second") {
+		t.Errorf("existing log file should be left untouched: %q", data)
+	}
+
+	config.AllowReinit = true
+	if err := InitLogger(config); err == nil || !strings.Contains(err.Error(), "AllowReinit") {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+func TestConcurrentWritesAreWholeLines(t *testing.T) {
+	duration := 2 * time.Second
+	if testing.Short() {
+		duration = 200 * time.Millisecond
+	}
+	for _, diode := range []bool{false, true} {
+		name := "sync"
+		if diode {
+			name = "diode"
+		}
+		t.Run(name, func(t *testing.T) {
+			useInfoLevel(t)
+			path := filepath.Join(t.TempDir(), "app.log")
+			config := Config{LogPath: path, EnableFileOutput: true, DiodeMode: diode, AsyncQueueSize: 1 << 14}
+			if err := InitLogger(config); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(Close)
+
+			// 64 个协程输出字段很多、远大于 PIPE_BUF 的日志，每个协程最多 150 条以限制文件大小
+			stop := time.Now().Add(duration)
+			var wg sync.WaitGroup
+			for g := 0; g < 64; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					fields := make(map[string]interface{}, 40)
+					for i := 0; i < 40; i++ {
+						fields["field_"+string(rune('a'+i%26))+string(rune('a'+i/26))] = strings.Repeat(string(rune('A'+g%26)), 100+g)
+					}
+					for i := 0; i < 150 && time.Now().Before(stop); i++ {
+						fields["seq"] = i
+						Info("concurrent write", fields)
+					}
+				}(g)
+			}
+			wg.Wait()
+			Close()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == 0 || data[len(data)-1] != '\n' {
+				t.Fatalf("log file should end with a complete line")
+			}
+			lines, written := bytes.Split(data[:len(data)-1], []byte("\n")), 0
+			for i, line := range lines {
+				var m map[string]interface{}
+				if err := json.Unmarshal(line, &m); err != nil {
+					t.Fatalf("line %d is not a standalone JSON object (%v): %.200q", i, err, line)
+				}
+				if m["message"] == "concurrent write" {
+					if len(m) < 44 {
+						t.Fatalf("line %d lost fields: %.200q", i, line)
+					}
+					written++
+				}
+			}
+			if written == 0 {
+				t.Fatalf("no entries written")
+			}
+			t.Logf("%d entries, %d bytes", written, len(data))
+		})
+	}
+}
+
+func TestLogFileDiscardPartial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lf, err := openLogFile(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.file.Close()
+	lf.Write([]byte("{\"message\":\"complete\"}\n"))
+
+	// 模拟写入到一半失败：截掉写入的部分
+	offset := lf.size
+	lf.file.Write([]byte(`{"message":"torn`))
+	if n := lf.discardPartial(offset, len(`{"message":"torn`)); n != 0 {
+		t.Errorf("partial write should be removed, %d bytes left", n)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{\"message\":\"complete\"}\n" {
+		t.Errorf("unexpected file content %q", data)
+	}
+
+	// 文件已被外部追加时不能截断，补一个换行结束这半行
+	lf.file.Write([]byte(`{"message":"torn`))
+	lf.file.Write([]byte("{\"external\":true}\n"))
+	if n := lf.discardPartial(offset, len(`{"message":"torn`)); n != len(`{"message":"torn`)+1 {
+		t.Errorf("torn line should be terminated, got %d", n)
+	}
+	if data, _ := os.ReadFile(path); !bytes.HasSuffix(data, []byte("{\"external\":true}\n\n")) {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
@@ -0,0 +1,172 @@
+// @Author Clover
+// @Data 2026/10/17 上午9:30:00
+// @Desc 检测外部对日志文件的截断、追加、删除与重命名
+
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchPollInterval 未配置 MonitorInterval 时轮询日志文件的间隔
+const defaultWatchPollInterval = time.Second
+
+// 外部变更的类型
+const (
+	externalTruncated = "truncated" // 文件被截断，例如 > app.log
+	externalAppended  = "appended"  // 其他进程向文件追加了内容
+	externalRemoved   = "removed"   // 文件被删除
+	externalReplaced  = "replaced"  // 文件被重命名或替换为其他文件
+)
+
+// startWatch 通过 fsnotify 监听日志文件所在目录，并以 pollInterval 轮询作为兜底，已经启动时不重复启动
+// NFS 等平台上 fsnotify 可能收不到事件，此时依靠轮询发现变更
+func (lf *logFile) startWatch(pollInterval time.Duration) {
+	if lf.watchDone != nil {
+		return
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultWatchPollInterval
+	}
+	w, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = w.Add(filepath.Dir(lf.path)); err != nil {
+			w.Close()
+			w = nil
+		}
+	}
+	lf.watchDone = make(chan struct{})
+	lf.watchStopped = make(chan struct{})
+	go lf.watch(w, pollInterval)
+}
+
+// stopWatch 停止监听并等待监听协程退出
+func (lf *logFile) stopWatch() {
+	if lf.watchDone == nil {
+		return
+	}
+	close(lf.watchDone)
+	<-lf.watchStopped
+	lf.watchDone = nil
+}
+
+func (lf *logFile) watch(w *fsnotify.Watcher, pollInterval time.Duration) {
+	defer close(lf.watchStopped)
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+	)
+	if w != nil {
+		defer w.Close()
+		events, errs = w.Events, w.Errors
+	}
+	ticker := currentClock().NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	target := filepath.Clean(lf.path)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(ev.Name) == target {
+				lf.syncExternal()
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+		case <-ticker.C():
+			lf.syncExternal()
+		case <-lf.watchDone:
+			return
+		}
+	}
+}
+
+// syncExternal 对比文件路径、句柄与内部记录的大小，发现外部变更时同步状态，
+// 文件被删除或替换时重新打开，并输出一条 Info 日志
+func (lf *logFile) syncExternal() {
+	lf.mu.Lock()
+	if lf.file == nil {
+		lf.mu.Unlock()
+		return
+	}
+	handleInfo, err := lf.file.Stat()
+	if err != nil {
+		lf.mu.Unlock()
+		return
+	}
+	var change string
+	var reopenErr error
+	pathInfo, err := os.Stat(lf.path)
+	switch {
+	case os.IsNotExist(err):
+		change = externalRemoved
+	case err != nil:
+	case !os.SameFile(pathInfo, handleInfo):
+		change = externalReplaced
+	case handleInfo.Size() < lf.size:
+		change = externalTruncated
+		lf.startTime = now()
+		lf.resetFrame()
+		if lf.index != nil {
+			// 索引超出被截断的日志文件，按剩余的内容重新生成
+			reopenErr = lf.index.rebuild(lf.path)
+		}
+	case handleInfo.Size() > lf.size:
+		change = externalAppended
+	}
+	if change == externalRemoved || change == externalReplaced {
+		reopenErr = lf.reopen()
+	} else if change != "" {
+		lf.size = handleInfo.Size()
+	}
+	size := lf.size
+	lf.mu.Unlock()
+
+	if change == "" {
+		return
+	}
+	logger := currentLogger()
+	if reopenErr != nil {
+		logger.Error().Err(reopenErr).Str("path", lf.path).Str("change", change).Msg("Error reopening log file after external change")
+		return
+	}
+	logger.Info().Str("path", lf.path).Str("change", change).Int64("size", size).Msg("Log file changed externally")
+}
+
+// reopen 关闭当前句柄并按路径重新打开日志文件，调用方需持有 lf.mu
+func (lf *logFile) reopen() error {
+	if _, err := validLogPath(lf.path, true); err != nil {
+		return err
+	}
+	if lf.header != nil && lf.zw == nil {
+		// 文件已被删除或移走时以首行元数据创建新文件，路径上已有其他文件时直接使用
+		if _, err := installFileHeader(lf.path, lf.header.line(HeaderReasonExternalRecreate)); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(lf.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	lf.file.Close()
+	lf.file = f
+	lf.resetFrame()
+	lf.size = 0
+	if fi, err := f.Stat(); err == nil {
+		lf.size = fi.Size()
+	}
+	lf.startTime = logFileStartTime(f)
+	if lf.index != nil {
+		return lf.index.rebuild(lf.path)
+	}
+	return nil
+}
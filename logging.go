@@ -5,29 +5,41 @@
 package logging
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 )
 
 const (
 	defaultProjectKey = "project"
+	timeFormat        = "2006-01-02 15:04:05" // 日志时间格式
+)
+
+// 日志文件输出格式
+const (
+	FileFormatJSON    = "json"    // 单行 JSON（默认）
+	FileFormatConsole = "console" // 无颜色的易读格式，便于本地开发时直接查看文件
 )
 
 var (
-	logfile      *os.File
-	once         sync.Once
-	logPath      string              // 日志文件路径
-	ProjectKey   = defaultProjectKey // 项目唯一标识
-	projectName  string              // 项目名称
-	maxLogSize   int64               // 最大日志文件大小
-	monitorTimer *time.Ticker        // 日志大小监控计时器
+	logfile       *logFile            // 当前日志文件
+	logPath       string              // 日志文件路径
+	ProjectKey    = defaultProjectKey // 项目唯一标识
+	projectName   string              // 项目名称
+	skipNilErrors bool                // 是否跳过 err 为 nil 的错误日志
+	options       loggerOptions       // InitLogger 传入的额外选项
+	fileFormat    = FileFormatJSON    // 日志文件输出格式
+	consoleOutput bool                // 是否启用控制台输出
+	fileOutput    bool                // 是否启用文件输出
+	activeWriters []io.Writer         // 当前使用的输出目标
 )
 
 // Config 用于配置日志记录器
@@ -37,203 +49,587 @@ type Config struct {
 	ProjectName         string        // 项目名称
 	MaxLogSize          int64         // 最大日志文件大小 (字节)
 	MonitorInterval     time.Duration // 监控日志大小的间隔时间
+	MaxTotalLogBytes    int64         // 日志文件及其轮转副本（例如 logrotate 产生的 app.log.1）的总大小上限，超过时从最旧的副本开始删除，0 表示不限制
 	EnableConsoleOutput bool          // 是否启用控制台输出
 	EnableFileOutput    bool          // 是否启用文件输出
 	LogLevel            string        // 日志级别
+	SkipNilErrors       bool          // ErrorWithErr/WarnWithErr 传入 nil 错误时是否直接跳过该条日志
+	FileFormat          string        // 日志文件输出格式: json (默认) 或 console
+	MaxFileAge          time.Duration // 单个日志文件的最长使用时间，超过后清除日志文件，0 表示不限制
+	AllowedFields       []string      // 字段白名单，非空时不在名单中的字段会被丢弃
+	AllowUnrestricted   bool          // 是否允许通过 Unrestricted 获取不受字段白名单限制的日志记录器
+
+	ConsoleLevelLabels map[zerolog.Level]string // 控制台输出使用的级别名称，例如 {zerolog.InfoLevel: "信息"}，不影响文件输出
+	MessageTranslator  func(msg string) string  // 控制台输出前对消息进行翻译，文件中保留原始消息
+	ConsoleFormatters  ConsoleFormatters        // 控制台输出各部分的自定义格式化函数，例如为级别添加 emoji
+	DisableMetrics     bool                     // 是否关闭 ErrorCount 等日志数量统计以及 Stats 的耗时统计
+	StatsSampleRate    int                      // Stats 每多少条日志计时一次，默认 64，1 表示每条都计时，负数表示不计时
+	DisableInterning   bool                     // 是否关闭重复出现的短字符串字段值的驻留，关闭后每条日志都重新编码字段值
+	ConsoleMultiline   bool                     // 是否在控制台中将 stack 及 MarkMultiline 标记的字段缩进输出在主日志行下方
+
+	Outputs []OutputConfig // 额外的日志文件，可以使用不同的格式与级别，与 LogPath 同时输出
+
+	EnableEventID     bool // 是否为每条日志添加 crypto/rand 生成的 UUID4 event_id 字段
+	EnableFastEventID bool // 是否使用单调递增的十六进制序号作为 event_id，开销更低，优先于 EnableEventID
+
+	WatchExternalChanges bool // 是否监听日志文件被外部截断、删除或重命名，并同步状态、重新打开文件
+
+	IndexInterval int64 // 每写入多少字节在 <LogPath>.idx 中记录一次偏移与时间，供 ReadEntriesBetween 与导出快速定位时间范围，0 表示不建索引；不支持 CompressActive
+
+	// ExclusiveCreate 是否以 O_EXCL 创建日志文件，避免同时启动的多个进程争相创建并覆盖同一文件；
+	// 文件已存在时不写入该文件，InitLogger 返回 errors.Is(err, os.ErrExist) 的错误由调用方处理。
+	// 与追加写入已有文件（O_APPEND 的用法）不兼容，每次启动需要使用新的 LogPath，不能与 AllowReinit 同时开启
+	ExclusiveCreate bool
+
+	// FileHeader 是否在 LogPath 每个新文件的第一行写入 {"log_header":{...}} 元数据：格式版本、项目、进程启动时间、
+	// 程序版本与提交、主机名以及新文件的原因（initial、rotation、size-clear、external-recreate）；
+	// 追加写入已有文件时不写入，只支持 JSON 格式且未开启 CompressActive 的日志文件，由 ReadEntriesWithHeader 读取
+	FileHeader bool
+
+	EnableWindowsEventLog bool   // 是否同时写入 Windows 事件日志，仅在 Windows 上可用
+	EventSource           string // 事件日志的事件来源，需要预先注册，为空时使用 ProjectName
+
+	UnixSocket UnixSocketConfig // 同时写入本地日志守护进程（如 syslog-ng）监听的 UNIX 域套接字
+
+	// RelayTo 聚合进程 ListenRelay 监听的套接字路径，设置后日志转发给聚合进程，不再打开 LogPath 与 Outputs；
+	// 聚合进程不可用时日志写入本地控制台（已开启 EnableConsoleOutput 时不重复输出），并缓存最近的日志在重新连接后补发
+	RelayTo         string
+	RelayBufferSize int // 聚合进程不可用时最多缓存的日志条数，超出时丢弃最早的日志，默认 1024
+
+	AllowReinit bool // 重复调用 InitLogger 时是否关闭之前的日志文件并重新初始化，否则返回 ErrAlreadyInitialized
+
+	FieldPrefix       string               // 用户字段名的前缀，例如 "app_" 将 user_id 输出为 app_user_id
+	ReservedKeyPolicy ReservedKeyPolicy    // 用户字段与 time、level、message、ProjectKey 等字段重名时的处理方式: rename (默认)、drop 或 allow
+	FieldTypes        map[string]FieldType // 字段的目标类型，写入时转换，转换失败时添加 coerce_failed_<key>=true
+	FloatPrecision    int                  // 浮点数字段保留的小数位数，0 表示不限制
+
+	DurationUnit    string // 时长字段的输出单位: ms、s 或 string，为空时输出纳秒整数
+	TimeFieldLayout string // time.Time 字段的输出格式，为空时使用 RFC3339Nano
+
+	DryRun bool // 完整执行日志处理流程但不写入任何输出目标，通过 DryRunReport 获取本应写入的内容
+
+	InactivityWarning time.Duration // 超过该时间没有日志输出时输出一条 Warn 日志，0 表示不检查
+
+	CompressActive        bool          // 是否以 zstd 流写入日志文件，文件名自动添加 .zst 后缀
+	CompressFlushInterval time.Duration // 压缩时结束 zstd 帧的间隔，进程崩溃时最多丢失该间隔内的日志，默认 1 秒
+
+	Clock Clock // 内部使用的时钟，为 nil 时使用真实时间
+	Rand  Rand  // 内部使用的随机数来源，为 nil 时使用 math/rand
+
+	EnvOverrides map[string]string // 环境变量名到 Config 字段名的映射，例如 {"LOG_LEVEL": "LogLevel"}，已设置的环境变量覆盖对应字段
+
+	ExtractPatterns []ExtractRule // 从匹配的日志消息中提取字段，按顺序使用第一条匹配的规则
+
+	MonotonicTime bool // 是否为每条日志添加距进程启动的单调毫秒数 mono_ms，并在系统时间回退时输出一次 clock_skew_detected
+
+	DiodeMode      bool          // 是否通过 zerolog/diode 在后台写入各输出目标，日志调用不再等待写入，跟不上时丢弃最早的日志
+	AsyncQueueSize int           // DiodeMode 下每个输出目标的 diode 容量，默认 1000
+	DrainTimeout   time.Duration // Drain 等待异步输出目标写完的时限，默认 5 秒
+
+	MaxFieldsPerEvent int // 单条日志最多保留的字段数，超出时按键名排序丢弃后面的字段并添加 fields_truncated，0 表示不限制
+	MaxEventBytes     int // 单条日志消息与字段编码后的最大字节数（估算），超出时省略全部字段并添加 event_too_large，0 表示不限制
+
+	CaptureGlobalZerolog bool // 是否将 zerolog 的全局 log.Logger 转发到本包的输出目标，转发的日志带有 via=global；关闭时不修改 log.Logger
+
+	InjectBuildInfo bool // 是否为每条日志添加 build_info 字段，包含 debug.ReadBuildInfo 读取的 Go 版本、主模块与依赖版本
+	InjectHostname  bool // 是否为每条日志添加启动时获取的 hostname 字段，获取失败时为 unknown
+	InjectIPAddress bool // 是否为每条日志添加启动时获取的 ip_address 字段（第一个非回环的 IPv4 地址），获取失败时为 unknown
+
+	ElevationRules   []ElevationRule // 匹配的日志使用规则中的级别作为最低级别，例如 tenant_id 为 acme 时输出 Debug 日志
+	DebugTokenSecret string          // ElevationMiddleware 校验 X-Debug-Token 请求头使用的密钥，为空时不提升
+
+	Escalations []Escalation // 匹配的 Warn 日志在窗口内超过指定数量时额外输出一条 Error（或指定级别）的汇总日志
+
+	// FieldRenames 输出前重命名字段，例如 {"user_id": "userId"} 兼容依赖旧字段名的看板；在白名单、前缀、保留字段处理与脱敏之后执行，
+	// 对日志函数、SetField 与 LogBuffer 的条目都生效。链式的规则（a->b、b->c）展开为最终字段名，构成循环的规则被忽略；
+	// 重命名后的字段名已存在时保留已有的字段，丢弃被重命名的字段并在 rename_dropped 中记录原字段名
+	FieldRenames map[string]string
+
+	StackCaptureLevel zerolog.Level // Recover 等记录调用栈的最低级别，低于该级别的日志不捕获调用栈，零值（Debug）表示总是捕获
+
+	// Profile 配置预设: default (默认) 或 minimal。minimal 适用于内存很小的设备：同步写入，缩小异步队列与转发缓存，
+	// Fatal 以下不捕获调用栈，并严格限制单条日志的字段数与大小；预设只填充未设置的字段，显式设置的字段优先，
+	// ConfigSnapshot 中由预设填充的字段的来源为 profile
+	Profile string
+}
+
+// LoggerOption 用于在 InitLogger 时对日志记录器进行额外配置
+type LoggerOption func(*loggerOptions)
+
+type loggerOptions struct {
+	writeTimeout time.Duration     // 单次写入的超时时间，0 表示不限制
+	writers      []io.Writer       // 额外的输出目标
+	maskers      []Masker          // 输出前处理消息与字符串字段的 Masker
+	observers    []WriteObserver   // 写入耗时的观察者
+	envFields    map[string]string // InitLoggerFromEnv 由环境变量设置的字段（字段名到环境变量名）
+}
+
+// WithWriters 添加额外的输出目标，例如 redissink.Sink
+func WithWriters(ws ...io.Writer) LoggerOption {
+	return func(o *loggerOptions) {
+		o.writers = append(o.writers, ws...)
+	}
+}
+
+// WithWriteObserver 添加写入耗时的观察者，每次写入输出目标后报告级别、输出目标类型与耗时，例如 promhook.WriterLatencyHook
+func WithWriteObserver(obs ...WriteObserver) LoggerOption {
+	return func(o *loggerOptions) {
+		o.observers = append(o.observers, obs...)
+	}
+}
+
+// withEnvFields 记录 InitLoggerFromEnv 由环境变量设置的字段，供 ConfigSnapshot 标记来源
+func withEnvFields(fields map[string]string) LoggerOption {
+	return func(o *loggerOptions) {
+		o.envFields = fields
+	}
+}
+
+// WithWriteTimeout 为每个输出目标设置写入超时，超时后将截断的日志写入 os.Stderr 并立即返回
+func WithWriteTimeout(d time.Duration) LoggerOption {
+	return func(o *loggerOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// wrapWriter 按照选项包装输出目标
+func wrapWriter(w io.Writer) io.Writer {
+	if options.writeTimeout > 0 {
+		w = newTimeoutWriter(w, options.writeTimeout)
+	}
+	if diodeMode {
+		dw := newDiodeWriter(w, asyncQueueSize)
+		diodes = append(diodes, dw)
+		w = dw
+	}
+	return w
 }
 
 // InitLogger 初始化日志记录器
-func InitLogger(config Config) {
+// 重复初始化时，开启 Config.AllowReinit 会先关闭之前的日志文件，否则返回 ErrAlreadyInitialized；
+// Config.Outputs 中的文件打开失败时不影响其他输出，返回所有打开失败的错误
+func InitLogger(config Config, opts ...LoggerOption) error {
+	ensureLogger()
+	initMu.Lock()
+	defer initMu.Unlock()
+	envFields, envErrs := applyEnvOverrides(&config)
+	profileFields, knownProfile := applyProfile(&config)
+	if err := validExclusiveCreate(config); err != nil {
+		return err
+	}
+	if initialized {
+		if !config.AllowReinit {
+			return ErrAlreadyInitialized
+		}
+		shutdown()
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	options = loggerOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	activeConfig = config
+	for field, env := range options.envFields {
+		envFields[field] = env
+	}
+	activeEnvFields = envFields
+	activeProfileFields = profileFields
 	logPath = config.LogPath
+	if config.CompressActive && !isCompressedPath(logPath) {
+		logPath += compressedExt
+	}
 	ProjectKey = config.ProjectKey
 	projectName = config.ProjectName
-	maxLogSize = config.MaxLogSize
+	skipNilErrors = config.SkipNilErrors
+	fileFormat = FileFormatJSON
+	setAllowedFields(config.AllowedFields, config.AllowUnrestricted)
+	consoleLevelLabels = config.ConsoleLevelLabels
+	metricsEnabled.Store(!config.DisableMetrics)
+	setStatsSampleRate(config.StatsSampleRate, config.DisableMetrics)
+	setInterning(!config.DisableInterning)
+	setEventIDMode(config.EnableEventID, config.EnableFastEventID)
+	setMonotonicTime(config.MonotonicTime)
+	messageTranslator = config.MessageTranslator
+	consoleFormatters = config.ConsoleFormatters
+	consoleMultiline = config.ConsoleMultiline
+	durationUnit = ""
+	if validDurationUnit(config.DurationUnit) {
+		durationUnit = config.DurationUnit
+	}
+	timeFieldLayout = config.TimeFieldLayout
+	setClock(config.Clock, config.Rand)
+	fieldPrefix = config.FieldPrefix
+	reservedKeyPolicy = ReservedKeyRename
+	if config.ReservedKeyPolicy != "" && validReservedKeyPolicy(config.ReservedKeyPolicy) {
+		reservedKeyPolicy = config.ReservedKeyPolicy
+	}
+	fieldTypes = config.FieldTypes
+	floatPrecision = config.FloatPrecision
+	diodeMode = config.DiodeMode
+	asyncQueueSize = config.AsyncQueueSize
+	if asyncQueueSize <= 0 {
+		asyncQueueSize = defaultAsyncQueueSize
+	}
+	drainTimeout = config.DrainTimeout
+	relayBufferSize = config.RelayBufferSize
+	if relayBufferSize <= 0 {
+		relayBufferSize = defaultRelayBufferSize
+	}
+	stackCaptureLevel.Store(int32(config.StackCaptureLevel))
+	maxFieldsPerEvent.Store(int64(config.MaxFieldsPerEvent))
+	maxEventBytes.Store(int64(config.MaxEventBytes))
+	setElevationRules(config.ElevationRules)
+	setEscalations(config.Escalations)
+	debugTokenSecret = config.DebugTokenSecret
+	var extractErrs []error
+	extractRules, extractErrs = compileExtractRules(config.ExtractPatterns)
+	var renameErrs []error
+	fieldRenames, renameErrs = compileRenames(config.FieldRenames)
+
+	// 与 init 中的设置相同时不再写入，避免与正在输出的日志产生数据竞争
+	if zerolog.TimeFieldFormat != timeFormat {
+		zerolog.TimeFieldFormat = timeFormat
+	}
 
-	zerolog.TimeFieldFormat = "2006-01-02 15:04:05"
+	consoleOutput = config.EnableConsoleOutput
+	fileOutput = config.EnableFileOutput && config.RelayTo == ""
 
-	var writers []io.Writer
+	var invalidFileFormat bool
+	switch config.FileFormat {
+	case "", FileFormatJSON:
+	case FileFormatConsole:
+		fileFormat = FileFormatConsole
+	default:
+		invalidFileFormat = true
+	}
 
-	if config.EnableConsoleOutput {
-		writers = append(writers, zerolog.ConsoleWriter{Out: os.Stderr})
+	dryRun = nil
+	var outputErr, indexErr error
+	if config.DryRun {
+		dryRun = newDryRunRecorder(config, options.writers)
+	} else {
+		if fileOutput {
+			var header *FileHeader
+			if config.FileHeader && !config.CompressActive && fileFormat == FileFormatJSON {
+				header = newFileHeader(projectName, ProjectKey)
+			}
+			var err error
+			logfile, err = openMainLogFile(logPath, config, header)
+			switch {
+			case err != nil && config.ExclusiveCreate:
+				// 文件已由其他进程创建，不终止进程，由调用方决定如何处理
+				outputErr = errors.Join(outputErr, err)
+				fileOutput = false
+			case err != nil:
+				baseLogger.Fatal().Err(err).Msg("Failed to open log file")
+			}
+		}
+		if logfile != nil && config.CompressActive {
+			if err := logfile.enableCompression(); err != nil {
+				baseLogger.Fatal().Err(err).Msg("Failed to enable log file compression")
+			}
+		}
+		if logfile != nil && config.IndexInterval > 0 && !config.CompressActive && fileFormat == FileFormatJSON {
+			indexErr = logfile.enableIndex(config.IndexInterval)
+		}
+
+		if config.RelayTo == "" {
+			var err error
+			outputs, err = openOutputs(config, logfile)
+			outputErr = errors.Join(outputErr, err)
+		} else {
+			var fallback io.Writer
+			if !consoleOutput {
+				fallback = newGuardedConsole(newConsoleWriter(os.Stderr, false), os.Stderr)
+			}
+			// 聚合进程尚未启动时仍保留输出，之后会重新连接
+			var err error
+			if relay, err = newRelayWriter(config.RelayTo, config.ProjectName, fallback); err != nil {
+				outputErr = errors.Join(outputErr, err)
+			}
+		}
+		if config.EnableWindowsEventLog {
+			source := eventLogSource(config)
+			var err error
+			if eventLog, err = openEventLog(source); err != nil {
+				outputErr = errors.Join(outputErr, fmt.Errorf("windows event log %s: %w", source, err))
+			}
+		}
+		if config.UnixSocket.SocketPath != "" {
+			if err := validUnixSocketConfig(config.UnixSocket); err != nil {
+				outputErr = errors.Join(outputErr, err)
+			} else {
+				// 连接失败时仍保留输出，守护进程启动后的写入会重新连接
+				var err error
+				if unixSocket, err = newUnixSocketWriter(config.UnixSocket); err != nil {
+					outputErr = errors.Join(outputErr, err)
+				}
+			}
+		}
 	}
 
-	if config.EnableFileOutput {
-		_, err := validLogPath(logPath, true)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to validate log path")
+	multi := zerolog.MultiLevelWriter(buildWriters()...)
+	// 设置基础日志记录器的输出、时间戳和项目名称字段
+	ctx := rootLogger.Output(multi).With().Timestamp().Str(ProjectKey, projectName)
+	var injected []string
+	if config.InjectBuildInfo {
+		if info := buildInfoDict(); info != nil {
+			ctx = ctx.Dict(buildInfoKey, info)
+			injected = append(injected, buildInfoKey)
 		}
+	}
+	var hostErrs []error
+	if config.InjectHostname {
+		name, err := hostname()
+		ctx = ctx.Str(hostnameKey, name)
+		hostErrs = append(hostErrs, err)
+		injected = append(injected, hostnameKey)
+	}
+	if config.InjectIPAddress {
+		ip, err := ipAddress()
+		ctx = ctx.Str(ipAddressKey, ip)
+		hostErrs = append(hostErrs, err)
+		injected = append(injected, ipAddressKey)
+	}
+	baseLogger, injectedKeys = ctx.Logger(), injected
+	contextDroppedFields = nil
+	if config.CaptureGlobalZerolog {
+		captureGlobalZerolog()
+	}
 
-		logfile, err = os.OpenFile(logPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	for _, err := range envErrs {
+		baseLogger.Warn().Err(err).Msg("Ignoring environment override")
+	}
+	for _, err := range hostErrs {
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error opening log file")
+			baseLogger.Warn().Err(err).Msgf("Failed to resolve host information, using %q", unknownHost)
 		}
-		writers = append(writers, logfile)
 	}
-
-	multi := zerolog.MultiLevelWriter(writers...)
-	// 直接使用 log.Logger 作为基础日志记录器，并设置输出、时间戳和项目名称字段
-	log.Logger = log.Output(multi).With().Timestamp().Str(ProjectKey, projectName).Logger()
+	for _, o := range outputs {
+		if o.aliasOf != "" {
+			baseLogger.Warn().Str("path", o.config.Path).Str("shared_with", o.aliasOf).
+				Msg("Output refers to the same file as another log path, sharing one file writer and size counter")
+		}
+	}
+	if indexErr != nil {
+		baseLogger.Warn().Err(indexErr).Msg("Failed to open log index, time range reads will scan the whole log file")
+	}
+	for _, err := range extractErrs {
+		baseLogger.Warn().Err(err).Msg("Ignoring invalid extract pattern")
+	}
+	for _, err := range renameErrs {
+		baseLogger.Warn().Err(err).Msg("Ignoring field renames that form a cycle")
+	}
+	if !knownProfile {
+		baseLogger.Warn().Msgf("Unknown profile '%s', using profile: %s", config.Profile, ProfileDefault)
+	}
+	if invalidFileFormat {
+		baseLogger.Warn().Msgf("Unknown file format '%s', using default format: %s", config.FileFormat, FileFormatJSON)
+	}
+	if !validReservedKeyPolicy(config.ReservedKeyPolicy) {
+		baseLogger.Warn().Msgf("Unknown reserved key policy '%s', using default policy: %s", config.ReservedKeyPolicy, ReservedKeyRename)
+	}
+	if !validDurationUnit(config.DurationUnit) {
+		baseLogger.Warn().Msgf("Unknown duration unit '%s', logging durations as nanoseconds", config.DurationUnit)
+	}
 
 	// 设置日志级别
 	if config.LogLevel != "" { // 只有当配置中LogLevel不为空时才尝试设置，避免覆盖 SetLogLevel 的设置
 		level, err := zerolog.ParseLevel(config.LogLevel)
 		if err != nil {
-			log.Warn().Msgf("Failed to parse log level '%s', using default level: Info", config.LogLevel)
+			baseLogger.Warn().Msgf("Failed to parse log level '%s', using default level: Info", config.LogLevel)
 		} else {
 			zerolog.SetGlobalLevel(level)
-			log.Info().Msgf("Log level set to %s from config", level.String())
+			baseLogger.Info().Msgf("Log level set to %s from config", level.String())
+			// 配置中显式开启 Debug 时输出实际生效的配置，便于排查配置问题
+			if level <= zerolog.DebugLevel {
+				baseLogger.Debug().Interface("config", configSnapshot()).Msg("Effective logging configuration")
+			}
 		}
 	}
-	if config.EnableFileOutput && config.MonitorInterval > 0 {
-		monitorTimer = time.NewTicker(config.MonitorInterval)
-		go monitorLogSize(monitorTimer.C)
-	}
-}
-
-// SetField 设置字段信息k-v
-func SetField(fields map[string]interface{}) {
-	// 直接使用 log.Logger
-	tmpLogger := log.With().Fields(fields).Logger()
-	log.Logger = tmpLogger // 设置
-}
-
-// monitorLogSize 监控日志文件大小并在超过限制时清除日志文件
-func monitorLogSize(ticker <-chan time.Time) {
-	for range ticker {
-		// Get the current log file size
-		fi, err := logfile.Stat()
-		if err != nil {
-			log.Error().Err(err).Msg("Error getting file info")
-			continue
-		}
-
-		if fi.Size() > maxLogSize {
-			log.Info().Msg("Log file size exceeds limit. Clearing log file.")
-			clearLogFile()
+	if logfile != nil {
+		logfile.startMonitor(config.MonitorInterval)
+		logfile.startFlusher(config.CompressFlushInterval)
+		if config.WatchExternalChanges {
+			logfile.startWatch(config.MonitorInterval)
 		}
 	}
+	inactivity = startInactivityMonitor(config.InactivityWarning)
+	retention = startRetention(config.MaxTotalLogBytes, config.MonitorInterval, manifestPaths())
+	startedAt = now()
+	initialized = true
+	return outputErr
 }
 
-func clearLogFile() {
-	var err error
-	if err = logfile.Close(); err != nil {
-		log.Error().Err(err).Msg("Error closing log file before truncation")
-		return
+// buildWriters 根据当前配置创建日志输出目标
+// DryRun 模式下所有输出目标都由记录器代替
+func buildWriters() []io.Writer {
+	if dryRun != nil {
+		activeWriters = dryRun.writers()
+		return activeWriters
 	}
-
-	// Truncate the log file to clear its content
-	if err := os.Truncate(logPath, 0); err != nil {
-		log.Error().Err(err).Msg("Error truncating log file")
-		return
+	diodes = nil
+	var writers []io.Writer
+	if consoleOutput {
+		writers = append(writers, newTrackedWriter("console", wrapWriter(newGuardedConsole(newConsoleWriter(os.Stderr, false), os.Stderr))))
 	}
-
-	// Reopen the log file
-	logfile, err = os.OpenFile(logPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Error reopening log file after truncation")
-		return
+	if fileOutput && logfile != nil {
+		writers = append(writers, newTrackedWriter("file:"+logPath, wrapWriter(newFileWriter(logfile))))
+	}
+	for i, w := range outputWriters() {
+		writers = append(writers, newTrackedWriter("output:"+outputs[i].config.Path, w))
+	}
+	if eventLog != nil {
+		writers = append(writers, newTrackedWriter("eventlog:"+eventLogSource(activeConfig), eventLogWriter{el: eventLog}))
+	}
+	if unixSocket != nil {
+		writers = append(writers, newTrackedWriter("unix:"+unixSocket.path, wrapWriter(unixSocket)))
+	}
+	if relay != nil {
+		writers = append(writers, newTrackedWriter("relay:"+relay.path, wrapWriter(relay)))
 	}
+	for _, w := range options.writers {
+		writers = append(writers, newTrackedWriter(sinkName(w), wrapWriter(w)))
+	}
+	activeWriters = writers
+	return writers
+}
 
-	// Update the zerolog writer with the new file descriptor
-	writers := []io.Writer{zerolog.ConsoleWriter{Out: os.Stderr}}
-	if logfile != nil {
-		writers = append(writers, logfile)
+// newFileWriter 按照配置的文件格式创建日志文件的输出
+func newFileWriter(f *logFile) io.Writer {
+	if fileFormat == FileFormatConsole {
+		// 文件中不允许出现颜色控制字符
+		return zerolog.ConsoleWriter{Out: f, NoColor: true, TimeFormat: zerolog.TimeFieldFormat}
 	}
-	multi := zerolog.MultiLevelWriter(writers...)
-	// 直接更新 log.Logger 的输出
-	log.Logger = log.Output(multi).With().Timestamp().Str("sdk", projectName).Logger()
+	return f
+}
 
-	log.Info().Msg("Log file cleared successfully.")
+// SetField 设置字段信息k-v
+func SetField(fields map[string]interface{}) {
+	std.SetField(fields)
 }
 
-// Close 关闭日志文件和监控计时器
+// Close 关闭日志文件和监控计时器，之后可以重新调用 InitLogger
 func Close() {
-	once.Do(func() {
-		if logfile != nil {
-			err := logfile.Close()
-			if err != nil {
-				log.Error().Msgf("Error closing log file: %v", err)
-			}
-			logfile = nil
-		}
-		if monitorTimer != nil {
-			monitorTimer.Stop()
-		}
-	})
+	initMu.Lock()
+	defer initMu.Unlock()
+	if !initialized {
+		return
+	}
+	shutdown()
+	initialized = false
 }
 
-// Info 定义简化的日志函数
+// Info 定义简化的日志函数，委托给默认实例
 func Info(msg string, fields ...map[string]interface{}) {
-	event := log.Info()
-	for _, field := range fields {
-		for k, v := range field {
-			event = event.Interface(k, v)
-		}
-	}
-	event.Msg(msg)
+	std.Info(msg, fields...)
 }
 
 func Error(msg string, fields ...map[string]interface{}) {
-	event := log.Error()
-	for _, field := range fields {
-		for k, v := range field {
-			event = event.Interface(k, v)
-		}
-	}
-	event.Msg(msg)
+	std.Error(msg, fields...)
 }
 
 func ErrorWithErr(err error, msg string, fields ...map[string]interface{}) {
-	event := log.Error()
-	event.Err(err)
-	for _, field := range fields {
-		for k, v := range field {
-			event = event.Interface(k, v)
-		}
-	}
-	event.Msg(msg)
+	std.ErrorWithErr(err, msg, fields...)
 }
 
 func Debug(msg string, fields ...map[string]interface{}) {
-	event := log.Debug()
-	for _, field := range fields {
-		for k, v := range field {
-			event = event.Interface(k, v)
-		}
-	}
-	event.Msg(msg)
+	std.Debug(msg, fields...)
 }
 
 func Warn(msg string, fields ...map[string]interface{}) {
-	event := log.Warn()
-	for _, field := range fields {
-		for k, v := range field {
-			event = event.Interface(k, v)
-		}
-	}
-	event.Msg(msg)
+	std.Warn(msg, fields...)
 }
 
 func WarnWithErr(err error, msg string, fields ...map[string]interface{}) {
-	event := log.Warn()
-	event.Err(err)
-	for _, field := range fields {
-		for k, v := range field {
-			event = event.Interface(k, v)
+	std.WarnWithErr(err, msg, fields...)
+}
+
+// Fatal 同步输出 Logger 中缓冲的日志与 Fatal 级别日志，随后以 exitCode 退出进程；
+// 设置了 SetFatalHandler 时由处理函数决定立即退出、延迟退出或降级为 Error 后继续运行
+func Fatal(msg string, exitCode int, fields ...map[string]interface{}) {
+	std.Fatal(msg, exitCode, fields...)
+}
+
+// emit 为事件添加字段，触发对应级别的回调后输出日志，event 由 currentLogger() 创建
+func emit(event *zerolog.Event, level zerolog.Level, err error, msg string, fields []map[string]interface{}) {
+	emitFrom(nil, event, level, err, msg, fields)
+}
+
+// emitFrom 与 emit 相同，logger 为创建 event 的日志记录器，为 nil 时表示 currentLogger()；
+// 告警被 Config.Escalations 提升时从 logger 重新创建事件，保留它的上下文字段与输出目标
+func emitFrom(logger *zerolog.Logger, event *zerolog.Event, level zerolog.Level, err error, msg string, fields []map[string]interface{}) {
+	if event == nil { // 当前级别未启用，匹配 Config.ElevationRules 时仍然输出
+		if event = elevateByRules(level, err, msg, fields); event == nil {
+			return
 		}
 	}
+	timer := startEmitTimer()
+	var merged map[string]interface{}
+	switch len(fields) {
+	case 0:
+	case 1:
+		merged = fields[0]
+	default:
+		merged = mergeFields(fields)
+	}
+	merged, truncated := limitFields(merged)
+	merged = withDynamicFields(merged)
+	if event, level = downgradeSuppressed(event, level, err, msg, merged); event == nil {
+		return
+	}
+	var bursts []escalationBurst
+	event, level, bursts = escalateRepeated(logger, event, level, err, msg, merged)
+	stateMu.RLock()
+	msg, merged = applyExtractRules(level, msg, merged)
+	merged = withContextDropped(reserveErrorKeys(applyFieldRules(merged), err))
+	timer.stage(&assembleHist)
+	event, msg = writeBoundedFields(event, msg, merged, truncated)
+	msg = maskString(msg)
+	stateMu.RUnlock()
+	timer.stage(&encodeHist)
+	if hasLevelHooks(level) {
+		entry := LogEntry{Level: level, Message: msg, Fields: mergeFields([]map[string]interface{}{merged})}
+		if err != nil {
+			entry.Fields[zerolog.ErrorFieldName] = err
+		}
+		runLevelHooks(entry)
+	}
 	event.Msg(msg)
+	timer.done()
+	emitEscalations(bursts)
 }
 
-func Fatal(msg string, exitCode int, fields ...map[string]interface{}) {
-	event := log.Fatal()
+// mergeFields 合并多个字段集合
+func mergeFields(fields []map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
 	for _, field := range fields {
 		for k, v := range field {
-			event = event.Interface(k, v)
+			merged[k] = v
 		}
 	}
-	event.Msg(msg)
-	os.Exit(exitCode)
+	return merged
+}
+
+// withErr 为事件添加错误及其类型元数据，err 为 nil 时不添加错误字段
+func withErr(event *zerolog.Event, err error) *zerolog.Event {
+	if err == nil {
+		return event
+	}
+	event = event.Err(err).Str("error_type", fmt.Sprintf("%T", err))
+	var coder interface{ Code() int }
+	if errors.As(err, &coder) {
+		event = event.Int("error_code", coder.Code())
+	}
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		event = event.Bool("temporary", temporary.Temporary())
+	}
+	return event
 }
 
 func validLogPath(path string, isCreate bool) (bool, error) {
@@ -262,9 +658,10 @@ type LogEntry struct {
 
 // LogBuffer 用于存储日志的缓冲区
 type LogBuffer struct {
-	entries []LogEntry
-	mu      sync.Mutex
-	active  bool // 是否激活缓冲模式
+	entries       []LogEntry
+	mu            sync.Mutex
+	active        bool          // 是否激活缓冲模式
+	flushDeadline time.Duration // Flush 等待异步输出目标的总时限，0 表示使用默认值
 }
 
 // NewLogBuffer 创建一个新的日志缓冲区
@@ -283,23 +680,134 @@ func (lb *LogBuffer) AddEntry(entry LogEntry) {
 		lb.entries = append(lb.entries, entry)
 	} else {
 		// 直接输出日志
-		evt := log.WithLevel(entry.Level).Fields(entry.Fields)
-		evt.Msg(entry.Message)
+		writeEntry(entry)
 	}
 }
 
-// Flush 清空缓冲区，并根据日志等级输出日志
-func (lb *LogBuffer) Flush(minLevel zerolog.Level) {
+// Peek 返回缓冲区中日志条目的副本，不会清空缓冲区
+func (lb *LogBuffer) Peek() []LogEntry {
 	lb.mu.Lock()
 	defer lb.mu.Unlock()
+	entries := make([]LogEntry, len(lb.entries))
+	copy(entries, lb.entries)
+	return entries
+}
+
+// Flush 清空缓冲区，并根据日志等级输出日志，返回已输出与丢弃的条数
+// 存在异步输出目标时分批输出，每批之前等待其队列腾出空间；超过 SetFlushDeadline 设置的总时限后
+// 剩余的日志不再输出并计入丢弃的条数，避免异步队列溢出时悄悄丢掉最早的日志
+func (lb *LogBuffer) Flush(minLevel zerolog.Level) (flushed, dropped int) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	entries := make([]LogEntry, 0, len(lb.entries))
 	for _, entry := range lb.entries {
 		if entry.Level >= minLevel {
-			evt := log.WithLevel(entry.Level).Fields(entry.Fields)
-			evt.Msg(entry.Message)
+			entries = append(entries, entry)
 		}
 	}
 	// 清空缓冲区
 	lb.entries = make([]LogEntry, 0)
+	return lb.replay(entries)
+}
+
+// FlushBatch 最多输出 batchSize 条不低于 minLevel 的日志，返回已输出与剩余的条数
+// 与 Flush 相同，低于 minLevel 的日志会被丢弃；剩余的日志保留在缓冲区中，
+// 调用方可以循环调用并在批次之间等待，实现限速发送
+func (lb *LogBuffer) FlushBatch(batchSize int, minLevel zerolog.Level) (flushed int, remaining int) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	kept := make([]LogEntry, 0, len(lb.entries))
+	for _, entry := range lb.entries {
+		if entry.Level < minLevel {
+			continue
+		}
+		if flushed < batchSize {
+			writeEntry(entry)
+			flushed++
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	lb.entries = kept
+	return flushed, len(kept)
+}
+
+// FlushContext 与 Flush 相同，但在输出每条日志前检查 ctx，返回已输出的条数
+// ctx 结束时停止输出，尚未处理的日志保留在缓冲区中，并返回 ctx.Err()
+func (lb *LogBuffer) FlushContext(ctx context.Context, minLevel zerolog.Level) (int, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	flushed := 0
+	for i, entry := range lb.entries {
+		if err := ctx.Err(); err != nil {
+			lb.entries = append(make([]LogEntry, 0, len(lb.entries)-i), lb.entries[i:]...)
+			return flushed, err
+		}
+		if entry.Level >= minLevel {
+			writeEntry(entry)
+			flushed++
+		}
+	}
+	lb.entries = make([]LogEntry, 0)
+	return flushed, nil
+}
+
+// PrioritisedFlush 清空缓冲区，将日志按级别从高到低写入 w，同级别保持写入顺序
+// 用于容量有限的输出目标，保证错误日志先于大量调试日志到达
+func (lb *LogBuffer) PrioritisedFlush(w io.Writer) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	sort.SliceStable(lb.entries, func(i, j int) bool {
+		return lb.entries[i].Level > lb.entries[j].Level
+	})
+	logger := currentLogger().Output(w)
+	for _, entry := range lb.entries {
+		writeEntryTo(&logger, entry)
+	}
+	lb.entries = make([]LogEntry, 0)
+}
+
+// Partition 清空缓冲区，按 fn 将日志分配到两个新的缓冲区，保持写入顺序
+// 例如将错误日志分出来立即输出，其余的调试日志在操作成功后丢弃；新缓冲区沿用 SetFlushDeadline 的设置
+func (lb *LogBuffer) Partition(fn func(LogEntry) bool) (matched, unmatched *LogBuffer) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	matched, unmatched = NewLogBuffer(), NewLogBuffer()
+	matched.flushDeadline, unmatched.flushDeadline = lb.flushDeadline, lb.flushDeadline
+	for _, entry := range lb.entries {
+		if fn(entry) {
+			matched.entries = append(matched.entries, entry)
+		} else {
+			unmatched.entries = append(unmatched.entries, entry)
+		}
+	}
+	lb.entries = make([]LogEntry, 0)
+	return matched, unmatched
+}
+
+// writeEntry 输出缓冲区中的日志条目
+func writeEntry(entry LogEntry) {
+	writeEntryTo(currentLogger(), entry)
+}
+
+// writeEntryTo 使用 logger 输出日志条目
+func writeEntryTo(logger *zerolog.Logger, entry LogEntry) {
+	evt := logger.WithLevel(entry.Level)
+	if evt == nil {
+		return
+	}
+	fields, truncated := limitFields(entry.Fields)
+	entry.Fields = withDynamicFields(fields)
+	stateMu.RLock()
+	entry.Message, entry.Fields = applyExtractRules(entry.Level, entry.Message, entry.Fields)
+	entry.Fields = withContextDropped(applyFieldRules(entry.Fields))
+	evt, entry.Message = writeBoundedFields(evt, entry.Message, entry.Fields, truncated)
+	entry.Message = maskString(entry.Message)
+	stateMu.RUnlock()
+	if hasLevelHooks(entry.Level) {
+		runLevelHooks(entry)
+	}
+	evt.Msg(entry.Message)
 }
 
 // SetActive 设置缓冲区的激活状态
@@ -313,18 +821,17 @@ func (lb *LogBuffer) SetActive(active bool) {
 func SetLogLevel(levelStr string) {
 	level, err := zerolog.ParseLevel(levelStr)
 	if err != nil {
-		log.Warn().Msgf("Failed to parse log level '%s', log level remains unchanged", levelStr)
+		currentLogger().Warn().Msgf("Failed to parse log level '%s', log level remains unchanged", levelStr)
 		return
 	}
 	zerolog.SetGlobalLevel(level)
-	log.Info().Msgf("Log level dynamically set to %s", level.String())
+	currentLogger().Info().Msgf("Log level dynamically set to %s", level.String())
 }
 
 func init() {
-	// 初始化一个默认的 Logger
-	zerolog.TimeFieldFormat = "2006-01-02 15:04:05"
-	zerolog.MultiLevelWriter(zerolog.ConsoleWriter{Out: os.Stderr})
-	multi := zerolog.MultiLevelWriter(zerolog.ConsoleWriter{Out: os.Stderr})
-
-	log.Logger = log.Output(multi).With().Timestamp().Logger()
+	// 默认的 Logger 在首次使用时由 ensureLogger 创建，不修改 zerolog 的全局 log.Logger
+	zerolog.TimeFieldFormat = timeFormat
+	metricsEnabled.Store(true)
+	setStatsSampleRate(0, false)
+	setInterning(true)
 }
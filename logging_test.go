@@ -1,13 +1,47 @@
 package logging
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
+func TestMain(m *testing.M) {
+	// 先创建默认的日志记录器，测试中保存与恢复 baseLogger 时不会再被延迟初始化覆盖
+	ensureLogger()
+	os.Exit(m.Run())
+}
+
+// captureOutput 将全局日志输出重定向到缓冲区，测试结束后恢复
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	prev, prevDropped, prevInjected := baseLogger, contextDroppedFields, injectedKeys
+	baseLogger, contextDroppedFields, injectedKeys = zerolog.New(buf), nil, nil
+	t.Cleanup(func() { baseLogger, contextDroppedFields, injectedKeys = prev, prevDropped, prevInjected })
+	return buf
+}
+
+// decodeLine 解析单行 JSON 日志
+func decodeLine(t *testing.T, line []byte) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal(line, &m); err != nil {
+		t.Fatalf("invalid json line %q: %v", line, err)
+	}
+	return m
+}
+
 func TestInitLoggerAndUsage(t *testing.T) {
 	// 定义一个简单的配置
 	config := Config{
@@ -81,6 +115,251 @@ func TestLogBuffer(t *testing.T) {
 	buf.Flush(zerolog.InfoLevel)
 }
 
+func TestPrioritisedFlush(t *testing.T) {
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	captureOutput(t)
+
+	buf := NewLogBuffer()
+	for i, level := range []zerolog.Level{zerolog.DebugLevel, zerolog.InfoLevel, zerolog.ErrorLevel, zerolog.DebugLevel, zerolog.FatalLevel, zerolog.ErrorLevel} {
+		buf.AddEntry(LogEntry{Level: level, Message: fmt.Sprintf("%s %d", level, i)})
+	}
+	out := &bytes.Buffer{}
+	buf.PrioritisedFlush(out)
+
+	var got []string
+	for _, line := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		got = append(got, decodeLine(t, line)["message"].(string))
+	}
+	want := []string{"fatal 4", "error 2", "error 5", "info 1", "debug 0", "debug 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected flush order: %v", got)
+	}
+	if len(buf.Peek()) != 0 {
+		t.Errorf("buffer should be empty after flush")
+	}
+}
+
+func TestPartition(t *testing.T) {
+	buf := NewLogBuffer()
+	for i, level := range []zerolog.Level{zerolog.DebugLevel, zerolog.ErrorLevel, zerolog.InfoLevel, zerolog.ErrorLevel} {
+		buf.AddEntry(LogEntry{Level: level, Message: fmt.Sprintf("%s %d", level, i)})
+	}
+	errs, rest := buf.Partition(func(e LogEntry) bool { return e.Level >= zerolog.ErrorLevel })
+
+	messages := func(lb *LogBuffer) []string {
+		var ms []string
+		for _, e := range lb.Peek() {
+			ms = append(ms, e.Message)
+		}
+		return ms
+	}
+	if got := messages(errs); !reflect.DeepEqual(got, []string{"error 1", "error 3"}) {
+		t.Errorf("unexpected matched entries: %v", got)
+	}
+	if got := messages(rest); !reflect.DeepEqual(got, []string{"debug 0", "info 2"}) {
+		t.Errorf("unexpected unmatched entries: %v", got)
+	}
+	if len(buf.Peek()) != 0 {
+		t.Errorf("buffer should be empty after partition")
+	}
+	// 新的缓冲区与原缓冲区互相独立
+	buf.AddEntry(LogEntry{Level: zerolog.ErrorLevel, Message: "later"})
+	if len(errs.Peek()) != 2 {
+		t.Errorf("partitioned buffer should not see later entries")
+	}
+}
+
+func TestFlushBatch(t *testing.T) {
+	out := captureOutput(t)
+	buf := NewLogBuffer()
+	for i := 0; i < 7; i++ {
+		level := zerolog.InfoLevel
+		if i%3 == 0 {
+			level = zerolog.DebugLevel
+		}
+		buf.AddEntry(LogEntry{Level: level, Message: fmt.Sprint(i)})
+	}
+
+	var got [][2]int
+	for {
+		flushed, remaining := buf.FlushBatch(2, zerolog.InfoLevel)
+		got = append(got, [2]int{flushed, remaining})
+		if remaining == 0 {
+			break
+		}
+	}
+	if want := [][2]int{{2, 2}, {2, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected batch progress: %v", got)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 4 {
+		t.Errorf("expected 4 entries written, got %d: %s", lines, out.String())
+	}
+	if flushed, remaining := buf.FlushBatch(2, zerolog.InfoLevel); flushed != 0 || remaining != 0 {
+		t.Errorf("empty buffer should flush nothing: %d %d", flushed, remaining)
+	}
+}
+
+// cancelAfterWriter 写入 n 次后取消 ctx
+type cancelAfterWriter struct {
+	n      int
+	cancel context.CancelFunc
+	lines  []string
+}
+
+func (w *cancelAfterWriter) Write(p []byte) (int, error) {
+	w.lines = append(w.lines, string(p))
+	if len(w.lines) == w.n {
+		w.cancel()
+	}
+	return len(p), nil
+}
+
+func TestFlushContext(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &cancelAfterWriter{n: 2, cancel: cancel}
+	baseLogger = zerolog.New(out)
+
+	buf := NewLogBuffer()
+	buf.AddEntry(LogEntry{Level: zerolog.DebugLevel, Message: "dropped"})
+	for i := 0; i < 5; i++ {
+		buf.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: fmt.Sprint(i)})
+	}
+
+	flushed, err := buf.FlushContext(ctx, zerolog.InfoLevel)
+	if !errors.Is(err, context.Canceled) || flushed != 2 || len(out.lines) != 2 {
+		t.Fatalf("expected cancellation after 2 entries, got %d %v", flushed, err)
+	}
+	remaining := buf.Peek()
+	if len(remaining) != 3 || remaining[0].Message != "2" {
+		t.Errorf("unflushed entries should stay in the buffer: %+v", remaining)
+	}
+
+	flushed, err = buf.FlushContext(context.Background(), zerolog.InfoLevel)
+	if err != nil || flushed != 3 || len(buf.Peek()) != 0 {
+		t.Errorf("expected remaining entries to be flushed, got %d %v", flushed, err)
+	}
+}
+
+type codeError struct {
+	code      int
+	temporary bool
+}
+
+func (e codeError) Error() string   { return fmt.Sprintf("code %d", e.code) }
+func (e codeError) Code() int       { return e.code }
+func (e codeError) Temporary() bool { return e.temporary }
+
+func TestErrorWithErr(t *testing.T) {
+	// nil 错误不输出 error 字段
+	buf := captureOutput(t)
+	ErrorWithErr(nil, "nil error")
+	m := decodeLine(t, buf.Bytes())
+	if _, ok := m["error"]; ok {
+		t.Errorf("nil error should not produce error field: %v", m)
+	}
+	if _, ok := m["error_type"]; ok {
+		t.Errorf("nil error should not produce error_type field: %v", m)
+	}
+
+	// 开启 SkipNilErrors 时直接跳过
+	skipNilErrors = true
+	buf.Reset()
+	ErrorWithErr(nil, "skipped")
+	WarnWithErr(nil, "skipped")
+	skipNilErrors = false
+	if buf.Len() != 0 {
+		t.Errorf("nil error should be skipped, got %s", buf.String())
+	}
+
+	// 包装的错误记录最外层类型
+	buf.Reset()
+	ErrorWithErr(fmt.Errorf("wrap: %w", errors.New("inner")), "wrapped")
+	m = decodeLine(t, buf.Bytes())
+	if m["error"] != "wrap: inner" || m["error_type"] != "*fmt.wrapError" {
+		t.Errorf("unexpected wrapped error fields: %v", m)
+	}
+
+	// 自定义接口错误（包括被包装的情况）
+	buf.Reset()
+	WarnWithErr(fmt.Errorf("op failed: %w", codeError{code: 503, temporary: true}), "custom")
+	m = decodeLine(t, buf.Bytes())
+	if m["error_code"] != float64(503) || m["temporary"] != true {
+		t.Errorf("unexpected custom error fields: %v", m)
+	}
+	if m["level"] != "warn" {
+		t.Errorf("expected warn level, got %v", m["level"])
+	}
+}
+
+func TestErrorFieldsReserved(t *testing.T) {
+	buf := captureOutput(t)
+	t.Cleanup(func() { reservedKeyPolicy = ReservedKeyRename })
+	user := map[string]interface{}{"error": "mine", "error_type": "t", "error_code": 1, "temporary": false, "user": "u1"}
+
+	// 附带错误时与错误字段重名的用户字段按 ReservedKeyPolicy 重命名
+	WarnWithErr(codeError{code: 503, temporary: true}, "renamed", user)
+	m := decodeStrict(t, buf.Bytes())
+	if m["error"] != "code 503" || m["error_code"] != float64(503) || m["temporary"] != true ||
+		m["field_error"] != "mine" || m["field_error_code"] != float64(1) || m["user"] != "u1" {
+		t.Errorf("colliding error fields should be renamed: %v", m)
+	}
+
+	// drop 与其他保留字段一起记录在 reserved_key_dropped 中
+	reservedKeyPolicy = ReservedKeyDrop
+	buf.Reset()
+	ErrorWithErr(errors.New("boom"), "dropped", map[string]interface{}{"error": "mine", "message": "m"})
+	m = decodeStrict(t, buf.Bytes())
+	if m["error"] != "boom" || !reflect.DeepEqual(m[reservedKeyDroppedKey], []interface{}{"error", "message"}) {
+		t.Errorf("colliding error fields should be dropped: %v", m)
+	}
+
+	// 没有错误时保留用户字段
+	buf.Reset()
+	Error("no error", map[string]interface{}{"error": "mine"})
+	if m = decodeStrict(t, buf.Bytes()); m["error"] != "mine" {
+		t.Errorf("error field without an error should be kept: %v", m)
+	}
+}
+
+func TestConsoleFileFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	InitLogger(Config{
+		LogPath:          path,
+		ProjectKey:       "project",
+		ProjectName:      "testProject",
+		EnableFileOutput: true,
+		FileFormat:       FileFormatConsole,
+	})
+	t.Cleanup(Close)
+
+	Info("before clear", map[string]interface{}{"user": "u1"})
+	logfile.clear(HeaderReasonSizeClear)
+	Info("after clear")
+	if err := Barrier(context.Background()); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	text := string(content)
+	if strings.Contains(text, "\x1b[") {
+		t.Errorf("file output should not contain color codes: %q", text)
+	}
+	if strings.Contains(text, "before clear") {
+		t.Errorf("log file should be cleared: %q", text)
+	}
+	if !strings.Contains(text, "INF after clear") || strings.Contains(text, "{") {
+		t.Errorf("console format should survive clearing the log file: %q", text)
+	}
+}
+
 func TestFatal(t *testing.T) {
 	// 定义一个简单的配置
 	config := Config{
@@ -98,7 +377,14 @@ func TestFatal(t *testing.T) {
 	// 清理测试日志文件
 	defer os.Remove("./test.log")
 
+	defer Close()
+	var code int
+	exitFunc = func(c int) { code = c }
+	defer func() { exitFunc = os.Exit }()
+
 	// 使用日志记录器记录一些信息
 	Fatal("test fatal", 123)
-
+	if code != 123 {
+		t.Errorf("expected exit code 123, got %d", code)
+	}
 }
@@ -0,0 +1,57 @@
+// @Author Clover
+// @Data 2026/10/16 下午7:40:00
+// @Desc 记录每次写入的审计 Writer
+
+package logging
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultPreviewSize 写入日志中默认记录的数据长度
+const defaultPreviewSize = 100
+
+// loggingWriter 包装 io.Writer，每次写入都会记录一条日志
+type loggingWriter struct {
+	w           io.Writer
+	level       zerolog.Level
+	fields      map[string]interface{}
+	previewSize int
+}
+
+// NewLoggingWriter 包装 w，每次 Write 都以 level 级别记录写入字节数与前 100 字节的数据
+// 写入失败时以 error 级别记录错误后再返回
+func NewLoggingWriter(w io.Writer, level zerolog.Level, fields map[string]interface{}) io.Writer {
+	return NewLoggingWriterSize(w, level, fields, defaultPreviewSize)
+}
+
+// NewLoggingWriterSize 与 NewLoggingWriter 相同，但可以指定记录的数据长度，小于 0 时不记录数据
+func NewLoggingWriterSize(w io.Writer, level zerolog.Level, fields map[string]interface{}, previewSize int) io.Writer {
+	return &loggingWriter{w: w, level: level, fields: fields, previewSize: previewSize}
+}
+
+// Write 写入底层 Writer 并记录本次写入
+func (lw *loggingWriter) Write(p []byte) (int, error) {
+	n, err := lw.w.Write(p)
+	writeFields := map[string]interface{}{"bytes_written": n}
+	if lw.previewSize >= 0 {
+		writeFields["data"] = previewData(p, lw.previewSize)
+	}
+	fields := []map[string]interface{}{lw.fields, writeFields}
+	if err != nil {
+		emit(withErr(currentLogger().Error(), err), zerolog.ErrorLevel, err, "write failed", fields)
+		return n, err
+	}
+	emit(currentLogger().WithLevel(lw.level), lw.level, nil, "write", fields)
+	return n, nil
+}
+
+// previewData 返回数据的前 size 字节，超出部分以截断标记代替
+func previewData(p []byte, size int) string {
+	if len(p) <= size {
+		return string(p)
+	}
+	return string(p[:size]) + "...(truncated)"
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestLoggingWriter(t *testing.T) {
+	buf := captureOutput(t)
+	dst := &bytes.Buffer{}
+	w := NewLoggingWriter(dst, zerolog.InfoLevel, map[string]interface{}{"target": "tmp"})
+
+	if n, err := w.Write([]byte("hello")); n != 5 || err != nil {
+		t.Fatalf("unexpected result: %d %v", n, err)
+	}
+	if dst.String() != "hello" {
+		t.Errorf("data should be proxied to the underlying writer: %q", dst.String())
+	}
+	m := decodeLine(t, buf.Bytes())
+	if m["level"] != "info" || m["bytes_written"] != float64(5) || m["data"] != "hello" || m["target"] != "tmp" {
+		t.Errorf("unexpected log: %v", m)
+	}
+
+	// 超过记录长度的数据被截断
+	buf.Reset()
+	w.Write([]byte(strings.Repeat("x", 150)))
+	m = decodeLine(t, buf.Bytes())
+	if m["data"] != strings.Repeat("x", 100)+"...(truncated)" || m["bytes_written"] != float64(150) {
+		t.Errorf("unexpected log: %v", m)
+	}
+
+	buf.Reset()
+	NewLoggingWriterSize(dst, zerolog.DebugLevel, nil, 3).Write([]byte("abcdef"))
+	if m = decodeLine(t, buf.Bytes()); m["data"] != "abc...(truncated)" || m["level"] != "debug" {
+		t.Errorf("unexpected log: %v", m)
+	}
+
+	// 写入错误先记录再返回
+	buf.Reset()
+	if _, err := NewLoggingWriter(failingWriter{}, zerolog.InfoLevel, nil).Write([]byte("x")); err == nil {
+		t.Fatal("expected write error")
+	}
+	m = decodeLine(t, buf.Bytes())
+	if m["level"] != "error" || m["error"] != "disk full" || m["bytes_written"] != float64(0) {
+		t.Errorf("unexpected log: %v", m)
+	}
+}
@@ -0,0 +1,29 @@
+// @Author Clover
+// @Data 2026/10/16 下午11:00:00
+// @Desc 按日志内容匹配的规则，供路由、抑制等功能共用
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldMatcher 判断一条日志是否匹配
+type FieldMatcher func(LogEntry) bool
+
+// MatchField 匹配字段 key 的值等于 value 的日志，数值按文本比较
+func MatchField(key string, value interface{}) FieldMatcher {
+	want := fmt.Sprint(value)
+	return func(e LogEntry) bool {
+		v, ok := e.Fields[key]
+		return ok && fmt.Sprint(v) == want
+	}
+}
+
+// MatchMessage 匹配消息中包含 substr 的日志
+func MatchMessage(substr string) FieldMatcher {
+	return func(e LogEntry) bool {
+		return strings.Contains(e.Message, substr)
+	}
+}
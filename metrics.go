@@ -0,0 +1,57 @@
+// @Author Clover
+// @Data 2026/10/16 下午5:20:00
+// @Desc 按级别统计日志数量
+
+package logging
+
+import (
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	metricsEnabled atomic.Bool // 是否统计日志数量
+	errorCount     atomic.Int64
+	warnCount      atomic.Int64
+	fatalCount     atomic.Int64
+)
+
+// metricsHook 统计各级别输出的日志数量
+type metricsHook struct{}
+
+func (metricsHook) Run(_ *zerolog.Event, level zerolog.Level, _ string) {
+	if !metricsEnabled.Load() {
+		return
+	}
+	switch level {
+	case zerolog.WarnLevel:
+		warnCount.Add(1)
+	case zerolog.ErrorLevel:
+		errorCount.Add(1)
+	case zerolog.FatalLevel:
+		fatalCount.Add(1)
+	}
+}
+
+// ErrorCount 返回启动以来输出的 Error 级别日志数量
+func ErrorCount() int64 {
+	return errorCount.Load()
+}
+
+// WarnCount 返回启动以来输出的 Warn 级别日志数量
+func WarnCount() int64 {
+	return warnCount.Load()
+}
+
+// FatalCount 返回启动以来输出的 Fatal 级别日志数量
+func FatalCount() int64 {
+	return fatalCount.Load()
+}
+
+// ResetCounts 将所有日志数量清零
+func ResetCounts() {
+	errorCount.Store(0)
+	warnCount.Store(0)
+	fatalCount.Store(0)
+}
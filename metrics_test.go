@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLevelCounts(t *testing.T) {
+	prev := baseLogger
+	baseLogger = zerolog.New(io.Discard).Hook(metricsHook{})
+	t.Cleanup(func() {
+		baseLogger = prev
+		metricsEnabled.Store(true)
+		ResetCounts()
+	})
+	ResetCounts()
+
+	Info("info")
+	Warn("warn")
+	WarnWithErr(errors.New("e"), "warn with err")
+	Error("error")
+	if ErrorCount() != 1 || WarnCount() != 2 || FatalCount() != 0 {
+		t.Errorf("unexpected counts: error=%d warn=%d fatal=%d", ErrorCount(), WarnCount(), FatalCount())
+	}
+
+	// 通过 SetField 派生的 Logger 仍然统计
+	SetField(map[string]interface{}{"k": "v"})
+	Error("error after set field")
+	if ErrorCount() != 2 {
+		t.Errorf("expected 2 errors, got %d", ErrorCount())
+	}
+
+	ResetCounts()
+	if ErrorCount() != 0 || WarnCount() != 0 {
+		t.Errorf("counts should be reset")
+	}
+
+	// DisableMetrics 关闭统计
+	metricsEnabled.Store(false)
+	Error("not counted")
+	if ErrorCount() != 0 {
+		t.Errorf("disabled metrics should not count, got %d", ErrorCount())
+	}
+}
@@ -0,0 +1,60 @@
+// @Author Clover
+// @Data 2026/10/17 下午11:50:00
+// @Desc 基于进程启动时间的单调时间与系统时间回退检测
+
+package logging
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	monoKey            = "mono_ms"
+	clockSkewTolerance = time.Millisecond // 系统时间相对单调时间回退超过该值时视为时间回退
+)
+
+var (
+	processStart = time.Now() // 单调时间的起点，带有单调时钟读数
+
+	monoEnabled    atomic.Bool  // 是否添加 mono_ms 并检测时间回退
+	lastWallOffset atomic.Int64 // 最近一条日志的系统时间减去单调时间（纳秒），为 0 表示尚未记录
+	skewReported   atomic.Bool  // 是否已经输出过 clock_skew_detected
+)
+
+// setMonotonicTime 开启或关闭 mono_ms，并重置时间回退检测
+func setMonotonicTime(enable bool) {
+	monoEnabled.Store(enable)
+	lastWallOffset.Store(0)
+	skewReported.Store(false)
+}
+
+// monoHook 为每条日志添加距进程启动的单调毫秒数 mono_ms，并检测系统时间回退
+type monoHook struct{}
+
+func (monoHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if !monoEnabled.Load() {
+		return
+	}
+	t := time.Now()
+	mono := t.Sub(processStart)
+	e.Int64(monoKey, mono.Milliseconds())
+	checkClockSkew(t.UnixNano() - int64(mono))
+}
+
+// checkClockSkew 比较系统时间与单调时间之差：系统时间没有被调整时该差值不变，
+// 变小说明系统时间回退（例如 NTP 跳变），第一次发现时输出一条带有 delta_ms 的 Warn 日志。
+// 与直接比较相邻两条日志的时间戳不同，并发输出的日志不会因为读取时间与比较的先后顺序而误报
+func checkClockSkew(offset int64) {
+	prev := lastWallOffset.Swap(offset)
+	if prev == 0 || prev-offset <= int64(clockSkewTolerance) || !skewReported.CompareAndSwap(false, true) {
+		return
+	}
+	delta := time.Duration(prev - offset)
+	// hook 可能在持有 stateMu 时执行，在新的协程中输出日志
+	go func() {
+		currentLogger().Warn().Int64("delta_ms", delta.Milliseconds()).Msg("clock_skew_detected")
+	}()
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonotonicTime(t *testing.T) {
+	out := &syncBuffer{}
+	InitLogger(Config{
+		LogPath:       filepath.Join(t.TempDir(), "mono.log"),
+		ProjectKey:    "project",
+		MonotonicTime: true,
+	}, WithWriters(out))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+		setMonotonicTime(false)
+	})
+
+	Info("first")
+	time.Sleep(5 * time.Millisecond)
+	Info("second")
+
+	var monos []float64
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		m := decodeLine(t, []byte(line))
+		mono, ok := m["mono_ms"].(float64)
+		if !ok {
+			t.Fatalf("missing mono_ms: %v", m)
+		}
+		monos = append(monos, mono)
+	}
+	if len(monos) != 2 || monos[1]-monos[0] < 5 {
+		t.Errorf("mono_ms should advance with the monotonic clock: %v", monos)
+	}
+
+	// 系统时间相对单调时间回退 5 秒，只报告一次
+	base := time.Now().UnixNano()
+	checkClockSkew(base)
+	checkClockSkew(base - int64(5*time.Second))
+	checkClockSkew(base - int64(10*time.Second))
+	waitFor(t, "clock skew warning", func() bool { return strings.Contains(out.String(), "clock_skew_detected") })
+	time.Sleep(20 * time.Millisecond)
+	if n := strings.Count(out.String(), "clock_skew_detected"); n != 1 {
+		t.Errorf("clock skew should be reported once, got %d", n)
+	}
+	if !strings.Contains(out.String(), `"delta_ms":5000`) {
+		t.Errorf("warning should carry the regression delta: %s", out.String())
+	}
+
+	// 同名的用户字段按 ReservedKeyPolicy 重命名，不会输出两个 mono_ms
+	before := len(out.String())
+	Info("user mono", map[string]interface{}{"mono_ms": 5})
+	m := decodeStrict(t, []byte(strings.TrimSpace(out.String()[before:])))
+	if _, ok := m["mono_ms"].(float64); !ok || m["field_mono_ms"] != float64(5) {
+		t.Errorf("colliding mono_ms should be renamed: %v", m)
+	}
+}
+
+func TestClockSkewIgnoresStableOffset(t *testing.T) {
+	setMonotonicTime(true)
+	t.Cleanup(func() { setMonotonicTime(false) })
+	offset := time.Now().UnixNano()
+	for i := 0; i < 3; i++ {
+		checkClockSkew(offset + int64(i)) // 时间前进或保持不变
+	}
+	if skewReported.Load() {
+		t.Error("a stable or advancing offset should not be reported as skew")
+	}
+}
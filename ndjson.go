@@ -0,0 +1,85 @@
+// @Author Clover
+// @Data 2026/10/17 下午3:00:00
+// @Desc 读取 NDJSON 格式的日志文件
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// malformedLineMessage 无法解析的行对应条目的消息
+const malformedLineMessage = "malformed log line"
+
+// ReadNDJSON 读取 zerolog 输出的 NDJSON 日志并解析为 LogEntry：
+// level 映射为 zerolog.Level，message 映射为 Message，其余字段保存在 Fields 中。
+// 无法解析的行以 Warn 级别的条目返回，原始内容保存在 raw 字段；空行与文件首行元数据（Config.FileHeader）会被跳过。
+// 只有读取 r 失败时才返回错误，此时同时返回已解析的条目
+func ReadNDJSON(r io.Reader) ([]LogEntry, error) {
+	var entries []LogEntry
+	err := scanNDJSON(r, func(entry LogEntry) {
+		entries = append(entries, entry)
+	})
+	return entries, err
+}
+
+// ReadEntries 读取日志文件并解析为 LogEntry，规则与 ReadNDJSON 相同；
+// 路径以 .zst 结尾时透明解压，末尾不完整的 zstd 帧（例如写入时进程崩溃）被忽略
+func ReadEntries(path string) ([]LogEntry, error) {
+	r, err := openLogReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadNDJSON(r)
+}
+
+// StreamNDJSON 与 ReadNDJSON 相同，但逐条通过通道返回，适用于较大的文件。
+// 读取结束后关闭通道；读取 r 失败时最后输出一条带 error 字段的 Warn 条目。
+// 调用方需要读完通道，否则读取协程会一直阻塞
+func StreamNDJSON(r io.Reader) <-chan LogEntry {
+	ch := make(chan LogEntry)
+	go func() {
+		defer close(ch)
+		err := scanNDJSON(r, func(entry LogEntry) {
+			ch <- entry
+		})
+		if err != nil {
+			ch <- LogEntry{
+				Level:   zerolog.WarnLevel,
+				Message: "failed to read log stream",
+				Fields:  map[string]interface{}{zerolog.ErrorFieldName: err.Error()},
+			}
+		}
+	}()
+	return ch
+}
+
+// scanNDJSON 逐行解析 r，对每个条目调用 fn
+func scanNDJSON(r io.Reader, fn func(LogEntry)) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 && !isFileHeader(trimmed) {
+			entry, perr := parseLogEntry(trimmed)
+			if perr != nil {
+				entry = LogEntry{
+					Level:   zerolog.WarnLevel,
+					Message: malformedLineMessage,
+					Fields:  map[string]interface{}{"raw": string(trimmed)},
+				}
+			}
+			fn(entry)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
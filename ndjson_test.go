@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rs/zerolog"
+)
+
+const ndjsonInput = `{"level":"info","time":"2024-07-18 15:04:05","message":"server started","port":8080}
+
+{"level":"error","message":"request failed","error":"timeout"}
+not json
+{"message":"no level"}`
+
+func TestReadNDJSON(t *testing.T) {
+	entries, err := ReadNDJSON(strings.NewReader(ndjsonInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d: %+v", len(entries), entries)
+	}
+	if e := entries[0]; e.Level != zerolog.InfoLevel || e.Message != "server started" || e.Fields["port"] != float64(8080) {
+		t.Errorf("unexpected first entry: %+v", e)
+	}
+	if e := entries[1]; e.Level != zerolog.ErrorLevel || e.Fields["error"] != "timeout" {
+		t.Errorf("unexpected second entry: %+v", e)
+	}
+	if e := entries[2]; e.Level != zerolog.WarnLevel || e.Message != malformedLineMessage || e.Fields["raw"] != "not json" {
+		t.Errorf("malformed line should become a warn entry: %+v", e)
+	}
+	if e := entries[3]; e.Level != zerolog.NoLevel || e.Message != "no level" {
+		t.Errorf("unexpected last entry: %+v", e)
+	}
+
+	readErr := errors.New("disk failure")
+	entries, err = ReadNDJSON(io.MultiReader(strings.NewReader(`{"message":"a"}`+"\n"), iotest.ErrReader(readErr)))
+	if !errors.Is(err, readErr) || len(entries) != 1 {
+		t.Errorf("expected partial entries and read error, got %v %+v", err, entries)
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	var messages []string
+	for e := range StreamNDJSON(strings.NewReader(ndjsonInput)) {
+		messages = append(messages, e.Message)
+	}
+	want := "server started,request failed," + malformedLineMessage + ",no level"
+	if got := strings.Join(messages, ","); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var last LogEntry
+	for e := range StreamNDJSON(iotest.ErrReader(errors.New("disk failure"))) {
+		last = e
+	}
+	if last.Level != zerolog.WarnLevel || last.Fields["error"] != "disk failure" {
+		t.Errorf("read error should be reported as a final warn entry: %+v", last)
+	}
+}
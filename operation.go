@@ -0,0 +1,144 @@
+// @Author Clover
+// @Data 2026/10/17 下午9:00:00
+// @Desc 成对的开始/结束日志，自动计算持续时间与结果
+
+package logging
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	opNameKey     = "op"
+	opIDKey       = "op_id"
+	opParentIDKey = "parent_op_id"
+	opPhaseKey    = "phase"
+	opOutcomeKey  = "outcome"
+	opDurationKey = "duration_ms"
+)
+
+// 操作日志的阶段
+const (
+	PhaseStart     = "start"
+	PhaseEnd       = "end"
+	PhaseAbandoned = "abandoned" // Close 时仍未结束的操作
+)
+
+var (
+	opsMu sync.Mutex
+	ops   = make(map[string]*Operation) // 尚未结束的操作
+)
+
+// Operation 跨越多条日志的操作，类似简化的 span
+type Operation struct {
+	name     string
+	id       string
+	parentID string
+	start    time.Time
+	fields   map[string]interface{} // Begin 传入的字段，之后的日志都会携带
+
+	mu    sync.Mutex
+	ended bool
+}
+
+// Begin 开始一个操作，输出 phase=start 的 Info 日志并生成 op_id，需要调用 End 结束
+func Begin(name string, fields ...map[string]interface{}) *Operation {
+	return beginOperation(name, "", fields)
+}
+
+// Begin 开始一个嵌套的子操作，子操作的日志带有 parent_op_id
+func (op *Operation) Begin(name string, fields ...map[string]interface{}) *Operation {
+	return beginOperation(name, op.id, fields)
+}
+
+func beginOperation(name, parentID string, fields []map[string]interface{}) *Operation {
+	op := &Operation{
+		name:     name,
+		id:       randomHex(8),
+		parentID: parentID,
+		start:    now(),
+		fields:   mergeFields(fields),
+	}
+	opsMu.Lock()
+	ops[op.id] = op
+	opsMu.Unlock()
+	Info(name+" started", op.with(map[string]interface{}{opPhaseKey: PhaseStart}))
+	return op
+}
+
+// ID 返回操作的 op_id
+func (op *Operation) ID() string {
+	return op.id
+}
+
+// Log 输出一条携带 op_id 的 Info 日志
+func (op *Operation) Log(msg string, fields ...map[string]interface{}) {
+	Info(msg, op.with(mergeFields(fields)))
+}
+
+// End 结束操作，输出 phase=end 的日志，附带 duration_ms 与 outcome：
+// err 为 nil 时 outcome=success（Info 级别），否则 outcome=failure（Error 级别）。重复调用不会再次输出
+func (op *Operation) End(err error) {
+	if !op.finish() {
+		return
+	}
+	fields := op.with(map[string]interface{}{
+		opPhaseKey:    PhaseEnd,
+		opDurationKey: now().Sub(op.start).Milliseconds(),
+	})
+	if err != nil {
+		fields[opOutcomeKey] = "failure"
+		ErrorWithErr(err, op.name+" failed", fields)
+		return
+	}
+	fields[opOutcomeKey] = "success"
+	Info(op.name+" finished", fields)
+}
+
+// finish 标记操作已结束，已经结束时返回 false
+func (op *Operation) finish() bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.ended {
+		return false
+	}
+	op.ended = true
+	opsMu.Lock()
+	delete(ops, op.id)
+	opsMu.Unlock()
+	return true
+}
+
+// with 返回 Begin 的字段、extra 以及操作字段的合并结果，操作字段优先
+func (op *Operation) with(extra map[string]interface{}) map[string]interface{} {
+	fields := mergeFields([]map[string]interface{}{op.fields, extra})
+	fields[opNameKey] = op.name
+	fields[opIDKey] = op.id
+	if op.parentID != "" {
+		fields[opParentIDKey] = op.parentID
+	}
+	return fields
+}
+
+// reportAbandonedOps 为尚未结束的操作输出 phase=abandoned 的 Warn 日志，按开始时间排序
+func reportAbandonedOps() {
+	opsMu.Lock()
+	pending := make([]*Operation, 0, len(ops))
+	for _, op := range ops {
+		pending = append(pending, op)
+	}
+	opsMu.Unlock()
+	sort.Slice(pending, func(i, j int) bool { return pending[i].start.Before(pending[j].start) })
+
+	for _, op := range pending {
+		if !op.finish() {
+			continue
+		}
+		Warn(op.name+" abandoned", op.with(map[string]interface{}{
+			opPhaseKey:    PhaseAbandoned,
+			opDurationKey: now().Sub(op.start).Milliseconds(),
+		}))
+	}
+}
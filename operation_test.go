@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOperation(t *testing.T) {
+	buf := captureOutput(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+
+	op := Begin("sync-users", map[string]interface{}{"source": "ldap"})
+	op.Log("fetched page", map[string]interface{}{"page": 1})
+	child := op.Begin("sync-groups")
+	clock.skip(1500 * time.Millisecond)
+	child.End(nil)
+	op.End(errors.New("ldap timeout"))
+	op.End(nil) // 重复调用不再输出
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %d: %v", len(lines), lines)
+	}
+	start, log, childStart, childEnd, end := lines[0], lines[1], lines[2], lines[3], lines[4]
+	id := op.ID()
+	if start["phase"] != "start" || start["op_id"] != id || start["op"] != "sync-users" || start["source"] != "ldap" {
+		t.Errorf("unexpected start: %v", start)
+	}
+	if log["op_id"] != id || log["page"] != float64(1) || log["source"] != "ldap" || log["phase"] != nil {
+		t.Errorf("intermediate logs should inherit the op_id: %v", log)
+	}
+	if childStart["parent_op_id"] != id || childStart["op_id"] != child.ID() || child.ID() == id {
+		t.Errorf("nested operation should record its parent: %v", childStart)
+	}
+	if childEnd["phase"] != "end" || childEnd["outcome"] != "success" || childEnd["level"] != "info" || childEnd["duration_ms"] != float64(1500) {
+		t.Errorf("unexpected child end: %v", childEnd)
+	}
+	if end["phase"] != "end" || end["outcome"] != "failure" || end["level"] != "error" || end["error"] != "ldap timeout" || end["op_id"] != id {
+		t.Errorf("unexpected end: %v", end)
+	}
+	if _, ok := end["parent_op_id"]; ok {
+		t.Errorf("top-level operation should not have a parent: %v", end)
+	}
+}
+
+func TestAbandonedOperations(t *testing.T) {
+	InitLogger(Config{ProjectKey: "project"})
+	t.Cleanup(Close)
+	buf := captureOutput(t)
+	useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+
+	leaked := Begin("import")
+	finished := Begin("export")
+	finished.End(nil)
+	buf.Reset()
+
+	Close()
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("expected one abandoned operation, got %v", lines)
+	}
+	if lines[0]["phase"] != "abandoned" || lines[0]["op_id"] != leaked.ID() || lines[0]["level"] != "warn" {
+		t.Errorf("unexpected abandoned report: %v", lines[0])
+	}
+	leaked.End(nil)
+	if buf.Len() != 0 {
+		t.Errorf("abandoned operation should not be reported again: %q", buf.String())
+	}
+}
@@ -0,0 +1,265 @@
+// @Author Clover
+// @Data 2026/10/16 下午8:40:00
+// @Desc 按配置同时输出到多个不同格式的日志文件
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// OutputFormat 日志文件输出格式
+type OutputFormat string
+
+const (
+	OutputFormatJSON    OutputFormat = "json"    // 单行 JSON（默认）
+	OutputFormatConsole OutputFormat = "console" // 无颜色的易读格式
+	OutputFormatLogfmt  OutputFormat = "logfmt"  // key=value 格式，便于运维人员查看和 grep
+)
+
+// OutputConfig 额外日志文件的配置
+type OutputConfig struct {
+	Path   string        // 日志文件路径
+	Format OutputFormat  // 输出格式，默认为 json
+	Level  zerolog.Level // 写入该文件的最低日志级别，零值为 debug
+	Rotate bool          // 是否按照 MaxLogSize 与 MaxFileAge 清除该文件
+
+	FieldRenames map[string]string // 只对该文件生效的字段重命名，在 Config.FieldRenames 之后执行，规则构成循环时不打开该文件
+}
+
+// output 已打开的额外日志文件
+type output struct {
+	file    *logFile
+	config  OutputConfig
+	aliasOf string       // 与之指向同一文件的 LogPath 或其他输出的路径，此时共用同一个 logFile
+	renames fieldRenamer // OutputConfig.FieldRenames 编译后的规则
+}
+
+var outputs []output // Config.Outputs 中成功打开的日志文件
+
+// openOutputs 打开所有额外的日志文件，某个文件出错不影响其他文件，返回所有错误
+// 与 main（LogPath 的日志文件，可以为 nil）或其他输出指向同一文件的输出共用同一个 logFile，
+// 避免各自计算大小、重复清除；任意一个共用者开启 Rotate 时按 Rotate 的配置清除
+func openOutputs(config Config, main *logFile) ([]output, error) {
+	var (
+		opened []output
+		errs   []error
+	)
+	for _, oc := range config.Outputs {
+		switch oc.Format {
+		case "":
+			oc.Format = OutputFormatJSON
+		case OutputFormatJSON, OutputFormatConsole, OutputFormatLogfmt:
+		default:
+			errs = append(errs, fmt.Errorf("output %s: unknown format %q", oc.Path, oc.Format))
+			continue
+		}
+		renames, renameErrs := compileRenames(oc.FieldRenames)
+		if len(renameErrs) > 0 {
+			errs = append(errs, fmt.Errorf("output %s: %w", oc.Path, errors.Join(renameErrs...)))
+			continue
+		}
+		var maxSize int64
+		var maxAge = config.MaxFileAge
+		if oc.Rotate {
+			maxSize = config.MaxLogSize
+		} else {
+			maxAge = 0
+		}
+		if shared := aliasedLogFile(oc.Path, main, opened); shared != nil {
+			if oc.Rotate && shared.maxSize == 0 && shared.maxAge == 0 && shared != main {
+				shared.setLimits(maxSize, maxAge)
+				shared.startMonitor(config.MonitorInterval)
+			}
+			opened = append(opened, output{file: shared, config: oc, aliasOf: shared.path, renames: renames})
+			continue
+		}
+		f, err := openLogFile(oc.Path, maxSize, maxAge)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("output %s: %w", oc.Path, err))
+			continue
+		}
+		if oc.Rotate {
+			f.startMonitor(config.MonitorInterval)
+		}
+		if config.WatchExternalChanges {
+			f.startWatch(config.MonitorInterval)
+		}
+		opened = append(opened, output{file: f, config: oc, renames: renames})
+	}
+	return opened, errors.Join(errs...)
+}
+
+// closeOutputs 关闭额外的日志文件，共用的文件由其所有者关闭
+func closeOutputs(outs []output) error {
+	var errs []error
+	for _, o := range outs {
+		if o.aliasOf != "" {
+			continue
+		}
+		if err := o.file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("output %s: %w", o.config.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// aliasedLogFile 返回与 path 指向同一文件的已打开日志文件：先比较绝对路径，
+// 文件已存在时再比较 inode 等文件标识，以发现符号链接与硬链接
+func aliasedLogFile(path string, main *logFile, opened []output) *logFile {
+	candidates := make([]*logFile, 0, len(opened)+1)
+	if main != nil {
+		candidates = append(candidates, main)
+	}
+	for _, o := range opened {
+		if o.aliasOf == "" {
+			candidates = append(candidates, o.file)
+		}
+	}
+	abs := absPath(path)
+	for _, lf := range candidates {
+		if absPath(lf.path) == abs {
+			return lf
+		}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	for _, lf := range candidates {
+		if other, err := lf.Stat(); err == nil && os.SameFile(fi, other) {
+			return lf
+		}
+	}
+	return nil
+}
+
+// absPath 返回清理后的绝对路径，失败时返回清理后的原路径
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// outputWriters 为额外的日志文件创建输出目标
+func outputWriters() []io.Writer {
+	writers := make([]io.Writer, 0, len(outputs))
+	for _, o := range outputs {
+		var w io.Writer = o.file
+		switch o.config.Format {
+		case OutputFormatConsole:
+			w = zerolog.ConsoleWriter{Out: o.file, NoColor: true, TimeFormat: zerolog.TimeFieldFormat}
+		case OutputFormatLogfmt:
+			w = logfmtWriter{out: o.file, projectKey: ProjectKey}
+		}
+		if len(o.renames) > 0 {
+			w = renameWriter{w: w, renames: o.renames}
+		}
+		writers = append(writers, levelFilterWriter{w: wrapWriter(w), min: o.config.Level})
+	}
+	return writers
+}
+
+// levelFilterWriter 只写入不低于 min 级别的日志
+type levelFilterWriter struct {
+	w   io.Writer
+	min zerolog.Level
+}
+
+func (lw levelFilterWriter) Write(p []byte) (int, error) {
+	return lw.w.Write(p)
+}
+
+// WriteLevel 丢弃低于 min 级别的日志
+func (lw levelFilterWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < lw.min {
+		return len(p), nil
+	}
+	return lw.w.Write(p)
+}
+
+// Barrier 等待被包装的输出目标完成写入
+func (lw levelFilterWriter) Barrier(ctx context.Context) error {
+	return barrierWriter(ctx, lw.w)
+}
+
+// logfmtWriter 将 JSON 日志转换为 logfmt 格式写入 out
+type logfmtWriter struct {
+	out        io.Writer
+	projectKey string
+}
+
+// Write 按时间、级别、项目、消息的顺序输出，其余字段按名称排序
+func (lw logfmtWriter) Write(p []byte) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	var evt map[string]interface{}
+	if err := dec.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	leading := []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, lw.projectKey, zerolog.MessageFieldName}
+	keys := make([]string, 0, len(evt))
+	for k := range evt {
+		if !containsString(leading, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range append(leading, keys...) {
+		v, ok := evt[k]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(v))
+	}
+	buf.WriteByte('\n')
+	if _, err := lw.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// logfmtValue 格式化字段值，包含空格、引号或等号的值加引号
+func logfmtValue(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case json.Number:
+		return val.String()
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			s = fmt.Sprint(val)
+		} else {
+			s = string(b)
+		}
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAliasedOutputPaths(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "app.log")
+	warnings := &syncBuffer{}
+	InitLogger(Config{
+		LogPath:          mainPath,
+		ProjectKey:       "project",
+		EnableFileOutput: true,
+		MaxLogSize:       64 << 10,
+		MonitorInterval:  time.Second,
+		Outputs: []OutputConfig{
+			{Path: filepath.Join(dir, "sub", "..", "app.log"), Rotate: true},
+			{Path: filepath.Join(dir, "errors.log")},
+		},
+	}, WithWriters(warnings))
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+
+	stateMu.RLock()
+	lf, outs := logfile, outputs
+	stateMu.RUnlock()
+	if outs[0].file != lf || outs[0].aliasOf != mainPath {
+		t.Fatalf("aliased output should share the main log file")
+	}
+	if outs[1].file == lf || outs[1].aliasOf != "" {
+		t.Fatalf("distinct output should have its own file")
+	}
+	if out := warnings.String(); !strings.Contains(out, "sharing one file writer") || !strings.Contains(out, `"shared_with":"`+strings.ReplaceAll(mainPath, `\`, `\\`)+`"`) {
+		t.Errorf("expected a deduplication warning, got %s", out)
+	}
+
+	// 两个写入目标各写约 20KB，共约 40KB，未超过 64KB 的限制
+	Info(strings.Repeat("x", 20<<10))
+	clock.step(t, time.Second)
+	clock.step(t, time.Second)
+	if lf.ClearCount() != 0 {
+		t.Fatalf("file should not be cleared below the limit, size %d", fileSize(t, mainPath))
+	}
+
+	// 超过限制后只清除一次
+	Info(strings.Repeat("y", 20<<10))
+	clock.step(t, time.Second)
+	clock.step(t, time.Second)
+	if lf.ClearCount() != 1 {
+		t.Errorf("aliased file should be cleared exactly once, got %d", lf.ClearCount())
+	}
+
+	Close()
+	if _, err := os.Stat(mainPath); err != nil {
+		t.Errorf("main log file should remain after close: %v", err)
+	}
+}
+
+func TestAliasedOutputSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared.log")
+	link := filepath.Join(dir, "link.log")
+	if err := os.WriteFile(target, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	outs, err := openOutputs(Config{Outputs: []OutputConfig{{Path: target}, {Path: link, Rotate: true}}, MaxLogSize: 100}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeOutputs(outs) })
+	if outs[1].file != outs[0].file || outs[1].aliasOf != target {
+		t.Fatalf("symlinked output should share the file opened for its target")
+	}
+	if outs[0].file.maxSize != 100 {
+		t.Errorf("rotation settings of an aliased output should apply to the shared file")
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestOutputs(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "app.log")
+	logfmtPath := filepath.Join(dir, "app-console.log")
+	err := InitLogger(Config{
+		ProjectKey:  "project",
+		ProjectName: "testProject",
+		Outputs: []OutputConfig{
+			{Path: jsonPath, Format: OutputFormatJSON, Level: zerolog.WarnLevel},
+			{Path: dir, Format: OutputFormatJSON},              // 目录无法作为日志文件打开
+			{Path: filepath.Join(dir, "x.log"), Format: "xml"}, // 未知格式
+			{Path: logfmtPath, Format: OutputFormatLogfmt, Rotate: true},
+		},
+	})
+	t.Cleanup(Close)
+	if err == nil || !strings.Contains(err.Error(), dir+":") || !strings.Contains(err.Error(), `unknown format "xml"`) {
+		t.Fatalf("expected errors for both invalid outputs, got %v", err)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("valid outputs should still be opened, got %d", len(outputs))
+	}
+
+	Info("request done", map[string]interface{}{"user": "u 1", "status": 200})
+	Warn("slow query")
+	if err := Barrier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 || decodeLine(t, []byte(lines[0]))["message"] != "slow query" {
+		t.Errorf("json output should only contain warn logs: %q", content)
+	}
+
+	content, err = os.ReadFile(logfmtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected logfmt output: %q", content)
+	}
+	if !strings.HasPrefix(lines[0], "time=") || !strings.Contains(lines[0], ` level=info project=testProject message="request done" `) ||
+		!strings.HasSuffix(lines[0], ` status=200 user="u 1"`) {
+		t.Errorf("unexpected logfmt line: %q", lines[0])
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "x.log")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("output with unknown format should not be created")
+	}
+}
@@ -0,0 +1,183 @@
+// @Author Clover
+// @Data 2026/10/16 下午10:10:00
+// @Desc panic 值与调用栈的结构化记录
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Frame 调用栈中的一帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// stackCaptureLevel 捕获调用栈的最低级别，由 Config.StackCaptureLevel 设置
+var stackCaptureLevel atomic.Int32
+
+// captureStack 级别不低于 Config.StackCaptureLevel 时返回当前 goroutine 的调用栈，否则返回 nil
+func captureStack(level zerolog.Level) []byte {
+	if int32(level) < stackCaptureLevel.Load() {
+		return nil
+	}
+	return debug.Stack()
+}
+
+// Recover 捕获 panic 并以 Error 级别记录 PanicFields 中的字段，不会再次 panic；
+// Config.StackCaptureLevel 高于 Error 时不记录 frames。
+// 必须直接通过 defer 调用：defer logging.Recover("worker crashed")
+func Recover(msg string, fields ...map[string]interface{}) {
+	if r := recover(); r != nil {
+		fields = append(fields, PanicFields(r, captureStack(zerolog.ErrorLevel)))
+		emit(currentLogger().Error(), zerolog.ErrorLevel, nil, msg, fields)
+	}
+}
+
+// PanicFields 将 recover 得到的值与 runtime.Stack 输出转换为日志字段：
+// panic（%v 文本）、panic_type、panic_value（error、Stringer 及结构体等值的结构化展开）
+// 与 frames（从 panic 位置开始的调用栈）。
+// panic 值为 error 时额外记录 panic_chain（错误链中的每一层），
+// 为 runtime.Error（如空指针解引用）时额外记录 runtime_error
+func PanicFields(recovered interface{}, stack []byte) map[string]interface{} {
+	fields := map[string]interface{}{
+		"panic":      fmt.Sprintf("%v", recovered),
+		"panic_type": fmt.Sprintf("%T", recovered),
+	}
+	if v, ok := panicValue(recovered); ok {
+		fields["panic_value"] = v
+	}
+	if err, ok := recovered.(error); ok {
+		fields["panic_chain"] = panicChain(err)
+		var re runtime.Error
+		if errors.As(err, &re) {
+			fields["runtime_error"] = re.Error()
+		}
+	}
+	if len(stack) > 0 {
+		fields["frames"] = trimPanicFrames(ParseStack(stack))
+	}
+	return fields
+}
+
+// panicValue 尽可能以结构化的形式展开 panic 值，基本类型只记录 %v 文本
+func panicValue(recovered interface{}) (interface{}, bool) {
+	switch v := recovered.(type) {
+	case error:
+		return map[string]interface{}{"type": fmt.Sprintf("%T", v), "message": v.Error()}, true
+	case fmt.Stringer:
+		return map[string]interface{}{"type": fmt.Sprintf("%T", v), "string": v.String()}, true
+	}
+	rv := reflect.ValueOf(recovered)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if _, err := json.Marshal(recovered); err == nil {
+			return recovered, true
+		}
+	}
+	return nil, false
+}
+
+// panicChain 按深度优先顺序展开错误链，包括 errors.Join 等包装的多个错误
+func panicChain(err error) []map[string]interface{} {
+	var chain []map[string]interface{}
+	var walk func(error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		chain = append(chain, map[string]interface{}{"type": fmt.Sprintf("%T", err), "message": err.Error()})
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(u.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				walk(e)
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// ParseStack 解析 runtime.Stack 或 debug.Stack 的输出，只解析第一个 goroutine
+func ParseStack(stack []byte) []Frame {
+	var frames []Frame
+	lines := bytes.Split(stack, []byte("\n"))
+	for i := 0; i < len(lines); i++ {
+		line := string(lines[i])
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			if len(frames) > 0 {
+				return frames
+			}
+			continue
+		case line == "", strings.HasPrefix(line, "\t"):
+			continue
+		}
+
+		function := strings.TrimPrefix(line, "created by ")
+		if idx := strings.Index(function, " in goroutine "); idx >= 0 {
+			function = function[:idx]
+		}
+		if strings.HasSuffix(function, ")") {
+			if idx := strings.LastIndex(function, "("); idx > 0 {
+				function = function[:idx]
+			}
+		}
+		frame := Frame{Function: function}
+		if i+1 < len(lines) && bytes.HasPrefix(lines[i+1], []byte("\t")) {
+			i++
+			frame.File, frame.Line = parseFileLine(strings.TrimPrefix(string(lines[i]), "\t"))
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// parseFileLine 解析形如 /path/file.go:12 +0x1d 的位置信息
+func parseFileLine(s string) (string, int) {
+	if idx := strings.LastIndex(s, " +0x"); idx >= 0 {
+		s = s[:idx]
+	}
+	idx := strings.LastIndex(s, ":")
+	if idx < 0 {
+		return s, 0
+	}
+	line, err := strconv.Atoi(s[idx+1:])
+	if err != nil {
+		return s, 0
+	}
+	return s[:idx], line
+}
+
+// trimPanicFrames 去掉 panic 发生之前的调用栈获取与恢复相关的帧，使第一帧为 panic 的位置
+func trimPanicFrames(frames []Frame) []Frame {
+	for i, f := range frames {
+		if f.Function == "panic" || f.Function == "runtime.gopanic" || strings.HasPrefix(f.Function, "runtime.panic") {
+			// 运行时错误（如空指针）经由 runtime.panicmem 等函数触发，跳过相邻的 runtime 帧
+			j := i + 1
+			for j < len(frames) && strings.HasPrefix(frames[j].Function, "runtime.") && strings.Contains(frames[j].File, "/runtime/") {
+				j++
+			}
+			return frames[j:]
+		}
+	}
+	return frames
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type panicPayload struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+type stringerPanic struct{ id int }
+
+func (s stringerPanic) String() string { return "stringer panic" }
+
+func panicOuter(v interface{}) { panicInner(v) }
+
+func panicInner(v interface{}) {
+	if v == nil {
+		var m map[string]int
+		m["x"] = 1 // 运行时错误
+	}
+	panic(v)
+}
+
+// capturePanic 通过 Recover 记录 panic，返回输出的日志字段
+func capturePanic(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	buf := captureOutput(t)
+	func() {
+		defer Recover("recovered", map[string]interface{}{"worker": 1})
+		panicOuter(v)
+	}()
+	return decodeLine(t, buf.Bytes())
+}
+
+func TestRecoverFrames(t *testing.T) {
+	m := capturePanic(t, panicPayload{Code: 7, Reason: "bad"})
+	if m["level"] != "error" || m["message"] != "recovered" || m["worker"] != float64(1) {
+		t.Fatalf("unexpected log: %v", m)
+	}
+	if m["panic"] != "{7 bad}" || m["panic_type"] != "logging.panicPayload" {
+		t.Errorf("unexpected panic text/type: %v %v", m["panic"], m["panic_type"])
+	}
+	if v, _ := m["panic_value"].(map[string]interface{}); v["code"] != float64(7) || v["reason"] != "bad" {
+		t.Errorf("struct panic value should be expanded: %v", m["panic_value"])
+	}
+
+	frames, _ := m["frames"].([]interface{})
+	if len(frames) < 3 {
+		t.Fatalf("expected frames, got %v", m["frames"])
+	}
+	for i, want := range []string{"logging.panicInner", "logging.panicOuter", "logging.capturePanic.func1"} {
+		f := frames[i].(map[string]interface{})
+		if !strings.HasSuffix(f["function"].(string), want) || !strings.HasSuffix(f["file"].(string), "panic_test.go") || f["line"].(float64) <= 0 {
+			t.Errorf("frame %d: want %s in panic_test.go, got %v", i, want, f)
+		}
+	}
+}
+
+func TestRecoverRuntimeError(t *testing.T) {
+	m := capturePanic(t, nil)
+	if !strings.HasPrefix(m["panic_type"].(string), "runtime.") {
+		t.Errorf("unexpected panic type: %v", m["panic_type"])
+	}
+	v, _ := m["panic_value"].(map[string]interface{})
+	if !strings.Contains(v["message"].(string), "nil map") {
+		t.Errorf("error panic value should include the message: %v", m["panic_value"])
+	}
+	if !strings.Contains(m["runtime_error"].(string), "nil map") {
+		t.Errorf("runtime errors should be recorded in runtime_error: %v", m)
+	}
+	frames := m["frames"].([]interface{})
+	if f := frames[0].(map[string]interface{}); !strings.HasSuffix(f["function"].(string), "logging.panicInner") {
+		t.Errorf("first frame should be the faulting function, got %v", f)
+	}
+}
+
+func TestPanicFields(t *testing.T) {
+	fields := PanicFields(stringerPanic{id: 1}, nil)
+	if v := fields["panic_value"].(map[string]interface{}); v["string"] != "stringer panic" {
+		t.Errorf("stringer panic value should use String(): %v", v)
+	}
+	if _, ok := fields["frames"]; ok {
+		t.Errorf("frames should be omitted without a stack")
+	}
+	if _, ok := PanicFields("plain", nil)["panic_value"]; ok {
+		t.Errorf("basic values should only be recorded as text")
+	}
+
+	base := &os.PathError{Op: "open", Path: "/x", Err: os.ErrNotExist}
+	fields = PanicFields(fmt.Errorf("load config: %w", errors.Join(base, io.EOF)), nil)
+	chain := fields["panic_chain"].([]map[string]interface{})
+	var types []string
+	for _, e := range chain {
+		types = append(types, e["type"].(string))
+	}
+	if got := strings.Join(types, ","); got != "*fmt.wrapError,*errors.joinError,*fs.PathError,*errors.errorString,*errors.errorString" {
+		t.Errorf("unexpected panic chain: %v", chain)
+	}
+	if _, ok := fields["runtime_error"]; ok {
+		t.Errorf("runtime_error should only be set for runtime errors")
+	}
+
+	stack := make([]byte, 4096)
+	frames := ParseStack(stack[:runtime.Stack(stack, false)])
+	if len(frames) < 2 || frames[0].Function != "github.com/Clov614/logging.TestPanicFields" || frames[1].Function != "testing.tRunner" {
+		t.Errorf("unexpected frames: %+v", frames)
+	}
+}
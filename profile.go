@@ -0,0 +1,53 @@
+// @Author Clover
+// @Data 2026/10/18 上午7:30:00
+// @Desc 低内存环境使用的配置预设
+
+package logging
+
+import (
+	"reflect"
+
+	"github.com/rs/zerolog"
+)
+
+// Config.Profile 的取值
+const (
+	ProfileDefault = "default" // 不修改配置
+	ProfileMinimal = "minimal" // 适用于内存很小的设备，缩小或关闭内部缓冲
+)
+
+// profilePresets 各预设的配置，只填充用户未设置的字段；
+// minimal 同步写入（不开启 DiodeMode），缩小异步队列与转发缓存，Fatal 以下不捕获调用栈，关闭字段值驻留，并严格限制单条日志的大小
+var profilePresets = map[string]Config{
+	ProfileDefault: {},
+	ProfileMinimal: {
+		AsyncQueueSize:    64,
+		RelayBufferSize:   64,
+		MaxFieldsPerEvent: 32,
+		MaxEventBytes:     2048,
+		StackCaptureLevel: zerolog.FatalLevel,
+		DisableInterning:  true,
+	},
+}
+
+// applyProfile 使用 config.Profile 的预设填充未设置的字段，返回被填充的字段名；预设不存在时返回 false
+func applyProfile(config *Config) ([]string, bool) {
+	name := config.Profile
+	if name == "" {
+		name = ProfileDefault
+	}
+	preset, ok := profilePresets[name]
+	if !ok {
+		return nil, false
+	}
+	var applied []string
+	v, pv := reflect.ValueOf(config).Elem(), reflect.ValueOf(preset)
+	for i := 0; i < pv.NumField(); i++ {
+		if pv.Field(i).IsZero() || !v.Field(i).IsZero() {
+			continue
+		}
+		v.Field(i).Set(pv.Field(i))
+		applied = append(applied, pv.Type().Field(i).Name)
+	}
+	return applied, true
+}
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// resetProfileLimits 恢复预设修改的全局限制，它们在 Close 之后仍然保留
+func resetProfileLimits(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		maxFieldsPerEvent.Store(0)
+		maxEventBytes.Store(0)
+		stackCaptureLevel.Store(0)
+		stateMu.Lock()
+		relayBufferSize = defaultRelayBufferSize
+		activeProfileFields = nil
+		stateMu.Unlock()
+	})
+}
+
+func TestProfileMinimal(t *testing.T) {
+	resetProfileLimits(t)
+	out := &syncBuffer{}
+	if err := InitLogger(Config{Profile: ProfileMinimal, MaxEventBytes: 8192}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+
+	snapshot := ConfigSnapshot()
+	field := func(name string) map[string]interface{} {
+		entry, _ := snapshot[name].(map[string]interface{})
+		return entry
+	}
+	if f := field("RelayBufferSize"); f["value"] != 64 || f["source"] != ConfigSourceProfile || f["profile"] != ProfileMinimal {
+		t.Errorf("RelayBufferSize = %v", f)
+	}
+	if f := field("StackCaptureLevel"); f["value"] != zerolog.FatalLevel || f["source"] != ConfigSourceProfile {
+		t.Errorf("StackCaptureLevel = %v", f)
+	}
+	// 显式设置的字段优先于预设
+	if f := field("MaxEventBytes"); f["value"] != 8192 || f["source"] != ConfigSourceCode {
+		t.Errorf("MaxEventBytes = %v", f)
+	}
+	if f := field("DiodeMode"); f["value"] != false {
+		t.Errorf("minimal profile should write synchronously: %v", f)
+	}
+
+	// Fatal 以下不捕获调用栈
+	func() {
+		defer Recover("worker crashed")
+		panic("boom")
+	}()
+	entry := relayedLine(t, out, "worker crashed")
+	if _, ok := entry["frames"]; ok || entry["panic"] != "boom" {
+		t.Errorf("minimal profile should not capture stacks below fatal: %v", entry)
+	}
+}
+
+func TestProfileUnknown(t *testing.T) {
+	resetProfileLimits(t)
+	out := &syncBuffer{}
+	if err := InitLogger(Config{Profile: "tiny"}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+	if !strings.Contains(out.String(), "Unknown profile 'tiny'") || maxEventBytes.Load() != 0 {
+		t.Errorf("unknown profile should warn and keep the defaults: %q", out.String())
+	}
+}
+
+// profileFootprint 在聚合进程不可用时以 profile 输出相同的日志，返回输出结束后仍占用的堆内存增量
+func profileFootprint(t *testing.T, profile string) int64 {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	stderr := os.Stderr
+	os.Stderr = devNull // 聚合进程不可用时写入的本地输出
+	defer func() { os.Stderr = stderr }()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	// 连接失败时返回错误，但输出仍然保留并缓存日志
+	InitLogger(Config{Profile: profile, ProjectName: "edge", RelayTo: socketPath(t)})
+	payload := strings.Repeat("x", 8<<10)
+	for i := 0; i < 2000; i++ {
+		Info("sensor reading", map[string]interface{}{"payload": payload, "seq": i})
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	Close()
+	return int64(after.HeapAlloc) - int64(before.HeapAlloc)
+}
+
+func TestProfileMemoryFootprint(t *testing.T) {
+	resetProfileLimits(t)
+	useInfoLevel(t)
+	def := profileFootprint(t, ProfileDefault)
+	minimal := profileFootprint(t, ProfileMinimal)
+	t.Logf("retained heap: default %d bytes, minimal %d bytes", def, minimal)
+	if def < 4<<20 || minimal*8 > def {
+		t.Errorf("minimal profile should retain far less memory: default %d bytes, minimal %d bytes", def, minimal)
+	}
+}
@@ -0,0 +1,45 @@
+// Package promhook
+// @Author Clover
+// @Data 2026/10/18 上午12:10:00
+// @Desc 以 Prometheus 直方图记录每次写入输出目标的耗时
+package promhook
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// WriterLatencyHook 实现 logging.WriteObserver，按 level 与 writer_type 标签记录写入耗时（秒），
+// 同时实现 prometheus.Collector，可直接注册到 Registry
+type WriterLatencyHook struct {
+	hist *prometheus.HistogramVec
+}
+
+// NewWriterLatencyHook 创建名为 <namespace>_logging_write_duration_seconds 的直方图，
+// 通过 logging.WithWriteObserver 传给 InitLogger 后开始记录
+func NewWriterLatencyHook(namespace string) *WriterLatencyHook {
+	return &WriterLatencyHook{
+		hist: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "logging",
+			Name:      "write_duration_seconds",
+			Help:      "Latency of log writes per level and writer type.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10), // 10µs ~ 2.6s
+		}, []string{"level", "writer_type"}),
+	}
+}
+
+// ObserveWrite 记录一次写入的耗时
+func (h *WriterLatencyHook) ObserveWrite(level zerolog.Level, writerType string, d time.Duration) {
+	h.hist.WithLabelValues(level.String(), writerType).Observe(d.Seconds())
+}
+
+func (h *WriterLatencyHook) Describe(ch chan<- *prometheus.Desc) {
+	h.hist.Describe(ch)
+}
+
+func (h *WriterLatencyHook) Collect(ch chan<- prometheus.Metric) {
+	h.hist.Collect(ch)
+}
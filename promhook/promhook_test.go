@@ -0,0 +1,42 @@
+package promhook_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Clov614/logging"
+	"github.com/Clov614/logging/promhook"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func ExampleNewWriterLatencyHook() {
+	hook := promhook.NewWriterLatencyHook("app")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(hook)
+
+	var buf bytes.Buffer
+	if err := logging.InitLogger(logging.Config{}, logging.WithWriters(&buf), logging.WithWriteObserver(hook)); err != nil {
+		panic(err)
+	}
+	defer logging.Close()
+	logging.Info("first")
+	logging.Warn("second")
+	logging.Warn("third")
+
+	families, err := reg.Gather()
+	if err != nil {
+		panic(err)
+	}
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			var labels []string
+			for _, lp := range m.GetLabel() {
+				labels = append(labels, lp.GetName()+"="+lp.GetValue())
+			}
+			fmt.Println(mf.GetName(), labels, m.GetHistogram().GetSampleCount())
+		}
+	}
+	// Output:
+	// app_logging_write_duration_seconds [level=info writer_type=*bytes.Buffer] 1
+	// app_logging_write_duration_seconds [level=warn writer_type=*bytes.Buffer] 2
+}
@@ -0,0 +1,250 @@
+// Package redissink
+// @Author Clover
+// @Data 2026/10/16 上午10:30:00
+// @Desc 将日志写入 Redis Stream。本包不依赖任何 Redis 客户端库，也不提供适配器，
+// 调用方需自行实现 Client（例如包装 go-redis 的 UniversalClient，README 中有示例）
+package redissink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Clov614/logging/internal/watermark"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultQueueSize     = 1024
+	defaultFlushInterval = time.Second
+	defaultTimeout       = 5 * time.Second
+)
+
+// ErrClosed 向已关闭的 Sink 写入日志时返回
+var ErrClosed = errors.New("redissink: sink closed")
+
+// Client Redis 客户端接口，由调用方实现，单节点和集群客户端均可通过适配实现
+type Client interface {
+	// XAddBatch 以 pipeline 方式向 stream 批量执行 XADD，maxLen 大于 0 时附带 MAXLEN ~ maxLen
+	XAddBatch(ctx context.Context, stream string, maxLen int64, entries []map[string]interface{}) error
+}
+
+// Pinger 可选的客户端接口，用于在不写入数据的情况下检查连通性
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Config 用于配置 Redis Stream 输出
+type Config struct {
+	Stream        string        // Stream 名称
+	MaxLen        int64         // 近似修剪长度 (MAXLEN ~ N)，0 表示不修剪
+	BatchSize     int           // 单次 pipeline 最多包含的条目数
+	QueueSize     int           // 待写入队列长度，队列满时丢弃日志
+	FlushInterval time.Duration // 定时写入间隔
+	Timeout       time.Duration // 单次批量写入的超时时间
+}
+
+// Sink 将每行 JSON 日志写入 Redis Stream，实现 io.Writer
+type Sink struct {
+	client    Client
+	config    Config
+	queue     chan map[string]interface{}
+	flushNow  chan struct{} // 请求立即写入当前批次
+	done      chan struct{}
+	mu        sync.Mutex
+	closed    bool
+	dropped   atomic.Uint64
+	mark      watermark.Watermark // 已入队与已处理（写入或丢弃）的条目序号
+	processed uint64              // 已处理的条目数，仅由后台写入协程访问
+}
+
+// New 创建 Redis Stream 输出并启动后台写入
+func New(client Client, config Config) *Sink {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	s := &Sink{
+		client:   client,
+		config:   config,
+		queue:    make(chan map[string]interface{}, config.QueueSize),
+		flushNow: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write 解析一行 JSON 日志并加入写入队列，队列已满时丢弃并计数
+func (s *Sink) Write(p []byte) (int, error) {
+	entry, err := flatten(p)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	// 只有持有 mu 时才会入队，后台协程只会取出，检查之后的发送不会阻塞；
+	// 序号在发送前分配，后台协程处理该条目时它一定已计入 Pending，Barrier 也会等待它
+	if len(s.queue) == cap(s.queue) {
+		s.dropped.Add(1)
+		return len(p), nil
+	}
+	s.mark.Issue()
+	s.queue <- entry
+	return len(p), nil
+}
+
+// Dropped 返回因队列已满或 Redis 不可用而丢弃的日志条数
+func (s *Sink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Barrier 立即写入当前批次，并等待调用前入队的日志全部写入或丢弃
+func (s *Sink) Barrier(ctx context.Context) error {
+	select {
+	case s.flushNow <- struct{}{}:
+	default:
+	}
+	return s.mark.WaitIssued(ctx)
+}
+
+// Ping 检查与 Redis 的连通性，不写入任何数据，供 logging 的 DryRun 模式使用
+// 客户端未实现 Pinger 时返回 nil
+func (s *Sink) Ping(ctx context.Context) error {
+	if p, ok := s.client.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// Headroom 返回写入队列的剩余空间，供 logging.LogBuffer.Flush 分批输出
+func (s *Sink) Headroom() int {
+	return cap(s.queue) - len(s.queue)
+}
+
+// Pending 返回已入队但尚未写入的日志条数
+func (s *Sink) Pending() uint64 {
+	return s.mark.Pending()
+}
+
+// Close 写入队列中剩余的日志并停止后台写入
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]map[string]interface{}, 0, s.config.BatchSize)
+	for {
+		select {
+		case entry, ok := <-s.queue:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= s.config.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		case <-s.flushNow:
+			// 取出已入队的日志，一并写入；与 LockFreeLogBuffer.Flush 相同，取空时不阻塞等待
+			batch = s.drainQueued(batch)
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// drainQueued 将调用时已入队的日志追加到 batch，队列为空或已关闭时立即返回
+func (s *Sink) drainQueued(batch []map[string]interface{}) []map[string]interface{} {
+	for n := len(s.queue); n > 0; n-- {
+		select {
+		case entry, ok := <-s.queue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (s *Sink) flush(batch []map[string]interface{}) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	if err := s.client.XAddBatch(ctx, s.config.Stream, s.config.MaxLen, batch); err != nil {
+		s.dropped.Add(uint64(len(batch)))
+	}
+	s.processed += uint64(len(batch))
+	s.mark.Complete(s.processed)
+}
+
+// flatten 将 JSON 日志展开为 Stream 条目，嵌套对象和数组以 JSON 字符串保存
+func flatten(p []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("redissink: invalid log line: %w", err)
+	}
+
+	entry := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		switch val := v.(type) {
+		case string:
+			entry[k] = val
+		case json.Number:
+			entry[k] = val.String()
+		case bool:
+			entry[k] = fmt.Sprint(val)
+		case nil:
+			entry[k] = ""
+		default:
+			b, err := json.Marshal(val)
+			if err != nil {
+				return nil, fmt.Errorf("redissink: encode field %s: %w", k, err)
+			}
+			entry[k] = string(b)
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,165 @@
+package redissink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockClient 记录 XAddBatch 调用
+type mockClient struct {
+	mu      sync.Mutex
+	batches [][]map[string]interface{}
+	maxLen  int64
+	stream  string
+	err     error
+}
+
+func (c *mockClient) XAddBatch(_ context.Context, stream string, maxLen int64, entries []map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	batch := make([]map[string]interface{}, len(entries))
+	copy(batch, entries)
+	c.batches = append(c.batches, batch)
+	c.stream = stream
+	c.maxLen = maxLen
+	return nil
+}
+
+func (c *mockClient) entries() []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var all []map[string]interface{}
+	for _, b := range c.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func TestSinkFlattenAndBatch(t *testing.T) {
+	client := &mockClient{}
+	sink := New(client, Config{Stream: "logs", MaxLen: 1000, BatchSize: 2, FlushInterval: time.Hour})
+
+	lines := []string{
+		`{"level":"info","message":"a","count":3,"ok":true,"ctx":{"user":"u1"}}`,
+		`{"level":"warn","message":"b","tags":["x","y"]}`,
+		`{"level":"error","message":"c"}`,
+	}
+	for _, line := range lines {
+		if _, err := sink.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	// Close 需要写入剩余不足一批的日志
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	entries := client.entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if client.stream != "logs" || client.maxLen != 1000 {
+		t.Errorf("unexpected stream args: %s %d", client.stream, client.maxLen)
+	}
+	first := entries[0]
+	if first["count"] != "3" || first["ok"] != "true" || first["ctx"] != `{"user":"u1"}` {
+		t.Errorf("unexpected flattened entry: %v", first)
+	}
+	if entries[1]["tags"] != `["x","y"]` {
+		t.Errorf("unexpected array encoding: %v", entries[1])
+	}
+
+	if _, err := sink.Write([]byte(`{"message":"late"}`)); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestSinkBarrier(t *testing.T) {
+	client := &mockClient{}
+	sink := New(client, Config{Stream: "logs", BatchSize: 100, FlushInterval: time.Hour})
+	defer sink.Close()
+
+	for i := 0; i < 3; i++ {
+		sink.Write([]byte(`{"message":"m"}`))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sink.Barrier(ctx); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	if n := len(client.entries()); n != 3 {
+		t.Errorf("barrier should flush pending batch, got %d entries", n)
+	}
+	if sink.Pending() != 0 {
+		t.Errorf("expected no pending entries, got %d", sink.Pending())
+	}
+}
+
+func TestSinkDropWhenUnavailable(t *testing.T) {
+	client := &mockClient{err: errors.New("connection refused")}
+	sink := New(client, Config{Stream: "logs", BatchSize: 10, FlushInterval: time.Hour})
+	for i := 0; i < 5; i++ {
+		if _, err := sink.Write([]byte(`{"message":"m"}`)); err != nil {
+			t.Fatalf("write should not fail when redis is down: %v", err)
+		}
+	}
+	sink.Close()
+	if sink.Dropped() != 5 {
+		t.Errorf("expected 5 dropped entries, got %d", sink.Dropped())
+	}
+}
+
+// TestSinkPendingNeverWraps 条目在入队前计入 Pending，后台协程先处理条目时 Pending 也不会回绕
+func TestSinkPendingNeverWraps(t *testing.T) {
+	client := &mockClient{}
+	sink := New(client, Config{Stream: "logs", BatchSize: 1, QueueSize: 2, FlushInterval: time.Hour})
+	defer sink.Close()
+
+	const writers, perWriter = 8, 500
+	var wg sync.WaitGroup
+	stop, sampled := make(chan struct{}), make(chan struct{})
+	var maxPending uint64
+	go func() {
+		defer close(sampled)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				if p := sink.Pending(); p > maxPending {
+					maxPending = p
+				}
+			}
+		}
+	}()
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				sink.Write([]byte(`{"message":"m"}`))
+			}
+		}()
+	}
+	wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sink.Barrier(ctx); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+	close(stop)
+	<-sampled
+
+	if maxPending > writers*perWriter {
+		t.Errorf("Pending wrapped around: %d", maxPending)
+	}
+	if sink.Pending() != 0 || uint64(len(client.entries()))+sink.Dropped() != writers*perWriter {
+		t.Errorf("pending %d, written %d, dropped %d", sink.Pending(), len(client.entries()), sink.Dropped())
+	}
+}
@@ -0,0 +1,362 @@
+// @Author Clover
+// @Data 2026/10/18 上午6:30:00
+// @Desc 同一主机上多个进程通过 UNIX 域套接字将日志转发给一个聚合进程
+
+package logging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// 聚合进程为转发的日志添加的字段
+const (
+	originPIDKey  = "origin_pid"  // 发送日志的进程号
+	originNameKey = "origin_name" // 发送日志的进程名称，即其 ProjectName
+	originTimeKey = "origin_time" // 发送方写入日志的时间，time 字段为聚合进程收到的时间
+)
+
+const (
+	relayFrameHeader   = 4       // 每帧前大端序 uint32 的长度
+	relayMaxFrame      = 1 << 20 // 单帧的最大长度，超出时视为协议错误并断开连接
+	relayRetryInterval = time.Second
+
+	defaultRelayBufferSize = 1024 // 转发进程不可用时默认最多缓存的日志条数
+)
+
+// relayHello 连接建立后发送的第一帧，之后每帧为一条 JSON 日志
+type relayHello struct {
+	PID        int    `json:"pid"`
+	Name       string `json:"name"`
+	ProjectKey string `json:"project_key"` // 发送方的 ProjectKey，聚合进程以自身的项目字段替换它
+}
+
+var (
+	relay           *relayWriter             // Config.RelayTo 的转发输出，未开启时为 nil，由 stateMu 保护
+	relayBufferSize = defaultRelayBufferSize // Config.RelayBufferSize，由 stateMu 保护
+)
+
+// appendFrame 将 payload 加上长度前缀追加到 dst
+func appendFrame(dst, payload []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+	return append(dst, payload...)
+}
+
+// readFrame 读取一帧，io.ReadFull 会等待被拆分到多次读取中的数据；
+// 帧不完整时连接关闭返回 io.ErrUnexpectedEOF，帧之间关闭返回 io.EOF
+func readFrame(r io.Reader, buf []byte) ([]byte, error) {
+	var header [relayFrameHeader]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(header[:])
+	if n > relayMaxFrame {
+		return nil, fmt.Errorf("relay frame of %d bytes exceeds %d", n, relayMaxFrame)
+	}
+	if cap(buf) < int(n) {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// RelayListener 聚合进程中接收其他进程转发的日志
+type RelayListener struct {
+	ln   net.Listener
+	path string
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// ListenRelay 在 socketPath 上监听其他进程（Config.RelayTo）转发的日志，每条日志经过本进程的字段规则、
+// 过滤与脱敏后写入本进程的输出目标，并添加 origin_pid、origin_name 与 origin_time 字段；
+// socketPath 上残留的、没有进程监听的套接字文件会被删除
+func ListenRelay(socketPath string) (*RelayListener, error) {
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil && removeStaleSocket(socketPath) {
+		ln, err = net.Listen("unix", socketPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("relay listen %s: %w", socketPath, err)
+	}
+	rl := &RelayListener{ln: ln, path: socketPath, conns: make(map[net.Conn]struct{})}
+	rl.wg.Add(1)
+	go rl.accept()
+	return rl, nil
+}
+
+// removeStaleSocket 删除没有进程监听的套接字文件，返回是否删除
+func removeStaleSocket(path string) bool {
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return false
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return false
+	}
+	return os.Remove(path) == nil
+}
+
+func (rl *RelayListener) accept() {
+	defer rl.wg.Done()
+	for {
+		conn, err := rl.ln.Accept()
+		if err != nil {
+			return
+		}
+		rl.mu.Lock()
+		if rl.closed {
+			rl.mu.Unlock()
+			conn.Close()
+			return
+		}
+		rl.conns[conn] = struct{}{}
+		rl.wg.Add(1)
+		rl.mu.Unlock()
+		go rl.serve(conn)
+	}
+}
+
+// serve 读取一个连接上的日志直到连接关闭，协议错误时断开连接
+func (rl *RelayListener) serve(conn net.Conn) {
+	defer rl.wg.Done()
+	defer func() {
+		rl.mu.Lock()
+		delete(rl.conns, conn)
+		rl.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	frame, err := readFrame(r, nil)
+	var hello relayHello
+	if err == nil {
+		err = json.Unmarshal(frame, &hello)
+	}
+	for err == nil {
+		if frame, err = readFrame(r, frame); err == nil {
+			injectRelayed(hello, frame)
+		}
+	}
+	if err != io.EOF && !rl.isClosed() {
+		// 发送方在写入一帧的中途退出，或发送的不是本协议的数据；不完整的帧被丢弃
+		currentLogger().Warn().Err(err).Int(originPIDKey, hello.PID).Str(originNameKey, hello.Name).
+			Msg("Relay connection closed abnormally")
+	}
+}
+
+func (rl *RelayListener) isClosed() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.closed
+}
+
+// injectRelayed 将转发的一行日志写入本进程的输出目标
+func injectRelayed(hello relayHello, line []byte) {
+	entry, err := parseLogEntry(line)
+	if err != nil {
+		currentLogger().Warn().Err(err).Int(originPIDKey, hello.PID).Str(originNameKey, hello.Name).
+			Msg("Dropped malformed relayed log entry")
+		return
+	}
+	if t, ok := entry.Fields[zerolog.TimestampFieldName]; ok {
+		entry.Fields[originTimeKey] = t
+		delete(entry.Fields, zerolog.TimestampFieldName)
+	}
+	if hello.ProjectKey != "" {
+		delete(entry.Fields, hello.ProjectKey)
+	}
+	entry.Fields[originPIDKey] = hello.PID
+	entry.Fields[originNameKey] = hello.Name
+	emit(currentLogger().WithLevel(entry.Level), entry.Level, nil, entry.Message, []map[string]interface{}{entry.Fields})
+}
+
+// Close 停止监听并断开所有连接，等待正在处理的日志写入完成
+func (rl *RelayListener) Close() error {
+	rl.mu.Lock()
+	rl.closed = true
+	err := rl.ln.Close()
+	for conn := range rl.conns {
+		conn.Close()
+	}
+	rl.mu.Unlock()
+	rl.wg.Wait()
+	return err
+}
+
+// relayWriter 将日志转发给聚合进程。聚合进程不可用时日志写入本地控制台，同时缓存最近的日志，
+// 按 relayRetryInterval 重新连接，连接恢复后按顺序补发缓存的日志再发送新的日志
+type relayWriter struct {
+	path     string
+	hello    []byte    // 连接建立后发送的第一帧
+	fallback io.Writer // 聚合进程不可用时的本地输出，为 nil 时只缓存
+	limit    int       // 最多缓存的日志条数
+
+	mu        sync.Mutex
+	conn      net.Conn
+	pending   [][]byte // 尚未送达的日志，不含长度前缀
+	dropped   int64    // 缓存已满而丢弃的条数
+	lastDial  time.Time
+	closed    bool
+	buf       []byte
+	retryStop chan struct{}
+	retryDone chan struct{}
+}
+
+// newRelayWriter 创建转发输出并尝试连接，连接失败时仍返回可用的输出，同时返回连接错误
+func newRelayWriter(path, name string, fallback io.Writer) (*relayWriter, error) {
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	hello, _ := json.Marshal(relayHello{PID: os.Getpid(), Name: name, ProjectKey: ProjectKey})
+	w := &relayWriter{
+		path:      path,
+		hello:     appendFrame(nil, hello),
+		fallback:  fallback,
+		limit:     relayBufferSize,
+		retryStop: make(chan struct{}),
+		retryDone: make(chan struct{}),
+	}
+	w.mu.Lock()
+	err := w.connect()
+	w.mu.Unlock()
+	go w.retry(currentClock().NewTicker(relayRetryInterval))
+	if err != nil {
+		return w, fmt.Errorf("relay %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// connect 建立连接、发送 hello 并补发缓存的日志，调用方需持有 mu
+func (w *relayWriter) connect() error {
+	w.lastDial = now()
+	conn, err := net.Dial("unix", w.path)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(w.hello); err != nil {
+		conn.Close()
+		return err
+	}
+	w.conn = conn
+	return w.flushPending()
+}
+
+// flushPending 按顺序发送缓存的日志，失败时断开连接并保留未送达的日志，调用方需持有 mu
+func (w *relayWriter) flushPending() error {
+	for len(w.pending) > 0 {
+		if err := w.send(w.pending[0]); err != nil {
+			return err
+		}
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+	}
+	w.pending = nil
+	return nil
+}
+
+// send 发送一帧，失败时断开连接，调用方需持有 mu
+func (w *relayWriter) send(line []byte) error {
+	w.buf = appendFrame(w.buf[:0], line)
+	if _, err := w.conn.Write(w.buf); err != nil {
+		// 一帧可能只写入了一部分，聚合进程会丢弃不完整的帧，重新连接后整帧重发
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Write 发送一条日志；聚合进程不可用时写入本地控制台并缓存，等待重新连接后补发，不返回错误
+func (w *relayWriter) Write(p []byte) (int, error) {
+	line := p
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil && !w.closed && now().Sub(w.lastDial) >= relayRetryInterval {
+		_ = w.connect()
+	}
+	if w.conn != nil && w.send(line) == nil {
+		return len(p), nil
+	}
+	w.enqueue(line)
+	if w.fallback != nil {
+		w.fallback.Write(p)
+	}
+	return len(p), nil
+}
+
+// enqueue 缓存一条日志，超出 limit 时丢弃最早的日志，调用方需持有 mu
+func (w *relayWriter) enqueue(line []byte) {
+	if w.closed {
+		return
+	}
+	if len(w.pending) >= w.limit {
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.dropped++
+	}
+	w.pending = append(w.pending, append([]byte(nil), line...))
+}
+
+// retry 没有新日志时也定期尝试重新连接，补发缓存的日志
+func (w *relayWriter) retry(ticker Ticker) {
+	defer close(w.retryDone)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			w.mu.Lock()
+			if w.conn == nil && len(w.pending) > 0 {
+				_ = w.connect()
+			}
+			w.mu.Unlock()
+		case <-w.retryStop:
+			return
+		}
+	}
+}
+
+// Close 停止重试，最后尝试一次补发缓存的日志后断开连接；仍未送达的日志已写入本地控制台
+func (w *relayWriter) Close() error {
+	close(w.retryStop)
+	<-w.retryDone
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	var err error
+	if w.conn == nil && len(w.pending) > 0 {
+		err = w.connect()
+	}
+	if w.conn != nil {
+		err = errors.Join(err, w.conn.Close())
+		w.conn = nil
+	}
+	if n := len(w.pending); n > 0 || w.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("relay %s: %d log entries not delivered", w.path, int64(n)+w.dropped))
+	}
+	w.pending = nil
+	return err
+}
@@ -0,0 +1,182 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// initAggregator 以 out 作为唯一输出初始化本进程，作为接收转发日志的聚合进程
+func initAggregator(t *testing.T) *syncBuffer {
+	t.Helper()
+	out := &syncBuffer{}
+	if err := InitLogger(Config{ProjectName: "aggregator"}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+	})
+	return out
+}
+
+// relayedLine 返回 out 中消息为 msg 的日志
+func relayedLine(t *testing.T, out *syncBuffer, msg string) map[string]interface{} {
+	t.Helper()
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.Contains(line, `"message":"`+msg+`"`) {
+			return decodeLine(t, []byte(line))
+		}
+	}
+	t.Fatalf("no entry %q in %s", msg, out.String())
+	return nil
+}
+
+func TestRelayEndToEnd(t *testing.T) {
+	out := initAggregator(t)
+	sock := socketPath(t)
+	rl, err := ListenRelay(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 发送端：与 Config.RelayTo 相同的转发输出
+	fallback := &syncBuffer{}
+	w, err := newRelayWriter(sock, "agent", fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	agent := zerolog.New(w).With().Timestamp().Str(ProjectKey, "agent-project").Logger()
+
+	agent.Warn().Str("k", "v").Msg("first")
+	waitFor(t, "relayed entry", func() bool { return strings.Contains(out.String(), `"first"`) })
+	entry := relayedLine(t, out, "first")
+	if entry["level"] != "warn" || entry["k"] != "v" || entry[ProjectKey] != "aggregator" ||
+		entry[originPIDKey] != float64(os.Getpid()) || entry[originNameKey] != "agent" || entry[originTimeKey] == nil {
+		t.Errorf("unexpected relayed entry: %v", entry)
+	}
+	if _, ok := entry["field_time"]; ok {
+		t.Errorf("sender time should be kept as %s: %v", originTimeKey, entry)
+	}
+
+	// 聚合进程不可用时写入本地输出并缓存，重新启动后按顺序补发
+	rl.Close()
+	agent.Info().Msg("while down")
+	agent.Info().Msg("still down")
+	if !strings.Contains(fallback.String(), "while down") {
+		t.Errorf("entries should fall back to the local console: %q", fallback.String())
+	}
+	if rl, err = ListenRelay(sock); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close() })
+	waitFor(t, "buffered entries to be retried", func() bool { return strings.Contains(out.String(), "still down") })
+	agent.Info().Msg("after restart")
+	waitFor(t, "entry after reconnect", func() bool { return strings.Contains(out.String(), "after restart") })
+	s := out.String()
+	if i, j, k := strings.Index(s, "while down"), strings.Index(s, "still down"), strings.Index(s, "after restart"); !(i < j && j < k) {
+		t.Errorf("relayed entries out of order: %s", s)
+	}
+	if strings.Contains(fallback.String(), "after restart") {
+		t.Errorf("delivered entries should not fall back: %q", fallback.String())
+	}
+}
+
+func TestRelayFraming(t *testing.T) {
+	out := initAggregator(t)
+	sock := socketPath(t)
+	rl, err := ListenRelay(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close() })
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	hello, _ := json.Marshal(relayHello{PID: 42, Name: "raw", ProjectKey: ProjectKey})
+	conn.Write(appendFrame(nil, hello))
+
+	// 一帧被拆分到多次写入时，等待剩余的数据而不是当作完整的帧
+	frame := appendFrame(nil, []byte(`{"level":"info","message":"split"}`))
+	for _, part := range [][]byte{frame[:2], frame[2:10], frame[10:]} {
+		conn.Write(part)
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitFor(t, "split frame", func() bool { return strings.Contains(out.String(), "split") })
+	if entry := relayedLine(t, out, "split"); entry[originPIDKey] != float64(42) || entry[originNameKey] != "raw" {
+		t.Errorf("unexpected relayed entry: %v", entry)
+	}
+
+	// 连接在一帧的中途关闭时丢弃不完整的帧
+	conn.Write(appendFrame(nil, []byte(`{"level":"info","message":"partial"}`))[:12])
+	conn.Close()
+	waitFor(t, "partial frame to be reported", func() bool { return strings.Contains(out.String(), "Relay connection closed abnormally") })
+	if strings.Contains(out.String(), `"partial"`) {
+		t.Errorf("partial frame should be dropped: %s", out.String())
+	}
+
+	// 超出长度上限的帧视为协议错误
+	conn2, err := net.Dial("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	conn2.Write(appendFrame(nil, hello))
+	conn2.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	if _, err := bufio.NewReader(conn2).ReadByte(); err == nil {
+		t.Errorf("connection should be closed after an oversized frame")
+	}
+}
+
+func TestRelayToConfig(t *testing.T) {
+	sock := socketPath(t)
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	config := Config{ProjectName: "agent", LogPath: path, EnableFileOutput: true, RelayTo: sock}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+	Info("via relay", map[string]interface{}{"k": "v"})
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	frame, err := readFrame(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hello relayHello
+	if err := json.Unmarshal(frame, &hello); err != nil || hello.PID != os.Getpid() || hello.Name != "agent" {
+		t.Fatalf("unexpected hello %s: %v", frame, err)
+	}
+	if frame, err = readFrame(r, nil); err != nil {
+		t.Fatal(err)
+	}
+	if entry := decodeLine(t, frame); entry["message"] != "via relay" || entry["k"] != "v" {
+		t.Errorf("unexpected relayed entry: %v", entry)
+	}
+	// 转发时不打开本地日志文件
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("log file should not be created when relaying: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+// @Author Clover
+// @Data 2026/10/18 上午8:30:00
+// @Desc 输出前按映射重命名字段，兼容下游依赖旧字段名的系统
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// renameDroppedKey 重命名后的字段名已被占用而被丢弃的字段（原字段名）
+const renameDroppedKey = "rename_dropped"
+
+var fieldRenames fieldRenamer // Config.FieldRenames 编译后的规则，由 stateMu 保护
+
+// fieldRenamer 原字段名到最终字段名的映射，链式的规则（a->b、b->c）已展开为最终字段名
+type fieldRenamer map[string]string
+
+// compileRenames 展开链式的规则并检查循环，构成循环（a->b、b->a）的规则被忽略并返回错误；
+// 空的目标字段名与重命名为自身的规则被忽略
+func compileRenames(renames map[string]string) (fieldRenamer, []error) {
+	if len(renames) == 0 {
+		return nil, nil
+	}
+	sources := make([]string, 0, len(renames))
+	for from := range renames {
+		sources = append(sources, from)
+	}
+	sort.Strings(sources)
+
+	compiled := make(fieldRenamer, len(renames))
+	cycles := make(map[string]bool)
+	var errs []error
+	for _, from := range sources {
+		path := []string{from}
+		to, cyclic := renames[from], false
+		for {
+			next, ok := renames[to]
+			if !ok || next == "" || next == to {
+				break
+			}
+			if containsString(path, to) {
+				cyclic = true
+				break
+			}
+			path = append(path, to)
+			to = next
+		}
+		switch {
+		case cyclic:
+			cycle := append(path[indexString(path, to):], to)
+			members := append([]string(nil), cycle[:len(cycle)-1]...)
+			sort.Strings(members)
+			if key := strings.Join(members, ","); !cycles[key] {
+				cycles[key] = true
+				errs = append(errs, fmt.Errorf("field rename cycle: %s", strings.Join(cycle, " -> ")))
+			}
+		case to != "" && to != from:
+			compiled[from] = to
+		}
+	}
+	return compiled, errs
+}
+
+// indexString 返回 s 在 list 中的下标，不存在时返回 -1
+func indexString(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply 重命名字段，不修改传入的 map。重命名后的字段名已存在（或 taken 返回 true）时保留已有的字段，
+// 丢弃被重命名的字段并在 rename_dropped 中记录原字段名；多个字段重命名为同一名称时按原字段名排序保留第一个
+func (r fieldRenamer) apply(fields map[string]interface{}, taken func(string) bool) map[string]interface{} {
+	if len(r) == 0 || len(fields) == 0 {
+		return fields
+	}
+	var sources []string
+	for k := range fields {
+		if _, ok := r[k]; ok {
+			sources = append(sources, k)
+		}
+	}
+	if len(sources) == 0 {
+		return fields
+	}
+	sort.Strings(sources)
+	result := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if _, ok := r[k]; !ok {
+			result[k] = v
+		}
+	}
+	var dropped []string
+	for _, k := range sources {
+		to := r[k]
+		if _, exists := result[to]; exists || taken(to) {
+			dropped = append(dropped, k)
+			continue
+		}
+		result[to] = fields[k]
+	}
+	if len(dropped) > 0 {
+		result[renameDroppedKey] = dropped
+	}
+	return result
+}
+
+// renameFields 按 Config.FieldRenames 重命名字段，在白名单、保留字段处理与脱敏之后执行；
+// 除 ReservedKeyAllow 外不会重命名为保留字段，调用方需持有 stateMu
+func renameFields(fields map[string]interface{}) map[string]interface{} {
+	return fieldRenames.apply(fields, func(k string) bool {
+		return reservedKeyPolicy != ReservedKeyAllow && isReservedKey(k)
+	})
+}
+
+// renameWriter 重命名每行 JSON 日志的顶层字段，保持字段顺序，无法解析的行原样写入
+type renameWriter struct {
+	w       io.Writer
+	renames fieldRenamer
+}
+
+// NewRenameWriter 包装输出目标，按 renames 重命名写入的 JSON 日志的顶层字段，
+// 用于某个输出目标需要不同的字段名（例如 Loki 要求标签名只包含字母、数字与下划线），其他输出目标保留原名；
+// 在 Config.FieldRenames 之后生效，冲突的处理与 Config.FieldRenames 相同，规则构成循环时返回错误
+func NewRenameWriter(renames map[string]string, w io.Writer) (io.Writer, error) {
+	compiled, errs := compileRenames(renames)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return renameWriter{w: w, renames: compiled}, nil
+}
+
+func (rw renameWriter) Write(p []byte) (int, error) {
+	if _, err := rw.w.Write(rw.rename(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteLevel 被包装的输出目标实现 zerolog.LevelWriter 时保留级别信息
+func (rw renameWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	lw, ok := rw.w.(zerolog.LevelWriter)
+	if !ok {
+		return rw.Write(p)
+	}
+	if _, err := lw.WriteLevel(level, rw.rename(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// rename 逐行重命名，没有需要重命名的字段时返回 p 本身
+func (rw renameWriter) rename(p []byte) []byte {
+	if len(rw.renames) == 0 {
+		return p
+	}
+	var out []byte
+	changed := false
+	rest := p
+	for len(rest) > 0 {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		rest = rest[len(line):]
+		if renamed, ok := rw.renameLine(line); ok {
+			out = append(out, renamed...)
+			changed = true
+		} else {
+			out = append(out, line...)
+		}
+	}
+	if !changed {
+		return p
+	}
+	return out
+}
+
+// renameLine 重命名一行 JSON 对象的顶层字段，没有需要重命名的字段或无法解析时返回 false
+func (rw renameWriter) renameLine(line []byte) ([]byte, bool) {
+	type member struct {
+		key   string
+		value json.RawMessage
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	var members []member
+	keys := make(map[string]bool)
+	hit := false
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, false
+		}
+		members = append(members, member{key, value})
+		keys[key] = true
+		if _, ok := rw.renames[key]; ok {
+			hit = true
+		}
+	}
+	if _, err := dec.Token(); err != nil || !hit {
+		return nil, false
+	}
+
+	// 重命名后的字段名被未重命名的字段占用，或被排序在前的字段先占用时丢弃
+	var sources []string
+	for _, m := range members {
+		if _, ok := rw.renames[m.key]; ok {
+			sources = append(sources, m.key)
+		}
+	}
+	sort.Strings(sources)
+	taken := make(map[string]bool)
+	for k := range keys {
+		if _, ok := rw.renames[k]; !ok {
+			taken[k] = true
+		}
+	}
+	target := make(map[string]string)
+	var dropped []string
+	for _, k := range sources {
+		to := rw.renames[k]
+		if taken[to] {
+			dropped = append(dropped, k)
+			continue
+		}
+		taken[to] = true
+		target[k] = to
+	}
+
+	buf := bytes.NewBufferString("{")
+	for _, m := range members {
+		key := m.key
+		if to, ok := target[key]; ok {
+			key = to
+		} else if _, ok := rw.renames[key]; ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	if len(dropped) > 0 && !taken[renameDroppedKey] {
+		v, _ := json.Marshal(dropped)
+		buf.WriteString(`,"` + renameDroppedKey + `":`)
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	if bytes.HasSuffix(line, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), true
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCompileRenames(t *testing.T) {
+	compiled, errs := compileRenames(map[string]string{
+		"a": "b", "b": "c", // 链式的规则展开为最终字段名
+		"x": "y", "y": "x", // 循环
+		"same": "same", "empty": "",
+	})
+	want := fieldRenamer{"a": "c", "b": "c"}
+	if !reflect.DeepEqual(compiled, want) {
+		t.Errorf("compiled = %v, want %v", compiled, want)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "x -> y -> x") {
+		t.Errorf("expected one cycle error, got %v", errs)
+	}
+}
+
+// initRenames 以 out 作为额外的输出初始化日志记录器并设置字段重命名
+func initRenames(t *testing.T, config Config, out io.Writer) {
+	t.Helper()
+	if err := InitLogger(config, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		Close()
+		options = loggerOptions{}
+		stateMu.Lock()
+		fieldRenames = nil
+		stateMu.Unlock()
+	})
+}
+
+func TestFieldRenames(t *testing.T) {
+	useInfoLevel(t)
+	out := &syncBuffer{}
+	initRenames(t, Config{ProjectKey: "project", FieldRenames: map[string]string{
+		"user_id": "userId", "tenant": "tenantId", "note": "message", "x": "y", "y": "x",
+	}}, out)
+	SetField(map[string]interface{}{"tenant": "acme"})
+	lines := func() []map[string]interface{} {
+		defer func() {
+			out.mu.Lock()
+			out.buf.Reset()
+			out.mu.Unlock()
+		}()
+		var result []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			result = append(result, decodeLine(t, []byte(line)))
+		}
+		return result
+	}
+	if l := lines(); !strings.Contains(l[len(l)-1]["message"].(string), "cycle") {
+		t.Errorf("rename cycle should be reported: %v", l)
+	}
+
+	Info("login", map[string]interface{}{"user_id": 7, "x": 1})
+	Info("both", map[string]interface{}{"user_id": 7, "userId": 8, "note": "n"})
+	buf := NewLogBuffer()
+	buf.AddEntry(LogEntry{Level: zerolog.InfoLevel, Message: "buffered", Fields: map[string]interface{}{"user_id": 9}})
+	buf.Flush(zerolog.InfoLevel)
+
+	l := lines()
+	if e := l[0]; e["userId"] != float64(7) || e["user_id"] != nil || e["tenantId"] != "acme" || e["x"] != float64(1) {
+		t.Errorf("fields should be renamed, cyclic rules ignored: %v", e)
+	}
+	// 重命名后的字段名已存在时保留已有的字段
+	if e := l[1]; e["userId"] != float64(8) || e["message"] != "both" ||
+		!reflect.DeepEqual(e[renameDroppedKey], []interface{}{"note", "user_id"}) {
+		t.Errorf("collisions should keep the existing field: %v", e)
+	}
+	if e := l[2]; e["userId"] != float64(9) || e["message"] != "buffered" {
+		t.Errorf("buffered entries should be renamed: %v", e)
+	}
+}
+
+func TestFieldRenamesPerSink(t *testing.T) {
+	useInfoLevel(t)
+	dir := t.TempDir()
+	mainPath, extraPath := filepath.Join(dir, "app.log"), filepath.Join(dir, "app.logfmt")
+	loki := &syncBuffer{}
+	w, err := NewRenameWriter(map[string]string{"trace.id": "trace_id", "http.status": "status"}, loki)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initRenames(t, Config{
+		LogPath: mainPath, EnableFileOutput: true,
+		Outputs: []OutputConfig{{Path: extraPath, Format: OutputFormatLogfmt, FieldRenames: map[string]string{"trace.id": "trace"}}},
+	}, w)
+	Info("served", map[string]interface{}{"trace.id": "abc", "http.status": 200, "status": "ok"})
+	Close()
+
+	// 日志文件保留原字段名
+	main, _ := os.ReadFile(mainPath)
+	if !strings.Contains(string(main), `"trace.id":"abc"`) || !strings.Contains(string(main), `"http.status":200`) {
+		t.Errorf("main file should keep the original names: %s", main)
+	}
+	extra, _ := os.ReadFile(extraPath)
+	if !strings.Contains(string(extra), "trace=abc") || strings.Contains(string(extra), "trace.id") {
+		t.Errorf("output should use its own renames: %s", extra)
+	}
+	line := loki.String()
+	e := decodeLine(t, []byte(line))
+	if e["trace_id"] != "abc" || e["status"] != "ok" || e["trace.id"] != nil ||
+		!reflect.DeepEqual(e[renameDroppedKey], []interface{}{"http.status"}) {
+		t.Errorf("sink should use label-safe names and keep existing fields: %v", e)
+	}
+	// 保持字段顺序
+	if strings.Index(line, `"level"`) > strings.Index(line, `"trace_id"`) || !strings.HasSuffix(line, "}\n") {
+		t.Errorf("renamed line should keep field order and the newline: %q", line)
+	}
+}
+
+func TestNewRenameWriterCycle(t *testing.T) {
+	if _, err := NewRenameWriter(map[string]string{"a": "b", "b": "a"}, &syncBuffer{}); err == nil {
+		t.Errorf("expected a cycle error")
+	}
+}
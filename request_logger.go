@@ -0,0 +1,98 @@
+// @Author Clover
+// @Data 2026/10/17 下午10:20:00
+// @Desc 请求范围的日志记录器，请求结束时输出缓冲的日志与访问日志
+
+package logging
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// requestIDHeader 优先使用的请求 ID 请求头，不存在时随机生成
+const requestIDHeader = "X-Request-ID"
+
+// ReqLogger 请求范围的日志记录器，日志自动携带请求字段并缓冲到请求结束时一并输出，
+// 同一请求的日志因此在输出中连续出现
+type ReqLogger struct {
+	fields map[string]interface{}
+	buf    *LogBuffer
+	start  time.Time
+}
+
+// RequestLogger 创建请求范围的日志记录器，预置 request_id、method、path、remote_addr 与 user_agent 字段
+// 返回的清理函数应在处理函数中 defer 调用：输出缓冲的日志，随后输出附带 duration_ms 的访问日志；
+// w 实现 Status() int 或 BytesWritten() int 时（常见于中间件包装的 ResponseWriter），访问日志还会附带 status 与 bytes
+func RequestLogger(r *http.Request, w http.ResponseWriter) (*ReqLogger, func()) {
+	id := r.Header.Get(requestIDHeader)
+	if id == "" {
+		id = randomHex(8)
+	}
+	rl := &ReqLogger{
+		fields: map[string]interface{}{
+			"request_id":  id,
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"remote_addr": r.RemoteAddr,
+			"user_agent":  r.UserAgent(),
+		},
+		buf:   NewLogBuffer(),
+		start: now(),
+	}
+	var once sync.Once
+	return rl, func() { once.Do(func() { rl.finish(w) }) }
+}
+
+// ID 返回请求 ID
+func (rl *ReqLogger) ID() string {
+	return rl.fields["request_id"].(string)
+}
+
+func (rl *ReqLogger) Debug(msg string, fields ...map[string]interface{}) {
+	rl.add(zerolog.DebugLevel, msg, fields)
+}
+
+func (rl *ReqLogger) Info(msg string, fields ...map[string]interface{}) {
+	rl.add(zerolog.InfoLevel, msg, fields)
+}
+
+func (rl *ReqLogger) Warn(msg string, fields ...map[string]interface{}) {
+	rl.add(zerolog.WarnLevel, msg, fields)
+}
+
+func (rl *ReqLogger) Error(msg string, fields ...map[string]interface{}) {
+	rl.add(zerolog.ErrorLevel, msg, fields)
+}
+
+func (rl *ReqLogger) ErrorWithErr(err error, msg string, fields ...map[string]interface{}) {
+	if err != nil {
+		fields = append(fields[:len(fields):len(fields)], map[string]interface{}{zerolog.ErrorFieldName: err.Error()})
+	}
+	rl.add(zerolog.ErrorLevel, msg, fields)
+}
+
+// add 将日志与请求字段合并后加入缓冲区
+func (rl *ReqLogger) add(level zerolog.Level, msg string, fields []map[string]interface{}) {
+	rl.buf.AddEntry(LogEntry{Level: level, Message: msg, Fields: rl.with(fields)})
+}
+
+// with 在调用方字段之前添加请求字段，调用方字段同名时优先
+func (rl *ReqLogger) with(fields []map[string]interface{}) map[string]interface{} {
+	return mergeFields(append([]map[string]interface{}{rl.fields}, fields...))
+}
+
+// finish 输出缓冲的日志与访问日志
+func (rl *ReqLogger) finish(w http.ResponseWriter) {
+	rl.buf.Flush(zerolog.TraceLevel)
+	access := map[string]interface{}{"duration_ms": now().Sub(rl.start).Milliseconds()}
+	if s, ok := w.(interface{ Status() int }); ok {
+		access["status"] = s.Status()
+	}
+	if b, ok := w.(interface{ BytesWritten() int }); ok {
+		access["bytes"] = b.BytesWritten()
+	}
+	Info("request completed", rl.with([]map[string]interface{}{access}))
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// statusRecorder 记录状态码与写入字节数的 ResponseWriter，模拟中间件的包装
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	sr.status = code
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	n, err := sr.ResponseWriter.Write(p)
+	sr.bytes += n
+	return n, err
+}
+
+func (sr *statusRecorder) Status() int       { return sr.status }
+func (sr *statusRecorder) BytesWritten() int { return sr.bytes }
+
+func TestRequestLogger(t *testing.T) {
+	buf := captureOutput(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders?id=1", nil)
+	r.Header.Set("X-Request-ID", "req-1")
+	r.Header.Set("User-Agent", "curl/8.0")
+	w := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+
+	func() {
+		rl, done := RequestLogger(r, w)
+		defer done()
+		rl.Info("validating order", map[string]interface{}{"items": 2})
+		rl.ErrorWithErr(errors.New("out of stock"), "reserve failed")
+		if buf.Len() != 0 {
+			t.Errorf("request logs should be buffered until the request ends: %s", buf.String())
+		}
+		clock.skip(25 * time.Millisecond)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("conflict"))
+	}()
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %v", len(lines), lines)
+	}
+	for _, line := range lines {
+		if line["request_id"] != "req-1" || line["method"] != "POST" || line["path"] != "/orders" || line["user_agent"] != "curl/8.0" {
+			t.Errorf("request fields should be pre-populated: %v", line)
+		}
+	}
+	if lines[0]["items"] != float64(2) || lines[1]["error"] != "out of stock" || lines[1]["level"] != "error" {
+		t.Errorf("unexpected buffered entries: %v", lines[:2])
+	}
+	access := lines[2]
+	if access["message"] != "request completed" || access["status"] != float64(409) || access["bytes"] != float64(8) || access["duration_ms"] != float64(25) {
+		t.Errorf("unexpected access log: %v", access)
+	}
+}
+
+func TestRequestLoggerGeneratesID(t *testing.T) {
+	buf := captureOutput(t)
+	rl, done := RequestLogger(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	done()
+	done() // 重复调用不再输出
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || rl.ID() == "" || lines[0]["request_id"] != rl.ID() {
+		t.Fatalf("expected one access line with a generated request id, got %v", lines)
+	}
+	if _, ok := lines[0]["status"]; ok {
+		t.Errorf("status should be omitted when the ResponseWriter does not expose it: %v", lines[0])
+	}
+}
@@ -0,0 +1,315 @@
+// @Author Clover
+// @Data 2026/10/18 上午12:50:00
+// @Desc 限制日志记录器产生的所有文件的总大小
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultRetentionInterval 未配置 MonitorInterval 时检查总大小的间隔
+const defaultRetentionInterval = time.Minute
+
+var retention *retentionMonitor // Config.MaxTotalLogBytes 启动的监控，由 stateMu 保护
+
+// logManifest 日志记录器产生的文件：正在写入的日志文件及其轮转副本，轮转副本只包括
+// 以文件名加 "." 或 "-" 后跟序号或时间戳的文件（app.log.1、app.log.2026-10-17、app.log-20261017）
+// 与 TimestampedFileName 预定义格式产生的文件（app-2026-10-17T15:04:05.log），均可带有 .zst 或 .gz 压缩后缀；
+// app.log.bak 等同一目录下的其他文件与所有 .idx 索引都不计入总大小，也不会被删除
+type logManifest struct {
+	active []string // 正在写入的日志文件的绝对路径，不会被删除
+}
+
+// logBackup 轮转或备份产生的日志文件
+type logBackup struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// backups 返回所有轮转副本，按修改时间从旧到新排列
+func (m logManifest) backups() []logBackup {
+	isActive := make(map[string]bool, len(m.active))
+	dirs := make(map[string][]string)
+	for _, p := range m.active {
+		isActive[p] = true
+		dirs[filepath.Dir(p)] = append(dirs[filepath.Dir(p)], filepath.Base(p))
+	}
+	var backups []logBackup
+	for dir, bases := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			// 索引随对应的日志文件保留，不单独删除
+			if !e.Type().IsRegular() || isActive[path] || strings.HasSuffix(e.Name(), indexExt) || !isBackupOfAny(e.Name(), bases) {
+				continue
+			}
+			if fi, err := e.Info(); err == nil {
+				backups = append(backups, logBackup{path: path, size: fi.Size(), modTime: fi.ModTime()})
+			}
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].modTime.Before(backups[j].modTime)
+		}
+		return backups[i].path < backups[j].path
+	})
+	return backups
+}
+
+// isBackupOfAny 判断 name 是否为 bases 中某个日志文件的轮转副本
+func isBackupOfAny(name string, bases []string) bool {
+	for _, base := range bases {
+		if isBackupOf(name, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBackupOf 判断 name 是否为日志文件 base 的轮转副本：base.<序号或时间戳>、base-<序号或时间戳>
+// 或 TimestampedFileName 的 <stem>-<时间戳><ext>，均可带有压缩后缀
+func isBackupOf(name, base string) bool {
+	name = trimCompressedExt(name)
+	for _, sep := range []string{".", "-"} {
+		if stamp, ok := strings.CutPrefix(name, base+sep); ok && isRotationStamp(stamp) {
+			return true
+		}
+	}
+	// 与 TimestampedFileName 相同地拆分出扩展名（包括压缩后缀）
+	stem := strings.TrimPrefix(base, ".")
+	ext := filepath.Ext(stem)
+	if ext == compressedExt || ext == ".gz" {
+		ext = filepath.Ext(strings.TrimSuffix(stem, ext)) + ext
+	}
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	stamp, ok := strings.CutSuffix(rest, trimCompressedExt(ext))
+	return ok && isRotationStamp(stamp)
+}
+
+// trimCompressedExt 去掉 .zst 或 .gz 压缩后缀
+func trimCompressedExt(name string) string {
+	for _, ext := range []string{compressedExt, ".gz"} {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok {
+			return trimmed
+		}
+	}
+	return name
+}
+
+// isRotationStamp 判断是否为轮转使用的序号、Unix 时间戳、YYYYMMDD 或 TimestampedFileName 的 date/datetime 时间戳
+func isRotationStamp(s string) bool {
+	if s == "" {
+		return false
+	}
+	if strings.Trim(s, "0123456789") == "" {
+		return true
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// activeSize 返回正在写入的日志文件的总大小
+func (m logManifest) activeSize() int64 {
+	var total int64
+	for _, p := range m.active {
+		if fi, err := os.Stat(p); err == nil {
+			total += fi.Size()
+		}
+	}
+	return total
+}
+
+// retentionResult 一次清理的结果
+type retentionResult struct {
+	Deleted   []string
+	Truncated string // 被截断的轮转副本
+	Reclaimed int64
+	Total     int64 // 清理后的总大小
+	OverFloor bool  // 删除所有轮转副本后，正在写入的日志文件仍超过上限
+}
+
+// enforce 总大小超过 maxBytes 时从最旧的轮转副本开始删除，正在写入的日志文件不会被删除；
+// 最旧的副本大于剩余的超出量时，未压缩的副本只截去开头的旧日志，保留末尾完整的行，压缩的副本仍整个删除
+func (m logManifest) enforce(maxBytes int64) retentionResult {
+	backups := m.backups()
+	total := m.activeSize()
+	for _, b := range backups {
+		total += b.size
+	}
+	var res retentionResult
+	for _, b := range backups {
+		excess := total - maxBytes
+		if excess <= 0 {
+			break
+		}
+		if b.size > excess && !isCompressedBackup(b.path) {
+			if kept, err := truncateHead(b.path, b.size-excess); err == nil && kept > 0 {
+				res.Truncated = b.path
+				res.Reclaimed += b.size - kept
+				total -= b.size - kept
+				break
+			}
+		}
+		if err := os.Remove(b.path); err != nil {
+			continue
+		}
+		res.Deleted = append(res.Deleted, b.path)
+		res.Reclaimed += b.size
+		total -= b.size
+	}
+	res.Total = total
+	res.OverFloor = total > maxBytes
+	return res
+}
+
+// isCompressedBackup 压缩的副本截断后无法解压，只能整个删除
+func isCompressedBackup(path string) bool {
+	switch filepath.Ext(path) {
+	case compressedExt, ".gz", ".bz2", ".xz", ".zip":
+		return true
+	}
+	return false
+}
+
+// truncateHead 删除文件开头的内容，保留不超过 keep 字节且从完整的行开始的末尾部分，返回保留的字节数，
+// 没有可以保留的完整行时不修改文件并返回 0
+func truncateHead(path string, keep int64) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	offset := fi.Size() - keep
+	if offset <= 0 {
+		return fi.Size(), nil
+	}
+	// 从 offset 之前的一个字节开始查找换行，offset 恰好位于行首时不丢弃该行
+	r := bufio.NewReader(io.NewSectionReader(f, offset-1, fi.Size()-offset+1))
+	skipped, err := r.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.HasSuffix(skipped, []byte("\n")) || len(rest) == 0 {
+		return 0, nil // 没有可以保留的完整行，由调用方删除整个文件
+	}
+	if _, err := f.WriteAt(rest, 0); err != nil {
+		return 0, err
+	}
+	if err := f.Truncate(int64(len(rest))); err != nil {
+		return 0, err
+	}
+	return int64(len(rest)), nil
+}
+
+// retentionMonitor 按间隔检查日志记录器产生的文件总大小
+type retentionMonitor struct {
+	manifest  logManifest
+	maxBytes  int64
+	overFloor bool // 上一次检查时正在写入的日志文件是否已超过上限
+	ticker    Ticker
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+// startRetention 启动总大小监控，maxBytes 不大于 0 或没有日志文件时返回 nil
+func startRetention(maxBytes int64, interval time.Duration, paths []string) *retentionMonitor {
+	if maxBytes <= 0 || len(paths) == 0 {
+		return nil
+	}
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+	m := &retentionMonitor{
+		manifest: logManifest{active: paths},
+		maxBytes: maxBytes,
+		ticker:   currentClock().NewTicker(interval),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+func (m *retentionMonitor) run() {
+	defer close(m.stopped)
+	for {
+		select {
+		case <-m.ticker.C():
+			m.check()
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// check 清理超出上限的轮转副本并输出清理结果，正在写入的日志文件本身超过上限时只警告一次，直到恢复
+func (m *retentionMonitor) check() {
+	res := m.manifest.enforce(m.maxBytes)
+	logger := currentLogger()
+	if res.Reclaimed > 0 {
+		evt := logger.Info().Strs("deleted", res.Deleted)
+		if res.Truncated != "" {
+			evt = evt.Str("truncated", res.Truncated)
+		}
+		evt.Int64("reclaimed_bytes", res.Reclaimed).Int64("total_bytes", res.Total).Int64("max_total_bytes", m.maxBytes).
+			Msg("Reclaimed log directory space")
+	}
+	if res.OverFloor && !m.overFloor {
+		logger.Warn().Int64("total_bytes", res.Total).Int64("max_total_bytes", m.maxBytes).
+			Msg("Active log files exceed MaxTotalLogBytes, no rotated files left to delete")
+	}
+	m.overFloor = res.OverFloor
+}
+
+// stop 停止监控协程并等待其退出
+func (m *retentionMonitor) stop() {
+	if m == nil {
+		return
+	}
+	m.ticker.Stop()
+	close(m.done)
+	<-m.stopped
+}
+
+// manifestPaths 返回当前正在写入的日志文件的绝对路径，调用方需持有 stateMu
+func manifestPaths() []string {
+	var paths []string
+	if logfile != nil {
+		paths = append(paths, absPath(logPath))
+	}
+	for _, o := range outputs {
+		if o.aliasOf == "" {
+			paths = append(paths, absPath(o.config.Path))
+		}
+	}
+	return paths
+}
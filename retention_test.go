@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAged 写入文件并将修改时间设置为 age 之前
+func writeAged(t *testing.T, path, content string, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mt := time.Now().Add(-age)
+	if err := os.Chtimes(path, mt, mt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetentionDeletesOldestBackupsFirst(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "app.log")
+	output := filepath.Join(dir, "error.log")
+	writeAged(t, active, strings.Repeat("a", 100), 0)
+	writeAged(t, output, strings.Repeat("e", 50), 0)
+	writeAged(t, filepath.Join(dir, "app.log.3.gz"), strings.Repeat("x", 40), 3*time.Hour)
+	writeAged(t, filepath.Join(dir, "error.log.1"), strings.Repeat("y", 40), 2*time.Hour)
+	writeAged(t, filepath.Join(dir, "app.log.1"), strings.Repeat("z", 40), time.Hour)
+	// 不属于日志记录器的文件即使更旧也不会被删除
+	writeAged(t, filepath.Join(dir, "app.logger"), strings.Repeat("o", 500), 24*time.Hour)
+	writeAged(t, filepath.Join(dir, "notes.txt"), strings.Repeat("o", 500), 24*time.Hour)
+
+	m := logManifest{active: []string{active, output}}
+	res := m.enforce(200)
+
+	want := []string{filepath.Join(dir, "app.log.3.gz"), filepath.Join(dir, "error.log.1")}
+	if strings.Join(res.Deleted, ",") != strings.Join(want, ",") {
+		t.Errorf("deleted = %v, want %v", res.Deleted, want)
+	}
+	if res.Reclaimed != 80 || res.Total != 190 || res.OverFloor {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	for _, name := range []string{"app.log", "error.log", "app.log.1", "app.logger", "notes.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+}
+
+func TestRetentionOnlyMatchesRotationNames(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "app.log")
+	writeAged(t, active, "a", 0)
+	archived := filepath.Base(TimestampedFileName(active, time.Date(2026, 10, 17, 15, 4, 5, 0, time.Local), TimestampDateTime))
+	rotations := []string{"app.log.2", "app.log.2026-10-17", "app.log-20261016", archived, "app-2026-10-15.log.gz", "app.log.1.zst"}
+	unrelated := []string{"app.log.bak", "app.log.lock", "app.log.orig", "app.log.1.idx", "other.log.idx", "app-notes.log"}
+	for i, name := range append(rotations, unrelated...) {
+		writeAged(t, filepath.Join(dir, name), strings.Repeat("x", 10), time.Duration(100-i)*time.Hour)
+	}
+
+	res := logManifest{active: []string{active}}.enforce(1)
+	var deleted []string
+	for _, p := range res.Deleted {
+		deleted = append(deleted, filepath.Base(p))
+	}
+	if strings.Join(deleted, ",") != strings.Join(rotations, ",") {
+		t.Errorf("deleted = %v, want %v", deleted, rotations)
+	}
+	for _, name := range append(unrelated, "app.log") {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should survive: %v", name, err)
+		}
+	}
+}
+
+func TestRetentionTruncatesOldestBackup(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "app.log")
+	backup := filepath.Join(dir, "app.log.1")
+	writeAged(t, active, strings.Repeat("a", 50), 0)
+	writeAged(t, backup, "line-1\nline-2\nline-3\nline-4\n", time.Hour)
+
+	res := logManifest{active: []string{active}}.enforce(65)
+
+	// 超出 13 字节，截去开头的两行而不是删除整个副本
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "line-3\nline-4\n" || res.Truncated != backup || len(res.Deleted) != 0 || res.Reclaimed != 14 {
+		t.Errorf("backup should keep its newest complete lines: %q %+v", data, res)
+	}
+}
+
+func TestRetentionFloor(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "app.log")
+	writeAged(t, active, strings.Repeat("a", 100), 0)
+	writeAged(t, filepath.Join(dir, "app.log.1.zst"), strings.Repeat("z", 30), time.Hour)
+
+	res := logManifest{active: []string{active}}.enforce(50)
+
+	// 压缩的副本整个删除，正在写入的日志文件保留
+	if len(res.Deleted) != 1 || !res.OverFloor || res.Total != 100 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if fi, err := os.Stat(active); err != nil || fi.Size() != 100 {
+		t.Errorf("active file should never be touched: %v %v", fi, err)
+	}
+}
+
+func TestMaxTotalLogBytes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	writeAged(t, path+".1", strings.Repeat("z", 4096), time.Hour)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local))
+	out := &syncBuffer{}
+	config := Config{LogPath: path, EnableFileOutput: true, MonitorInterval: time.Second, MaxTotalLogBytes: 1024}
+	if err := InitLogger(config, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+
+	clock.Advance(time.Second)
+	waitFor(t, "retention summary", func() bool { return strings.Contains(out.String(), "Reclaimed log directory space") })
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("oversized backup should be removed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("active log file should be kept: %v", err)
+	}
+}
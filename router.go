@@ -0,0 +1,67 @@
+// @Author Clover
+// @Data 2026/10/16 下午1:30:00
+// @Desc 按字段内容将日志路由到不同的输出目标
+
+package logging
+
+import (
+	"io"
+	"sync"
+)
+
+// Sink 日志输出目标，每次 Write 写入一行完整的日志
+type Sink interface {
+	io.Writer
+}
+
+type route struct {
+	matcher FieldMatcher
+	sink    Sink
+}
+
+// Router 解析每行 JSON 日志，按顺序匹配路由规则并写入第一个匹配的输出目标
+type Router struct {
+	mu          sync.RWMutex
+	routes      []route
+	defaultSink Sink // 未匹配任何规则时的输出目标，为 nil 时丢弃
+}
+
+// NewRouter 创建一个日志路由
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Route 添加一条路由规则，规则按添加顺序匹配
+func (r *Router) Route(matcher FieldMatcher, sink Sink) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route{matcher: matcher, sink: sink})
+	return r
+}
+
+// Default 设置未匹配任何规则时的输出目标
+func (r *Router) Default(sink Sink) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultSink = sink
+	return r
+}
+
+// Write 实现 io.Writer，无法解析的日志写入默认输出目标
+func (r *Router) Write(p []byte) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, err := parseLogEntry(p)
+	if err == nil {
+		for _, rt := range r.routes {
+			if rt.matcher(entry) {
+				return rt.sink.Write(p)
+			}
+		}
+	}
+	if r.defaultSink == nil {
+		return len(p), nil
+	}
+	return r.defaultSink.Write(p)
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRouter(t *testing.T) {
+	security, metrics, fallback := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	router := NewRouter().
+		Route(func(e LogEntry) bool { return e.Fields["event_type"] == "security" }, security).
+		Route(func(e LogEntry) bool { return e.Level >= zerolog.WarnLevel }, metrics).
+		Default(fallback)
+
+	logger := zerolog.New(router)
+	logger.Error().Str("event_type", "security").Msg("login failed")
+	logger.Warn().Str("event_type", "metrics").Msg("slow query")
+	logger.Info().Msg("request done")
+
+	if !strings.Contains(security.String(), "login failed") || strings.Count(security.String(), "\n") != 1 {
+		t.Errorf("unexpected security output: %q", security.String())
+	}
+	if !strings.Contains(metrics.String(), "slow query") || strings.Count(metrics.String(), "\n") != 1 {
+		t.Errorf("unexpected metrics output: %q", metrics.String())
+	}
+	if !strings.Contains(fallback.String(), "request done") || strings.Count(fallback.String(), "\n") != 1 {
+		t.Errorf("unexpected default output: %q", fallback.String())
+	}
+
+	// 没有默认输出目标时丢弃未匹配的日志
+	if n, err := NewRouter().Write([]byte("{}\n")); n != 3 || err != nil {
+		t.Errorf("unexpected result: %d %v", n, err)
+	}
+}
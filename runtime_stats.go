@@ -0,0 +1,72 @@
+// @Author Clover
+// @Data 2026/10/18 上午9:30:00
+// @Desc 定时输出 Go 运行时的内存、GC 与协程统计
+
+package logging
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const runtimeStatsMessage = "Runtime stats"
+
+// RuntimeStatsOption 用于配置 LogRuntimeStats
+type RuntimeStatsOption func(*runtimeStatsOptions)
+
+type runtimeStatsOptions struct {
+	level zerolog.Level // 输出的级别，默认 Debug
+}
+
+// WithRuntimeStatsLevel 设置运行时统计日志的级别
+func WithRuntimeStatsLevel(level zerolog.Level) RuntimeStatsOption {
+	return func(o *runtimeStatsOptions) {
+		o.level = level
+	}
+}
+
+// LogRuntimeStats 在后台每隔 interval 调用 runtime.ReadMemStats 并输出一条运行时统计日志（默认 Debug 级别），
+// 字段为 alloc_bytes、total_alloc_bytes、heap_inuse_bytes、gc_pause_ns（最近一次 GC 的暂停时间）、num_goroutines 与 num_gc，
+// 便于在基于日志的看板中发现内存泄漏与 GC 压力；ctx 取消时停止，interval 不为正数时不启动
+func LogRuntimeStats(ctx context.Context, interval time.Duration, opts ...RuntimeStatsOption) {
+	if interval <= 0 {
+		return
+	}
+	o := runtimeStatsOptions{level: zerolog.DebugLevel}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ticker := currentClock().NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				emit(currentLogger().WithLevel(o.level), o.level, nil, runtimeStatsMessage, []map[string]interface{}{runtimeStats()})
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// runtimeStats 读取当前的运行时统计
+func runtimeStats() map[string]interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	var lastPause uint64
+	if m.NumGC > 0 {
+		lastPause = m.PauseNs[(m.NumGC+255)%256]
+	}
+	return map[string]interface{}{
+		"alloc_bytes":       m.Alloc,
+		"total_alloc_bytes": m.TotalAlloc,
+		"heap_inuse_bytes":  m.HeapInuse,
+		"gc_pause_ns":       lastPause,
+		"num_goroutines":    runtime.NumGoroutine(),
+		"num_gc":            m.NumGC,
+	}
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLogRuntimeStats(t *testing.T) {
+	useInfoLevel(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local))
+	prev := baseLogger
+	out := &syncBuffer{}
+	baseLogger = zerolog.New(out)
+	t.Cleanup(func() { baseLogger = prev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	LogRuntimeStats(ctx, time.Minute)
+	LogRuntimeStats(ctx, time.Minute, WithRuntimeStatsLevel(zerolog.WarnLevel))
+	runtime.GC()
+	clock.step(t, time.Minute)
+	waitFor(t, "runtime stats", func() bool { return strings.Contains(out.String(), runtimeStatsMessage) })
+
+	// 默认的 Debug 级别未启用，只输出 Warn 级别的统计
+	lines := decodeLines(t, bytes.NewBufferString(out.String()))
+	if len(lines) != 1 || lines[0]["level"] != "warn" {
+		t.Fatalf("expected one warn event, got %v", lines)
+	}
+	for _, key := range []string{"alloc_bytes", "total_alloc_bytes", "heap_inuse_bytes", "gc_pause_ns", "num_goroutines", "num_gc"} {
+		if _, ok := lines[0][key]; !ok {
+			t.Errorf("missing %s: %v", key, lines[0])
+		}
+	}
+	if lines[0]["num_gc"].(float64) < 1 || lines[0]["alloc_bytes"].(float64) <= 0 || lines[0]["num_goroutines"].(float64) < 1 {
+		t.Errorf("unexpected stats: %v", lines[0])
+	}
+
+	cancel()
+	waitFor(t, "tickers to stop", func() bool {
+		clock.mu.Lock()
+		defer clock.mu.Unlock()
+		for _, tk := range clock.tickers {
+			if !tk.stopped {
+				return false
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,186 @@
+// @Author Clover
+// @Data 2026/10/17 下午6:10:00
+// @Desc 替换日志消息中的银行卡号、SSN 等敏感内容
+
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Masker 在日志输出前替换消息与字符串字段中的敏感内容，通过 WithMasker 添加
+// 实现需要可以并发调用
+type Masker interface {
+	Mask(s string) string
+}
+
+// WithMasker 添加 Masker，多个 Masker 按添加顺序依次执行
+func WithMasker(ms ...Masker) LoggerOption {
+	return func(o *loggerOptions) {
+		o.maskers = append(o.maskers, ms...)
+	}
+}
+
+// maskString 依次执行所有 Masker，调用方需持有 stateMu
+func maskString(s string) string {
+	for _, m := range options.maskers {
+		s = m.Mask(s)
+	}
+	return s
+}
+
+// maskFields 对字符串类型的字段值执行 Masker，不修改传入的 map，调用方需持有 stateMu
+func maskFields(fields map[string]interface{}) map[string]interface{} {
+	if len(options.maskers) == 0 || len(fields) == 0 {
+		return fields
+	}
+	var result map[string]interface{}
+	for k, v := range fields {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if masked := maskString(s); masked != s {
+			if result == nil {
+				result = make(map[string]interface{}, len(fields))
+				for k, v := range fields {
+					result[k] = v
+				}
+			}
+			result[k] = masked
+		}
+	}
+	if result == nil {
+		return fields
+	}
+	return result
+}
+
+// scrubPattern Scrubber 中的一条规则
+type scrubPattern struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+	minDigits   int               // 字符串中数字少于该数量时跳过正则匹配
+	valid       func(string) bool // 对匹配结果的额外校验，为 nil 时全部替换
+}
+
+// Scrubber 按正则表达式替换敏感内容的 Masker
+type Scrubber struct {
+	mu       sync.RWMutex
+	patterns []scrubPattern
+}
+
+// NewScrubber 创建不包含任何规则的 Scrubber
+func NewScrubber() *Scrubber {
+	return &Scrubber{}
+}
+
+// NewPCIScrubber 创建替换银行卡号与 SSN 的 Scrubber：
+// 通过 Luhn 校验的 13-19 位数字（允许按 4-4-4-4 或 4-6-5 以空格或短横线分组）替换为 [REDACTED-CC]，
+// 形如 123-45-6789 或 123456789 的 SSN 替换为 [REDACTED-SSN]
+func NewPCIScrubber() *Scrubber {
+	return &Scrubber{patterns: []scrubPattern{
+		{
+			name:        "cc",
+			re:          regexp.MustCompile(`\b(?:\d{13,19}|\d{4}(?:[ -]\d{4}){2}[ -]\d{1,7}|\d{4}[ -]\d{6}[ -]\d{5})\b`),
+			replacement: "[REDACTED-CC]",
+			minDigits:   13,
+			valid:       luhnValid,
+		},
+		{
+			name:        "ssn",
+			re:          regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b|\b\d{9}\b`),
+			replacement: "[REDACTED-SSN]",
+			minDigits:   9,
+			valid:       ssnValid,
+		},
+	}}
+}
+
+// AddPattern 添加自定义规则，匹配的内容替换为 [REDACTED-<NAME>]
+func (s *Scrubber) AddPattern(name, re string) error {
+	compiled, err := regexp.Compile(re)
+	if err != nil {
+		return fmt.Errorf("scrubber pattern %s: %w", name, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patterns = append(s.patterns, scrubPattern{
+		name:        name,
+		re:          compiled,
+		replacement: "[REDACTED-" + strings.ToUpper(name) + "]",
+	})
+	return nil
+}
+
+// Mask 按添加顺序依次执行所有规则
+func (s *Scrubber) Mask(str string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	digits := -1
+	for _, p := range s.patterns {
+		if p.minDigits > 0 {
+			if digits < 0 {
+				digits = countDigits(str)
+			}
+			if digits < p.minDigits {
+				continue
+			}
+		}
+		if p.valid == nil {
+			str = p.re.ReplaceAllLiteralString(str, p.replacement)
+		} else {
+			str = p.re.ReplaceAllStringFunc(str, func(m string) string {
+				if p.valid(m) {
+					return p.replacement
+				}
+				return m
+			})
+		}
+		digits = -1
+	}
+	return str
+}
+
+// countDigits 返回字符串中数字的个数
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// luhnValid 对去掉分隔符后的数字执行 Luhn 校验
+func luhnValid(s string) bool {
+	sum, double, n := 0, false, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// ssnValid 排除不可能分配的 SSN（区域号 000、666、9xx，组号 00，序号 0000）
+func ssnValid(s string) bool {
+	s = strings.ReplaceAll(s, "-", "")
+	area, group, serial := s[:3], s[3:5], s[5:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestPCIScrubber(t *testing.T) {
+	s := NewPCIScrubber()
+	cases := []struct{ in, want string }{
+		{"charge card 4111 1111 1111 1111 ok", "charge card [REDACTED-CC] ok"},
+		{"card=4111-1111-1111-1111", "card=[REDACTED-CC]"},
+		{"amex 378282246310005", "amex [REDACTED-CC]"},
+		{"not luhn 4111111111111112", "not luhn 4111111111111112"},
+		{"ssn 078-05-1120 and 219099999", "ssn [REDACTED-SSN] and [REDACTED-SSN]"},
+		{"invalid ssn 000-12-3456 666123456", "invalid ssn 000-12-3456 666123456"},
+		{"order 20261017 shipped", "order 20261017 shipped"},
+		{"no digits here", "no digits here"},
+	}
+	for _, c := range cases {
+		if got := s.Mask(c.in); got != c.want {
+			t.Errorf("Mask(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+
+	if err := s.AddPattern("email", `[\w.]+@[\w.]+`); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Mask("user a.b@example.com paid with 4111111111111111"); got != "user [REDACTED-EMAIL] paid with [REDACTED-CC]" {
+		t.Errorf("unexpected custom pattern result: %q", got)
+	}
+	if err := s.AddPattern("bad", "("); err == nil {
+		t.Error("invalid pattern should return an error")
+	}
+}
+
+func TestWithMasker(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+	out := &bytes.Buffer{}
+	if err := InitLogger(Config{ProjectKey: "project"}, WithWriters(out), WithMasker(NewPCIScrubber())); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+
+	var hooked LogEntry
+	OnLevel(zerolog.DebugLevel, func(e LogEntry) { hooked = e })
+	Debug("payment with 4111 1111 1111 1111 failed", map[string]interface{}{"ssn": "078-05-1120", "amount": 12})
+	m := decodeLine(t, out.Bytes())
+	if m["message"] != "payment with [REDACTED-CC] failed" || m["ssn"] != "[REDACTED-SSN]" || m["amount"] != float64(12) {
+		t.Errorf("unexpected masked output: %v", m)
+	}
+	if strings.Contains(hooked.Message, "4111") {
+		t.Errorf("level hooks should receive the masked message: %q", hooked.Message)
+	}
+
+	out.Reset()
+	lb := NewLogBuffer()
+	lb.AddEntry(LogEntry{Level: zerolog.DebugLevel, Message: "ssn 078-05-1120"})
+	lb.Flush(zerolog.DebugLevel)
+	if m = decodeLine(t, out.Bytes()); m["message"] != "ssn [REDACTED-SSN]" {
+		t.Errorf("buffered entries should be masked: %v", m)
+	}
+}
+
+func BenchmarkPCIScrubber(b *testing.B) {
+	s := NewPCIScrubber()
+	for _, bc := range []struct{ name, msg string }{
+		{"clean", "user logged in from the mobile app"},
+		{"digits", "order 20261017 item 42 quantity 3"},
+		{"card", "charge card 4111 1111 1111 1111 for order 20261017"},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s.Mask(bc.msg)
+			}
+		})
+	}
+}
+
+func BenchmarkInfoWithMasker(b *testing.B) {
+	prev := baseLogger
+	b.Cleanup(func() { baseLogger = prev })
+	for _, bc := range []struct {
+		name string
+		opts []LoggerOption
+	}{
+		{"none", []LoggerOption{WithWriters(io.Discard)}},
+		{"pci", []LoggerOption{WithWriters(io.Discard), WithMasker(NewPCIScrubber())}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			if err := InitLogger(Config{ProjectKey: "project"}, bc.opts...); err != nil {
+				b.Fatal(err)
+			}
+			defer Close()
+			fields := map[string]interface{}{"user": "u1", "order": 20261017}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Info("charge card 4111 1111 1111 1111 for order", fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// @Author Clover
+// @Data 2026/10/18 上午9:10:00
+// @Desc 不输出日志，只判断日志是否会被输出
+
+package logging
+
+import "github.com/rs/zerolog"
+
+// ShouldLog 判断以 key 为消息、fields 为字段的 level 级别日志此时是否会被输出，自身不输出任何内容，
+// 可用于日志被丢弃时一并跳过准备日志内容、记录指标等开销较大的工作，例如：
+//
+//	if logging.ShouldLog(zerolog.DebugLevel, "cache miss") {
+//		logging.Debug("cache miss", expensiveFields())
+//	}
+//
+// 判断与立即调用对应的日志函数一致：依次检查级别、Config.ElevationRules 与 Suppress 的抑制时段，
+// 只读取共享状态，不计入抑制时段的降级数量、升级规则的计数与指标，因此与实际输出交替调用不会重复计数。
+// 不考虑 ctx 中通过 ElevateContext 提升的级别；升级规则只会提高级别，不影响结果
+func ShouldLog(level zerolog.Level, key string, fields ...map[string]interface{}) bool {
+	entry := LogEntry{Level: level, Message: key}
+	if !levelEnabled(level) {
+		if !elevationActive.Load() {
+			return false
+		}
+		entry.Fields = mergeFields(fields)
+		if !elevationMatches(entry) {
+			return false
+		}
+	}
+	if level != zerolog.WarnLevel && level != zerolog.ErrorLevel || suppressActive.Load() == 0 {
+		return true
+	}
+	// 与 emit 一致，抑制时段匹配的是经过字段数限制并加入动态字段后的字段
+	merged, _ := limitFields(mergeFields(fields))
+	entry.Fields = withDynamicFields(merged)
+	if !wouldSuppress(entry) {
+		return true
+	}
+	// 被降级的日志以 Debug 级别输出，不再匹配 Config.ElevationRules
+	return levelEnabled(zerolog.DebugLevel)
+}
+
+// levelEnabled 判断当前日志记录器与 zerolog 的全局级别是否启用 level
+func levelEnabled(level zerolog.Level) bool {
+	l := currentLogger()
+	return level >= l.GetLevel() && level >= zerolog.GlobalLevel()
+}
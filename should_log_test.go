@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// TestShouldLogMatchesEmission ShouldLog 的结果与紧接着调用的日志函数是否输出一致
+func TestShouldLogMatchesEmission(t *testing.T) {
+	useInfoLevel(t)
+	useFakeClock(t, time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local))
+	out := &syncBuffer{}
+	config := Config{ElevationRules: []ElevationRule{{Matcher: MatchField("tenant_id", "acme"), Level: zerolog.DebugLevel}}}
+	if err := InitLogger(config, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; setElevationRules(nil) })
+	cancel := Suppress(MatchMessage("flaky upstream"), time.Hour)
+	defer cancel()
+
+	acme := map[string]interface{}{"tenant_id": "acme"}
+	tests := []struct {
+		level  zerolog.Level
+		msg    string
+		fields map[string]interface{}
+		want   bool
+	}{
+		{zerolog.DebugLevel, "plain debug", nil, false},
+		{zerolog.DebugLevel, "elevated debug", acme, true},
+		{zerolog.InfoLevel, "plain info", nil, true},
+		{zerolog.WarnLevel, "disk almost full", nil, true},
+		// 抑制时段内降级为 Debug 后被全局级别丢弃，也不再匹配提升规则
+		{zerolog.WarnLevel, "flaky upstream", nil, false},
+		{zerolog.ErrorLevel, "flaky upstream", acme, false},
+	}
+	emitters := map[zerolog.Level]func(string, ...map[string]interface{}){
+		zerolog.DebugLevel: Debug, zerolog.InfoLevel: Info, zerolog.WarnLevel: Warn, zerolog.ErrorLevel: Error,
+	}
+	for _, tt := range tests {
+		got := ShouldLog(tt.level, tt.msg, tt.fields)
+		if got != tt.want {
+			t.Errorf("ShouldLog(%s, %q) = %v, want %v", tt.level, tt.msg, got, tt.want)
+		}
+		before := out.String()
+		emitters[tt.level](tt.msg, tt.fields)
+		if emitted := out.String() != before; emitted != got {
+			t.Errorf("ShouldLog(%s, %q) = %v but the event emitted = %v", tt.level, tt.msg, got, emitted)
+		}
+	}
+
+	// 开启 Debug 后被降级的日志会输出
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	if !ShouldLog(zerolog.WarnLevel, "flaky upstream") {
+		t.Errorf("suppressed warnings should pass once debug is enabled")
+	}
+}
+
+// TestShouldLogSharedCounters 与实际输出交替调用 ShouldLog 时，抑制时段、升级规则与指标的计数只包含实际输出的日志
+func TestShouldLogSharedCounters(t *testing.T) {
+	useInfoLevel(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local))
+	out := initEscalations(t, Escalation{Match: MatchMessage("disk slow"), Count: 2, Window: time.Minute})
+	ResetCounts()
+	t.Cleanup(ResetCounts)
+
+	cancel := Suppress(MatchMessage("flaky upstream"), time.Hour)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 5; j++ {
+			if ShouldLog(zerolog.WarnLevel, "flaky upstream") {
+				t.Fatalf("suppressed warning should not pass")
+			}
+			if !ShouldLog(zerolog.WarnLevel, "disk slow") {
+				t.Fatalf("warning should pass")
+			}
+		}
+		Warn("flaky upstream")
+		if i < 2 {
+			Warn("disk slow")
+		}
+		clock.Advance(time.Second)
+	}
+	cancel()
+
+	var summary map[string]interface{}
+	for _, line := range decodeLines(t, bytes.NewBufferString(out.String())) {
+		if _, ok := line["suppressed_count"]; ok {
+			summary = line
+		}
+	}
+	if summary == nil || summary["suppressed_count"] != float64(3) {
+		t.Errorf("suppression should only count emitted events: %v", summary)
+	}
+	if WarnCount() != 2 {
+		t.Errorf("expected 2 warnings, got %d", WarnCount())
+	}
+	if lines := escalationLines(t, out); len(lines) != 0 {
+		t.Fatalf("ShouldLog should not count towards escalations, got %v", lines)
+	}
+
+	// 第三条实际输出的告警超过阈值
+	Warn("disk slow")
+	lines := escalationLines(t, out)
+	if len(lines) != 1 || lines[0][escalationCountKey] != float64(3) {
+		t.Errorf("expected one escalation counting 3 warnings, got %v", lines)
+	}
+}
@@ -0,0 +1,28 @@
+// @Author Clover
+// @Data 2026/10/18 上午8:10:00
+// @Desc 测试中静默日志输出
+
+package logging
+
+import "io"
+
+// InitSilent 初始化不输出任何日志的日志记录器，相当于关闭控制台与文件输出的 InitLogger，
+// 日志仍经过字段处理、统计与 hook，只是写入 io.Discard；用于导入的包在初始化时输出日志的测试
+func InitSilent() error {
+	return InitLogger(Config{}, WithWriters(io.Discard))
+}
+
+// Silence 暂时将当前日志记录器的输出替换为 io.Discard，返回恢复之前日志记录器的函数，
+// 例如 t.Cleanup(logging.Silence())；期间调用 InitLogger 或 SetField 的修改会在恢复时被覆盖
+func Silence() func() {
+	ensureLogger()
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	prev := baseLogger
+	baseLogger = baseLogger.Output(io.Discard)
+	return func() {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		baseLogger = prev
+	}
+}
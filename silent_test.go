@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSilence(t *testing.T) {
+	useInfoLevel(t)
+	out := captureOutput(t)
+	restore := Silence()
+	Info("hidden")
+	if out.Len() != 0 {
+		t.Errorf("silenced logger should not write: %q", out.String())
+	}
+	restore()
+	Info("visible")
+	if entry := decodeLine(t, out.Bytes()); entry["message"] != "visible" {
+		t.Errorf("restore should bring back the previous logger: %v", entry)
+	}
+}
+
+func TestInitSilent(t *testing.T) {
+	useInfoLevel(t)
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+	prev := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = prev }()
+
+	if err := InitSilent(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{} })
+	before := ErrorCount()
+	Info("package init")
+	Error("still counted")
+	if fi, _ := stderr.Stat(); fi.Size() != 0 {
+		t.Errorf("InitSilent should not write to the console")
+	}
+	if ErrorCount() != before+1 {
+		t.Errorf("silent logger should still count entries")
+	}
+}
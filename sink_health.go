@@ -0,0 +1,185 @@
+// @Author Clover
+// @Data 2026/10/17 下午10:40:00
+// @Desc 记录每个输出目标的写入状态
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SinkHealth 单个输出目标的写入状态
+type SinkHealth struct {
+	Name          string    `json:"name"` // console、file:<path>、output:<path>、eventlog:<source>、unix:<path> 或 writer:<type>
+	Healthy       bool      `json:"healthy"`
+	Writes        int64     `json:"writes"`
+	Errors        int64     `json:"errors"`
+	LastWrite     time.Time `json:"last_write"` // 最近一次成功写入的时间
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorTime time.Time `json:"last_error_time"`
+	Async         bool      `json:"async"`       // 是否为可以报告队列长度的异步输出目标
+	QueueDepth    uint64    `json:"queue_depth"` // 已提交但尚未写入的条数，仅对异步输出目标有效
+}
+
+// WriteObserver 观察每次写入输出目标的耗时，需要可以并发调用
+type WriteObserver interface {
+	ObserveWrite(level zerolog.Level, writerType string, d time.Duration)
+}
+
+// trackedWriter 包装输出目标，记录写入次数、最近一次成功写入与最近一次错误，并向 WriteObserver 与 Stats 报告写入耗时
+type trackedWriter struct {
+	name      string
+	kind      string // 输出目标的类型，用作 WriteObserver 的 writerType
+	w         io.Writer
+	observers []WriteObserver
+	tick      atomic.Uint64 // Stats 的抽样计数
+	hist      histogram     // 抽样的写入耗时，见 Stats
+
+	mu            sync.Mutex
+	writes        int64
+	errors        int64
+	lastWrite     time.Time
+	lastErr       error
+	lastErrTime   time.Time
+	lastAttemptOK bool
+}
+
+func newTrackedWriter(name string, w io.Writer) *trackedWriter {
+	return &trackedWriter{name: name, kind: sinkKind(name), w: w, observers: options.observers}
+}
+
+// sinkKind 返回输出目标的类型：console、file、output、eventlog、unix，额外输出目标为其 Go 类型
+func sinkKind(name string) string {
+	kind, rest, _ := strings.Cut(name, ":")
+	if kind == "writer" {
+		return rest
+	}
+	return kind
+}
+
+// sinkName 返回额外输出目标的名称
+func sinkName(w io.Writer) string {
+	return fmt.Sprintf("writer:%T", w)
+}
+
+func (tw *trackedWriter) Write(p []byte) (int, error) {
+	return tw.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 保留级别信息，被包装的输出目标实现 zerolog.LevelWriter 时按级别写入
+func (tw *trackedWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	start := tw.startTimer()
+	if lw, ok := tw.w.(zerolog.LevelWriter); ok {
+		n, err = lw.WriteLevel(level, p)
+	} else {
+		n, err = tw.w.Write(p)
+	}
+	tw.finish(level, start, err)
+	return n, err
+}
+
+// writeTimer 一次写入的开始时间，不需要计时时为零值
+type writeTimer struct {
+	start   time.Time
+	sampled bool // 是否计入 Stats
+}
+
+// startTimer 存在 WriteObserver 或本次写入被 Stats 抽样时返回写入的开始时间
+func (tw *trackedWriter) startTimer() writeTimer {
+	sampled := sampleStats(&tw.tick)
+	if len(tw.observers) == 0 && !sampled {
+		return writeTimer{}
+	}
+	return writeTimer{start: time.Now(), sampled: sampled}
+}
+
+// finish 记录一次写入的结果，并向 WriteObserver 与 Stats 报告耗时
+func (tw *trackedWriter) finish(level zerolog.Level, t writeTimer, err error) {
+	tw.record(err)
+	if t.start.IsZero() {
+		return
+	}
+	d := time.Since(t.start)
+	if t.sampled {
+		tw.hist.observe(d)
+	}
+	for _, o := range tw.observers {
+		o.ObserveWrite(level, tw.kind, d)
+	}
+}
+
+// Barrier 等待被包装的输出目标完成写入
+func (tw *trackedWriter) Barrier(ctx context.Context) error {
+	return barrierWriter(ctx, tw.w)
+}
+
+// record 记录一次写入的结果
+func (tw *trackedWriter) record(err error) {
+	t := now()
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writes++
+	tw.lastAttemptOK = err == nil
+	if err != nil {
+		tw.errors++
+		tw.lastErr, tw.lastErrTime = err, t
+		return
+	}
+	tw.lastWrite = t
+}
+
+// health 返回输出目标当前的写入状态，最近一次写入成功或尚未写入时视为正常
+func (tw *trackedWriter) health() SinkHealth {
+	tw.mu.Lock()
+	h := SinkHealth{
+		Name:          tw.name,
+		Healthy:       tw.writes == 0 || tw.lastAttemptOK,
+		Writes:        tw.writes,
+		Errors:        tw.errors,
+		LastWrite:     tw.lastWrite,
+		LastErrorTime: tw.lastErrTime,
+	}
+	if tw.lastErr != nil {
+		h.LastError = tw.lastErr.Error()
+	}
+	tw.mu.Unlock()
+	h.QueueDepth, h.Async = queueDepth(tw.w)
+	return h
+}
+
+// queueDepth 累加包装链上各层尚未写入的日志数，没有任何一层可以报告时返回 false
+func queueDepth(w io.Writer) (depth uint64, async bool) {
+	for {
+		if pw, ok := w.(pendingWriter); ok {
+			depth += pw.Pending()
+			async = true
+		}
+		inner, ok := unwrapOnce(w)
+		if !ok {
+			return depth, async
+		}
+		w = inner
+	}
+}
+
+// sinkHealth 返回当前所有输出目标的写入状态
+func sinkHealth() []SinkHealth {
+	stateMu.RLock()
+	writers := activeWriters
+	stateMu.RUnlock()
+	var sinks []SinkHealth
+	for _, w := range writers {
+		if tw, ok := w.(*trackedWriter); ok {
+			sinks = append(sinks, tw.health())
+		}
+	}
+	return sinks
+}
@@ -0,0 +1,389 @@
+// Package sqlitesink
+// @Author Clover
+// @Data 2026/10/16 下午8:10:00
+// @Desc 将日志写入本地 SQLite 数据库，便于按级别与时间查询
+package sqlitesink
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Clov614/logging"
+	"github.com/Clov614/logging/internal/watermark"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultQueueSize     = 1024
+	defaultFlushInterval = time.Second
+	defaultProjectKey    = "project"
+)
+
+// ErrClosed 向已关闭的 Sink 写入日志时返回
+var ErrClosed = errors.New("sqlitesink: sink closed")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS logs (
+	ts          INTEGER NOT NULL,
+	level       INTEGER NOT NULL,
+	project     TEXT    NOT NULL DEFAULT '',
+	message     TEXT    NOT NULL DEFAULT '',
+	fields_json TEXT    NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS logs_ts ON logs (ts);
+CREATE INDEX IF NOT EXISTS logs_level ON logs (level);
+`
+
+// Config 用于配置 SQLite 输出
+type Config struct {
+	ProjectKey    string        // 日志中项目标识的字段名，默认为 project
+	BatchSize     int           // 单个事务最多包含的条目数
+	QueueSize     int           // 待写入队列长度，队列满时丢弃日志
+	FlushInterval time.Duration // 定时写入间隔
+	MaxRows       int64         // 最多保留的日志条数，超出时从最旧的开始删除，0 表示不限制
+	MaxBytes      int64         // 数据库的最大占用字节数，超出时从最旧的开始删除，0 表示不限制
+}
+
+// Filter 查询条件，零值字段表示不限制
+type Filter struct {
+	MinLevel zerolog.Level // 最低日志级别，零值为 debug
+	Since    time.Time     // 起始时间（包含）
+	Until    time.Time     // 结束时间（不包含）
+	Project  string        // 项目标识
+	Contains string        // 日志消息包含的文本
+	Limit    int           // 最多返回的条数，按时间倒序取最新的日志
+}
+
+// Sink 将每行 JSON 日志写入 SQLite 的 logs 表，实现 io.Writer
+// 数据库驱动由调用方注册并打开，本包不依赖具体的驱动
+type Sink struct {
+	db        *sql.DB
+	config    Config
+	queue     chan row
+	flushNow  chan struct{} // 请求立即写入当前批次
+	done      chan struct{}
+	mu        sync.Mutex
+	closed    bool
+	dropped   atomic.Uint64
+	mark      watermark.Watermark // 已入队与已处理（写入或丢弃）的条目序号
+	processed uint64              // 已处理的条目数，仅由后台写入协程访问
+}
+
+type row struct {
+	ts      int64
+	level   zerolog.Level
+	project string
+	message string
+	fields  string
+}
+
+// New 在 db 中创建 logs 表与索引，开启 WAL 模式并启动后台写入
+func New(db *sql.DB, config Config) (*Sink, error) {
+	if config.ProjectKey == "" {
+		config.ProjectKey = defaultProjectKey
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	// WAL 模式允许查看器在写入的同时读取
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		return nil, fmt.Errorf("sqlitesink: enable WAL: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("sqlitesink: create schema: %w", err)
+	}
+	s := &Sink{
+		db:       db,
+		config:   config,
+		queue:    make(chan row, config.QueueSize),
+		flushNow: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Write 解析一行 JSON 日志并加入写入队列，队列已满时丢弃并计数
+func (s *Sink) Write(p []byte) (int, error) {
+	r, err := s.parse(p)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	select {
+	case s.queue <- r:
+		s.mark.Issue()
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped 返回因队列已满或写入失败而丢弃的日志条数
+func (s *Sink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Barrier 立即写入当前批次，并等待调用前入队的日志全部写入或丢弃
+func (s *Sink) Barrier(ctx context.Context) error {
+	select {
+	case s.flushNow <- struct{}{}:
+	default:
+	}
+	return s.mark.WaitIssued(ctx)
+}
+
+// Headroom 返回写入队列的剩余空间，供 logging.LogBuffer.Flush 分批输出
+func (s *Sink) Headroom() int {
+	return cap(s.queue) - len(s.queue)
+}
+
+// Pending 返回已入队但尚未写入的日志条数
+func (s *Sink) Pending() uint64 {
+	return s.mark.Pending()
+}
+
+// Close 写入队列中剩余的日志并停止后台写入，不会关闭 db
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+// Query 按条件查询日志，结果按时间先后排序
+// 返回条目的 Fields 包含日志时间（time.Time）与项目标识
+func (s *Sink) Query(filter Filter) ([]logging.LogEntry, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	where = append(where, "level >= ?")
+	args = append(args, int(filter.MinLevel))
+	if !filter.Since.IsZero() {
+		where = append(where, "ts >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "ts < ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+	if filter.Project != "" {
+		where = append(where, "project = ?")
+		args = append(args, filter.Project)
+	}
+	if filter.Contains != "" {
+		where = append(where, "instr(message, ?) > 0")
+		args = append(args, filter.Contains)
+	}
+	query := "SELECT ts, level, project, message, fields_json FROM logs WHERE " +
+		strings.Join(where, " AND ") + " ORDER BY ts DESC, rowid DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitesink: query: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []logging.LogEntry
+	for rows.Next() {
+		var (
+			r     row
+			level int
+		)
+		if err := rows.Scan(&r.ts, &level, &r.project, &r.message, &r.fields); err != nil {
+			return nil, fmt.Errorf("sqlitesink: scan: %w", err)
+		}
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(r.fields), &fields); err != nil {
+			return nil, fmt.Errorf("sqlitesink: decode fields: %w", err)
+		}
+		fields[zerolog.TimestampFieldName] = time.Unix(0, r.ts)
+		if r.project != "" {
+			fields[s.config.ProjectKey] = r.project
+		}
+		entries = append(entries, logging.LogEntry{Level: zerolog.Level(level), Message: r.message, Fields: fields})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlitesink: query: %w", err)
+	}
+	// 查询按倒序取最新的日志，返回前恢复时间顺序
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]row, 0, s.config.BatchSize)
+	for {
+		select {
+		case r, ok := <-s.queue:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) >= s.config.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		case <-s.flushNow:
+			// 取出已入队的日志，一并写入
+			for n := len(s.queue); n > 0; n-- {
+				r, ok := <-s.queue
+				if !ok {
+					break
+				}
+				batch = append(batch, r)
+			}
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (s *Sink) flush(batch []row) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := s.insert(batch); err != nil {
+		s.dropped.Add(uint64(len(batch)))
+	} else {
+		s.prune()
+	}
+	s.processed += uint64(len(batch))
+	s.mark.Complete(s.processed)
+}
+
+// insert 在一个事务中写入整个批次
+func (s *Sink) insert(batch []row) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT INTO logs (ts, level, project, message, fields_json) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range batch {
+		if _, err := stmt.Exec(r.ts, int(r.level), r.project, r.message, r.fields); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// prune 按 MaxRows 与 MaxBytes 从最旧的日志开始删除
+func (s *Sink) prune() {
+	if s.config.MaxRows > 0 {
+		s.db.Exec("DELETE FROM logs WHERE rowid IN (SELECT rowid FROM logs ORDER BY rowid DESC LIMIT -1 OFFSET ?)", s.config.MaxRows)
+	}
+	if s.config.MaxBytes <= 0 {
+		return
+	}
+	for {
+		size, err := s.size()
+		if err != nil || size <= s.config.MaxBytes {
+			return
+		}
+		// 每次删除约十分之一的最旧日志，直到低于限制
+		res, err := s.db.Exec("DELETE FROM logs WHERE rowid IN (SELECT rowid FROM logs ORDER BY rowid LIMIT (SELECT max(1, count(*) / 10) FROM logs))")
+		if err != nil {
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return
+		}
+	}
+}
+
+// size 返回数据库已使用的字节数，删除后释放的空闲页不计入
+func (s *Sink) size() (int64, error) {
+	var pageCount, freePages, pageSize int64
+	if err := s.db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, err
+	}
+	if err := s.db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return 0, err
+	}
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return (pageCount - freePages) * pageSize, nil
+}
+
+// parse 将 JSON 日志拆分为时间、级别、项目、消息与其余字段
+func (s *Sink) parse(p []byte) (row, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return row{}, fmt.Errorf("sqlitesink: invalid log line: %w", err)
+	}
+
+	r := row{ts: time.Now().UnixNano(), level: zerolog.NoLevel}
+	if v, ok := raw[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.ParseInLocation(zerolog.TimeFieldFormat, v, time.Local); err == nil {
+			r.ts = t.UnixNano()
+		} else if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			r.ts = t.UnixNano()
+		}
+	}
+	if v, ok := raw[zerolog.LevelFieldName].(string); ok {
+		if level, err := zerolog.ParseLevel(v); err == nil {
+			r.level = level
+		}
+	}
+	r.project, _ = raw[s.config.ProjectKey].(string)
+	r.message, _ = raw[zerolog.MessageFieldName].(string)
+	for _, k := range []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName, s.config.ProjectKey} {
+		delete(raw, k)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return row{}, fmt.Errorf("sqlitesink: encode fields: %w", err)
+	}
+	r.fields = string(b)
+	return r, nil
+}
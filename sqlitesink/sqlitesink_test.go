@@ -0,0 +1,126 @@
+//go:build cgo
+
+package sqlitesink
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog"
+)
+
+func openSink(t *testing.T, config Config) (*Sink, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := New(db, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, db
+}
+
+func TestSinkQuery(t *testing.T) {
+	s, db := openSink(t, Config{FlushInterval: time.Hour})
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	logger := zerolog.New(s).With().Str("project", "app").Logger()
+	for i, level := range []zerolog.Level{zerolog.DebugLevel, zerolog.InfoLevel, zerolog.WarnLevel, zerolog.ErrorLevel} {
+		logger.WithLevel(level).Time("time", base.Add(time.Duration(i)*time.Minute)).Int("n", i).Msg(level.String() + " message")
+	}
+	if err := s.Barrier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("journal mode should be wal, got %q %v", mode, err)
+	}
+
+	entries, err := s.Query(Filter{MinLevel: zerolog.WarnLevel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Message != "warn message" || entries[1].Level != zerolog.ErrorLevel {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	e := entries[0]
+	if e.Fields["n"] != float64(2) || e.Fields["project"] != "app" || !e.Fields["time"].(time.Time).Equal(base.Add(2*time.Minute)) {
+		t.Errorf("unexpected fields: %v", e.Fields)
+	}
+
+	entries, _ = s.Query(Filter{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)})
+	if len(entries) != 2 || entries[0].Message != "info message" {
+		t.Errorf("unexpected entries in time range: %+v", entries)
+	}
+	entries, _ = s.Query(Filter{Limit: 1})
+	if len(entries) != 1 || entries[0].Message != "error message" {
+		t.Errorf("limit should return the newest entries: %+v", entries)
+	}
+	if entries, _ = s.Query(Filter{Project: "other"}); len(entries) != 0 {
+		t.Errorf("unexpected entries for other project: %+v", entries)
+	}
+	if entries, _ = s.Query(Filter{Contains: "debug"}); len(entries) != 1 {
+		t.Errorf("unexpected entries containing debug: %+v", entries)
+	}
+}
+
+func TestSinkPrune(t *testing.T) {
+	s, _ := openSink(t, Config{BatchSize: 3, FlushInterval: time.Hour, MaxRows: 5})
+	logger := zerolog.New(s)
+	for i := 0; i < 12; i++ {
+		logger.Info().Int("n", i).Msg("entry")
+	}
+	if err := s.Barrier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || entries[0].Fields["n"] != float64(7) || entries[4].Fields["n"] != float64(11) {
+		t.Errorf("oldest entries should be pruned first: %+v", entries)
+	}
+}
+
+func TestSinkPruneBySize(t *testing.T) {
+	s, _ := openSink(t, Config{FlushInterval: time.Hour, MaxBytes: 64 << 10})
+	logger := zerolog.New(s)
+	payload := make([]byte, 1024)
+	for i := range payload {
+		payload[i] = 'x'
+	}
+	for i := 0; i < 1000; i++ {
+		logger.Info().Int("n", i).Str("payload", string(payload)).Msg("entry")
+		if i%100 == 99 {
+			s.Barrier(context.Background())
+		}
+	}
+	if err := s.Barrier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	size, err := s.size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size > 64<<10 {
+		t.Errorf("database size %d exceeds limit", size)
+	}
+	entries, err := s.Query(Filter{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Fields["n"] != float64(999) {
+		t.Errorf("newest entry should be kept: %+v", entries)
+	}
+	if s.Dropped() != 0 {
+		t.Errorf("unexpected dropped entries: %d", s.Dropped())
+	}
+}
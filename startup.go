@@ -0,0 +1,71 @@
+// @Author Clover
+// @Data 2026/10/17 下午9:40:00
+// @Desc 进程启动与退出日志
+
+package logging
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// exitFunc 退出进程的函数，Fatal 与 LogShutdown 在输出日志后调用，测试中可以替换
+var exitFunc = os.Exit
+
+// BuildInfo 程序的构建信息，通常在编译时通过 -ldflags 注入
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+// LogStartup 输出统一格式的进程启动日志，包含构建信息、启动参数、pid、主机名、Go 版本以及进程启动时间；
+// 开启 Config.InjectHostname 时每条日志已经带有 hostname，不再重复添加
+func LogStartup(info BuildInfo, args []string) {
+	fields := map[string]interface{}{
+		"version":    info.Version,
+		"commit":     info.Commit,
+		"build_date": info.BuildDate,
+		"args":       strings.Join(args, " "),
+		"pid":        os.Getpid(),
+		"go_version": runtime.Version(),
+		// 进程启动时的系统时间是 mono_ms 的起点，离线工具可以据此还原日志的绝对顺序
+		"process_start":         processStart,
+		"process_start_mono_ms": 0,
+	}
+	stateMu.RLock()
+	injected := slices.Contains(injectedKeys, hostnameKey)
+	stateMu.RUnlock()
+	if !injected {
+		fields[hostnameKey], _ = os.Hostname()
+	}
+	emit(currentLogger().Info(), zerolog.InfoLevel, nil, "Process started", []map[string]interface{}{fields})
+}
+
+// LogShutdown 同步输出 Logger 中缓冲的日志与 Fatal 级别的进程退出日志，随后以 exitCode 退出进程
+func LogShutdown(exitCode int, reason string) {
+	Logger.flushSync()
+	emit(currentLogger().WithLevel(zerolog.FatalLevel), zerolog.FatalLevel, nil, "Process shutting down", []map[string]interface{}{{
+		"exit_code": exitCode,
+		"reason":    reason,
+	}})
+	exit(exitCode)
+}
+
+// exit 等待异步输出目标写完已提交的日志后退出进程
+func exit(exitCode int) {
+	awaitWrites()
+	exitFunc(exitCode)
+}
+
+// awaitWrites 等待异步输出目标写完已提交的日志并同步日志文件，最多等待 Logger 的 Flush 时限
+func awaitWrites() {
+	ctx, cancel := context.WithTimeout(context.Background(), Logger.deadline())
+	_ = Barrier(ctx)
+	cancel()
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLogStartupAndShutdown(t *testing.T) {
+	buf := captureOutput(t)
+	var code = -1
+	exitFunc = func(c int) { code = c }
+	t.Cleanup(func() { exitFunc = os.Exit })
+
+	LogStartup(BuildInfo{Version: "1.2.3", Commit: "abc123", BuildDate: "2026-10-17"}, []string{"serve", "--port", "8080"})
+	LogShutdown(3, "config reload failed")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %v", len(lines), lines)
+	}
+	start, stop := lines[0], lines[1]
+	hostname, _ := os.Hostname()
+	if start["level"] != "info" || start["version"] != "1.2.3" || start["commit"] != "abc123" || start["build_date"] != "2026-10-17" ||
+		start["args"] != "serve --port 8080" || start["pid"] != float64(os.Getpid()) || start["hostname"] != hostname ||
+		start["go_version"] != runtime.Version() || start["process_start"] != processStart.Format(time.RFC3339Nano) ||
+		start["process_start_mono_ms"] != float64(0) {
+		t.Errorf("unexpected startup event: %v", start)
+	}
+	if stop["level"] != "fatal" || stop["exit_code"] != float64(3) || stop["reason"] != "config reload failed" {
+		t.Errorf("unexpected shutdown event: %v", stop)
+	}
+	if code != 3 {
+		t.Errorf("expected exit handler to be called with 3, got %d", code)
+	}
+}
@@ -0,0 +1,95 @@
+// @Author Clover
+// @Data 2026/10/16 下午9:40:00
+// @Desc 全局状态的并发保护与重复初始化检测
+
+package logging
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrAlreadyInitialized 未开启 Config.AllowReinit 时重复调用 InitLogger 返回
+var ErrAlreadyInitialized = errors.New("logger already initialized, call Close first or set Config.AllowReinit")
+
+var (
+	initMu      sync.Mutex   // 串行化 InitLogger 与 Close
+	stateMu     sync.RWMutex // 保护包级配置、输出目标与 baseLogger
+	initialized bool         // 是否已初始化且尚未关闭，由 initMu 保护
+
+	baseLogger zerolog.Logger // 包内所有日志使用的基础日志记录器，由 stateMu 保护，不使用 zerolog 的全局 log.Logger
+	rootLogger zerolog.Logger // 只带有 hook 的日志记录器，InitLogger 从它派生 baseLogger，重复初始化时字段不会累积
+	baseOnce   sync.Once
+)
+
+// ensureLogger 首次使用时创建输出到 os.Stderr 的默认日志记录器，调用方不能持有 stateMu
+func ensureLogger() {
+	baseOnce.Do(func() {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		// 统计日志数量、生成 event_id、记录最近日志时间与添加 mono_ms 的 hook 只注册一次，之后通过 Output/With 派生的 Logger 会保留这些 hook
+		rootLogger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Hook(metricsHook{}, eventIDHook{}, activityHook{}, monoHook{})
+		baseLogger = rootLogger.With().Timestamp().Logger()
+	})
+}
+
+// currentLogger 返回当前日志记录器的副本
+func currentLogger() *zerolog.Logger {
+	ensureLogger()
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	l := baseLogger
+	return &l
+}
+
+// skipNilErrorsEnabled 返回是否跳过 err 为 nil 的错误日志
+func skipNilErrorsEnabled() bool {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return skipNilErrors
+}
+
+// shutdown 为尚未结束的操作输出 abandoned 日志，然后关闭当前的日志文件与额外输出，调用方需持有 initMu
+// 文件在释放 stateMu 后关闭，避免与监控协程中的日志输出互相等待
+func shutdown() {
+	reportAbandonedOps()
+	stateMu.Lock()
+	lf, outs, el, us, rw, im, rm, ds := logfile, outputs, eventLog, unixSocket, relay, inactivity, retention, diodes
+	logfile, outputs, eventLog, unixSocket, relay, activeWriters, inactivity, retention, diodes = nil, nil, nil, nil, nil, nil, nil, nil, nil
+	startedAt = time.Time{}
+	releaseGlobalZerolog()
+	stateMu.Unlock()
+
+	im.stop()
+	rm.stop()
+	closeDiodes(ds)
+
+	logger := currentLogger()
+	if lf != nil {
+		if err := lf.Close(); err != nil {
+			logger.Error().Msgf("Error closing log file: %v", err)
+		}
+	}
+	if err := closeOutputs(outs); err != nil {
+		logger.Error().Msgf("Error closing log outputs: %v", err)
+	}
+	if el != nil {
+		if err := el.Close(); err != nil {
+			logger.Error().Msgf("Error closing windows event log: %v", err)
+		}
+	}
+	if us != nil {
+		if err := us.Close(); err != nil {
+			logger.Error().Msgf("Error closing unix socket: %v", err)
+		}
+	}
+	if rw != nil {
+		if err := rw.Close(); err != nil {
+			logger.Error().Msgf("Error closing log relay: %v", err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestInitLoggerAlreadyInitialized(t *testing.T) {
+	prev := baseLogger
+	t.Cleanup(func() { baseLogger = prev })
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	if err := InitLogger(Config{LogPath: first, ProjectKey: "project", EnableFileOutput: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+
+	err := InitLogger(Config{LogPath: second, ProjectKey: "project", EnableFileOutput: true})
+	if !errors.Is(err, ErrAlreadyInitialized) {
+		t.Fatalf("expected ErrAlreadyInitialized, got %v", err)
+	}
+	if _, err := os.Stat(second); !os.IsNotExist(err) {
+		t.Errorf("rejected configuration should not open its log file")
+	}
+
+	// AllowReinit 关闭之前的日志文件后重新初始化
+	old := logfile
+	if err := InitLogger(Config{LogPath: second, ProjectKey: "project", EnableFileOutput: true, AllowReinit: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("previous log file should be closed, got %v", err)
+	}
+	Info("after reinit")
+	if content, _ := os.ReadFile(second); len(content) == 0 {
+		t.Errorf("new log file should receive logs")
+	}
+
+	// Close 之后可以重新初始化
+	Close()
+	if err := InitLogger(Config{LogPath: first, ProjectKey: "project", EnableFileOutput: true}); err != nil {
+		t.Fatalf("init after close: %v", err)
+	}
+}
+
+func TestConcurrentInitLogger(t *testing.T) {
+	prev := baseLogger
+	prevHandler := zerolog.ErrorHandler
+	// 其他协程关闭日志文件后仍在写入的日志会报错，这里忽略
+	zerolog.ErrorHandler = func(error) {}
+	t.Cleanup(func() {
+		Close()
+		baseLogger = prev
+		zerolog.ErrorHandler = prevHandler
+	})
+
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				err := InitLogger(Config{
+					LogPath:          filepath.Join(dir, fmt.Sprintf("%d.log", i)),
+					ProjectKey:       "project",
+					ProjectName:      fmt.Sprintf("p%d", i),
+					EnableFileOutput: true,
+					AllowReinit:      j%2 == 0,
+					Outputs:          []OutputConfig{{Path: filepath.Join(dir, fmt.Sprintf("%d.logfmt", i)), Format: OutputFormatLogfmt}},
+				}, WithWriters(io.Discard))
+				if err != nil && !errors.Is(err, ErrAlreadyInitialized) {
+					t.Error(err)
+				}
+				Info("concurrent", map[string]interface{}{"i": i, "j": j})
+				ErrorWithErr(errors.New("boom"), "failed")
+				SetField(map[string]interface{}{"worker": i})
+				Barrier(context.Background())
+				if j%3 == 0 {
+					Close()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,150 @@
+// @Author Clover
+// @Data 2026/10/16 下午11:10:00
+// @Desc 在已知的噪声时段内降级匹配的告警日志
+
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// maxSuppressedMessages 每个抑制时段最多记录的不同消息数
+const maxSuppressedMessages = 50
+
+// suppression 一个抑制时段
+type suppression struct {
+	matcher  FieldMatcher
+	until    time.Time
+	stop     chan struct{} // 结束时关闭，停止等待时段到期的协程
+	count    int
+	messages []string // 按首次出现顺序记录的不同消息
+	ended    bool
+}
+
+var (
+	suppressMu     sync.Mutex
+	suppressions   []*suppression
+	suppressActive atomic.Int32 // 当前抑制时段数量，为 0 时跳过匹配
+)
+
+// Suppress 在 d 时间内将匹配 matcher 的 Warn/Error 日志降级为 Debug 输出，
+// 时段结束时输出一条汇总日志，记录被降级的数量与不同的消息。
+// 多个抑制时段可以重叠，调用返回的 cancel 可提前结束该时段
+func Suppress(matcher FieldMatcher, d time.Duration) (cancel func()) {
+	s := &suppression{matcher: matcher, until: now().Add(d), stop: make(chan struct{})}
+	suppressMu.Lock()
+	suppressions = append(suppressions, s)
+	suppressActive.Add(1)
+	suppressMu.Unlock()
+	expired := currentClock().After(d)
+	go func() {
+		select {
+		case <-expired:
+			endSuppression(s)
+		case <-s.stop:
+		}
+	}()
+	return func() { endSuppression(s) }
+}
+
+// suppressed 判断日志是否处于抑制时段内，并记录到所有匹配的时段
+func suppressed(entry LogEntry) bool {
+	if suppressActive.Load() == 0 {
+		return false
+	}
+	t := now()
+	var (
+		matched bool
+		expired []*suppression
+	)
+	suppressMu.Lock()
+	for _, s := range suppressions {
+		if !t.Before(s.until) {
+			expired = append(expired, s)
+			continue
+		}
+		if s.matcher(entry) {
+			matched = true
+			s.count++
+			if len(s.messages) < maxSuppressedMessages && !containsString(s.messages, entry.Message) {
+				s.messages = append(s.messages, entry.Message)
+			}
+		}
+	}
+	suppressMu.Unlock()
+
+	// 定时器尚未触发时，在下一条日志到达时结束过期的时段
+	for _, s := range expired {
+		endSuppression(s)
+	}
+	return matched
+}
+
+// wouldSuppress 判断日志是否处于抑制时段内，不计数也不结束过期的时段
+func wouldSuppress(entry LogEntry) bool {
+	if suppressActive.Load() == 0 {
+		return false
+	}
+	t := now()
+	suppressMu.Lock()
+	defer suppressMu.Unlock()
+	for _, s := range suppressions {
+		if t.Before(s.until) && s.matcher(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// endSuppression 结束抑制时段并输出汇总日志，重复调用无效
+func endSuppression(s *suppression) {
+	suppressMu.Lock()
+	if s.ended {
+		suppressMu.Unlock()
+		return
+	}
+	s.ended = true
+	close(s.stop)
+	for i, other := range suppressions {
+		if other == s {
+			suppressions = append(suppressions[:i], suppressions[i+1:]...)
+			break
+		}
+	}
+	suppressActive.Add(-1)
+	count, messages := s.count, s.messages
+	suppressMu.Unlock()
+
+	if messages == nil {
+		messages = []string{}
+	}
+	currentLogger().Info().
+		Int("suppressed_count", count).
+		Strs("suppressed_messages", messages).
+		Msgf("Suppression window ended, %d events were downgraded to debug", count)
+}
+
+// downgradeSuppressed 在抑制时段内将 Warn/Error 日志改为 Debug 级别，返回新的事件与级别
+func downgradeSuppressed(event *zerolog.Event, level zerolog.Level, err error, msg string, fields map[string]interface{}) (*zerolog.Event, zerolog.Level) {
+	if level != zerolog.WarnLevel && level != zerolog.ErrorLevel {
+		return event, level
+	}
+	entry := LogEntry{Level: level, Message: msg, Fields: fields}
+	if err != nil {
+		entry.Fields = mergeFields([]map[string]interface{}{fields})
+		entry.Fields[zerolog.ErrorFieldName] = err
+	}
+	if !suppressed(entry) {
+		return event, level
+	}
+	event.Discard()
+	event = currentLogger().Debug()
+	if err != nil {
+		event = withErr(event, err)
+	}
+	return event, zerolog.DebugLevel
+}
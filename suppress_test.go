@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// decodeLines 解析多行 JSON 日志
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if len(line) > 0 {
+			lines = append(lines, decodeLine(t, line))
+		}
+	}
+	buf.Reset()
+	return lines
+}
+
+func TestSuppress(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local))
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	buf := captureOutput(t)
+
+	resets := Suppress(MatchMessage("connection reset"), time.Hour)
+	deploy := Suppress(MatchField("phase", "deploy"), 2*time.Hour)
+
+	WarnWithErr(errors.New("EOF"), "connection reset by peer")
+	Error("connection reset by peer", map[string]interface{}{"phase": "deploy"})
+	Error("migration failed", map[string]interface{}{"phase": "deploy"})
+	Warn("disk almost full")
+	Info("connection reset during info")
+
+	lines := decodeLines(t, buf)
+	levels := make([]string, len(lines))
+	for i, m := range lines {
+		levels[i] = m["level"].(string)
+	}
+	if want := []string{"debug", "debug", "debug", "warn", "info"}; !reflect.DeepEqual(levels, want) {
+		t.Fatalf("unexpected levels: %v", levels)
+	}
+	if lines[0]["error"] != "EOF" {
+		t.Errorf("downgraded event should keep its fields: %v", lines[0])
+	}
+
+	// 定时器尚未触发时，下一条日志之前输出汇总
+	clock.skip(time.Hour)
+	Warn("connection reset by peer")
+	lines = decodeLines(t, buf)
+	if len(lines) != 2 || lines[0]["suppressed_count"] != float64(2) || lines[1]["level"] != "warn" {
+		t.Fatalf("expected summary before unsuppressed event: %v", lines)
+	}
+	if msgs := lines[0]["suppressed_messages"]; !reflect.DeepEqual(msgs, []interface{}{"connection reset by peer"}) {
+		t.Errorf("unexpected distinct messages: %v", msgs)
+	}
+
+	// cancel 提前结束，重复调用无效
+	deploy()
+	deploy()
+	resets()
+	lines = decodeLines(t, buf)
+	if len(lines) != 1 || lines[0]["suppressed_count"] != float64(2) {
+		t.Fatalf("expected one summary for the cancelled window: %v", lines)
+	}
+	if msgs := lines[0]["suppressed_messages"]; !reflect.DeepEqual(msgs, []interface{}{"connection reset by peer", "migration failed"}) {
+		t.Errorf("unexpected distinct messages: %v", msgs)
+	}
+	Error("migration failed", map[string]interface{}{"phase": "deploy"})
+	if lines = decodeLines(t, buf); lines[0]["level"] != "error" {
+		t.Errorf("events after cancel should not be suppressed: %v", lines)
+	}
+}
+
+func TestSuppressTimer(t *testing.T) {
+	clock := useFakeClock(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local))
+	prev := baseLogger
+	out := &syncBuffer{}
+	baseLogger = zerolog.New(out)
+	t.Cleanup(func() { baseLogger = prev })
+
+	cancel := Suppress(MatchMessage("flaky"), time.Minute)
+	defer cancel()
+	Warn("flaky upstream")
+	clock.waitForWaiters(t, 1)
+	clock.Advance(time.Minute)
+	waitFor(t, "suppression summary", func() bool { return strings.Contains(out.String(), "suppressed_count") })
+	if suppressActive.Load() != 0 {
+		t.Errorf("suppression should end when the timer fires")
+	}
+}
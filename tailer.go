@@ -0,0 +1,214 @@
+// @Author Clover
+// @Data 2026/10/16 下午4:10:00
+// @Desc 跟踪日志文件并输出解析后的日志条目
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultTailPollInterval fsnotify 不可用时轮询文件的间隔
+const defaultTailPollInterval = 200 * time.Millisecond
+
+// FileTailer 从头读取日志文件，随后持续输出新追加的日志条目
+type FileTailer struct {
+	path         string
+	PollInterval time.Duration // fsnotify 不可用时的轮询间隔
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewFileTailer 创建日志文件跟踪器
+func NewFileTailer(path string) *FileTailer {
+	return &FileTailer{
+		path:         path,
+		PollInterval: defaultTailPollInterval,
+	}
+}
+
+// Entries 返回解析后的日志条目，ctx 结束或发生错误时关闭通道，错误可通过 Err 获取
+// 无法解析为 JSON 的行会被跳过；文件被截断时从头重新读取；.zst 文件只读取已经完整写入的 zstd 帧
+func (ft *FileTailer) Entries(ctx context.Context) <-chan LogEntry {
+	ch := make(chan LogEntry)
+	go func() {
+		defer close(ch)
+		if err := ft.tail(ctx, ch); err != nil && !errors.Is(err, context.Canceled) {
+			ft.mu.Lock()
+			ft.err = err
+			ft.mu.Unlock()
+		}
+	}()
+	return ch
+}
+
+// Err 返回导致跟踪停止的错误
+func (ft *FileTailer) Err() error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	return ft.err
+}
+
+func (ft *FileTailer) tail(ctx context.Context, ch chan<- LogEntry) error {
+	f, err := os.Open(ft.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 优先使用 fsnotify，不可用时退化为轮询
+	var events <-chan fsnotify.Event
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		defer watcher.Close()
+		if err = watcher.Add(ft.path); err == nil {
+			events = watcher.Events
+		}
+	}
+	var poll <-chan time.Time
+	if events == nil {
+		ticker := currentClock().NewTicker(ft.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C()
+	}
+
+	var src tailSource = &plainTailSource{f: f, reader: bufio.NewReader(f)}
+	if isCompressedPath(ft.path) {
+		src = &zstdTailSource{f: f}
+	}
+	for {
+		// 输出当前所有完整的行
+		lines, err := src.readLines()
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if isFileHeader(line) {
+				continue
+			}
+			entry, err := parseLogEntry(line)
+			if err != nil {
+				continue
+			}
+			select {
+			case ch <- entry:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-events:
+			if !ok { // watcher 已关闭，改为轮询
+				events = nil
+				ticker := currentClock().NewTicker(ft.PollInterval)
+				defer ticker.Stop()
+				poll = ticker.C()
+			}
+		case <-poll:
+		}
+
+		// 文件被截断后从头读取
+		if err := src.rewindIfTruncated(); err != nil {
+			return err
+		}
+	}
+}
+
+// tailSource 从被跟踪的文件中读取新追加的行
+type tailSource interface {
+	readLines() ([][]byte, error) // 读取当前所有完整的行
+	rewindIfTruncated() error     // 文件被截断时从头读取
+}
+
+// plainTailSource 读取未压缩的日志文件
+type plainTailSource struct {
+	f       *os.File
+	reader  *bufio.Reader
+	offset  int64
+	partial []byte
+}
+
+func (ps *plainTailSource) readLines() ([][]byte, error) {
+	var lines [][]byte
+	for {
+		line, err := ps.reader.ReadBytes('\n')
+		ps.offset += int64(len(line))
+		if err != nil {
+			if err != io.EOF {
+				return lines, err
+			}
+			ps.partial = append(ps.partial, line...)
+			return lines, nil
+		}
+		if len(ps.partial) > 0 {
+			line = append(ps.partial, line...)
+			ps.partial = nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+func (ps *plainTailSource) rewindIfTruncated() error {
+	if fi, err := ps.f.Stat(); err == nil && fi.Size() < ps.offset {
+		if _, err := ps.f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		ps.reader.Reset(ps.f)
+		ps.offset = 0
+		ps.partial = nil
+	}
+	return nil
+}
+
+// zstdTailSource 读取 zstd 压缩的日志文件，每次只解压已经完整写入的帧
+type zstdTailSource struct {
+	f       *os.File
+	offset  int64 // 已解压的完整帧的结束位置
+	partial []byte
+}
+
+func (zs *zstdTailSource) readLines() ([][]byte, error) {
+	fi, err := zs.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	end := completeFramesEnd(zs.f, zs.offset, fi.Size())
+	data, err := decodeFrames(zs.f, zs.offset, end)
+	if err != nil {
+		return nil, err
+	}
+	zs.offset = end
+	data = append(zs.partial, data...)
+	var lines [][]byte
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, data[:i+1])
+		data = data[i+1:]
+	}
+	zs.partial = data
+	return lines, nil
+}
+
+func (zs *zstdTailSource) rewindIfTruncated() error {
+	if fi, err := zs.f.Stat(); err == nil && fi.Size() < zs.offset {
+		zs.offset = 0
+		zs.partial = nil
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestFileTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tail.log")
+	initial := `{"level":"info","message":"first","user":"u1"}` + "\n" + `not json` + "\n" + `{"level":"warn","message":"second"}` + "\n"
+	if err := os.WriteFile(path, []byte(initial), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tailer := NewFileTailer(path)
+	entries := tailer.Entries(ctx)
+
+	next := func() LogEntry {
+		t.Helper()
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				t.Fatalf("entries closed unexpectedly: %v", tailer.Err())
+			}
+			return entry
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for entry")
+		}
+		return LogEntry{}
+	}
+
+	// 已存在的日志，跳过无法解析的行
+	if e := next(); e.Message != "first" || e.Level != zerolog.InfoLevel || e.Fields["user"] != "u1" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := next(); e.Message != "second" || e.Level != zerolog.WarnLevel {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	// 追加的日志，包括分两次写入的行
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"level":"error","mess`)
+	f.Sync()
+	f.WriteString(`age":"third"}` + "\n")
+	f.Close()
+	if e := next(); e.Message != "third" || e.Level != zerolog.ErrorLevel {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	// 文件被截断后从头读取
+	if err := os.WriteFile(path, []byte(`{"level":"info","message":"after truncate"}`+"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(); e.Message != "after truncate" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	cancel()
+	for range entries {
+	}
+	if err := tailer.Err(); err != nil {
+		t.Errorf("cancellation should not be reported as error: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package testutil
+// @Author Clover
+// @Data 2026/10/16 上午9:40:00
+// @Desc 测试辅助函数
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Clov614/logging"
+	"github.com/rs/zerolog"
+)
+
+// AssertLogged 断言缓冲区中存在指定级别且消息包含 substr 的日志条目
+func AssertLogged(t testing.TB, buf *logging.LogBuffer, level zerolog.Level, substr string) {
+	t.Helper()
+	entries := buf.Peek()
+	if len(matchEntries(entries, level, substr)) > 0 {
+		return
+	}
+	t.Errorf("expected a %s entry containing %q, buffered %s entries:\n%s",
+		level, substr, level, formatEntries(levelEntries(entries, level)))
+}
+
+// AssertNotLogged 断言缓冲区中不存在指定级别且消息包含 substr 的日志条目
+func AssertNotLogged(t testing.TB, buf *logging.LogBuffer, level zerolog.Level, substr string) {
+	t.Helper()
+	matched := matchEntries(buf.Peek(), level, substr)
+	if len(matched) == 0 {
+		return
+	}
+	t.Errorf("expected no %s entry containing %q, found:\n%s", level, substr, formatEntries(matched))
+}
+
+func matchEntries(entries []logging.LogEntry, level zerolog.Level, substr string) []logging.LogEntry {
+	var matched []logging.LogEntry
+	for _, entry := range levelEntries(entries, level) {
+		if strings.Contains(entry.Message, substr) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+func levelEntries(entries []logging.LogEntry, level zerolog.Level) []logging.LogEntry {
+	var result []logging.LogEntry
+	for _, entry := range entries {
+		if entry.Level == level {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func formatEntries(entries []logging.LogEntry) string {
+	if len(entries) == 0 {
+		return "\t(none)"
+	}
+	var sb strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "\t[%s] %s", entry.Level, entry.Message)
+		if len(entry.Fields) > 0 {
+			fmt.Fprintf(&sb, " %v", entry.Fields)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Clov614/logging"
+	"github.com/rs/zerolog"
+)
+
+// recorder 记录断言失败信息而不终止测试
+type recorder struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+	r.msg = fmt.Sprintf(format, args...)
+}
+
+func TestAssertLogged(t *testing.T) {
+	buf := logging.NewLogBuffer()
+	buf.AddEntry(logging.LogEntry{Level: zerolog.InfoLevel, Message: "server started"})
+	buf.AddEntry(logging.LogEntry{Level: zerolog.ErrorLevel, Message: "connection refused"})
+
+	AssertLogged(t, buf, zerolog.InfoLevel, "started")
+	AssertNotLogged(t, buf, zerolog.InfoLevel, "refused")
+
+	// 失败时应输出同级别的缓冲条目
+	r := &recorder{TB: t}
+	AssertLogged(r, buf, zerolog.ErrorLevel, "timeout")
+	if !r.failed {
+		t.Fatal("AssertLogged should fail when no entry matches")
+	}
+	if !strings.Contains(r.msg, "connection refused") || strings.Contains(r.msg, "server started") {
+		t.Errorf("unexpected failure message: %s", r.msg)
+	}
+
+	r = &recorder{TB: t}
+	AssertNotLogged(r, buf, zerolog.ErrorLevel, "refused")
+	if !r.failed || !strings.Contains(r.msg, "connection refused") {
+		t.Errorf("AssertNotLogged should fail and list matched entries, got: %s", r.msg)
+	}
+}
@@ -0,0 +1,169 @@
+// @Author Clover
+// @Data 2026/10/16 上午10:05:00
+// @Desc 带超时的日志写入
+
+package logging
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Clov614/logging/internal/watermark"
+)
+
+// maxFallbackEventSize 超时后写入 os.Stderr 的日志最大长度
+const maxFallbackEventSize = 256
+
+// timeoutQueueSize 每个带超时的输出目标排队等待写入的日志上限，超出时与超时相同地写入备用输出
+const timeoutQueueSize = 1024
+
+// timeoutQueueDrop 因写入队列已满而没有写入输出目标的次数
+var timeoutQueueDrop atomic.Int64
+
+// TimeoutQueueDrops 返回 WithWriteTimeout 的输出目标因写入队列已满、日志改为写入 os.Stderr 的次数
+func TimeoutQueueDrops() int64 {
+	return timeoutQueueDrop.Load()
+}
+
+// 排队写入的状态
+const (
+	writeQueued    int32 = iota // 等待写入
+	writeStarted                // 已开始写入底层输出目标
+	writeAbandoned              // 调用方已超时并写入备用输出，不再写入底层输出目标
+)
+
+// timeoutWriter 为底层 io.Writer 的每次写入设置超时时间：
+// 写入由单个后台协程按提交顺序执行，排队的日志有上限，输出目标卡住时不会堆积协程
+type timeoutWriter struct {
+	w        io.Writer
+	timeout  time.Duration
+	fallback io.Writer // 超时后的备用输出
+
+	mu      sync.Mutex // 保证提交序号与入队顺序一致
+	queue   chan *queuedWrite
+	running bool // 后台协程是否在运行，队列为空时退出，由 mu 保护
+	mark    watermark.Watermark
+}
+
+// queuedWrite 排队等待写入的日志
+type queuedWrite struct {
+	buf   []byte
+	seq   uint64
+	state atomic.Int32
+	done  chan writeResult
+}
+
+type writeResult struct {
+	n   int
+	err error
+}
+
+func newTimeoutWriter(w io.Writer, timeout time.Duration) *timeoutWriter {
+	return &timeoutWriter{
+		w:        w,
+		timeout:  timeout,
+		fallback: os.Stderr,
+		queue:    make(chan *queuedWrite, timeoutQueueSize),
+	}
+}
+
+// Write 在超时时间内等待底层写入完成，超时则输出截断的日志并返回
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	return tw.WriteContext(context.Background(), p)
+}
+
+// WriteContext 与 Write 相同，ctx 先于超时结束时不输出截断的日志，写入在后台继续进行；
+// 写入队列已满时不再等待，与超时相同地输出截断的日志，并计入 TimeoutQueueDrops
+func (tw *timeoutWriter) WriteContext(ctx context.Context, p []byte) (int, error) {
+	// zerolog 会复用 p，后台写入需要使用副本
+	item := &queuedWrite{buf: make([]byte, len(p)), done: make(chan writeResult, 1)}
+	copy(item.buf, p)
+	if !tw.enqueue(item) {
+		timeoutQueueDrop.Add(1)
+		_, _ = tw.fallback.Write(truncateEvent(item.buf))
+		return len(p), nil
+	}
+
+	timer := time.NewTimer(tw.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-item.done:
+		return res.n, res.err
+	case <-timer.C:
+		// 尚未开始的写入不再执行，避免同一条日志在输出目标恢复后再次写入；已开始的写入无法撤回
+		item.state.CompareAndSwap(writeQueued, writeAbandoned)
+		_, _ = tw.fallback.Write(truncateEvent(item.buf))
+		return len(p), nil
+	case <-ctx.Done():
+		deadlineSpill.Add(1)
+		return len(p), nil
+	}
+}
+
+// enqueue 分配序号并加入写入队列，必要时启动后台协程，队列已满时返回 false
+func (tw *timeoutWriter) enqueue(item *queuedWrite) bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	// 只有持有 mu 时才会入队，后台协程只会取出，检查之后的发送不会阻塞
+	if len(tw.queue) == cap(tw.queue) {
+		return false
+	}
+	item.seq = tw.mark.Issue()
+	tw.queue <- item
+	if !tw.running {
+		tw.running = true
+		go tw.run()
+	}
+	return true
+}
+
+// run 按顺序写入队列中的日志，队列为空时退出
+func (tw *timeoutWriter) run() {
+	for {
+		var item *queuedWrite
+		select {
+		case item = <-tw.queue:
+		default:
+			tw.mu.Lock()
+			if len(tw.queue) == 0 {
+				tw.running = false
+				tw.mu.Unlock()
+				return
+			}
+			tw.mu.Unlock()
+			continue
+		}
+		if item.state.CompareAndSwap(writeQueued, writeStarted) {
+			n, err := tw.w.Write(item.buf)
+			item.done <- writeResult{n: n, err: err}
+		}
+		tw.mark.Complete(item.seq)
+	}
+}
+
+// Barrier 等待调用前提交的写入全部完成（包括已超时但仍在进行的写入）
+func (tw *timeoutWriter) Barrier(ctx context.Context) error {
+	if err := tw.mark.WaitIssued(ctx); err != nil {
+		return err
+	}
+	return barrierWriter(ctx, tw.w)
+}
+
+// Pending 返回尚未完成的写入数量
+func (tw *timeoutWriter) Pending() uint64 {
+	return tw.mark.Pending()
+}
+
+// truncateEvent 截断日志内容并保证以换行结尾
+func truncateEvent(p []byte) []byte {
+	if len(p) <= maxFallbackEventSize {
+		return p
+	}
+	truncated := make([]byte, 0, maxFallbackEventSize+len("...(truncated)\n"))
+	truncated = append(truncated, p[:maxFallbackEventSize]...)
+	return append(truncated, "...(truncated)\n"...)
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowWriter 模拟写入缓慢的输出目标
+type slowWriter struct {
+	delay time.Duration
+	buf   bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.buf.Write(p)
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	// 写入在超时时间内完成
+	fast := &slowWriter{}
+	tw := newTimeoutWriter(fast, 100*time.Millisecond)
+	if _, err := tw.Write([]byte("fast\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fast.buf.String() != "fast\n" {
+		t.Errorf("unexpected content: %q", fast.buf.String())
+	}
+
+	// 写入超时后输出截断的日志到备用输出
+	slow := &slowWriter{delay: 200 * time.Millisecond}
+	fallback := &bytes.Buffer{}
+	tw = newTimeoutWriter(slow, 10*time.Millisecond)
+	tw.fallback = fallback
+
+	line := strings.Repeat("x", maxFallbackEventSize*2) + "\n"
+	start := time.Now()
+	n, err := tw.Write([]byte(line))
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("write should return after timeout, took %v", elapsed)
+	}
+	if err != nil || n != len(line) {
+		t.Errorf("unexpected result: n=%d err=%v", n, err)
+	}
+	if !strings.HasSuffix(fallback.String(), "...(truncated)\n") || fallback.Len() > maxFallbackEventSize+len("...(truncated)\n") {
+		t.Errorf("unexpected fallback output: %q", fallback.String())
+	}
+}
+
+func TestTimeoutWriterHungSink(t *testing.T) {
+	sink := &blockingWriter{release: make(chan struct{})}
+	fallback := &syncBuffer{}
+	tw := newTimeoutWriter(sink, 5*time.Millisecond)
+	tw.fallback = fallback
+	tw.queue = make(chan *queuedWrite, 4)
+	drops := TimeoutQueueDrops()
+
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		if _, err := tw.Write([]byte(fmt.Sprintf("event %d\n", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 只有一个后台协程，第一条日志卡在写入中，其后排满队列，其余日志不入队，直接写入备用输出
+	if n := runtime.NumGoroutine(); n > goroutines+1 {
+		t.Errorf("timed-out writes should not pile up goroutines: %d before, %d after", goroutines, n)
+	}
+	if got := TimeoutQueueDrops() - drops; got != 5 {
+		t.Errorf("expected 5 dropped events, got %d", got)
+	}
+	if got := strings.Count(fallback.String(), "\n"); got != 10 || !strings.Contains(fallback.String(), "event 9\n") {
+		t.Errorf("timed-out and overflowing events should all reach the fallback output, got %d: %q", got, fallback.String())
+	}
+
+	// 输出目标恢复后，只有超时前已开始的那一条写入完成，其余已写入备用输出的日志不会再写入
+	close(sink.release)
+	if err := tw.Barrier(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	written := sink.buf.String()
+	if strings.Count(written, "\n") != 1 {
+		t.Errorf("abandoned events should not reach the sink: %q", written)
+	}
+	if tw.Pending() != 0 {
+		t.Errorf("expected no pending writes, got %d", tw.Pending())
+	}
+
+	// 之后的写入正常完成
+	if _, err := tw.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	if sink.buf.String() != written+"after\n" {
+		t.Errorf("unexpected sink content: %q", sink.buf.String())
+	}
+}
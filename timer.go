@@ -0,0 +1,42 @@
+// @Author Clover
+// @Data 2026/10/18 上午1:30:00
+// @Desc 在作用域结束时记录耗时，发生 panic 时同样记录
+
+package logging
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	timerNameKey     = "timer"
+	timerDurationKey = "duration_ms"
+	timerPanicKey    = "panic"
+)
+
+// NewScopedTimer 记录开始时间并返回用于 defer 的函数，该函数以 level 输出 name 的耗时 duration_ms：
+//
+//	defer logging.NewScopedTimer("load config", zerolog.DebugLevel)()
+//
+// 外层函数 panic 时同样输出耗时并附带 panic 字段，随后以原来的值重新 panic；
+// 返回的函数必须直接通过 defer 调用，否则无法捕获 panic
+func NewScopedTimer(name string, level zerolog.Level, fields ...map[string]interface{}) func() {
+	start := now()
+	return func() {
+		r := recover()
+		timer := map[string]interface{}{
+			timerNameKey:     name,
+			timerDurationKey: now().Sub(start).Milliseconds(),
+		}
+		if r != nil {
+			timer[timerPanicKey] = fmt.Sprintf("%v", r)
+		}
+		// 计时字段优先于调用方传入的同名字段
+		emit(currentLogger().WithLevel(level), level, nil, name, append(fields[:len(fields):len(fields)], timer))
+		if r != nil {
+			panic(r)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestScopedTimer(t *testing.T) {
+	buf := captureOutput(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 1, 0, 0, 0, time.Local))
+	func() {
+		defer NewScopedTimer("load config", zerolog.InfoLevel, map[string]interface{}{"file": "app.yaml"})()
+		clock.Advance(1500 * time.Millisecond)
+	}()
+
+	m := decodeLine(t, buf.Bytes())
+	if m["message"] != "load config" || m["level"] != "info" || m["timer"] != "load config" || m["duration_ms"] != float64(1500) || m["file"] != "app.yaml" {
+		t.Errorf("unexpected timer entry: %v", m)
+	}
+	if _, ok := m["panic"]; ok {
+		t.Errorf("panic field should only be set on panic: %v", m)
+	}
+}
+
+func TestScopedTimerPanic(t *testing.T) {
+	buf := captureOutput(t)
+	clock := useFakeClock(t, time.Date(2026, 10, 18, 1, 0, 0, 0, time.Local))
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		defer NewScopedTimer("migrate", zerolog.WarnLevel)()
+		clock.Advance(20 * time.Millisecond)
+		panic("schema mismatch")
+	}()
+
+	if recovered != "schema mismatch" {
+		t.Errorf("timer should re-panic with the original value, got %v", recovered)
+	}
+	m := decodeLine(t, buf.Bytes())
+	if m["level"] != "warn" || m["duration_ms"] != float64(20) || m["panic"] != "schema mismatch" {
+		t.Errorf("timer should log the elapsed time before re-panicking: %v", m)
+	}
+}
@@ -0,0 +1,104 @@
+// @Author Clover
+// @Data 2026/10/17 下午2:30:00
+// @Desc W3C traceparent 解析与跨服务关联字段
+
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// 跟踪上下文输出的字段名
+const (
+	traceIDKey    = "trace_id"
+	spanIDKey     = "span_id"
+	traceFlagsKey = "trace_flags"
+)
+
+// traceContext 从 traceparent 中解析出的跟踪信息
+type traceContext struct {
+	traceID string // 32 位小写十六进制
+	spanID  string // 16 位小写十六进制
+	flags   string // 2 位小写十六进制
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceparent 解析 W3C traceparent 请求头（如 00-<trace-id>-<span-id>-<flags>）并存入 ctx，
+// 之后通过 InfoCtx 等函数输出的日志会携带 trace_id、span_id 与 trace_flags 字段。
+// 请求头为空或无效时生成新的 trace-id，保证服务内部的日志仍然可以关联；无效的请求头会输出一条 Debug 日志
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	tc, ok := parseTraceparent(header)
+	if !ok {
+		if header != "" {
+			Debug("Ignoring invalid traceparent header", map[string]interface{}{"traceparent": header})
+		}
+		tc = traceContext{traceID: randomHex(16), spanID: randomHex(8), flags: "00"}
+	}
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceparentFromContext 返回 ctx 中的跟踪信息对应的 traceparent 请求头，用于转发给下游的 HTTP/gRPC 调用
+// ctx 中没有跟踪信息时返回空字符串
+func TraceparentFromContext(ctx context.Context) string {
+	tc, ok := ctx.Value(traceContextKey{}).(traceContext)
+	if !ok {
+		return ""
+	}
+	return "00-" + tc.traceID + "-" + tc.spanID + "-" + tc.flags
+}
+
+// parseTraceparent 按 W3C Trace Context 规范解析 traceparent，
+// 未知版本只要前四段格式正确也可以接受
+func parseTraceparent(header string) (traceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return traceContext{}, false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return traceContext{}, false
+	}
+	if !isLowerHex(traceID, 32) || !isLowerHex(spanID, 16) || !isLowerHex(flags, 2) {
+		return traceContext{}, false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return traceContext{}, false
+	}
+	return traceContext{traceID: traceID, spanID: spanID, flags: flags}, true
+}
+
+// isLowerHex 判断 s 是否为长度为 n 的小写十六进制字符串
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex 使用 crypto/rand 生成 n 字节的十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return strings.Repeat("0", n*2)
+	}
+	return hex.EncodeToString(b)
+}
+
+// traceFields 返回 ctx 中的跟踪字段，没有跟踪信息时返回 nil
+func traceFields(ctx context.Context) map[string]interface{} {
+	tc, ok := ctx.Value(traceContextKey{}).(traceContext)
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{traceIDKey: tc.traceID, spanIDKey: tc.spanID, traceFlagsKey: tc.flags}
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTraceparent(t *testing.T) {
+	buf := captureOutput(t)
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	ctx := ContextWithTraceparent(context.Background(), header)
+	if got := TraceparentFromContext(ctx); got != header {
+		t.Errorf("TraceparentFromContext = %q, want %q", got, header)
+	}
+	InfoCtx(ctx, "request handled", map[string]interface{}{"span_id": "override"})
+	m := decodeLine(t, buf.Bytes())
+	if m["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || m["trace_flags"] != "01" || m["span_id"] != "override" {
+		t.Errorf("unexpected trace fields: %v", m)
+	}
+
+	// 无效的请求头输出一条 Debug 日志并生成新的 trace-id
+	buf.Reset()
+	ctx = ContextWithTraceparent(context.Background(), "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || decodeLine(t, []byte(lines[0]))["level"] != "debug" {
+		t.Errorf("expected a single debug notice, got %q", buf.String())
+	}
+	tp := TraceparentFromContext(ctx)
+	if _, ok := parseTraceparent(tp); !ok || strings.Contains(tp, "00000000000000000000000000000000") {
+		t.Errorf("expected a generated traceparent, got %q", tp)
+	}
+
+	// 缺少请求头时静默生成
+	buf.Reset()
+	if tp := TraceparentFromContext(ContextWithTraceparent(context.Background(), "")); tp == "" || buf.Len() != 0 {
+		t.Errorf("missing header should mint a trace id silently: %q %q", tp, buf.String())
+	}
+	if TraceparentFromContext(context.Background()) != "" {
+		t.Error("context without trace should return an empty traceparent")
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		header string
+		ok     bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4bf92f3577b34da6-00f067aa0ba902b7-01", false},
+		{"garbage", false},
+	}
+	for _, c := range cases {
+		if _, ok := parseTraceparent(c.header); ok != c.ok {
+			t.Errorf("parseTraceparent(%q) ok = %v, want %v", c.header, ok, c.ok)
+		}
+	}
+}
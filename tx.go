@@ -0,0 +1,117 @@
+// @Author Clover
+// @Data 2026/10/17 下午7:50:00
+// @Desc 数据库事务日志
+
+package logging
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	txIDKey       = "tx_id"
+	txActionKey   = "tx_action"
+	txDurationKey = "duration_ms"
+)
+
+var (
+	txStartsMu sync.Mutex
+	txStarts   = make(map[string]time.Time) // LogBeginTx 记录的事务开始时间，提交或回滚时删除
+)
+
+// LogBeginTx 输出事务开始的 Debug 日志，并记录开始时间用于计算 LogCommitTx/LogRollbackTx 的 duration_ms
+func LogBeginTx(ctx context.Context, txID string, fields ...map[string]interface{}) {
+	txStartsMu.Lock()
+	txStarts[txID] = now()
+	txStartsMu.Unlock()
+	logTx(ctx, txID, "begin", "transaction begin", -1, fields)
+}
+
+// LogCommitTx 输出事务提交的 Debug 日志，之前调用过 LogBeginTx 时附带 duration_ms
+func LogCommitTx(ctx context.Context, txID string, fields ...map[string]interface{}) {
+	logTx(ctx, txID, "commit", "transaction commit", endTx(txID), fields)
+}
+
+// LogRollbackTx 输出事务回滚的 Debug 日志，之前调用过 LogBeginTx 时附带 duration_ms
+func LogRollbackTx(ctx context.Context, txID string, fields ...map[string]interface{}) {
+	logTx(ctx, txID, "rollback", "transaction rollback", endTx(txID), fields)
+}
+
+// endTx 删除事务的开始时间并返回持续时间，未记录开始时间时返回 -1
+func endTx(txID string) time.Duration {
+	txStartsMu.Lock()
+	defer txStartsMu.Unlock()
+	start, ok := txStarts[txID]
+	if !ok {
+		return -1
+	}
+	delete(txStarts, txID)
+	return now().Sub(start)
+}
+
+// logTx 输出事务日志，tx_id、tx_action 与 duration_ms 优先于调用方传入的同名字段
+func logTx(ctx context.Context, txID, action, msg string, d time.Duration, fields []map[string]interface{}) {
+	tx := map[string]interface{}{txIDKey: txID, txActionKey: action}
+	if d >= 0 {
+		tx[txDurationKey] = d.Milliseconds()
+	}
+	DebugCtx(ctx, msg, append(fields[:len(fields):len(fields)], tx)...)
+}
+
+// TxLogger 为同一事务的日志自动添加 tx_id，并在提交或回滚时计算 duration_ms
+type TxLogger struct {
+	txID  string
+	start time.Time
+}
+
+// NewTxLogger 创建事务日志记录器，持续时间从创建时开始计算
+func NewTxLogger(txID string) *TxLogger {
+	return &TxLogger{txID: txID, start: now()}
+}
+
+// ID 返回事务 ID
+func (tl *TxLogger) ID() string {
+	return tl.txID
+}
+
+// Begin 输出事务开始的 Debug 日志
+func (tl *TxLogger) Begin(ctx context.Context, fields ...map[string]interface{}) {
+	logTx(ctx, tl.txID, "begin", "transaction begin", -1, fields)
+}
+
+// Commit 输出事务提交的 Debug 日志，附带从创建开始的 duration_ms
+func (tl *TxLogger) Commit(ctx context.Context, fields ...map[string]interface{}) {
+	logTx(ctx, tl.txID, "commit", "transaction commit", now().Sub(tl.start), fields)
+}
+
+// Rollback 输出事务回滚的 Debug 日志，附带从创建开始的 duration_ms
+func (tl *TxLogger) Rollback(ctx context.Context, fields ...map[string]interface{}) {
+	logTx(ctx, tl.txID, "rollback", "transaction rollback", now().Sub(tl.start), fields)
+}
+
+func (tl *TxLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	DebugCtx(ctx, msg, tl.with(fields)...)
+}
+
+func (tl *TxLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	InfoCtx(ctx, msg, tl.with(fields)...)
+}
+
+func (tl *TxLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	WarnCtx(ctx, msg, tl.with(fields)...)
+}
+
+func (tl *TxLogger) Error(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	ErrorCtx(ctx, msg, tl.with(fields)...)
+}
+
+func (tl *TxLogger) ErrorWithErr(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+	ErrorWithErrCtx(ctx, err, msg, tl.with(fields)...)
+}
+
+// with 在调用方字段之后追加 tx_id
+func (tl *TxLogger) with(fields []map[string]interface{}) []map[string]interface{} {
+	return append(fields[:len(fields):len(fields)], map[string]interface{}{txIDKey: tl.txID})
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTxLogging(t *testing.T) {
+	buf := captureOutput(t)
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	clock := useFakeClock(t, time.Date(2024, 7, 18, 15, 0, 0, 0, time.Local))
+	ctx := context.Background()
+
+	LogBeginTx(ctx, "tx-1", map[string]interface{}{"isolation": "serializable"})
+	clock.skip(25 * time.Millisecond)
+	LogCommitTx(ctx, "tx-1", map[string]interface{}{"tx_id": "ignored", "rows": 3})
+	LogRollbackTx(ctx, "tx-unknown")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	begin, commit, rollback := lines[0], lines[1], lines[2]
+	if begin["level"] != "debug" || begin["tx_id"] != "tx-1" || begin["tx_action"] != "begin" || begin["isolation"] != "serializable" {
+		t.Errorf("unexpected begin: %v", begin)
+	}
+	if _, ok := begin["duration_ms"]; ok {
+		t.Errorf("begin should not have a duration: %v", begin)
+	}
+	if commit["tx_id"] != "tx-1" || commit["tx_action"] != "commit" || commit["duration_ms"] != float64(25) || commit["rows"] != float64(3) {
+		t.Errorf("unexpected commit: %v", commit)
+	}
+	if rollback["tx_id"] != "tx-unknown" || rollback["tx_action"] != "rollback" {
+		t.Errorf("unexpected rollback: %v", rollback)
+	}
+	if _, ok := rollback["duration_ms"]; ok {
+		t.Errorf("rollback without begin should not have a duration: %v", rollback)
+	}
+	txStartsMu.Lock()
+	open := len(txStarts)
+	txStartsMu.Unlock()
+	if open != 0 {
+		t.Errorf("finished transactions should be forgotten, %d left", open)
+	}
+}
+
+func TestTxLogger(t *testing.T) {
+	buf := captureOutput(t)
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	clock := useFakeClock(t, time.Date(2024, 7, 18, 15, 0, 0, 0, time.Local))
+	ctx := context.Background()
+
+	tx := NewTxLogger("tx-2")
+	tx.Begin(ctx)
+	tx.Info(ctx, "inserting order", map[string]interface{}{"order_id": 7})
+	clock.skip(time.Second)
+	tx.Rollback(ctx, map[string]interface{}{"reason": "conflict"})
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if line["tx_id"] != "tx-2" {
+			t.Errorf("missing tx_id: %v", line)
+		}
+	}
+	if lines[1]["level"] != "info" || lines[1]["order_id"] != float64(7) {
+		t.Errorf("unexpected info line: %v", lines[1])
+	}
+	if lines[2]["tx_action"] != "rollback" || lines[2]["duration_ms"] != float64(1000) || lines[2]["reason"] != "conflict" {
+		t.Errorf("unexpected rollback line: %v", lines[2])
+	}
+}
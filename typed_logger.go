@@ -0,0 +1,77 @@
+// @Author Clover
+// @Data 2026/10/18 上午5:10:00
+// @Desc 携带强类型上下文的日志记录器
+
+package logging
+
+import "github.com/rs/zerolog"
+
+// TypedLogger 携带类型为 T 的上下文，每条日志输出前调用 Encoder 将上下文转换为字段，
+// 避免在各处手写 map[string]interface{}，例如 T 为包含租户与用户的结构体
+type TypedLogger[T any] struct {
+	encoder func(T) map[string]interface{}
+	parent  *TypedLogger[T]
+	ctx     T
+	hasCtx  bool
+}
+
+// NewTypedLogger 创建没有上下文的 TypedLogger，encoder 为 nil 时上下文不产生字段
+func NewTypedLogger[T any](encoder func(T) map[string]interface{}) *TypedLogger[T] {
+	return &TypedLogger[T]{encoder: encoder}
+}
+
+// With 返回携带 ctx 的子日志记录器，父日志记录器不受影响；
+// 子日志记录器的日志同时包含各级上下文的字段，同名字段以较近的上下文为准
+func (tl *TypedLogger[T]) With(ctx T) *TypedLogger[T] {
+	return &TypedLogger[T]{encoder: tl.encoder, parent: tl, ctx: ctx, hasCtx: true}
+}
+
+// Context 返回最近一次 With 设置的上下文，没有上下文时返回零值与 false
+func (tl *TypedLogger[T]) Context() (T, bool) {
+	return tl.ctx, tl.hasCtx
+}
+
+func (tl *TypedLogger[T]) Debug(msg string, fields ...map[string]interface{}) {
+	emit(currentLogger().Debug(), zerolog.DebugLevel, nil, msg, tl.with(fields))
+}
+
+func (tl *TypedLogger[T]) Info(msg string, fields ...map[string]interface{}) {
+	emit(currentLogger().Info(), zerolog.InfoLevel, nil, msg, tl.with(fields))
+}
+
+func (tl *TypedLogger[T]) Warn(msg string, fields ...map[string]interface{}) {
+	emit(currentLogger().Warn(), zerolog.WarnLevel, nil, msg, tl.with(fields))
+}
+
+func (tl *TypedLogger[T]) Error(msg string, fields ...map[string]interface{}) {
+	emit(currentLogger().Error(), zerolog.ErrorLevel, nil, msg, tl.with(fields))
+}
+
+func (tl *TypedLogger[T]) ErrorWithErr(err error, msg string, fields ...map[string]interface{}) {
+	if err == nil && skipNilErrorsEnabled() {
+		return
+	}
+	emit(withErr(currentLogger().Error(), err), zerolog.ErrorLevel, err, msg, tl.with(fields))
+}
+
+// with 将各级上下文编码为字段，放在调用方的字段之前，调用方的同名字段优先
+func (tl *TypedLogger[T]) with(fields []map[string]interface{}) []map[string]interface{} {
+	if tl.encoder == nil {
+		return fields
+	}
+	var ctxFields []map[string]interface{}
+	for l := tl; l != nil; l = l.parent {
+		if l.hasCtx {
+			ctxFields = append(ctxFields, l.encoder(l.ctx))
+		}
+	}
+	if len(ctxFields) == 0 {
+		return fields
+	}
+	// 由远到近排列，较近的上下文覆盖较远的上下文
+	merged := make([]map[string]interface{}, 0, len(ctxFields)+len(fields))
+	for i := len(ctxFields) - 1; i >= 0; i-- {
+		merged = append(merged, ctxFields[i])
+	}
+	return append(merged, fields...)
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+)
+
+type tenantCtx struct {
+	Tenant string
+	User   string
+}
+
+func encodeTenant(c tenantCtx) map[string]interface{} {
+	m := map[string]interface{}{"tenant": c.Tenant}
+	if c.User != "" {
+		m["user"] = c.User
+	}
+	return m
+}
+
+func TestTypedLogger(t *testing.T) {
+	buf := captureOutput(t)
+	root := NewTypedLogger(encodeTenant)
+	tenant := root.With(tenantCtx{Tenant: "acme"})
+	user := tenant.With(tenantCtx{Tenant: "acme-eu", User: "u1"})
+
+	user.Info("order placed", map[string]interface{}{"order": 7, "user": "override"})
+	m := decodeLine(t, buf.Bytes())
+	if m["tenant"] != "acme-eu" || m["user"] != "override" || m["order"] != float64(7) || m["level"] != "info" {
+		t.Errorf("unexpected entry: %v", m)
+	}
+
+	// 父日志记录器不受子日志记录器的上下文影响
+	buf.Reset()
+	tenant.ErrorWithErr(errors.New("boom"), "failed")
+	m = decodeLine(t, buf.Bytes())
+	if m["tenant"] != "acme" || m["user"] != nil || m["error"] != "boom" || m["level"] != "error" {
+		t.Errorf("unexpected entry: %v", m)
+	}
+
+	buf.Reset()
+	root.Warn("no context")
+	m = decodeLine(t, buf.Bytes())
+	if m["tenant"] != nil || m["message"] != "no context" {
+		t.Errorf("root logger should not carry context: %v", m)
+	}
+	if c, ok := user.Context(); !ok || c.User != "u1" {
+		t.Errorf("Context() = %v, %v", c, ok)
+	}
+}
@@ -0,0 +1,142 @@
+// @Author Clover
+// @Data 2026/10/18 上午1:10:00
+// @Desc 将日志写入本地日志守护进程监听的 UNIX 域套接字
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// 套接字类型
+const (
+	UnixNetworkStream   = "unix"     // 面向连接的流式套接字
+	UnixNetworkDatagram = "unixgram" // 数据报套接字，每条日志一个数据报
+)
+
+// 日志之间的分帧方式
+const (
+	FramingLF         = "lf"          // 每条日志以换行结束（默认）
+	FramingNUL        = "nul"         // 每条日志以 NUL 字节结束，例如 Graylog GELF
+	FramingOctetCount = "octet-count" // 每条日志前加上 "长度 空格"，即 RFC 6587 的 octet counting
+)
+
+// UnixSocketConfig UNIX 域套接字输出，SocketPath 为空时不开启
+type UnixSocketConfig struct {
+	SocketPath string // 日志守护进程监听的套接字路径
+	Network    string // unix 或 unixgram，默认 unix
+	Framing    string // lf、nul 或 octet-count，默认 lf
+}
+
+var unixSocket *unixSocketWriter // 当前的套接字输出，未开启时为 nil，由 stateMu 保护
+
+// validUnixSocketConfig 检查套接字类型与分帧方式
+func validUnixSocketConfig(c UnixSocketConfig) error {
+	switch c.Network {
+	case "", UnixNetworkStream, UnixNetworkDatagram:
+	default:
+		return fmt.Errorf("unknown unix socket network %q", c.Network)
+	}
+	switch c.Framing {
+	case "", FramingLF, FramingNUL, FramingOctetCount:
+	default:
+		return fmt.Errorf("unknown unix socket framing %q", c.Framing)
+	}
+	return nil
+}
+
+// unixSocketWriter 保持与日志守护进程的连接，写入失败时重新连接并重试一次，
+// 守护进程暂时不可用时返回错误，下一次写入时再尝试连接
+type unixSocketWriter struct {
+	network string
+	path    string
+	framing string
+
+	mu   sync.Mutex
+	conn net.Conn
+	buf  []byte // 分帧后的日志，复用以减少分配
+}
+
+// newUnixSocketWriter 创建套接字输出并尝试连接，连接失败时仍返回可用的输出，同时返回连接错误
+func newUnixSocketWriter(c UnixSocketConfig) (*unixSocketWriter, error) {
+	w := &unixSocketWriter{network: c.Network, path: c.SocketPath, framing: c.Framing}
+	if w.network == "" {
+		w.network = UnixNetworkStream
+	}
+	if w.framing == "" {
+		w.framing = FramingLF
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.dial(); err != nil {
+		return w, fmt.Errorf("unix socket %s: %w", w.path, err)
+	}
+	return w, nil
+}
+
+// dial 建立连接，调用方需持有 mu
+func (w *unixSocketWriter) dial() error {
+	conn, err := net.Dial(w.network, w.path)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write 按分帧方式写入一条日志
+func (w *unixSocketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = w.frame(w.buf[:0], p)
+	if w.conn == nil {
+		if err := w.dial(); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := w.conn.Write(w.buf); err != nil {
+		// 守护进程重启后旧连接失效，重新连接后重试一次
+		w.conn.Close()
+		w.conn = nil
+		if err := w.dial(); err != nil {
+			return 0, err
+		}
+		if _, err := w.conn.Write(w.buf); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// frame 去掉 zerolog 添加的换行后按分帧方式追加到 dst
+func (w *unixSocketWriter) frame(dst, p []byte) []byte {
+	msg := bytes.TrimRight(p, "\n")
+	switch w.framing {
+	case FramingNUL:
+		dst = append(append(dst, msg...), 0)
+	case FramingOctetCount:
+		dst = strconv.AppendInt(dst, int64(len(msg)), 10)
+		dst = append(append(dst, ' '), msg...)
+	default:
+		dst = append(append(dst, msg...), '\n')
+	}
+	return dst
+}
+
+// Close 关闭连接
+func (w *unixSocketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
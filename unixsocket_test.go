@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// socketPath 返回较短的临时套接字路径，避免超过 UNIX 域套接字路径的长度限制
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "log.sock")
+}
+
+// acceptLines 接受一个连接并将收到的每一行发送到返回的通道，返回的函数关闭监听与已接受的连接
+func acceptLines(t *testing.T, ln net.Listener) (<-chan string, func()) {
+	t.Helper()
+	lines := make(chan string, 16)
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+	return lines, func() {
+		ln.Close()
+		if conn, ok := <-accepted; ok {
+			conn.Close()
+		}
+	}
+}
+
+func receive(t *testing.T, lines <-chan string) string {
+	t.Helper()
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for log line")
+		return ""
+	}
+}
+
+func TestUnixSocketOutput(t *testing.T) {
+	path := socketPath(t)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, stop := acceptLines(t, ln)
+	defer stop()
+
+	config := Config{ProjectKey: "project", ProjectName: "app", UnixSocket: UnixSocketConfig{SocketPath: path}}
+	if err := InitLogger(config); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+	Info("to daemon", map[string]interface{}{"user": "u1"})
+
+	m := decodeLine(t, []byte(receive(t, lines)))
+	if m["message"] != "to daemon" || m["user"] != "u1" || m["project"] != "app" {
+		t.Errorf("unexpected entry: %v", m)
+	}
+	sinks := sinkHealth()
+	if len(sinks) != 1 || sinks[0].Name != "unix:"+path || !sinks[0].Healthy {
+		t.Errorf("unix socket should be tracked as a sink: %+v", sinks)
+	}
+}
+
+func TestUnixSocketReconnect(t *testing.T) {
+	path := socketPath(t)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := newUnixSocketWriter(UnixSocketConfig{SocketPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	lines, stop := acceptLines(t, ln)
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, lines); got != "first" {
+		t.Fatalf("got %q", got)
+	}
+
+	// 模拟守护进程重启：关闭旧的监听与连接后在同一路径重新监听
+	stop()
+	os.Remove(path)
+	if _, err := w.Write([]byte("lost\n")); err != nil {
+		t.Logf("write while daemon is down: %v", err)
+	}
+	ln, err = net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, stop = acceptLines(t, ln)
+	defer stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		// 对端关闭后第一次写入可能仍然成功，持续写入直到新连接收到日志
+		_, _ = w.Write([]byte("second\n"))
+		select {
+		case got := <-lines:
+			if got != "second" {
+				t.Errorf("got %q", got)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("writer did not reconnect")
+		}
+	}
+}
+
+func TestUnixSocketDatagram(t *testing.T) {
+	path := socketPath(t)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	w, err := newUnixSocketWriter(UnixSocketConfig{SocketPath: path, Network: UnixNetworkDatagram, Framing: FramingNUL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte(`{"message":"one"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != `{"message":"one"}`+"\x00" {
+		t.Errorf("datagram = %q", got)
+	}
+}
+
+func TestUnixSocketFraming(t *testing.T) {
+	cases := map[string]string{
+		FramingLF:         "{\"a\":1}\n",
+		FramingNUL:        "{\"a\":1}\x00",
+		FramingOctetCount: "7 {\"a\":1}",
+	}
+	for framing, want := range cases {
+		w := &unixSocketWriter{framing: framing}
+		if got := string(w.frame(nil, []byte("{\"a\":1}\n"))); got != want {
+			t.Errorf("%s: got %q, want %q", framing, got, want)
+		}
+	}
+}
+
+func TestUnixSocketInvalidConfig(t *testing.T) {
+	err := InitLogger(Config{UnixSocket: UnixSocketConfig{SocketPath: "/tmp/x.sock", Framing: "syslog"}})
+	defer Close()
+	if err == nil || !strings.Contains(err.Error(), "framing") {
+		t.Errorf("expected framing error, got %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build debug
+
+package logging
+
+import "github.com/rs/zerolog"
+
+// debugBuild 是否为 -tags debug 构建，内部的 debugTrace 调用需放在 if debugBuild 中，发布构建时整段被编译器删除
+const debugBuild = true
+
+// VerboseDebug 输出 Trace 级别的详细调试日志，仅在 -tags debug 构建时生效
+func VerboseDebug(msg string, fields ...map[string]interface{}) {
+	emit(currentLogger().Trace(), zerolog.TraceLevel, nil, msg, fields)
+}
+
+// debugTrace 输出日志文件清除、缓冲区刷新与监控等内部操作的 Trace 日志，不经过用户字段规则
+func debugTrace(msg string, fields map[string]interface{}) {
+	currentLogger().Trace().Fields(fields).Str("component", "logging").Msg(msg)
+}
@@ -0,0 +1,30 @@
+//go:build debug
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestVerboseDebug(t *testing.T) {
+	prevLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prevLevel) })
+	buf := captureOutput(t)
+
+	VerboseDebug("expensive detail", map[string]interface{}{"k": "v"})
+	debugTrace("internal step", map[string]interface{}{"step": 1})
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %v", len(lines), lines)
+	}
+	if lines[0]["level"] != "trace" || lines[0]["message"] != "expensive detail" || lines[0]["k"] != "v" {
+		t.Errorf("unexpected verbose line: %v", lines[0])
+	}
+	if lines[1]["component"] != "logging" || lines[1]["step"] != float64(1) {
+		t.Errorf("unexpected internal trace line: %v", lines[1])
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !debug
+
+package logging
+
+// debugBuild 是否为 -tags debug 构建
+const debugBuild = false
+
+// VerboseDebug 发布构建中为空函数，会被内联消除，没有任何开销；使用 -tags debug 构建时输出 Trace 级别日志
+func VerboseDebug(msg string, fields ...map[string]interface{}) {}
+
+func debugTrace(msg string, fields map[string]interface{}) {}
@@ -0,0 +1,16 @@
+//go:build !debug
+
+package logging
+
+import "testing"
+
+func TestVerboseDebugIsNoOp(t *testing.T) {
+	buf := captureOutput(t)
+	VerboseDebug("expensive detail", map[string]interface{}{"k": "v"})
+	if buf.Len() != 0 {
+		t.Errorf("VerboseDebug should not emit in release builds: %s", buf.String())
+	}
+	if n := testing.AllocsPerRun(100, func() { VerboseDebug("expensive detail") }); n != 0 {
+		t.Errorf("VerboseDebug should not allocate in release builds, got %v allocs", n)
+	}
+}
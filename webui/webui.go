@@ -0,0 +1,231 @@
+// Package webui
+// @Author Clover
+// @Data 2026/10/17 上午11:30:00
+// @Desc 通过 HTTP 查看日志文件并实时跟踪新日志
+package webui
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Clov614/logging"
+	"github.com/rs/zerolog"
+)
+
+// shutdownTimeout Stop 等待请求结束的最长时间
+const shutdownTimeout = 5 * time.Second
+
+// ErrServerStarted 重复调用 Start 时返回
+var ErrServerStarted = errors.New("webui: server already started")
+
+// WebServer 提供日志查看页面，页面通过 server-sent events 实时接收日志文件中的新日志
+type WebServer struct {
+	addr    string
+	logPath string
+	user    string
+	pass    string
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	cancel   context.CancelFunc // 结束所有事件流
+}
+
+// NewWebServer 创建在 addr 上提供 logPath 查看页面的服务
+func NewWebServer(addr string, logPath string) *WebServer {
+	return &WebServer{addr: addr, logPath: logPath}
+}
+
+// WithBasicAuth 要求所有请求使用 HTTP Basic 认证
+func (s *WebServer) WithBasicAuth(user, password string) *WebServer {
+	s.user, s.pass = user, password
+	return s
+}
+
+// Start 开始监听并在后台提供服务
+func (s *WebServer) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.server != nil {
+		return ErrServerStarted
+	}
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("webui: listen %s: %w", s.addr, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.server = &http.Server{
+		Handler:     s.Handler(),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	s.listener = ln
+	s.cancel = cancel
+	go s.server.Serve(ln)
+	return nil
+}
+
+// Addr 返回实际监听的地址，未启动时返回配置的地址
+func (s *WebServer) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+// Stop 结束所有事件流并关闭服务
+// 超过 shutdownTimeout 仍未关闭的连接（例如客户端预先建立但未发送请求的连接）会被强制关闭
+func (s *WebServer) Stop() error {
+	s.mu.Lock()
+	server, cancel := s.server, s.cancel
+	s.server, s.listener, s.cancel = nil, nil, nil
+	s.mu.Unlock()
+	if server == nil {
+		return nil
+	}
+	cancel()
+	ctx, done := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer done()
+	if err := server.Shutdown(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return server.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+// Handler 返回查看页面（/）与事件流（/events）的处理器，可挂载到已有的 HTTP 服务上
+func (s *WebServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveIndex)
+	mux.HandleFunc("/events", s.serveEvents)
+	return s.basicAuth(mux)
+}
+
+func (s *WebServer) basicAuth(next http.Handler) http.Handler {
+	if s.user == "" && s.pass == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(s.user)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(pass), []byte(s.pass)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="logs", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *WebServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, indexHTML)
+}
+
+// serveEvents 从头发送日志文件中的日志，随后持续推送新追加的日志，每条日志为一个 SSE 事件
+func (s *WebServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	tailer := logging.NewFileTailer(s.logPath)
+	for entry := range tailer.Entries(r.Context()) {
+		data, err := json.Marshal(eventData(entry))
+		if err != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+	if err := tailer.Err(); err != nil {
+		fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+		flusher.Flush()
+	}
+}
+
+// eventData 将日志条目还原为 JSON 对象
+func eventData(entry logging.LogEntry) map[string]interface{} {
+	data := make(map[string]interface{}, len(entry.Fields)+2)
+	for k, v := range entry.Fields {
+		data[k] = v
+	}
+	if entry.Level != zerolog.NoLevel {
+		data[zerolog.LevelFieldName] = entry.Level.String()
+	}
+	data[zerolog.MessageFieldName] = entry.Message
+	return data
+}
+
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Logs</title>
+<style>
+body { font-family: monospace; margin: 0; background: #1e1e1e; color: #ddd; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 2px 8px; vertical-align: top; white-space: pre-wrap; }
+tr.trace, tr.debug { color: #888; }
+tr.info { color: #ddd; }
+tr.warn { color: #e5c07b; }
+tr.error { color: #e06c75; }
+tr.fatal, tr.panic { color: #fff; background: #a33; }
+.fields { color: #61afef; }
+#status { position: fixed; top: 0; right: 0; padding: 4px 8px; background: #333; }
+</style>
+</head>
+<body>
+<div id="status">connecting</div>
+<table><tbody id="logs"></tbody></table>
+<script>
+const logs = document.getElementById("logs");
+const status = document.getElementById("status");
+const source = new EventSource("events");
+source.onopen = () => { status.textContent = "live"; };
+source.onerror = () => { status.textContent = "disconnected"; };
+source.onmessage = (e) => {
+	let entry;
+	try { entry = JSON.parse(e.data); } catch (err) { return; }
+	const {time = "", level = "", message = "", ...fields} = entry;
+	const row = document.createElement("tr");
+	row.className = level;
+	for (const text of [time, level.toUpperCase(), message]) {
+		const cell = document.createElement("td");
+		cell.textContent = text;
+		row.appendChild(cell);
+	}
+	const cell = document.createElement("td");
+	cell.className = "fields";
+	cell.textContent = Object.entries(fields).map(([k, v]) => k + "=" + JSON.stringify(v)).join(" ");
+	row.appendChild(cell);
+	const atBottom = window.innerHeight + window.scrollY >= document.body.offsetHeight - 4;
+	logs.appendChild(row);
+	if (atBottom) { window.scrollTo(0, document.body.scrollHeight); }
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,114 @@
+package webui
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(`{"level":"info","time":"2024-07-18 15:04:05","message":"started","port":8080}`+"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	s := NewWebServer("127.0.0.1:0", path).WithBasicAuth("admin", "secret")
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Stop() })
+	if err := s.Start(); err != ErrServerStarted {
+		t.Errorf("expected ErrServerStarted, got %v", err)
+	}
+	base := "http://" + s.Addr()
+
+	resp, err := http.Get(base + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("expected basic auth challenge, got %d", resp.StatusCode)
+	}
+
+	get := func(p string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, base+p, nil)
+		req.SetBasicAuth("admin", "secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	resp = get("/")
+	page := make([]byte, 4096)
+	n, _ := resp.Body.Read(page)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page[:n]), "EventSource") {
+		t.Errorf("unexpected index page: %d %q", resp.StatusCode, page[:n])
+	}
+
+	resp = get("/events")
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	events := make(chan map[string]interface{})
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var m map[string]interface{}
+			if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &m) == nil {
+				events <- m
+			}
+		}
+		close(events)
+	}()
+	next := func() map[string]interface{} {
+		t.Helper()
+		select {
+		case m := <-events:
+			return m
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return nil
+		}
+	}
+	if m := next(); m["message"] != "started" || m["level"] != "info" || m["port"] != float64(8080) {
+		t.Errorf("unexpected existing entry: %v", m)
+	}
+
+	// 新追加的日志实时推送
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"level":"error","message":"request failed"}` + "\n")
+	f.Close()
+	if m := next(); m["message"] != "request failed" || m["level"] != "error" {
+		t.Errorf("unexpected live entry: %v", m)
+	}
+
+	// Stop 结束事件流；先关闭客户端的空闲连接，避免 Shutdown 等待尚未发送请求的连接
+	http.DefaultClient.CloseIdleConnections()
+	if err := s.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("unexpected event after stop")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream should end on stop")
+	}
+}
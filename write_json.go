@@ -0,0 +1,28 @@
+// @Author Clover
+// @Data 2026/10/18 上午4:30:00
+// @Desc 将任意值作为嵌套的 JSON 对象写入日志字段
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// WriteJSON 以 level 输出日志，并将 v 编码为嵌套的 JSON 对象写入 key 字段，而不是转义后的字符串；
+// v 无法编码为 JSON（例如包含 chan、func 或循环引用）时仍输出日志，key 字段为 "<json error: ...>"，并返回编码错误
+func WriteJSON(level zerolog.Level, msg string, key string, v interface{}, fields ...map[string]interface{}) error {
+	var value interface{}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		value = fmt.Sprintf("<json error: %v>", err)
+	} else {
+		value = json.RawMessage(raw)
+	}
+	// 与其他字段一起经过字段规则，key 与调用方的字段重名时以 v 为准
+	fields = append(fields[:len(fields):len(fields)], map[string]interface{}{key: value})
+	emit(currentLogger().WithLevel(level), level, nil, msg, fields)
+	return err
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestWriteJSON(t *testing.T) {
+	buf := captureOutput(t)
+	type order struct {
+		ID    int      `json:"id"`
+		Items []string `json:"items"`
+	}
+	if err := WriteJSON(zerolog.InfoLevel, "order placed", "order", order{ID: 7, Items: []string{"a", "b"}}, map[string]interface{}{"user": "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	m := decodeLine(t, buf.Bytes())
+	nested, ok := m["order"].(map[string]interface{})
+	if !ok || nested["id"] != float64(7) || len(nested["items"].([]interface{})) != 2 {
+		t.Errorf("order should be a nested object: %v", m["order"])
+	}
+	if m["user"] != "u1" || m["level"] != "info" || m["message"] != "order placed" {
+		t.Errorf("unexpected entry: %v", m)
+	}
+}
+
+func TestWriteJSONUnmarshallable(t *testing.T) {
+	buf := captureOutput(t)
+	err := WriteJSON(zerolog.WarnLevel, "bad payload", "payload", map[string]interface{}{"fn": func() {}})
+	if err == nil {
+		t.Fatal("expected marshal error")
+	}
+	m := decodeLine(t, buf.Bytes())
+	if s, _ := m["payload"].(string); !strings.HasPrefix(s, "<json error: ") || m["message"] != "bad payload" {
+		t.Errorf("event should still be logged with the error: %v", m)
+	}
+}
@@ -0,0 +1,216 @@
+// @Author Clover
+// @Data 2026/10/18 上午3:50:00
+// @Desc 按阶段抽样统计日志写入路径的耗时分布
+
+package logging
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// defaultStatsSampleRate 默认每 64 条日志计时一次
+const defaultStatsSampleRate = 64
+
+// 写入路径的阶段，输出目标的写入为 write:<输出目标名称>
+const (
+	StageAssemble = "assemble" // 合并字段、动态字段、抑制、提取与字段规则
+	StageEncode   = "encode"   // 字段编码为 JSON 与消息脱敏
+	StageEmit     = "emit"     // 整个日志调用，包含级别回调与所有输出目标的写入
+)
+
+// StageStats 单个阶段的耗时分布，分位数由固定的分桶估算
+type StageStats struct {
+	Count uint64        `json:"count"` // 计时的次数
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+}
+
+// WriteStats Stats 的返回值
+type WriteStats struct {
+	SampleRate int                   `json:"sample_rate"` // 每多少条日志计时一次，0 表示未计时
+	Stages     map[string]StageStats `json:"stages"`
+}
+
+var (
+	statsRate    atomic.Int64  // 每多少条日志计时一次，0 表示关闭
+	emitTick     atomic.Uint64 // emit 的抽样计数
+	assembleHist histogram
+	encodeHist   histogram
+	emitHist     histogram
+)
+
+// setStatsSampleRate 设置抽样间隔，rate 为 0 时使用默认值，关闭统计时不计时
+func setStatsSampleRate(rate int, disabled bool) {
+	switch {
+	case disabled || rate < 0:
+		rate = 0
+	case rate == 0:
+		rate = defaultStatsSampleRate
+	}
+	statsRate.Store(int64(rate))
+}
+
+// sampleStats 本次是否计时，每个计数器独立抽样
+func sampleStats(tick *atomic.Uint64) bool {
+	rate := statsRate.Load()
+	return rate > 0 && tick.Add(1)%uint64(rate) == 0
+}
+
+// Stats 返回日志写入路径各阶段的耗时分布（p50/p95/p99），用于判断日志本身是否增加了延迟：
+// assemble、encode、emit 以及每个输出目标的 write:<名称>，只统计抽样计时的日志
+func Stats() WriteStats {
+	ws := WriteStats{
+		SampleRate: int(statsRate.Load()),
+		Stages: map[string]StageStats{
+			StageAssemble: assembleHist.stats(),
+			StageEncode:   encodeHist.stats(),
+			StageEmit:     emitHist.stats(),
+		},
+	}
+	for _, tw := range trackedWriters() {
+		ws.Stages["write:"+tw.name] = tw.hist.stats()
+	}
+	return ws
+}
+
+// ResetStats 清空所有阶段的耗时分布
+func ResetStats() {
+	assembleHist.reset()
+	encodeHist.reset()
+	emitHist.reset()
+	for _, tw := range trackedWriters() {
+		tw.hist.reset()
+	}
+}
+
+// trackedWriters 返回当前的所有输出目标
+func trackedWriters() []*trackedWriter {
+	stateMu.RLock()
+	writers := activeWriters
+	stateMu.RUnlock()
+	var tws []*trackedWriter
+	for _, w := range writers {
+		if tw, ok := w.(*trackedWriter); ok {
+			tws = append(tws, tw)
+		}
+	}
+	return tws
+}
+
+// emitTimer 为抽样的日志记录各阶段耗时，未抽样时为零值，所有方法直接返回
+type emitTimer struct {
+	start, mark time.Time
+}
+
+func startEmitTimer() emitTimer {
+	if !sampleStats(&emitTick) {
+		return emitTimer{}
+	}
+	t := time.Now()
+	return emitTimer{start: t, mark: t}
+}
+
+// stage 记录距上一阶段结束的耗时
+func (et *emitTimer) stage(h *histogram) {
+	if et.start.IsZero() {
+		return
+	}
+	t := time.Now()
+	h.observe(t.Sub(et.mark))
+	et.mark = t
+}
+
+// done 记录整个日志调用的耗时
+func (et *emitTimer) done() {
+	if et.start.IsZero() {
+		return
+	}
+	emitHist.observe(time.Since(et.start))
+}
+
+// histBuckets 分桶数，第 i 个桶的上界为 64ns<<i，最后一个桶（约 34 秒）之后为溢出桶
+const histBuckets = 30
+
+// histogram 固定分桶的耗时直方图，observe 可以并发调用且不加锁
+type histogram struct {
+	counts [histBuckets + 1]atomic.Uint64
+}
+
+// histBound 返回第 i 个桶的上界
+func histBound(i int) time.Duration {
+	return 64 << i
+}
+
+// histBucket 返回 d 所在的桶：第一个上界不小于 d 的桶
+func histBucket(d time.Duration) int {
+	for i := 0; i < histBuckets; i++ {
+		if d <= histBound(i) {
+			return i
+		}
+	}
+	return histBuckets
+}
+
+func (h *histogram) observe(d time.Duration) {
+	h.counts[histBucket(d)].Add(1)
+}
+
+func (h *histogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+}
+
+func (h *histogram) stats() StageStats {
+	var counts [histBuckets + 1]uint64
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+	}
+	return StageStats{
+		Count: sumCounts(counts[:]),
+		P50:   quantile(counts[:], 0.50),
+		P95:   quantile(counts[:], 0.95),
+		P99:   quantile(counts[:], 0.99),
+	}
+}
+
+func sumCounts(counts []uint64) uint64 {
+	var total uint64
+	for _, c := range counts {
+		total += c
+	}
+	return total
+}
+
+// quantile 估算分位数 q：找到累计次数达到 q 的桶，在桶的上下界之间按桶内位置线性插值，
+// 落在溢出桶时返回最后一个桶的上界
+func quantile(counts []uint64, q float64) time.Duration {
+	total := sumCounts(counts)
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, c := range counts {
+		if c == 0 || seen+c < rank {
+			seen += c
+			continue
+		}
+		if i == histBuckets {
+			return histBound(histBuckets - 1)
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = histBound(i - 1)
+		}
+		frac := float64(rank-seen) / float64(c)
+		return lower + time.Duration(frac*float64(histBound(i)-lower))
+	}
+	return histBound(histBuckets - 1)
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	cases := map[time.Duration]int{
+		0:                0,
+		64:               0,
+		65:               1,
+		128:              1,
+		time.Microsecond: 4, // 1000ns 落在 (512, 1024]
+		time.Hour:        histBuckets,
+	}
+	for d, want := range cases {
+		if got := histBucket(d); got != want {
+			t.Errorf("histBucket(%v) = %d, want %d", d, got, want)
+		}
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	counts := make([]uint64, histBuckets+1)
+	if got := quantile(counts, 0.5); got != 0 {
+		t.Errorf("empty histogram quantile = %v", got)
+	}
+
+	// 100 次全部落在 (512, 1024]：按桶内位置在上下界之间线性插值
+	counts[4] = 100
+	if got := quantile(counts, 0.5); got != 768 {
+		t.Errorf("p50 = %v, want 768ns", got)
+	}
+	if got := quantile(counts, 1); got != 1024 {
+		t.Errorf("p100 = %v, want 1024ns", got)
+	}
+
+	// 90 次落在第一个桶，10 次落在 (1024, 2048]
+	counts[4] = 0
+	counts[0] = 90
+	counts[5] = 10
+	if got := quantile(counts, 0.5); got > 64 {
+		t.Errorf("p50 = %v, should fall in the first bucket", got)
+	}
+	if got := quantile(counts, 0.95); got != 1536 {
+		t.Errorf("p95 = %v, want 1536ns", got)
+	}
+
+	// 溢出桶返回最后一个桶的上界
+	counts[histBuckets] = 1000
+	if got := quantile(counts, 0.99); got != histBound(histBuckets-1) {
+		t.Errorf("overflow p99 = %v", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	out := &syncBuffer{}
+	if err := InitLogger(Config{ProjectKey: "project", StatsSampleRate: 1}, WithWriters(out)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(); options = loggerOptions{}; setStatsSampleRate(0, false) })
+	ResetStats()
+
+	for i := 0; i < 10; i++ {
+		Info("timed", map[string]interface{}{"i": i})
+	}
+	st := Stats()
+	if st.SampleRate != 1 {
+		t.Errorf("sample rate = %d", st.SampleRate)
+	}
+	for _, stage := range []string{StageAssemble, StageEncode, StageEmit, "write:" + sinkName(out)} {
+		s := st.Stages[stage]
+		if s.Count != 10 || s.P50 <= 0 || s.P50 > s.P99 {
+			t.Errorf("%s: unexpected stats %+v", stage, s)
+		}
+	}
+
+	ResetStats()
+	if s := Stats().Stages[StageEmit]; s.Count != 0 {
+		t.Errorf("ResetStats should clear histograms: %+v", s)
+	}
+}
+
+func TestStatsSampling(t *testing.T) {
+	prev := baseLogger
+	baseLogger = zerolog.New(io.Discard)
+	t.Cleanup(func() { baseLogger = prev; setStatsSampleRate(0, false) })
+	setStatsSampleRate(4, false)
+	ResetStats()
+	emitTick.Store(0)
+	for i := 0; i < 40; i++ {
+		Info("sampled")
+	}
+	if got := Stats().Stages[StageEmit].Count; got != 10 {
+		t.Errorf("expected 1 in 4 events to be timed, got %d", got)
+	}
+
+	setStatsSampleRate(0, true)
+	ResetStats()
+	Info("not timed")
+	if got := Stats().Stages[StageEmit].Count; got != 0 {
+		t.Errorf("DisableMetrics should turn timing off, got %d", got)
+	}
+}
+
+// BenchmarkStats 比较关闭计时与默认抽样（每 64 条计时一次）的开销
+func BenchmarkStats(b *testing.B) {
+	prev := baseLogger
+	baseLogger = zerolog.New(newTrackedWriter("writer:discard", io.Discard))
+	b.Cleanup(func() { baseLogger = prev; setStatsSampleRate(0, false) })
+	for _, bc := range []struct {
+		name string
+		rate int
+	}{
+		{"off", -1},
+		{"sampled", defaultStatsSampleRate},
+	} {
+		setStatsSampleRate(bc.rate, false)
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Info("request handled", map[string]interface{}{"status": 200})
+			}
+		})
+	}
+}